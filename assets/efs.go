@@ -6,3 +6,6 @@ import (
 
 //go:embed "migrations"
 var Migrations embed.FS
+
+//go:embed "holidays"
+var Holidays embed.FS
@@ -0,0 +1 @@
+ALTER TABLE ROTAS DROP COLUMN STATE;
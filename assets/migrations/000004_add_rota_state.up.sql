@@ -0,0 +1,2 @@
+ALTER TABLE ROTAS
+    ADD COLUMN STATE JSONB NOT NULL DEFAULT '{}';
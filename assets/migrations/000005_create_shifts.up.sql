@@ -0,0 +1,27 @@
+-- Shifts keep the slack user id rather than referencing MEMBERS so the history survives members leaving the rota.
+CREATE TABLE SHIFTS
+(
+    ID         TEXT PRIMARY KEY   DEFAULT ('SH' || generate_uid(14)),
+    ROTA_ID    TEXT      NOT NULL,
+    USER_ID    TEXT      NOT NULL,
+    STARTS_AT  TIMESTAMP NOT NULL,
+    ENDS_AT    TIMESTAMP NOT NULL,
+    REASON     TEXT      NOT NULL,
+    METADATA   JSONB     NOT NULL,
+    CREATED_AT TIMESTAMP NOT NULL DEFAULT NOW(),
+    UPDATED_AT TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_rota_id_on_shift
+        FOREIGN KEY (ROTA_ID)
+            REFERENCES ROTAS (ID)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX idx_rota_id_and_starts_at_on_shifts ON SHIFTS (ROTA_ID, STARTS_AT);
+CREATE INDEX idx_user_id_and_starts_at_on_shifts ON SHIFTS (USER_ID, STARTS_AT);
+
+CREATE TRIGGER shifts_updated_at_trigger
+    BEFORE UPDATE
+    ON SHIFTS
+    FOR EACH ROW
+    EXECUTE PROCEDURE trigger_set_timestamp();
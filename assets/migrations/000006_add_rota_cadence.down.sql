@@ -0,0 +1,2 @@
+UPDATE ROTAS
+SET METADATA = METADATA - 'cadence';
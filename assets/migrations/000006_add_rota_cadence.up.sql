@@ -0,0 +1,10 @@
+-- Rotas used to hand over at midnight of the day they were created on, keep doing that for the existing ones.
+UPDATE ROTAS
+SET METADATA = METADATA || JSONB_BUILD_OBJECT(
+        'cadence', JSONB_BUILD_OBJECT(
+            'every', 1,
+            'weekday', EXTRACT(DOW FROM CREATED_AT)::INT,
+            'time', '00:00'
+        )
+    )
+WHERE NOT METADATA ? 'cadence';
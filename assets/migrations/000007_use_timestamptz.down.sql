@@ -0,0 +1,16 @@
+UPDATE ROTAS
+SET METADATA = METADATA - 'time_zone';
+
+ALTER TABLE SHIFTS
+    ALTER COLUMN STARTS_AT TYPE TIMESTAMP USING STARTS_AT AT TIME ZONE 'UTC',
+    ALTER COLUMN ENDS_AT TYPE TIMESTAMP USING ENDS_AT AT TIME ZONE 'UTC',
+    ALTER COLUMN CREATED_AT TYPE TIMESTAMP USING CREATED_AT AT TIME ZONE 'UTC',
+    ALTER COLUMN UPDATED_AT TYPE TIMESTAMP USING UPDATED_AT AT TIME ZONE 'UTC';
+
+ALTER TABLE MEMBERS
+    ALTER COLUMN CREATED_AT TYPE TIMESTAMP USING CREATED_AT AT TIME ZONE 'UTC',
+    ALTER COLUMN UPDATED_AT TYPE TIMESTAMP USING UPDATED_AT AT TIME ZONE 'UTC';
+
+ALTER TABLE ROTAS
+    ALTER COLUMN CREATED_AT TYPE TIMESTAMP USING CREATED_AT AT TIME ZONE 'UTC',
+    ALTER COLUMN UPDATED_AT TYPE TIMESTAMP USING UPDATED_AT AT TIME ZONE 'UTC';
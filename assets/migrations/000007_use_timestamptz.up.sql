@@ -0,0 +1,20 @@
+-- Timestamps were stored in UTC without a time zone, make it explicit so they can be compared with the time zone
+-- of each rota.
+ALTER TABLE ROTAS
+    ALTER COLUMN CREATED_AT TYPE TIMESTAMPTZ USING CREATED_AT AT TIME ZONE 'UTC',
+    ALTER COLUMN UPDATED_AT TYPE TIMESTAMPTZ USING UPDATED_AT AT TIME ZONE 'UTC';
+
+ALTER TABLE MEMBERS
+    ALTER COLUMN CREATED_AT TYPE TIMESTAMPTZ USING CREATED_AT AT TIME ZONE 'UTC',
+    ALTER COLUMN UPDATED_AT TYPE TIMESTAMPTZ USING UPDATED_AT AT TIME ZONE 'UTC';
+
+ALTER TABLE SHIFTS
+    ALTER COLUMN STARTS_AT TYPE TIMESTAMPTZ USING STARTS_AT AT TIME ZONE 'UTC',
+    ALTER COLUMN ENDS_AT TYPE TIMESTAMPTZ USING ENDS_AT AT TIME ZONE 'UTC',
+    ALTER COLUMN CREATED_AT TYPE TIMESTAMPTZ USING CREATED_AT AT TIME ZONE 'UTC',
+    ALTER COLUMN UPDATED_AT TYPE TIMESTAMPTZ USING UPDATED_AT AT TIME ZONE 'UTC';
+
+-- Rotas used to hand over in UTC.
+UPDATE ROTAS
+SET METADATA = METADATA || '{"time_zone": "UTC"}'::JSONB
+WHERE NOT METADATA ? 'time_zone';
@@ -0,0 +1,27 @@
+-- Overrides let someone cover a window of time for a rota without changing who is next in the rotation.
+CREATE TABLE OVERRIDES
+(
+    ID         TEXT PRIMARY KEY     DEFAULT ('OV' || generate_uid(14)),
+    ROTA_ID    TEXT        NOT NULL,
+    USER_ID    TEXT        NOT NULL,
+    STARTS_AT  TIMESTAMPTZ NOT NULL,
+    ENDS_AT    TIMESTAMPTZ NOT NULL,
+    METADATA   JSONB       NOT NULL,
+    CREATED_AT TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UPDATED_AT TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_ends_after_starts_on_override
+        CHECK (ENDS_AT > STARTS_AT),
+    CONSTRAINT fk_rota_id_on_override
+        FOREIGN KEY (ROTA_ID)
+            REFERENCES ROTAS (ID)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX idx_rota_id_and_starts_at_on_overrides ON OVERRIDES (ROTA_ID, STARTS_AT);
+
+CREATE TRIGGER overrides_updated_at_trigger
+    BEFORE UPDATE
+    ON OVERRIDES
+    FOR EACH ROW
+    EXECUTE PROCEDURE trigger_set_timestamp();
@@ -0,0 +1,29 @@
+-- Swaps are requests to trade an upcoming shift with a teammate, they only change the rota once the teammate
+-- accepts them.
+CREATE TABLE SWAPS
+(
+    ID                  TEXT PRIMARY KEY     DEFAULT ('SW' || generate_uid(14)),
+    ROTA_ID             TEXT        NOT NULL,
+    REQUESTER_ID        TEXT        NOT NULL,
+    REQUESTER_STARTS_AT TIMESTAMPTZ NOT NULL,
+    REQUESTER_ENDS_AT   TIMESTAMPTZ NOT NULL,
+    RECIPIENT_ID        TEXT        NOT NULL,
+    RECIPIENT_STARTS_AT TIMESTAMPTZ NOT NULL,
+    RECIPIENT_ENDS_AT   TIMESTAMPTZ NOT NULL,
+    STATUS              TEXT        NOT NULL,
+    EXPIRES_AT          TIMESTAMPTZ NOT NULL,
+    METADATA            JSONB       NOT NULL,
+    CREATED_AT          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UPDATED_AT          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_rota_id_on_swap
+        FOREIGN KEY (ROTA_ID)
+            REFERENCES ROTAS (ID)
+            ON DELETE CASCADE
+);
+
+CREATE TRIGGER swaps_updated_at_trigger
+    BEFORE UPDATE
+    ON SWAPS
+    FOR EACH ROW
+    EXECUTE PROCEDURE trigger_set_timestamp();
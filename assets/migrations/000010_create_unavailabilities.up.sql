@@ -0,0 +1,24 @@
+-- Unavailabilities are the periods in which a member can't be on duty, they belong to the slack user rather than
+-- to a rota so they apply to every rota the user is a member of.
+CREATE TABLE UNAVAILABILITIES
+(
+    ID         TEXT PRIMARY KEY     DEFAULT ('UA' || generate_uid(14)),
+    TEAM_ID    TEXT        NOT NULL,
+    USER_ID    TEXT        NOT NULL,
+    STARTS_AT  TIMESTAMPTZ NOT NULL,
+    ENDS_AT    TIMESTAMPTZ NOT NULL,
+    METADATA   JSONB       NOT NULL,
+    CREATED_AT TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UPDATED_AT TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_ends_after_starts_on_unavailability
+        CHECK (ENDS_AT > STARTS_AT)
+);
+
+CREATE INDEX idx_team_id_and_user_id_and_starts_at_on_unavailabilities ON UNAVAILABILITIES (TEAM_ID, USER_ID, STARTS_AT);
+
+CREATE TRIGGER unavailabilities_updated_at_trigger
+    BEFORE UPDATE
+    ON UNAVAILABILITIES
+    FOR EACH ROW
+    EXECUTE PROCEDURE trigger_set_timestamp();
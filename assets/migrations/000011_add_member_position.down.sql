@@ -0,0 +1 @@
+ALTER TABLE MEMBERS DROP COLUMN POSITION;
@@ -0,0 +1,8 @@
+ALTER TABLE MEMBERS
+    ADD COLUMN POSITION INT NOT NULL DEFAULT 0;
+-- Members used to be ordered by the time they joined the rota, keep them in that order.
+UPDATE MEMBERS
+SET POSITION = ORDERED.POSITION
+FROM (SELECT ID, ROW_NUMBER() OVER (PARTITION BY ROTA_ID ORDER BY CREATED_AT, ID) - 1 AS POSITION
+      FROM MEMBERS) AS ORDERED
+WHERE MEMBERS.ID = ORDERED.ID;
@@ -0,0 +1,5 @@
+ALTER TABLE ROTAS
+    DROP CONSTRAINT chk_ends_after_starts_on_rota,
+    DROP COLUMN STATUS,
+    DROP COLUMN STARTS_AT,
+    DROP COLUMN ENDS_AT;
@@ -0,0 +1,8 @@
+-- Rotas can be paused, in which case nobody is handed over until they're resumed, and can be given the dates they
+-- start and end on. Rotas end once their end date is past.
+ALTER TABLE ROTAS
+    ADD COLUMN STATUS    TEXT NOT NULL DEFAULT 'active',
+    ADD COLUMN STARTS_AT TIMESTAMPTZ,
+    ADD COLUMN ENDS_AT   TIMESTAMPTZ,
+    ADD CONSTRAINT chk_ends_after_starts_on_rota
+        CHECK (ENDS_AT > STARTS_AT);
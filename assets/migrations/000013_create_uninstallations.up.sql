@@ -0,0 +1,18 @@
+-- Uninstallations are the tombstones of the workspaces that uninstalled rotabot or revoked its tokens, their rotas
+-- stop handing over and are deleted once the grace period has passed unless the workspace starts using rotabot again.
+CREATE TABLE UNINSTALLATIONS
+(
+    TEAM_ID        TEXT PRIMARY KEY,
+    REASON         TEXT        NOT NULL,
+    UNINSTALLED_AT TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CREATED_AT     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UPDATED_AT     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_uninstalled_at_on_uninstallations ON UNINSTALLATIONS (UNINSTALLED_AT);
+
+CREATE TRIGGER uninstallations_updated_at_trigger
+    BEFORE UPDATE
+    ON UNINSTALLATIONS
+    FOR EACH ROW
+    EXECUTE PROCEDURE trigger_set_timestamp();
@@ -0,0 +1,12 @@
+-- Events are the ids of the events of slack's Events API rotabot handled, slack sends an event again when it isn't
+-- acknowledged in time so they're only handled the first time they're received. They're deleted once slack stopped
+-- retrying them.
+CREATE TABLE EVENTS
+(
+    ID         TEXT PRIMARY KEY,
+    TEAM_ID    TEXT        NOT NULL,
+    TYPE       TEXT        NOT NULL,
+    CREATED_AT TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_created_at_on_events ON EVENTS (CREATED_AT);
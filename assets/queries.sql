@@ -3,30 +3,180 @@ SELECT ROTAS.*
 FROM ROTAS
 WHERE ID = $1;
 
+-- name: FindRotaByIDForUpdate :one
+SELECT ROTAS.*
+FROM ROTAS
+WHERE ID = $1 FOR UPDATE SKIP LOCKED;
+
+-- name: LockRotaByID :one
+SELECT ROTAS.*
+FROM ROTAS
+WHERE ID = $1 FOR UPDATE;
+
+-- name: ListRotas :many
+SELECT ROTAS.*
+FROM ROTAS
+WHERE ID > $1
+  AND TEAM_ID NOT IN (SELECT UNINSTALLATIONS.TEAM_ID FROM UNINSTALLATIONS)
+ORDER BY ID
+LIMIT $2;
+
 -- name: ListRotasByChannel :many
 SELECT ROTAS.*
 FROM ROTAS
 WHERE ROTAS.CHANNEL_ID = $1
   AND ROTAS.TEAM_ID = $2;
 
+-- name: ListRotasByUserID :many
+SELECT ROTAS.*
+FROM ROTAS
+WHERE ROTAS.TEAM_ID = $2
+  AND ROTAS.ID IN (SELECT MEMBERS.ROTA_ID FROM MEMBERS WHERE MEMBERS.USER_ID = $1)
+ORDER BY ROTAS.NAME, ROTAS.ID;
+
 -- name: saveRota :one
-INSERT INTO ROTAS (TEAM_ID, CHANNEL_ID, NAME, METADATA)
-VALUES ($1, $2, $3, $4) RETURNING ID;
+INSERT INTO ROTAS (TEAM_ID, CHANNEL_ID, NAME, METADATA, STARTS_AT, ENDS_AT)
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING ID;
 
 -- name: updateRota :one
 UPDATE ROTAS
-SET NAME     = $1,
-    METADATA = $2
-WHERE ID = $3 RETURNING ID;
+SET NAME      = $1,
+    METADATA  = $2,
+    STARTS_AT = $3,
+    ENDS_AT   = $4
+WHERE ID = $5 RETURNING ID;
+
+-- name: UpdateRotaState :exec
+UPDATE ROTAS
+SET STATE = $1
+WHERE ID = $2;
+
+-- name: UpdateRotaStatus :exec
+UPDATE ROTAS
+SET STATUS = $1
+WHERE ID = $2;
 
 -- name: saveMember :one
-INSERT INTO MEMBERS (ROTA_ID, USER_ID, METADATA)
-VALUES ($1, $2, $3) RETURNING ID;
+INSERT INTO MEMBERS (ROTA_ID, USER_ID, METADATA, POSITION)
+VALUES ($1, $2, $3, (SELECT COALESCE(MAX(POSITION) + 1, 0) FROM MEMBERS WHERE ROTA_ID = $1)) RETURNING ID;
 
 -- name: deleteMember :exec
-DELETE FROM MEMBERS WHERE USER_ID = $1;
+DELETE FROM MEMBERS WHERE ROTA_ID = $1 AND USER_ID = $2;
+
+-- name: updateMemberPosition :exec
+UPDATE MEMBERS
+SET POSITION = $1
+WHERE ID = $2;
 
 -- name: ListUserIDsByRotaID :many
 SELECT MEMBERS.USER_ID
 FROM MEMBERS
-WHERE MEMBERS.ROTA_ID = $1;
\ No newline at end of file
+WHERE MEMBERS.ROTA_ID = $1
+ORDER BY MEMBERS.POSITION, MEMBERS.CREATED_AT, MEMBERS.ID;
+
+-- name: ListMembersByRotaID :many
+SELECT MEMBERS.*
+FROM MEMBERS
+WHERE MEMBERS.ROTA_ID = $1
+ORDER BY MEMBERS.POSITION, MEMBERS.CREATED_AT, MEMBERS.ID;
+
+-- name: saveShift :one
+INSERT INTO SHIFTS (ROTA_ID, USER_ID, STARTS_AT, ENDS_AT, REASON, METADATA)
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING ID;
+
+-- name: EndShifts :exec
+UPDATE SHIFTS
+SET ENDS_AT = sqlc.arg(ends_at)
+WHERE SHIFTS.ROTA_ID = sqlc.arg(rota_id)
+  AND SHIFTS.STARTS_AT < sqlc.arg(ends_at)
+  AND SHIFTS.ENDS_AT > sqlc.arg(ends_at)
+  AND SHIFTS.REASON <> 'skip';
+
+-- name: ListShiftsByRotaID :many
+SELECT SHIFTS.*
+FROM SHIFTS
+WHERE SHIFTS.ROTA_ID = sqlc.arg(rota_id)
+  AND SHIFTS.STARTS_AT < sqlc.arg(until)
+  AND SHIFTS.ENDS_AT > sqlc.arg(since)
+ORDER BY SHIFTS.STARTS_AT;
+
+-- name: ListSkipsByRotaID :many
+SELECT SHIFTS.*
+FROM SHIFTS
+WHERE SHIFTS.ROTA_ID = $1
+  AND SHIFTS.REASON = 'skip'
+ORDER BY SHIFTS.STARTS_AT;
+
+-- name: ListShiftsByUserID :many
+SELECT SHIFTS.*
+FROM SHIFTS
+WHERE SHIFTS.USER_ID = sqlc.arg(user_id)
+  AND SHIFTS.STARTS_AT < sqlc.arg(until)
+  AND SHIFTS.ENDS_AT > sqlc.arg(since)
+ORDER BY SHIFTS.STARTS_AT;
+
+-- name: saveOverride :one
+INSERT INTO OVERRIDES (ROTA_ID, USER_ID, STARTS_AT, ENDS_AT, METADATA)
+VALUES ($1, $2, $3, $4, $5) RETURNING ID;
+
+-- name: ListOverridesByRotaID :many
+SELECT OVERRIDES.*
+FROM OVERRIDES
+WHERE OVERRIDES.ROTA_ID = sqlc.arg(rota_id)
+  AND OVERRIDES.STARTS_AT < sqlc.arg(until)
+  AND OVERRIDES.ENDS_AT > sqlc.arg(since)
+ORDER BY OVERRIDES.CREATED_AT;
+
+-- name: saveSwap :one
+INSERT INTO SWAPS (ROTA_ID, REQUESTER_ID, REQUESTER_STARTS_AT, REQUESTER_ENDS_AT, RECIPIENT_ID, RECIPIENT_STARTS_AT,
+                   RECIPIENT_ENDS_AT, STATUS, EXPIRES_AT, METADATA)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING ID;
+
+-- name: FindSwapByIDForUpdate :one
+SELECT SWAPS.*
+FROM SWAPS
+WHERE ID = $1 FOR UPDATE;
+
+-- name: UpdateSwapStatus :exec
+UPDATE SWAPS
+SET STATUS = $1
+WHERE ID = $2;
+
+-- name: saveUnavailability :one
+INSERT INTO UNAVAILABILITIES (TEAM_ID, USER_ID, STARTS_AT, ENDS_AT, METADATA)
+VALUES ($1, $2, $3, $4, $5) RETURNING ID;
+
+-- name: ListUnavailabilitiesByUserIDs :many
+SELECT UNAVAILABILITIES.*
+FROM UNAVAILABILITIES
+WHERE UNAVAILABILITIES.TEAM_ID = sqlc.arg(team_id)
+  AND UNAVAILABILITIES.USER_ID = ANY (sqlc.arg(user_ids)::TEXT[])
+  AND UNAVAILABILITIES.STARTS_AT < sqlc.arg(until)
+  AND UNAVAILABILITIES.ENDS_AT > sqlc.arg(since)
+ORDER BY UNAVAILABILITIES.STARTS_AT;
+
+-- name: CreateUninstallation :exec
+INSERT INTO UNINSTALLATIONS (TEAM_ID, REASON)
+VALUES ($1, $2) ON CONFLICT (TEAM_ID) DO NOTHING;
+
+-- name: DeleteUninstallation :exec
+DELETE FROM UNINSTALLATIONS WHERE TEAM_ID = $1;
+
+-- name: ListUninstallations :many
+SELECT UNINSTALLATIONS.*
+FROM UNINSTALLATIONS
+WHERE UNINSTALLATIONS.UNINSTALLED_AT <= $1
+ORDER BY UNINSTALLATIONS.UNINSTALLED_AT;
+
+-- name: saveEvent :execrows
+INSERT INTO EVENTS (ID, TEAM_ID, TYPE)
+VALUES ($1, $2, $3) ON CONFLICT (ID) DO NOTHING;
+
+-- name: DeleteEvents :exec
+DELETE FROM EVENTS WHERE CREATED_AT < $1;
+
+-- name: deleteRotasByTeamID :exec
+DELETE FROM ROTAS WHERE TEAM_ID = $1;
+
+-- name: deleteUnavailabilitiesByTeamID :exec
+DELETE FROM UNAVAILABILITIES WHERE TEAM_ID = $1;
@@ -76,6 +76,20 @@ SET default_tablespace = '';
 
 SET default_table_access_method = heap;
 
+--
+-- Name: events; Type: TABLE; Schema: public; Owner: rotabot
+--
+
+CREATE TABLE public.events (
+    id text NOT NULL,
+    team_id text NOT NULL,
+    type text NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.events OWNER TO rotabot;
+
 --
 -- Name: members; Type: TABLE; Schema: public; Owner: rotabot
 --
@@ -85,13 +99,33 @@ CREATE TABLE public.members (
     rota_id text NOT NULL,
     user_id text NOT NULL,
     metadata jsonb NOT NULL,
-    created_at timestamp without time zone DEFAULT now() NOT NULL,
-    updated_at timestamp without time zone DEFAULT now() NOT NULL
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    "position" integer DEFAULT 0 NOT NULL
 );
 
 
 ALTER TABLE public.members OWNER TO rotabot;
 
+--
+-- Name: overrides; Type: TABLE; Schema: public; Owner: rotabot
+--
+
+CREATE TABLE public.overrides (
+    id text DEFAULT ('OV'::text || public.generate_uid(14)) NOT NULL,
+    rota_id text NOT NULL,
+    user_id text NOT NULL,
+    starts_at timestamp with time zone NOT NULL,
+    ends_at timestamp with time zone NOT NULL,
+    metadata jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT chk_ends_after_starts_on_override CHECK ((ends_at > starts_at))
+);
+
+
+ALTER TABLE public.overrides OWNER TO rotabot;
+
 --
 -- Name: rotas; Type: TABLE; Schema: public; Owner: rotabot
 --
@@ -102,8 +136,13 @@ CREATE TABLE public.rotas (
     channel_id text NOT NULL,
     name text NOT NULL,
     metadata jsonb NOT NULL,
-    created_at timestamp without time zone DEFAULT now() NOT NULL,
-    updated_at timestamp without time zone DEFAULT now() NOT NULL
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    state jsonb DEFAULT '{}'::jsonb NOT NULL,
+    status text DEFAULT 'active'::text NOT NULL,
+    starts_at timestamp with time zone,
+    ends_at timestamp with time zone,
+    CONSTRAINT chk_ends_after_starts_on_rota CHECK ((ends_at > starts_at))
 );
 
 
@@ -121,11 +160,103 @@ CREATE TABLE public.schema_migrations (
 
 ALTER TABLE public.schema_migrations OWNER TO rotabot;
 
+--
+-- Name: shifts; Type: TABLE; Schema: public; Owner: rotabot
+--
+
+CREATE TABLE public.shifts (
+    id text DEFAULT ('SH'::text || public.generate_uid(14)) NOT NULL,
+    rota_id text NOT NULL,
+    user_id text NOT NULL,
+    starts_at timestamp with time zone NOT NULL,
+    ends_at timestamp with time zone NOT NULL,
+    reason text NOT NULL,
+    metadata jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.shifts OWNER TO rotabot;
+
+--
+-- Name: swaps; Type: TABLE; Schema: public; Owner: rotabot
+--
+
+CREATE TABLE public.swaps (
+    id text DEFAULT ('SW'::text || public.generate_uid(14)) NOT NULL,
+    rota_id text NOT NULL,
+    requester_id text NOT NULL,
+    requester_starts_at timestamp with time zone NOT NULL,
+    requester_ends_at timestamp with time zone NOT NULL,
+    recipient_id text NOT NULL,
+    recipient_starts_at timestamp with time zone NOT NULL,
+    recipient_ends_at timestamp with time zone NOT NULL,
+    status text NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    metadata jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.swaps OWNER TO rotabot;
+
+--
+-- Name: unavailabilities; Type: TABLE; Schema: public; Owner: rotabot
+--
+
+CREATE TABLE public.unavailabilities (
+    id text DEFAULT ('UA'::text || public.generate_uid(14)) NOT NULL,
+    team_id text NOT NULL,
+    user_id text NOT NULL,
+    starts_at timestamp with time zone NOT NULL,
+    ends_at timestamp with time zone NOT NULL,
+    metadata jsonb NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL,
+    CONSTRAINT chk_ends_after_starts_on_unavailability CHECK ((ends_at > starts_at))
+);
+
+
+ALTER TABLE public.unavailabilities OWNER TO rotabot;
+
+--
+-- Name: uninstallations; Type: TABLE; Schema: public; Owner: rotabot
+--
+
+CREATE TABLE public.uninstallations (
+    team_id text NOT NULL,
+    reason text NOT NULL,
+    uninstalled_at timestamp with time zone DEFAULT now() NOT NULL,
+    created_at timestamp with time zone DEFAULT now() NOT NULL,
+    updated_at timestamp with time zone DEFAULT now() NOT NULL
+);
+
+
+ALTER TABLE public.uninstallations OWNER TO rotabot;
+
+--
+-- Data for Name: events; Type: TABLE DATA; Schema: public; Owner: rotabot
+--
+
+COPY public.events (id, team_id, type, created_at) FROM stdin;
+\.
+
+
 --
 -- Data for Name: members; Type: TABLE DATA; Schema: public; Owner: rotabot
 --
 
-COPY public.members (id, rota_id, user_id, metadata, created_at, updated_at) FROM stdin;
+COPY public.members (id, rota_id, user_id, metadata, created_at, updated_at, "position") FROM stdin;
+\.
+
+
+--
+-- Data for Name: overrides; Type: TABLE DATA; Schema: public; Owner: rotabot
+--
+
+COPY public.overrides (id, rota_id, user_id, starts_at, ends_at, metadata, created_at, updated_at) FROM stdin;
 \.
 
 
@@ -133,7 +264,7 @@ COPY public.members (id, rota_id, user_id, metadata, created_at, updated_at) FRO
 -- Data for Name: rotas; Type: TABLE DATA; Schema: public; Owner: rotabot
 --
 
-COPY public.rotas (id, team_id, channel_id, name, metadata, created_at, updated_at) FROM stdin;
+COPY public.rotas (id, team_id, channel_id, name, metadata, created_at, updated_at, state, status, starts_at, ends_at) FROM stdin;
 \.
 
 
@@ -142,10 +273,50 @@ COPY public.rotas (id, team_id, channel_id, name, metadata, created_at, updated_
 --
 
 COPY public.schema_migrations (version, dirty) FROM stdin;
-3	f
+14	f
+\.
+
+
+--
+-- Data for Name: shifts; Type: TABLE DATA; Schema: public; Owner: rotabot
+--
+
+COPY public.shifts (id, rota_id, user_id, starts_at, ends_at, reason, metadata, created_at, updated_at) FROM stdin;
+\.
+
+
+--
+-- Data for Name: swaps; Type: TABLE DATA; Schema: public; Owner: rotabot
+--
+
+COPY public.swaps (id, rota_id, requester_id, requester_starts_at, requester_ends_at, recipient_id, recipient_starts_at, recipient_ends_at, status, expires_at, metadata, created_at, updated_at) FROM stdin;
+\.
+
+
+--
+-- Data for Name: unavailabilities; Type: TABLE DATA; Schema: public; Owner: rotabot
+--
+
+COPY public.unavailabilities (id, team_id, user_id, starts_at, ends_at, metadata, created_at, updated_at) FROM stdin;
+\.
+
+
+--
+-- Data for Name: uninstallations; Type: TABLE DATA; Schema: public; Owner: rotabot
+--
+
+COPY public.uninstallations (team_id, reason, uninstalled_at, created_at, updated_at) FROM stdin;
 \.
 
 
+--
+-- Name: events events_pkey; Type: CONSTRAINT; Schema: public; Owner: rotabot
+--
+
+ALTER TABLE ONLY public.events
+    ADD CONSTRAINT events_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: members members_pkey; Type: CONSTRAINT; Schema: public; Owner: rotabot
 --
@@ -154,6 +325,14 @@ ALTER TABLE ONLY public.members
     ADD CONSTRAINT members_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: overrides overrides_pkey; Type: CONSTRAINT; Schema: public; Owner: rotabot
+--
+
+ALTER TABLE ONLY public.overrides
+    ADD CONSTRAINT overrides_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: rotas rotas_pkey; Type: CONSTRAINT; Schema: public; Owner: rotabot
 --
@@ -170,6 +349,73 @@ ALTER TABLE ONLY public.schema_migrations
     ADD CONSTRAINT schema_migrations_pkey PRIMARY KEY (version);
 
 
+--
+-- Name: shifts shifts_pkey; Type: CONSTRAINT; Schema: public; Owner: rotabot
+--
+
+ALTER TABLE ONLY public.shifts
+    ADD CONSTRAINT shifts_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: swaps swaps_pkey; Type: CONSTRAINT; Schema: public; Owner: rotabot
+--
+
+ALTER TABLE ONLY public.swaps
+    ADD CONSTRAINT swaps_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: unavailabilities unavailabilities_pkey; Type: CONSTRAINT; Schema: public; Owner: rotabot
+--
+
+ALTER TABLE ONLY public.unavailabilities
+    ADD CONSTRAINT unavailabilities_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: uninstallations uninstallations_pkey; Type: CONSTRAINT; Schema: public; Owner: rotabot
+--
+
+ALTER TABLE ONLY public.uninstallations
+    ADD CONSTRAINT uninstallations_pkey PRIMARY KEY (team_id);
+
+
+--
+-- Name: idx_created_at_on_events; Type: INDEX; Schema: public; Owner: rotabot
+--
+
+CREATE INDEX idx_created_at_on_events ON public.events USING btree (created_at);
+
+
+--
+-- Name: idx_rota_id_and_starts_at_on_overrides; Type: INDEX; Schema: public; Owner: rotabot
+--
+
+CREATE INDEX idx_rota_id_and_starts_at_on_overrides ON public.overrides USING btree (rota_id, starts_at);
+
+
+--
+-- Name: idx_rota_id_and_starts_at_on_shifts; Type: INDEX; Schema: public; Owner: rotabot
+--
+
+CREATE INDEX idx_rota_id_and_starts_at_on_shifts ON public.shifts USING btree (rota_id, starts_at);
+
+
+--
+-- Name: idx_team_id_and_user_id_and_starts_at_on_unavailabilities; Type: INDEX; Schema: public; Owner: rotabot
+--
+
+CREATE INDEX idx_team_id_and_user_id_and_starts_at_on_unavailabilities ON public.unavailabilities USING btree (team_id, user_id, starts_at);
+
+
+--
+-- Name: idx_uninstalled_at_on_uninstallations; Type: INDEX; Schema: public; Owner: rotabot
+--
+
+CREATE INDEX idx_uninstalled_at_on_uninstallations ON public.uninstallations USING btree (uninstalled_at);
+
+
 --
 -- Name: idx_unique_rota_within_team_and_channel; Type: INDEX; Schema: public; Owner: rotabot
 --
@@ -191,6 +437,13 @@ CREATE UNIQUE INDEX idx_unique_user_within_rota ON public.members USING btree (r
 CREATE INDEX idx_user_id_on_members ON public.members USING btree (user_id);
 
 
+--
+-- Name: idx_user_id_and_starts_at_on_shifts; Type: INDEX; Schema: public; Owner: rotabot
+--
+
+CREATE INDEX idx_user_id_and_starts_at_on_shifts ON public.shifts USING btree (user_id, starts_at);
+
+
 --
 -- Name: members members_updated_at_trigger; Type: TRIGGER; Schema: public; Owner: rotabot
 --
@@ -198,6 +451,13 @@ CREATE INDEX idx_user_id_on_members ON public.members USING btree (user_id);
 CREATE TRIGGER members_updated_at_trigger BEFORE UPDATE ON public.members FOR EACH ROW EXECUTE FUNCTION public.trigger_set_timestamp();
 
 
+--
+-- Name: overrides overrides_updated_at_trigger; Type: TRIGGER; Schema: public; Owner: rotabot
+--
+
+CREATE TRIGGER overrides_updated_at_trigger BEFORE UPDATE ON public.overrides FOR EACH ROW EXECUTE FUNCTION public.trigger_set_timestamp();
+
+
 --
 -- Name: rotas rotas_updated_at_trigger; Type: TRIGGER; Schema: public; Owner: rotabot
 --
@@ -205,6 +465,34 @@ CREATE TRIGGER members_updated_at_trigger BEFORE UPDATE ON public.members FOR EA
 CREATE TRIGGER rotas_updated_at_trigger BEFORE UPDATE ON public.rotas FOR EACH ROW EXECUTE FUNCTION public.trigger_set_timestamp();
 
 
+--
+-- Name: shifts shifts_updated_at_trigger; Type: TRIGGER; Schema: public; Owner: rotabot
+--
+
+CREATE TRIGGER shifts_updated_at_trigger BEFORE UPDATE ON public.shifts FOR EACH ROW EXECUTE FUNCTION public.trigger_set_timestamp();
+
+
+--
+-- Name: swaps swaps_updated_at_trigger; Type: TRIGGER; Schema: public; Owner: rotabot
+--
+
+CREATE TRIGGER swaps_updated_at_trigger BEFORE UPDATE ON public.swaps FOR EACH ROW EXECUTE FUNCTION public.trigger_set_timestamp();
+
+
+--
+-- Name: unavailabilities unavailabilities_updated_at_trigger; Type: TRIGGER; Schema: public; Owner: rotabot
+--
+
+CREATE TRIGGER unavailabilities_updated_at_trigger BEFORE UPDATE ON public.unavailabilities FOR EACH ROW EXECUTE FUNCTION public.trigger_set_timestamp();
+
+
+--
+-- Name: uninstallations uninstallations_updated_at_trigger; Type: TRIGGER; Schema: public; Owner: rotabot
+--
+
+CREATE TRIGGER uninstallations_updated_at_trigger BEFORE UPDATE ON public.uninstallations FOR EACH ROW EXECUTE FUNCTION public.trigger_set_timestamp();
+
+
 --
 -- Name: members fk_rota_id_on_member; Type: FK CONSTRAINT; Schema: public; Owner: rotabot
 --
@@ -213,6 +501,30 @@ ALTER TABLE ONLY public.members
     ADD CONSTRAINT fk_rota_id_on_member FOREIGN KEY (rota_id) REFERENCES public.rotas(id) ON DELETE CASCADE;
 
 
+--
+-- Name: overrides fk_rota_id_on_override; Type: FK CONSTRAINT; Schema: public; Owner: rotabot
+--
+
+ALTER TABLE ONLY public.overrides
+    ADD CONSTRAINT fk_rota_id_on_override FOREIGN KEY (rota_id) REFERENCES public.rotas(id) ON DELETE CASCADE;
+
+
+--
+-- Name: shifts fk_rota_id_on_shift; Type: FK CONSTRAINT; Schema: public; Owner: rotabot
+--
+
+ALTER TABLE ONLY public.shifts
+    ADD CONSTRAINT fk_rota_id_on_shift FOREIGN KEY (rota_id) REFERENCES public.rotas(id) ON DELETE CASCADE;
+
+
+--
+-- Name: swaps fk_rota_id_on_swap; Type: FK CONSTRAINT; Schema: public; Owner: rotabot
+--
+
+ALTER TABLE ONLY public.swaps
+    ADD CONSTRAINT fk_rota_id_on_swap FOREIGN KEY (rota_id) REFERENCES public.rotas(id) ON DELETE CASCADE;
+
+
 --
 -- PostgreSQL database dump complete
 --
@@ -7,6 +7,7 @@ import (
 	"github.com/getsentry/sentry-go"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rotabot-io/rotabot/scheduler"
 	"github.com/rotabot-io/rotabot/slack"
 
 	"github.com/rotabot-io/rotabot/lib/db"
@@ -42,6 +43,16 @@ var rotabotCommand = &cli.Command{
 			DefaultText: "localhost",
 			Required:    false,
 		},
+		&cli.DurationFlag{
+			Name:  "scheduler.interval",
+			Usage: "Longest time the scheduler waits before checking if any rota needs to hand over",
+			Value: scheduler.DefaultInterval,
+		},
+		&cli.DurationFlag{
+			Name:  "scheduler.grace_period",
+			Usage: "How long the rotas of a workspace that uninstalled rotabot are kept for before they're deleted",
+			Value: scheduler.DefaultGracePeriod,
+		},
 		&cli.BoolFlag{
 			Name:  "migrate",
 			Usage: "This run the db migrations automatically",
@@ -110,13 +121,19 @@ func commandAction() cli.ActionFunc {
 		defer metricListener.Close()
 
 		params := &ServerParams{
-			BaseContext:      c.Context,
-			AppComponent:     "backend",
-			MetricsComponent: "metrics",
+			BaseContext:        c.Context,
+			AppComponent:       "backend",
+			MetricsComponent:   "metrics",
+			SchedulerComponent: "scheduler",
 
 			SlackSigningSecret: c.String("slack.signing_secret"),
 			SlackService:       slack.New(pool),
 
+			Scheduler: scheduler.New(pool,
+				scheduler.WithInterval(c.Duration("scheduler.interval")),
+				scheduler.WithGracePeriod(c.Duration("scheduler.grace_period")),
+			),
+
 			HttpListener:    httpListener,
 			MetricsListener: metricListener,
 		}
@@ -16,6 +16,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/scheduler"
 	"github.com/rotabot-io/rotabot/slack"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 )
@@ -59,13 +60,16 @@ var _ = Describe("E2E", func() {
 		})
 
 		server = NewServer(&ServerParams{
-			BaseContext:      ctx,
-			AppComponent:     "backend",
-			MetricsComponent: "metrics",
+			BaseContext:        ctx,
+			AppComponent:       "backend",
+			MetricsComponent:   "metrics",
+			SchedulerComponent: "scheduler",
 
 			SlackSigningSecret: "TEST",
 			SlackService:       slack.New(conn),
 
+			Scheduler: scheduler.New(conn),
+
 			HttpListener:    httpListener,
 			MetricsListener: metricListener,
 		})
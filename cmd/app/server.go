@@ -9,6 +9,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/rotabot-io/rotabot/scheduler"
 	"github.com/rotabot-io/rotabot/slack"
 
 	"go.uber.org/zap/zapcore"
@@ -26,12 +27,15 @@ import (
 type ServerParams struct {
 	BaseContext context.Context
 
-	AppComponent     string
-	MetricsComponent string
+	AppComponent       string
+	MetricsComponent   string
+	SchedulerComponent string
 
 	SlackSigningSecret string
 	SlackService       genSlack.Service
 
+	Scheduler *scheduler.Scheduler
+
 	HttpListener    net.Listener
 	MetricsListener net.Listener
 }
@@ -47,6 +51,7 @@ type Server struct {
 
 func NewServer(params *ServerParams) *Server {
 	var group run.Group
+	initScheduler(params, &group)
 	return &Server{
 		group:         &group,
 		ctx:           params.BaseContext,
@@ -143,6 +148,20 @@ func initMetricsServer(params *ServerParams, rg *run.Group) *http.Server {
 	return srv
 }
 
+func initScheduler(p *ServerParams, rg *run.Group) {
+	ctx, cancel := context.WithCancel(p.BaseContext)
+	logger := zapctx.Logger(ctx).With(zap.String("component", p.SchedulerComponent))
+	ctx = zapctx.WithLogger(ctx, logger)
+
+	rg.Add(func() error {
+		logger.Info("starting scheduler")
+		return p.Scheduler.Run(ctx)
+	}, func(error) {
+		logger.Info("stopping scheduler")
+		cancel()
+	})
+}
+
 func zapToStdLog(l *zap.Logger) *log.Logger {
 	return log.New(&zapio.Writer{Log: l, Level: zapcore.ErrorLevel}, "", 0)
 }
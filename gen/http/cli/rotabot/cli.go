@@ -223,13 +223,13 @@ Example:
     %[1]s slack events --body '{
       "api_app_id": "Neque blanditiis eum.",
       "challenge": "Voluptatem est iste quam eaque.",
-      "event": {
-         "type": "Commodi molestiae similique dignissimos quia quas."
-      },
+      "event": "Ex qui autem maxime architecto sed.",
+      "event_id": "Commodi molestiae similique dignissimos quia quas.",
+      "event_time": 2716341454249305568,
       "team_id": "Beatae exercitationem quo.",
       "token": "Natus quo nostrum eaque eum ipsam voluptatum.",
       "type": "Numquam qui facere hic est ea."
-   }' --signature "Fugit ex qui autem maxime." --timestamp 1620146830616195276
+   }' --signature "Consequatur dolorum hic sit reprehenderit placeat molestias." --timestamp 7001278128853322839
 `, os.Args[0])
 }
 
@@ -243,7 +243,7 @@ MessageActions implements MessageActions.
 
 Example:
     %[1]s slack message-actions --body '{
-      "payload": "TGFib3J1bSBjb25zZXF1YXR1ciBkb2xvcnVtLg=="
-   }' --signature "Sit reprehenderit placeat molestias repudiandae quod id." --timestamp 3232366975570777412
+      "payload": "SWQgdmVsaXQgbGliZXJvLg=="
+   }' --signature "Aliquid fuga necessitatibus." --timestamp 1383201381851321814
 `, os.Args[0])
 }
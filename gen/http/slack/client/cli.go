@@ -69,7 +69,7 @@ func BuildEventsPayload(slackEventsBody string, slackEventsSignature string, sla
 	{
 		err = json.Unmarshal([]byte(slackEventsBody), &body)
 		if err != nil {
-			return nil, fmt.Errorf("invalid JSON for body, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"api_app_id\": \"Neque blanditiis eum.\",\n      \"challenge\": \"Voluptatem est iste quam eaque.\",\n      \"event\": {\n         \"type\": \"Commodi molestiae similique dignissimos quia quas.\"\n      },\n      \"team_id\": \"Beatae exercitationem quo.\",\n      \"token\": \"Natus quo nostrum eaque eum ipsam voluptatum.\",\n      \"type\": \"Numquam qui facere hic est ea.\"\n   }'")
+			return nil, fmt.Errorf("invalid JSON for body, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"api_app_id\": \"Neque blanditiis eum.\",\n      \"challenge\": \"Voluptatem est iste quam eaque.\",\n      \"event\": \"Ex qui autem maxime architecto sed.\",\n      \"event_id\": \"Commodi molestiae similique dignissimos quia quas.\",\n      \"event_time\": 2716341454249305568,\n      \"team_id\": \"Beatae exercitationem quo.\",\n      \"token\": \"Natus quo nostrum eaque eum ipsam voluptatum.\",\n      \"type\": \"Numquam qui facere hic est ea.\"\n   }'")
 		}
 	}
 	var signature string
@@ -89,13 +89,9 @@ func BuildEventsPayload(slackEventsBody string, slackEventsSignature string, sla
 		Challenge: body.Challenge,
 		Type:      body.Type,
 		APIAppID:  body.APIAppID,
-	}
-	if body.Event != nil {
-		v.Event = &struct {
-			Type *string
-		}{
-			Type: body.Event.Type,
-		}
+		EventID:   body.EventID,
+		EventTime: body.EventTime,
+		Event:     body.Event,
 	}
 	v.Signature = signature
 	v.Timestamp = timestamp
@@ -111,7 +107,7 @@ func BuildMessageActionsPayload(slackMessageActionsBody string, slackMessageActi
 	{
 		err = json.Unmarshal([]byte(slackMessageActionsBody), &body)
 		if err != nil {
-			return nil, fmt.Errorf("invalid JSON for body, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"payload\": \"TGFib3J1bSBjb25zZXF1YXR1ciBkb2xvcnVtLg==\"\n   }'")
+			return nil, fmt.Errorf("invalid JSON for body, \nerror: %s, \nexample of valid JSON:\n%s", err, "'{\n      \"payload\": \"SWQgdmVsaXQgbGliZXJvLg==\"\n   }'")
 		}
 		if body.Payload == nil {
 			err = goa.MergeErrors(err, goa.MissingFieldError("payload", "body"))
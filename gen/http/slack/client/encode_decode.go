@@ -78,7 +78,20 @@ func DecodeCommandsResponse(decoder func(*http.Response) goahttp.Decoder, restor
 		}
 		switch resp.StatusCode {
 		case http.StatusOK:
-			return nil, nil
+			var (
+				body CommandsResponseBody
+				err  error
+			)
+			err = decoder(resp).Decode(&body)
+			if err != nil {
+				return nil, goahttp.ErrDecodingError("Slack", "Commands", err)
+			}
+			err = ValidateCommandsResponseBody(&body)
+			if err != nil {
+				return nil, goahttp.ErrValidationError("Slack", "Commands", err)
+			}
+			res := NewCommandsCommandResponseOK(&body)
+			return res, nil
 		default:
 			body, _ := io.ReadAll(resp.Body)
 			return nil, goahttp.ErrInvalidResponse("Slack", "Commands", resp.StatusCode, string(body))
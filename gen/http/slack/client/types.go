@@ -9,6 +9,7 @@ package client
 
 import (
 	slack "github.com/rotabot-io/rotabot/gen/slack"
+	goa "goa.design/goa/v3/pkg"
 )
 
 // CommandsRequestBody is the type of the "Slack" service "Commands" endpoint
@@ -39,10 +40,11 @@ type EventsRequestBody struct {
 	Challenge *string `form:"challenge,omitempty" json:"challenge,omitempty" xml:"challenge,omitempty"`
 	Type      string  `form:"type" json:"type" xml:"type"`
 	APIAppID  string  `form:"api_app_id" json:"api_app_id" xml:"api_app_id"`
-	// The actual event information
-	Event *struct {
-		Type *string `form:"type" json:"type" xml:"type"`
-	} `form:"event,omitempty" json:"event,omitempty" xml:"event,omitempty"`
+	EventID   *string `form:"event_id,omitempty" json:"event_id,omitempty" xml:"event_id,omitempty"`
+	EventTime *int64  `form:"event_time,omitempty" json:"event_time,omitempty" xml:"event_time,omitempty"`
+	// The actual event information, its fields depend on its type so it's kept as
+	// it was sent
+	Event any `form:"event,omitempty" json:"event,omitempty" xml:"event,omitempty"`
 }
 
 // MessageActionsRequestBody is the type of the "Slack" service
@@ -51,6 +53,13 @@ type MessageActionsRequestBody struct {
 	Payload []byte `form:"payload" json:"payload" xml:"payload"`
 }
 
+// CommandsResponseBody is the type of the "Slack" service "Commands" endpoint
+// HTTP response body.
+type CommandsResponseBody struct {
+	ResponseType *string `form:"response_type,omitempty" json:"response_type,omitempty" xml:"response_type,omitempty"`
+	Text         *string `form:"text,omitempty" json:"text,omitempty" xml:"text,omitempty"`
+}
+
 // EventsResponseBody is the type of the "Slack" service "Events" endpoint HTTP
 // response body.
 type EventsResponseBody struct {
@@ -97,13 +106,9 @@ func NewEventsRequestBody(p *slack.Event) *EventsRequestBody {
 		Challenge: p.Challenge,
 		Type:      p.Type,
 		APIAppID:  p.APIAppID,
-	}
-	if p.Event != nil {
-		body.Event = &struct {
-			Type *string `form:"type" json:"type" xml:"type"`
-		}{
-			Type: p.Event.Type,
-		}
+		EventID:   p.EventID,
+		EventTime: p.EventTime,
+		Event:     p.Event,
 	}
 	return body
 }
@@ -117,6 +122,17 @@ func NewMessageActionsRequestBody(p *slack.Action) *MessageActionsRequestBody {
 	return body
 }
 
+// NewCommandsCommandResponseOK builds a "Slack" service "Commands" endpoint
+// result from a HTTP "OK" response.
+func NewCommandsCommandResponseOK(body *CommandsResponseBody) *slack.CommandResponse {
+	v := &slack.CommandResponse{
+		ResponseType: body.ResponseType,
+		Text:         body.Text,
+	}
+
+	return v
+}
+
 // NewEventsEventResponseOK builds a "Slack" service "Events" endpoint result
 // from a HTTP "OK" response.
 func NewEventsEventResponseOK(body *EventsResponseBody) *slack.EventResponse {
@@ -145,3 +161,14 @@ func NewMessageActionsActionResponseOK(body *MessageActionsResponseBody) *slack.
 
 	return v
 }
+
+// ValidateCommandsResponseBody runs the validations defined on
+// CommandsResponseBody
+func ValidateCommandsResponseBody(body *CommandsResponseBody) (err error) {
+	if body.ResponseType != nil {
+		if !(*body.ResponseType == "ephemeral" || *body.ResponseType == "in_channel") {
+			err = goa.MergeErrors(err, goa.InvalidEnumValueError("body.response_type", *body.ResponseType, []any{"ephemeral", "in_channel"}))
+		}
+	}
+	return
+}
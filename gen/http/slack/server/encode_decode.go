@@ -22,8 +22,11 @@ import (
 // Slack Commands endpoint.
 func EncodeCommandsResponse(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter, any) error {
 	return func(ctx context.Context, w http.ResponseWriter, v any) error {
+		res, _ := v.(*slack.CommandResponse)
+		enc := encoder(ctx, w)
+		body := NewCommandsResponseBody(res)
 		w.WriteHeader(http.StatusOK)
-		return nil
+		return enc.Encode(body)
 	}
 }
 
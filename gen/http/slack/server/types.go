@@ -40,10 +40,11 @@ type EventsRequestBody struct {
 	Challenge *string `form:"challenge,omitempty" json:"challenge,omitempty" xml:"challenge,omitempty"`
 	Type      *string `form:"type,omitempty" json:"type,omitempty" xml:"type,omitempty"`
 	APIAppID  *string `form:"api_app_id,omitempty" json:"api_app_id,omitempty" xml:"api_app_id,omitempty"`
-	// The actual event information
-	Event *struct {
-		Type *string `form:"type" json:"type" xml:"type"`
-	} `form:"event,omitempty" json:"event,omitempty" xml:"event,omitempty"`
+	EventID   *string `form:"event_id,omitempty" json:"event_id,omitempty" xml:"event_id,omitempty"`
+	EventTime *int64  `form:"event_time,omitempty" json:"event_time,omitempty" xml:"event_time,omitempty"`
+	// The actual event information, its fields depend on its type so it's kept as
+	// it was sent
+	Event any `form:"event,omitempty" json:"event,omitempty" xml:"event,omitempty"`
 }
 
 // MessageActionsRequestBody is the type of the "Slack" service
@@ -52,6 +53,13 @@ type MessageActionsRequestBody struct {
 	Payload []byte `form:"payload,omitempty" json:"payload,omitempty" xml:"payload,omitempty"`
 }
 
+// CommandsResponseBody is the type of the "Slack" service "Commands" endpoint
+// HTTP response body.
+type CommandsResponseBody struct {
+	ResponseType *string `form:"response_type,omitempty" json:"response_type,omitempty" xml:"response_type,omitempty"`
+	Text         *string `form:"text,omitempty" json:"text,omitempty" xml:"text,omitempty"`
+}
+
 // EventsResponseBody is the type of the "Slack" service "Events" endpoint HTTP
 // response body.
 type EventsResponseBody struct {
@@ -66,6 +74,16 @@ type MessageActionsResponseBody struct {
 	Errors         map[string]string `form:"errors,omitempty" json:"errors,omitempty" xml:"errors,omitempty"`
 }
 
+// NewCommandsResponseBody builds the HTTP response body from the result of the
+// "Commands" endpoint of the "Slack" service.
+func NewCommandsResponseBody(res *slack.CommandResponse) *CommandsResponseBody {
+	body := &CommandsResponseBody{
+		ResponseType: res.ResponseType,
+		Text:         res.Text,
+	}
+	return body
+}
+
 // NewEventsResponseBody builds the HTTP response body from the result of the
 // "Events" endpoint of the "Slack" service.
 func NewEventsResponseBody(res *slack.EventResponse) *EventsResponseBody {
@@ -126,13 +144,9 @@ func NewEventsEvent(body *EventsRequestBody, signature string, timestamp int64)
 		Challenge: body.Challenge,
 		Type:      *body.Type,
 		APIAppID:  *body.APIAppID,
-	}
-	if body.Event != nil {
-		v.Event = &struct {
-			Type *string
-		}{
-			Type: body.Event.Type,
-		}
+		EventID:   body.EventID,
+		EventTime: body.EventTime,
+		Event:     body.Event,
 	}
 	v.Signature = signature
 	v.Timestamp = timestamp
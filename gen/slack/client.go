@@ -30,9 +30,13 @@ func NewClient(commands, events, messageActions goa.Endpoint) *Client {
 }
 
 // Commands calls the "Commands" endpoint of the "Slack" service.
-func (c *Client) Commands(ctx context.Context, p *Command) (err error) {
-	_, err = c.CommandsEndpoint(ctx, p)
-	return
+func (c *Client) Commands(ctx context.Context, p *Command) (res *CommandResponse, err error) {
+	var ires any
+	ires, err = c.CommandsEndpoint(ctx, p)
+	if err != nil {
+		return
+	}
+	return ires.(*CommandResponse), nil
 }
 
 // Events calls the "Events" endpoint of the "Slack" service.
@@ -41,7 +41,7 @@ func (e *Endpoints) Use(m func(goa.Endpoint) goa.Endpoint) {
 func NewCommandsEndpoint(s Service) goa.Endpoint {
 	return func(ctx context.Context, req any) (any, error) {
 		p := req.(*Command)
-		return nil, s.Commands(ctx, p)
+		return s.Commands(ctx, p)
 	}
 }
 
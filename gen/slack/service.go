@@ -14,7 +14,7 @@ import (
 // Slack api for interacting with slack commands, actions, events etc.
 type Service interface {
 	// Commands implements Commands.
-	Commands(context.Context, *Command) (err error)
+	Commands(context.Context, *Command) (res *CommandResponse, err error)
 	// Events implements Events.
 	Events(context.Context, *Event) (res *EventResponse, err error)
 	// MessageActions implements MessageActions.
@@ -66,6 +66,12 @@ type Command struct {
 	APIAppID            *string
 }
 
+// CommandResponse is the result type of the Slack service Commands method.
+type CommandResponse struct {
+	ResponseType *string
+	Text         *string
+}
+
 // Event is the payload type of the Slack service Events method.
 type Event struct {
 	Signature string
@@ -75,10 +81,11 @@ type Event struct {
 	Challenge *string
 	Type      string
 	APIAppID  string
-	// The actual event information
-	Event *struct {
-		Type *string
-	}
+	EventID   *string
+	EventTime *int64
+	// The actual event information, its fields depend on its type so it's kept as
+	// it was sent
+	Event any
 }
 
 // EventResponse is the result type of the Slack service Events method.
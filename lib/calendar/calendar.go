@@ -0,0 +1,243 @@
+// Package calendar knows which days are holidays. Calendars are either embedded in rotabot or uploaded by
+// users as iCalendar (.ics) or CSV files.
+//
+// CSV files have the date of the holiday, formatted as YYYY-MM-DD, in the first column and optionally its name
+// in the second one. A header row is allowed.
+package calendar
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rotabot-io/rotabot/assets"
+)
+
+var (
+	ErrInvalidCalendar = errors.New("invalid calendar")
+	ErrUnknownCalendar = errors.New("unknown calendar")
+)
+
+// dateLayout is how the days of holidays are written down.
+const dateLayout = "2006-01-02"
+
+// Holiday is a day on which nobody works.
+type Holiday struct {
+	Date string `json:"date"`
+	Name string `json:"name,omitempty"`
+}
+
+// Calendar is a set of holidays.
+type Calendar struct {
+	days map[string]struct{}
+}
+
+// New returns a calendar with the holidays of all the given lists.
+func New(holidays ...[]Holiday) Calendar {
+	c := Calendar{days: map[string]struct{}{}}
+	for _, list := range holidays {
+		for _, h := range list {
+			c.days[h.Date] = struct{}{}
+		}
+	}
+	return c
+}
+
+// IsHoliday reports whether the day t falls on, in t's location, is a holiday.
+func (c Calendar) IsHoliday(t time.Time) bool {
+	_, ok := c.days[t.Format(dateLayout)]
+	return ok
+}
+
+// embedded are the calendars shipped with rotabot, keyed by the name shown to users.
+var embedded = map[string]string{
+	"England and Wales": "holidays/england-and-wales.csv",
+	"United States":     "holidays/united-states.csv",
+}
+
+// Names returns the names of the calendars shipped with rotabot in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(embedded))
+	for name := range embedded {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Embedded returns the holidays of the calendar shipped with rotabot with the given name.
+func Embedded(name string) ([]Holiday, error) {
+	file, ok := embedded[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCalendar, name)
+	}
+	data, err := assets.Holidays.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(file, data)
+}
+
+// LastYear returns the year of the last of the holidays, sorted the way Parse returns them. The calendars shipped
+// with rotabot list every holiday of the years they cover, there's no telling which days are holidays after it.
+func LastYear(holidays []Holiday) int {
+	if len(holidays) == 0 {
+		return 0
+	}
+	last, err := time.Parse(dateLayout, holidays[len(holidays)-1].Date)
+	if err != nil {
+		return 0
+	}
+	return last.Year()
+}
+
+// Parse reads the holidays from an iCalendar or CSV file, the format is picked from the extension of the file
+// falling back to looking at its contents.
+func Parse(filename string, data []byte) ([]Holiday, error) {
+	ext := strings.ToLower(path.Ext(filename))
+	isICS := ext == ".ics" || ext == ".ical" ||
+		ext != ".csv" && bytes.HasPrefix(bytes.TrimSpace(data), []byte("BEGIN:VCALENDAR"))
+
+	parse := parseCSV
+	if isICS {
+		parse = parseICS
+	}
+	holidays, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(holidays) == 0 {
+		return nil, fmt.Errorf("%w: no holidays found", ErrInvalidCalendar)
+	}
+	slices.SortStableFunc(holidays, func(a, b Holiday) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+	return holidays, nil
+}
+
+func parseCSV(data []byte) ([]Holiday, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+
+	holidays := []Holiday{}
+	for first := true; ; first = false {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return holidays, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCalendar, err)
+		}
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		date, err := time.Parse(dateLayout, strings.TrimSpace(record[0]))
+		if err != nil {
+			if first {
+				// This is the header.
+				continue
+			}
+			line, _ := r.FieldPos(0)
+			return nil, fmt.Errorf("%w: line %d has an invalid date %q", ErrInvalidCalendar, line, record[0])
+		}
+		h := Holiday{Date: date.Format(dateLayout)}
+		if len(record) > 1 {
+			h.Name = strings.TrimSpace(record[1])
+		}
+		holidays = append(holidays, h)
+	}
+}
+
+// parseICS reads the events of an iCalendar file, every day an event takes place on is a holiday.
+// See https://datatracker.ietf.org/doc/html/rfc5545
+func parseICS(data []byte) ([]Holiday, error) {
+	holidays := []Holiday{}
+	var start, end, name string
+	inEvent := false
+	for _, line := range unfold(data) {
+		property, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// Parameters, like the ones in DTSTART;VALUE=DATE, don't matter to us.
+		property, _, _ = strings.Cut(strings.ToUpper(property), ";")
+		switch {
+		case property == "BEGIN" && value == "VEVENT":
+			inEvent = true
+			start, end, name = "", "", ""
+		case property == "END" && value == "VEVENT":
+			inEvent = false
+			days, err := eventDays(start, end)
+			if err != nil {
+				return nil, err
+			}
+			for _, day := range days {
+				holidays = append(holidays, Holiday{Date: day, Name: name})
+			}
+		case !inEvent:
+		case property == "DTSTART":
+			start = value
+		case property == "DTEND":
+			end = value
+		case property == "SUMMARY":
+			name = strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\\`, `\`).Replace(value)
+		}
+	}
+	return holidays, nil
+}
+
+// eventDays returns the days an event takes place on, the end of an event is exclusive.
+func eventDays(start, end string) ([]string, error) {
+	from, err := icsDate(start)
+	if err != nil {
+		return nil, err
+	}
+	if end == "" {
+		return []string{from.Format(dateLayout)}, nil
+	}
+	to, err := icsDate(end)
+	if err != nil {
+		return nil, err
+	}
+	days := []string{from.Format(dateLayout)}
+	for d := from.AddDate(0, 0, 1); d.Before(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, d.Format(dateLayout))
+	}
+	return days, nil
+}
+
+// icsDate parses the date part of an iCalendar DATE or DATE-TIME value.
+func icsDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("%w: invalid date %q", ErrInvalidCalendar, value)
+	}
+	t, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid date %q", ErrInvalidCalendar, value)
+	}
+	return t, nil
+}
+
+// unfold splits an iCalendar file into lines, joining the lines that were folded because they were too long.
+func unfold(data []byte) []string {
+	lines := []string{}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
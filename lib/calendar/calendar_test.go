@@ -0,0 +1,94 @@
+package calendar
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Calendar", func() {
+	Describe("Parse", func() {
+		It("reads holidays from a CSV file", func() {
+			holidays, err := Parse("holidays.csv", []byte("date,name\n2024-12-26,Boxing Day\n2024-12-25, Christmas Day\n\n2025-01-01\n"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(holidays).To(Equal([]Holiday{
+				{Date: "2024-12-25", Name: "Christmas Day"},
+				{Date: "2024-12-26", Name: "Boxing Day"},
+				{Date: "2025-01-01"},
+			}))
+		})
+
+		It("points at the line with an invalid date", func() {
+			_, err := Parse("holidays.csv", []byte("date,name\n2024-12-25,Christmas Day\n25/12/2024,Christmas Day\n"))
+			Expect(err).To(MatchError(ErrInvalidCalendar))
+			Expect(err).To(MatchError(ContainSubstring("line 3")))
+		})
+
+		It("reads holidays from an iCalendar file", func() {
+			ics := "BEGIN:VCALENDAR\r\n" +
+				"VERSION:2.0\r\n" +
+				"BEGIN:VEVENT\r\n" +
+				"DTSTART;VALUE=DATE:20241225\r\n" +
+				"DTEND;VALUE=DATE:20241227\r\n" +
+				"SUMMARY:Christmas\\, and Boxing\r\n" +
+				"  Day\r\n" +
+				"END:VEVENT\r\n" +
+				"BEGIN:VEVENT\r\n" +
+				"DTSTART:20250101T000000Z\r\n" +
+				"SUMMARY:New Year's Day\r\n" +
+				"END:VEVENT\r\n" +
+				"END:VCALENDAR\r\n"
+
+			holidays, err := Parse("holidays", []byte(ics))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(holidays).To(Equal([]Holiday{
+				{Date: "2024-12-25", Name: "Christmas, and Boxing Day"},
+				{Date: "2024-12-26", Name: "Christmas, and Boxing Day"},
+				{Date: "2025-01-01", Name: "New Year's Day"},
+			}))
+		})
+
+		It("fails when there are no holidays", func() {
+			_, err := Parse("holidays.ics", []byte("BEGIN:VCALENDAR\nEND:VCALENDAR\n"))
+			Expect(err).To(MatchError(ErrInvalidCalendar))
+		})
+	})
+
+	Describe("Embedded", func() {
+		It("loads every calendar shipped with rotabot", func() {
+			Expect(Names()).To(Equal([]string{"England and Wales", "United States"}))
+			for _, name := range Names() {
+				holidays, err := Embedded(name)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(holidays).ToNot(BeEmpty())
+			}
+		})
+
+		It("fails for calendars it doesn't know about", func() {
+			_, err := Embedded("Atlantis")
+			Expect(err).To(MatchError(ErrUnknownCalendar))
+		})
+	})
+
+	Describe("LastYear", func() {
+		It("returns the year of the last holiday", func() {
+			holidays, err := Embedded("England and Wales")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(LastYear(holidays)).To(Equal(2027))
+			Expect(LastYear(nil)).To(BeZero())
+		})
+	})
+
+	Describe("IsHoliday", func() {
+		It("checks the day in the location of the time", func() {
+			c := New([]Holiday{{Date: "2024-12-25"}})
+			newYork, err := time.LoadLocation("America/New_York")
+			Expect(err).ToNot(HaveOccurred())
+
+			christmasEve := time.Date(2024, time.December, 25, 3, 0, 0, 0, time.UTC)
+			Expect(c.IsHoliday(christmasEve)).To(BeTrue())
+			Expect(c.IsHoliday(christmasEve.In(newYork))).To(BeFalse())
+		})
+	})
+})
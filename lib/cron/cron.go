@@ -0,0 +1,242 @@
+// Package cron parses standard 5-field cron expressions and works out when they fire.
+//
+// The fields are minute, hour, day of the month, month and day of the week. Each field accepts *, single
+// values, ranges (1-5), lists (1,15) and steps (*/15 or 1-30/2). Months and days of the week can also be
+// referred to by their first three letters (JAN, MON). Like most cron implementations, when both the day of
+// the month and the day of the week are restricted the expression fires when either of them matches.
+//
+// The day of the month additionally accepts L for the last day of the month and nW for the weekday nearest
+// to the nth day of the month, so "0 9 1W * *" fires at 9:00 on the first business day of every month.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidExpression = errors.New("invalid cron expression")
+
+// searchLimit bounds how far ahead Next looks for the time an expression fires, expressions like "0 0 30 2 *"
+// never fire.
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	domRestricted, dowRestricted  bool
+	lastDay                       bool
+	nearestWeekday                []int
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of the month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	// Both 0 and 7 mean Sunday.
+	dowField = field{name: "day of the week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+// Parse parses a 5-field cron expression.
+func Parse(expr string) (Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Schedule{}, fmt.Errorf("%w: expected 5 fields but got %d", ErrInvalidExpression, len(fields))
+	}
+
+	var s Schedule
+	var err error
+	if s.minute, err = minuteField.parse(fields[0]); err != nil {
+		return Schedule{}, err
+	}
+	if s.hour, err = hourField.parse(fields[1]); err != nil {
+		return Schedule{}, err
+	}
+	if err = s.parseDom(fields[2]); err != nil {
+		return Schedule{}, err
+	}
+	if s.month, err = monthField.parse(fields[3]); err != nil {
+		return Schedule{}, err
+	}
+	if s.dow, err = dowField.parse(fields[4]); err != nil {
+		return Schedule{}, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	// Fields starting with * are not considered restricted, even when they have a step.
+	s.domRestricted = !strings.HasPrefix(fields[2], "*")
+	s.dowRestricted = !strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// parseDom parses the day of the month, which on top of the usual syntax accepts L and nW.
+func (s *Schedule) parseDom(value string) error {
+	rest := []string{}
+	for _, part := range strings.Split(value, ",") {
+		switch {
+		case part == "L":
+			s.lastDay = true
+		case strings.HasSuffix(part, "W"):
+			day, err := strconv.Atoi(strings.TrimSuffix(part, "W"))
+			if err != nil || day < domField.min || day > domField.max {
+				return fmt.Errorf("%w: invalid day of the month %q", ErrInvalidExpression, part)
+			}
+			s.nearestWeekday = append(s.nearestWeekday, day)
+		default:
+			rest = append(rest, part)
+		}
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	var err error
+	s.dom, err = domField.parse(strings.Join(rest, ","))
+	return err
+}
+
+func (f field) parse(value string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("%w: invalid step %q for the %s", ErrInvalidExpression, stepPart, f.name)
+			}
+		}
+
+		var from, to int
+		switch {
+		case rangePart == "*":
+			from, to = f.min, f.max
+		case strings.Contains(rangePart, "-"):
+			start, end, _ := strings.Cut(rangePart, "-")
+			var err error
+			if from, err = f.value(start); err != nil {
+				return 0, err
+			}
+			if to, err = f.value(end); err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			if from, err = f.value(rangePart); err != nil {
+				return 0, err
+			}
+			to = from
+			if hasStep {
+				to = f.max
+			}
+		}
+		if from > to {
+			return 0, fmt.Errorf("%w: invalid range %q for the %s", ErrInvalidExpression, rangePart, f.name)
+		}
+		for i := from; i <= to; i += step {
+			bits |= 1 << i
+		}
+	}
+	return bits, nil
+}
+
+func (f field) value(value string) (int, error) {
+	if i, ok := f.names[strings.ToUpper(value)]; ok {
+		return i, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i < f.min || i > f.max {
+		return 0, fmt.Errorf("%w: invalid %s %q", ErrInvalidExpression, f.name, value)
+	}
+	return i, nil
+}
+
+// Next returns the first time after t at which the schedule fires, in t's location. It returns the zero time
+// when the schedule doesn't fire within the next five years.
+func (s Schedule) Next(t time.Time) time.Time {
+	limit := t.Add(searchLimit)
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for t.Before(limit) {
+		if s.month&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s Schedule) matchesDay(t time.Time) bool {
+	dom := s.matchesDom(t)
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+func (s Schedule) matchesDom(t time.Time) bool {
+	if !s.domRestricted {
+		return true
+	}
+	if s.dom&(1<<t.Day()) != 0 {
+		return true
+	}
+	lastDay := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	if s.lastDay && t.Day() == lastDay {
+		return true
+	}
+	for _, day := range s.nearestWeekday {
+		if nearestWeekday(t.Year(), t.Month(), day, lastDay, t.Location()) == t.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// nearestWeekday returns the weekday closest to the given day without leaving the month.
+func nearestWeekday(year int, month time.Month, day, lastDay int, loc *time.Location) int {
+	if day > lastDay {
+		day = lastDay
+	}
+	switch time.Date(year, month, day, 0, 0, 0, 0, loc).Weekday() { // nolint:exhaustive
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == lastDay {
+			return day - 2
+		}
+		return day + 1
+	default:
+		return day
+	}
+}
@@ -0,0 +1,79 @@
+package cron
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func date(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+var _ = Describe("Cron", func() {
+	DescribeTable("Parse fails on invalid expressions",
+		func(expr string) {
+			_, err := Parse(expr)
+			Expect(err).To(MatchError(ErrInvalidExpression))
+		},
+		Entry("too few fields", "0 9 * *"),
+		Entry("too many fields", "0 9 * * * *"),
+		Entry("out of range minute", "60 9 * * *"),
+		Entry("out of range hour", "0 24 * * *"),
+		Entry("zero day of the month", "0 9 0 * *"),
+		Entry("unknown month", "0 9 * FOO *"),
+		Entry("reversed range", "0 9 * * FRI-MON"),
+		Entry("invalid step", "*/0 9 * * *"),
+		Entry("invalid nearest weekday", "0 9 32W * *"),
+	)
+
+	DescribeTable("Next",
+		func(expr string, after time.Time, expected ...time.Time) {
+			s, err := Parse(expr)
+			Expect(err).ToNot(HaveOccurred())
+
+			t := after
+			for _, e := range expected {
+				t = s.Next(t)
+				Expect(t).To(Equal(e))
+			}
+		},
+		Entry("every day at 10:00", "0 10 * * *", date(2023, time.January, 4, 10, 0),
+			date(2023, time.January, 5, 10, 0), date(2023, time.January, 6, 10, 0)),
+		Entry("every 15 minutes", "*/15 * * * *", date(2023, time.January, 4, 10, 7),
+			date(2023, time.January, 4, 10, 15), date(2023, time.January, 4, 10, 30)),
+		Entry("mondays and thursdays at 10:00", "0 10 * * MON,THU", date(2023, time.January, 4, 12, 0),
+			date(2023, time.January, 5, 10, 0), date(2023, time.January, 9, 10, 0), date(2023, time.January, 12, 10, 0)),
+		Entry("weekdays with sunday as 7", "30 8 * * 5-7", date(2023, time.January, 4, 12, 0),
+			date(2023, time.January, 6, 8, 30), date(2023, time.January, 7, 8, 30), date(2023, time.January, 8, 8, 30)),
+		Entry("either the day of the month or the day of the week", "0 0 13 * FRI", date(2023, time.January, 4, 0, 0),
+			date(2023, time.January, 6, 0, 0), date(2023, time.January, 13, 0, 0), date(2023, time.January, 20, 0, 0)),
+		Entry("last day of the month", "0 18 L * *", date(2023, time.January, 4, 0, 0),
+			date(2023, time.January, 31, 18, 0), date(2023, time.February, 28, 18, 0)),
+		Entry("first business day of the month", "0 9 1W * *", date(2023, time.January, 4, 0, 0),
+			date(2023, time.February, 1, 9, 0), date(2023, time.March, 1, 9, 0), date(2023, time.April, 3, 9, 0)),
+		Entry("nearest weekday without leaving the month", "0 9 30W * *", date(2023, time.April, 1, 0, 0),
+			date(2023, time.April, 28, 9, 0)),
+		Entry("a specific month by name", "0 0 1 JUL *", date(2023, time.January, 4, 0, 0),
+			date(2023, time.July, 1, 0, 0), date(2024, time.July, 1, 0, 0)),
+		Entry("leap days", "0 0 29 2 *", date(2023, time.January, 4, 0, 0),
+			date(2024, time.February, 29, 0, 0)),
+	)
+
+	It("returns the zero time when the expression never fires", func() {
+		s, err := Parse("0 0 30 2 *")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(s.Next(date(2023, time.January, 4, 0, 0)).IsZero()).To(BeTrue())
+	})
+
+	It("keeps the location of the given time", func() {
+		loc, err := time.LoadLocation("Europe/London")
+		Expect(err).ToNot(HaveOccurred())
+		s, err := Parse("0 9 * * *")
+		Expect(err).ToNot(HaveOccurred())
+
+		next := s.Next(time.Date(2023, time.July, 4, 10, 0, 0, 0, loc))
+		Expect(next).To(Equal(time.Date(2023, time.July, 5, 9, 0, 0, 0, loc)))
+	})
+})
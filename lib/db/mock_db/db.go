@@ -12,6 +12,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	pgtype "github.com/jackc/pgx/v5/pgtype"
 	db "github.com/rotabot-io/rotabot/lib/db"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -39,6 +40,20 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CreateEvent mocks base method.
+func (m *MockRepository) CreateEvent(arg0 context.Context, arg1 db.CreateEventParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEvent indicates an expected call of CreateEvent.
+func (mr *MockRepositoryMockRecorder) CreateEvent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEvent", reflect.TypeOf((*MockRepository)(nil).CreateEvent), arg0, arg1)
+}
+
 // CreateOrUpdateRota mocks base method.
 func (m *MockRepository) CreateOrUpdateRota(arg0 context.Context, arg1 db.CreateOrUpdateRotaParams) (string, error) {
 	m.ctrl.T.Helper()
@@ -54,6 +69,122 @@ func (mr *MockRepositoryMockRecorder) CreateOrUpdateRota(arg0, arg1 any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateRota", reflect.TypeOf((*MockRepository)(nil).CreateOrUpdateRota), arg0, arg1)
 }
 
+// CreateOverride mocks base method.
+func (m *MockRepository) CreateOverride(arg0 context.Context, arg1 db.CreateOverrideParams) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOverride", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOverride indicates an expected call of CreateOverride.
+func (mr *MockRepositoryMockRecorder) CreateOverride(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOverride", reflect.TypeOf((*MockRepository)(nil).CreateOverride), arg0, arg1)
+}
+
+// CreateShift mocks base method.
+func (m *MockRepository) CreateShift(arg0 context.Context, arg1 db.CreateShiftParams) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShift", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateShift indicates an expected call of CreateShift.
+func (mr *MockRepositoryMockRecorder) CreateShift(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShift", reflect.TypeOf((*MockRepository)(nil).CreateShift), arg0, arg1)
+}
+
+// CreateSwap mocks base method.
+func (m *MockRepository) CreateSwap(arg0 context.Context, arg1 db.CreateSwapParams) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSwap", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSwap indicates an expected call of CreateSwap.
+func (mr *MockRepositoryMockRecorder) CreateSwap(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSwap", reflect.TypeOf((*MockRepository)(nil).CreateSwap), arg0, arg1)
+}
+
+// CreateUnavailability mocks base method.
+func (m *MockRepository) CreateUnavailability(arg0 context.Context, arg1 db.CreateUnavailabilityParams) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUnavailability", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUnavailability indicates an expected call of CreateUnavailability.
+func (mr *MockRepositoryMockRecorder) CreateUnavailability(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUnavailability", reflect.TypeOf((*MockRepository)(nil).CreateUnavailability), arg0, arg1)
+}
+
+// CreateUninstallation mocks base method.
+func (m *MockRepository) CreateUninstallation(arg0 context.Context, arg1 db.CreateUninstallationParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUninstallation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUninstallation indicates an expected call of CreateUninstallation.
+func (mr *MockRepositoryMockRecorder) CreateUninstallation(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUninstallation", reflect.TypeOf((*MockRepository)(nil).CreateUninstallation), arg0, arg1)
+}
+
+// DeleteEvents mocks base method.
+func (m *MockRepository) DeleteEvents(arg0 context.Context, arg1 pgtype.Timestamptz) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEvents", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEvents indicates an expected call of DeleteEvents.
+func (mr *MockRepositoryMockRecorder) DeleteEvents(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEvents", reflect.TypeOf((*MockRepository)(nil).DeleteEvents), arg0, arg1)
+}
+
+// DeleteUninstallation mocks base method.
+func (m *MockRepository) DeleteUninstallation(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUninstallation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUninstallation indicates an expected call of DeleteUninstallation.
+func (mr *MockRepositoryMockRecorder) DeleteUninstallation(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUninstallation", reflect.TypeOf((*MockRepository)(nil).DeleteUninstallation), arg0, arg1)
+}
+
+// EndShifts mocks base method.
+func (m *MockRepository) EndShifts(arg0 context.Context, arg1 db.EndShiftsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndShifts", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EndShifts indicates an expected call of EndShifts.
+func (mr *MockRepositoryMockRecorder) EndShifts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndShifts", reflect.TypeOf((*MockRepository)(nil).EndShifts), arg0, arg1)
+}
+
 // FindRotaByID mocks base method.
 func (m *MockRepository) FindRotaByID(arg0 context.Context, arg1 string) (db.Rota, error) {
 	m.ctrl.T.Helper()
@@ -69,6 +200,81 @@ func (mr *MockRepositoryMockRecorder) FindRotaByID(arg0, arg1 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRotaByID", reflect.TypeOf((*MockRepository)(nil).FindRotaByID), arg0, arg1)
 }
 
+// FindRotaByIDForUpdate mocks base method.
+func (m *MockRepository) FindRotaByIDForUpdate(arg0 context.Context, arg1 string) (db.Rota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRotaByIDForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Rota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRotaByIDForUpdate indicates an expected call of FindRotaByIDForUpdate.
+func (mr *MockRepositoryMockRecorder) FindRotaByIDForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRotaByIDForUpdate", reflect.TypeOf((*MockRepository)(nil).FindRotaByIDForUpdate), arg0, arg1)
+}
+
+// FindSwapByIDForUpdate mocks base method.
+func (m *MockRepository) FindSwapByIDForUpdate(arg0 context.Context, arg1 string) (db.Swap, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSwapByIDForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Swap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSwapByIDForUpdate indicates an expected call of FindSwapByIDForUpdate.
+func (mr *MockRepositoryMockRecorder) FindSwapByIDForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSwapByIDForUpdate", reflect.TypeOf((*MockRepository)(nil).FindSwapByIDForUpdate), arg0, arg1)
+}
+
+// ListMembersByRotaID mocks base method.
+func (m *MockRepository) ListMembersByRotaID(arg0 context.Context, arg1 string) ([]db.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembersByRotaID", arg0, arg1)
+	ret0, _ := ret[0].([]db.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembersByRotaID indicates an expected call of ListMembersByRotaID.
+func (mr *MockRepositoryMockRecorder) ListMembersByRotaID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembersByRotaID", reflect.TypeOf((*MockRepository)(nil).ListMembersByRotaID), arg0, arg1)
+}
+
+// ListOverridesByRotaID mocks base method.
+func (m *MockRepository) ListOverridesByRotaID(arg0 context.Context, arg1 db.ListOverridesByRotaIDParams) ([]db.Override, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOverridesByRotaID", arg0, arg1)
+	ret0, _ := ret[0].([]db.Override)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOverridesByRotaID indicates an expected call of ListOverridesByRotaID.
+func (mr *MockRepositoryMockRecorder) ListOverridesByRotaID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOverridesByRotaID", reflect.TypeOf((*MockRepository)(nil).ListOverridesByRotaID), arg0, arg1)
+}
+
+// ListRotas mocks base method.
+func (m *MockRepository) ListRotas(arg0 context.Context, arg1 db.ListRotasParams) ([]db.Rota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRotas", arg0, arg1)
+	ret0, _ := ret[0].([]db.Rota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRotas indicates an expected call of ListRotas.
+func (mr *MockRepositoryMockRecorder) ListRotas(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRotas", reflect.TypeOf((*MockRepository)(nil).ListRotas), arg0, arg1)
+}
+
 // ListRotasByChannel mocks base method.
 func (m *MockRepository) ListRotasByChannel(arg0 context.Context, arg1 db.ListRotasByChannelParams) ([]db.Rota, error) {
 	m.ctrl.T.Helper()
@@ -84,6 +290,96 @@ func (mr *MockRepositoryMockRecorder) ListRotasByChannel(arg0, arg1 any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRotasByChannel", reflect.TypeOf((*MockRepository)(nil).ListRotasByChannel), arg0, arg1)
 }
 
+// ListRotasByUserID mocks base method.
+func (m *MockRepository) ListRotasByUserID(arg0 context.Context, arg1 db.ListRotasByUserIDParams) ([]db.Rota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRotasByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]db.Rota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRotasByUserID indicates an expected call of ListRotasByUserID.
+func (mr *MockRepositoryMockRecorder) ListRotasByUserID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRotasByUserID", reflect.TypeOf((*MockRepository)(nil).ListRotasByUserID), arg0, arg1)
+}
+
+// ListShiftsByRotaID mocks base method.
+func (m *MockRepository) ListShiftsByRotaID(arg0 context.Context, arg1 db.ListShiftsByRotaIDParams) ([]db.Shift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShiftsByRotaID", arg0, arg1)
+	ret0, _ := ret[0].([]db.Shift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShiftsByRotaID indicates an expected call of ListShiftsByRotaID.
+func (mr *MockRepositoryMockRecorder) ListShiftsByRotaID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShiftsByRotaID", reflect.TypeOf((*MockRepository)(nil).ListShiftsByRotaID), arg0, arg1)
+}
+
+// ListShiftsByUserID mocks base method.
+func (m *MockRepository) ListShiftsByUserID(arg0 context.Context, arg1 db.ListShiftsByUserIDParams) ([]db.Shift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShiftsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]db.Shift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShiftsByUserID indicates an expected call of ListShiftsByUserID.
+func (mr *MockRepositoryMockRecorder) ListShiftsByUserID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShiftsByUserID", reflect.TypeOf((*MockRepository)(nil).ListShiftsByUserID), arg0, arg1)
+}
+
+// ListSkipsByRotaID mocks base method.
+func (m *MockRepository) ListSkipsByRotaID(arg0 context.Context, arg1 string) ([]db.Shift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSkipsByRotaID", arg0, arg1)
+	ret0, _ := ret[0].([]db.Shift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSkipsByRotaID indicates an expected call of ListSkipsByRotaID.
+func (mr *MockRepositoryMockRecorder) ListSkipsByRotaID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSkipsByRotaID", reflect.TypeOf((*MockRepository)(nil).ListSkipsByRotaID), arg0, arg1)
+}
+
+// ListUnavailabilitiesByUserIDs mocks base method.
+func (m *MockRepository) ListUnavailabilitiesByUserIDs(arg0 context.Context, arg1 db.ListUnavailabilitiesByUserIDsParams) ([]db.Unavailability, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUnavailabilitiesByUserIDs", arg0, arg1)
+	ret0, _ := ret[0].([]db.Unavailability)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnavailabilitiesByUserIDs indicates an expected call of ListUnavailabilitiesByUserIDs.
+func (mr *MockRepositoryMockRecorder) ListUnavailabilitiesByUserIDs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnavailabilitiesByUserIDs", reflect.TypeOf((*MockRepository)(nil).ListUnavailabilitiesByUserIDs), arg0, arg1)
+}
+
+// ListUninstallations mocks base method.
+func (m *MockRepository) ListUninstallations(arg0 context.Context, arg1 pgtype.Timestamptz) ([]db.Uninstallation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUninstallations", arg0, arg1)
+	ret0, _ := ret[0].([]db.Uninstallation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUninstallations indicates an expected call of ListUninstallations.
+func (mr *MockRepositoryMockRecorder) ListUninstallations(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUninstallations", reflect.TypeOf((*MockRepository)(nil).ListUninstallations), arg0, arg1)
+}
+
 // ListUserIDsByRotaID mocks base method.
 func (m *MockRepository) ListUserIDsByRotaID(arg0 context.Context, arg1 string) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -99,6 +395,63 @@ func (mr *MockRepositoryMockRecorder) ListUserIDsByRotaID(arg0, arg1 any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserIDsByRotaID", reflect.TypeOf((*MockRepository)(nil).ListUserIDsByRotaID), arg0, arg1)
 }
 
+// LockRotaByID mocks base method.
+func (m *MockRepository) LockRotaByID(arg0 context.Context, arg1 string) (db.Rota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockRotaByID", arg0, arg1)
+	ret0, _ := ret[0].(db.Rota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockRotaByID indicates an expected call of LockRotaByID.
+func (mr *MockRepositoryMockRecorder) LockRotaByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockRotaByID", reflect.TypeOf((*MockRepository)(nil).LockRotaByID), arg0, arg1)
+}
+
+// MoveMember mocks base method.
+func (m *MockRepository) MoveMember(arg0 context.Context, arg1 db.MoveMemberParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveMember", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveMember indicates an expected call of MoveMember.
+func (mr *MockRepositoryMockRecorder) MoveMember(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveMember", reflect.TypeOf((*MockRepository)(nil).MoveMember), arg0, arg1)
+}
+
+// PurgeTeam mocks base method.
+func (m *MockRepository) PurgeTeam(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTeam", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeTeam indicates an expected call of PurgeTeam.
+func (mr *MockRepositoryMockRecorder) PurgeTeam(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTeam", reflect.TypeOf((*MockRepository)(nil).PurgeTeam), arg0, arg1)
+}
+
+// RemoveMember mocks base method.
+func (m *MockRepository) RemoveMember(arg0 context.Context, arg1 db.RemoveMemberParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockRepositoryMockRecorder) RemoveMember(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockRepository)(nil).RemoveMember), arg0, arg1)
+}
+
 // UpdateRotaMembers mocks base method.
 func (m *MockRepository) UpdateRotaMembers(arg0 context.Context, arg1 []db.Member) error {
 	m.ctrl.T.Helper()
@@ -112,3 +465,45 @@ func (mr *MockRepositoryMockRecorder) UpdateRotaMembers(arg0, arg1 any) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRotaMembers", reflect.TypeOf((*MockRepository)(nil).UpdateRotaMembers), arg0, arg1)
 }
+
+// UpdateRotaState mocks base method.
+func (m *MockRepository) UpdateRotaState(arg0 context.Context, arg1 db.UpdateRotaStateParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRotaState", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRotaState indicates an expected call of UpdateRotaState.
+func (mr *MockRepositoryMockRecorder) UpdateRotaState(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRotaState", reflect.TypeOf((*MockRepository)(nil).UpdateRotaState), arg0, arg1)
+}
+
+// UpdateRotaStatus mocks base method.
+func (m *MockRepository) UpdateRotaStatus(arg0 context.Context, arg1 db.UpdateRotaStatusParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRotaStatus", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRotaStatus indicates an expected call of UpdateRotaStatus.
+func (mr *MockRepositoryMockRecorder) UpdateRotaStatus(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRotaStatus", reflect.TypeOf((*MockRepository)(nil).UpdateRotaStatus), arg0, arg1)
+}
+
+// UpdateSwapStatus mocks base method.
+func (m *MockRepository) UpdateSwapStatus(arg0 context.Context, arg1 db.UpdateSwapStatusParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSwapStatus", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSwapStatus indicates an expected call of UpdateSwapStatus.
+func (mr *MockRepositoryMockRecorder) UpdateSwapStatus(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSwapStatus", reflect.TypeOf((*MockRepository)(nil).UpdateSwapStatus), arg0, arg1)
+}
@@ -8,21 +8,91 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Event struct {
+	ID        string             `json:"id"`
+	TeamID    string             `json:"team_id"`
+	Type      string             `json:"type"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type Member struct {
-	ID        string           `json:"id"`
-	RotaID    string           `json:"rota_id"`
-	UserID    string           `json:"user_id"`
-	Metadata  MemberMetadata   `json:"metadata"`
-	CreatedAt pgtype.Timestamp `json:"created_at"`
-	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	ID        string             `json:"id"`
+	RotaID    string             `json:"rota_id"`
+	UserID    string             `json:"user_id"`
+	Metadata  MemberMetadata     `json:"metadata"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	Position  int32              `json:"position"`
+}
+
+type Override struct {
+	ID        string             `json:"id"`
+	RotaID    string             `json:"rota_id"`
+	UserID    string             `json:"user_id"`
+	StartsAt  pgtype.Timestamptz `json:"starts_at"`
+	EndsAt    pgtype.Timestamptz `json:"ends_at"`
+	Metadata  OverrideMetadata   `json:"metadata"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
 type Rota struct {
-	ID        string           `json:"id"`
-	TeamID    string           `json:"team_id"`
-	ChannelID string           `json:"channel_id"`
-	Name      string           `json:"name"`
-	Metadata  RotaMetadata     `json:"metadata"`
-	CreatedAt pgtype.Timestamp `json:"created_at"`
-	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	ID        string             `json:"id"`
+	TeamID    string             `json:"team_id"`
+	ChannelID string             `json:"channel_id"`
+	Name      string             `json:"name"`
+	Metadata  RotaMetadata       `json:"metadata"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	State     RotaState          `json:"state"`
+	Status    RotaStatus         `json:"status"`
+	StartsAt  pgtype.Timestamptz `json:"starts_at"`
+	EndsAt    pgtype.Timestamptz `json:"ends_at"`
+}
+
+type Shift struct {
+	ID        string             `json:"id"`
+	RotaID    string             `json:"rota_id"`
+	UserID    string             `json:"user_id"`
+	StartsAt  pgtype.Timestamptz `json:"starts_at"`
+	EndsAt    pgtype.Timestamptz `json:"ends_at"`
+	Reason    ShiftReason        `json:"reason"`
+	Metadata  ShiftMetadata      `json:"metadata"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Swap struct {
+	ID                string             `json:"id"`
+	RotaID            string             `json:"rota_id"`
+	RequesterID       string             `json:"requester_id"`
+	RequesterStartsAt pgtype.Timestamptz `json:"requester_starts_at"`
+	RequesterEndsAt   pgtype.Timestamptz `json:"requester_ends_at"`
+	RecipientID       string             `json:"recipient_id"`
+	RecipientStartsAt pgtype.Timestamptz `json:"recipient_starts_at"`
+	RecipientEndsAt   pgtype.Timestamptz `json:"recipient_ends_at"`
+	Status            SwapStatus         `json:"status"`
+	ExpiresAt         pgtype.Timestamptz `json:"expires_at"`
+	Metadata          SwapMetadata       `json:"metadata"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Unavailability struct {
+	ID        string                 `json:"id"`
+	TeamID    string                 `json:"team_id"`
+	UserID    string                 `json:"user_id"`
+	StartsAt  pgtype.Timestamptz     `json:"starts_at"`
+	EndsAt    pgtype.Timestamptz     `json:"ends_at"`
+	Metadata  UnavailabilityMetadata `json:"metadata"`
+	CreatedAt pgtype.Timestamptz     `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz     `json:"updated_at"`
+}
+
+type Uninstallation struct {
+	TeamID        string             `json:"team_id"`
+	Reason        UninstallReason    `json:"reason"`
+	UninstalledAt pgtype.Timestamptz `json:"uninstalled_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
 }
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 
 	"github.com/rotabot-io/rotabot/internal"
 
@@ -18,6 +21,9 @@ type CreateOrUpdateRotaParams struct {
 	ChannelID string
 	Name      string
 	Metadata  RotaMetadata
+	// StartsAt and EndsAt are zero for rotas that start right away and never end.
+	StartsAt time.Time
+	EndsAt   time.Time
 }
 
 func (q *Queries) CreateOrUpdateRota(ctx context.Context, p CreateOrUpdateRotaParams) (string, error) {
@@ -29,6 +35,8 @@ func (q *Queries) CreateOrUpdateRota(ctx context.Context, p CreateOrUpdateRotaPa
 			ID:       p.RotaID,
 			Name:     p.Name,
 			Metadata: p.Metadata,
+			StartsAt: optionalTimestamptz(p.StartsAt),
+			EndsAt:   optionalTimestamptz(p.EndsAt),
 		})
 	} else {
 		rotaId, err = q.saveRota(ctx, saveRotaParams{
@@ -36,6 +44,8 @@ func (q *Queries) CreateOrUpdateRota(ctx context.Context, p CreateOrUpdateRotaPa
 			TeamID:    p.TeamID,
 			ChannelID: p.ChannelID,
 			Metadata:  p.Metadata,
+			StartsAt:  optionalTimestamptz(p.StartsAt),
+			EndsAt:    optionalTimestamptz(p.EndsAt),
 		})
 	}
 	if err != nil {
@@ -68,7 +78,7 @@ func (q *Queries) UpdateRotaMembers(ctx context.Context, members []Member) error
 	for _, userId := range e {
 		// Check existing member needs to be deleted
 		if inx := slices.IndexFunc(members, func(member Member) bool { return member.UserID == userId }); inx == -1 {
-			err = q.deleteMember(ctx, userId)
+			err = q.deleteMember(ctx, deleteMemberParams{RotaID: rotaId, UserID: userId})
 			if err != nil {
 				l.Error("unable_to_delete_member",
 					zap.Error(err),
@@ -79,6 +89,7 @@ func (q *Queries) UpdateRotaMembers(ctx context.Context, members []Member) error
 			}
 		}
 	}
+	// Existing members keep their position, new ones are added at the end in the order they were given.
 	for _, m := range members {
 		// Check if desired member already exist before adding
 		if inx := slices.IndexFunc(e, func(userId string) bool { return m.UserID == userId }); inx == -1 {
@@ -103,6 +114,255 @@ func (q *Queries) UpdateRotaMembers(ctx context.Context, members []Member) error
 	return nil
 }
 
+type MoveMemberParams struct {
+	RotaID string
+	UserID string
+	// Offset is how many positions the member moves, negative values move them towards the start of the rota.
+	Offset int
+}
+
+// MoveMember changes where the member is in the order of the rota, members at either end of the rota stay there
+// when asked to move past it.
+func (q *Queries) MoveMember(ctx context.Context, p MoveMemberParams) error {
+	l := zapctx.Logger(ctx).With(zap.String("rota_id", p.RotaID), zap.String("user_id", p.UserID))
+	members, err := q.ListMembersByRotaID(ctx, p.RotaID)
+	if err != nil {
+		l.Error("unable_to_fetch_existing_members", zap.Error(err))
+		return err
+	}
+	from := slices.IndexFunc(members, func(m Member) bool { return m.UserID == p.UserID })
+	if from == -1 {
+		return ErrNotFound
+	}
+	to := from + p.Offset
+	if to < 0 {
+		to = 0
+	}
+	if to > len(members)-1 {
+		to = len(members) - 1
+	}
+	moved := members[from]
+	members = slices.Insert(slices.Delete(members, from, from+1), to, moved)
+
+	// Positions are numbered again from scratch, this also closes the gaps left by members that were removed.
+	for i, m := range members {
+		if m.Position == int32(i) {
+			continue
+		}
+		if err = q.updateMemberPosition(ctx, updateMemberPositionParams{Position: int32(i), ID: m.ID}); err != nil {
+			l.Error("unable_to_update_member_position", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+type RemoveMemberParams struct {
+	RotaID string
+	UserID string
+}
+
+// RemoveMember takes the user out of the rota, it returns ErrNotFound when they aren't a member of it. Unlike
+// UpdateRotaMembers it can leave the rota without members.
+func (q *Queries) RemoveMember(ctx context.Context, p RemoveMemberParams) error {
+	l := zapctx.Logger(ctx).With(zap.String("rota_id", p.RotaID), zap.String("user_id", p.UserID))
+	userIDs, err := q.ListUserIDsByRotaID(ctx, p.RotaID)
+	if err != nil {
+		l.Error("unable_to_fetch_existing_members", zap.Error(err))
+		return err
+	}
+	if !slices.Contains(userIDs, p.UserID) {
+		return ErrNotFound
+	}
+	if err = q.deleteMember(ctx, deleteMemberParams{RotaID: p.RotaID, UserID: p.UserID}); err != nil {
+		l.Error("unable_to_delete_member", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+type CreateShiftParams struct {
+	RotaID   string
+	UserID   string
+	StartsAt time.Time
+	EndsAt   time.Time
+	Reason   ShiftReason
+	Metadata ShiftMetadata
+}
+
+func (q *Queries) CreateShift(ctx context.Context, p CreateShiftParams) (string, error) {
+	l := zapctx.Logger(ctx)
+	shiftId, err := q.saveShift(ctx, saveShiftParams{
+		RotaID:   p.RotaID,
+		UserID:   p.UserID,
+		StartsAt: Timestamptz(p.StartsAt),
+		EndsAt:   Timestamptz(p.EndsAt),
+		Reason:   p.Reason,
+		Metadata: p.Metadata,
+	})
+	if err != nil {
+		err = mapError(err)
+		l.Error("unable_to_save_shift",
+			zap.Error(err),
+			zap.String("rota_id", p.RotaID),
+			zap.String("user_id", p.UserID),
+		)
+		return "", err
+	}
+	return shiftId, nil
+}
+
+type CreateOverrideParams struct {
+	RotaID   string
+	UserID   string
+	StartsAt time.Time
+	EndsAt   time.Time
+	Metadata OverrideMetadata
+}
+
+func (q *Queries) CreateOverride(ctx context.Context, p CreateOverrideParams) (string, error) {
+	l := zapctx.Logger(ctx)
+	overrideId, err := q.saveOverride(ctx, saveOverrideParams{
+		RotaID:   p.RotaID,
+		UserID:   p.UserID,
+		StartsAt: Timestamptz(p.StartsAt),
+		EndsAt:   Timestamptz(p.EndsAt),
+		Metadata: p.Metadata,
+	})
+	if err != nil {
+		err = mapError(err)
+		l.Error("unable_to_save_override",
+			zap.Error(err),
+			zap.String("rota_id", p.RotaID),
+			zap.String("user_id", p.UserID),
+		)
+		return "", err
+	}
+	return overrideId, nil
+}
+
+type CreateSwapParams struct {
+	RotaID            string
+	RequesterID       string
+	RequesterStartsAt time.Time
+	RequesterEndsAt   time.Time
+	RecipientID       string
+	RecipientStartsAt time.Time
+	RecipientEndsAt   time.Time
+	ExpiresAt         time.Time
+	Metadata          SwapMetadata
+}
+
+// CreateSwap saves a request to swap shifts, it stays pending until the recipient answers it or it expires.
+func (q *Queries) CreateSwap(ctx context.Context, p CreateSwapParams) (string, error) {
+	l := zapctx.Logger(ctx)
+	swapId, err := q.saveSwap(ctx, saveSwapParams{
+		RotaID:            p.RotaID,
+		RequesterID:       p.RequesterID,
+		RequesterStartsAt: Timestamptz(p.RequesterStartsAt),
+		RequesterEndsAt:   Timestamptz(p.RequesterEndsAt),
+		RecipientID:       p.RecipientID,
+		RecipientStartsAt: Timestamptz(p.RecipientStartsAt),
+		RecipientEndsAt:   Timestamptz(p.RecipientEndsAt),
+		Status:            SSPending,
+		ExpiresAt:         Timestamptz(p.ExpiresAt),
+		Metadata:          p.Metadata,
+	})
+	if err != nil {
+		err = mapError(err)
+		l.Error("unable_to_save_swap",
+			zap.Error(err),
+			zap.String("rota_id", p.RotaID),
+			zap.String("requester_id", p.RequesterID),
+			zap.String("recipient_id", p.RecipientID),
+		)
+		return "", err
+	}
+	return swapId, nil
+}
+
+type CreateUnavailabilityParams struct {
+	TeamID   string
+	UserID   string
+	StartsAt time.Time
+	EndsAt   time.Time
+	Metadata UnavailabilityMetadata
+}
+
+// CreateUnavailability saves a period in which the user can't be on duty for any of the rotas they're a member of.
+func (q *Queries) CreateUnavailability(ctx context.Context, p CreateUnavailabilityParams) (string, error) {
+	l := zapctx.Logger(ctx)
+	unavailabilityId, err := q.saveUnavailability(ctx, saveUnavailabilityParams{
+		TeamID:   p.TeamID,
+		UserID:   p.UserID,
+		StartsAt: Timestamptz(p.StartsAt),
+		EndsAt:   Timestamptz(p.EndsAt),
+		Metadata: p.Metadata,
+	})
+	if err != nil {
+		err = mapError(err)
+		l.Error("unable_to_save_unavailability",
+			zap.Error(err),
+			zap.String("team_id", p.TeamID),
+			zap.String("user_id", p.UserID),
+		)
+		return "", err
+	}
+	return unavailabilityId, nil
+}
+
+// PurgeTeam deletes the rotas of the team together with their members, shifts, overrides and swaps, the
+// unavailabilities of its users and its uninstallation. Nothing is left behind of the team once it's done.
+func (q *Queries) PurgeTeam(ctx context.Context, teamID string) error {
+	l := zapctx.Logger(ctx).With(zap.String("team_id", teamID))
+	if err := q.deleteRotasByTeamID(ctx, teamID); err != nil {
+		l.Error("unable_to_delete_rotas", zap.Error(err))
+		return err
+	}
+	if err := q.deleteUnavailabilitiesByTeamID(ctx, teamID); err != nil {
+		l.Error("unable_to_delete_unavailabilities", zap.Error(err))
+		return err
+	}
+	if err := q.DeleteUninstallation(ctx, teamID); err != nil {
+		l.Error("unable_to_delete_uninstallation", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+type CreateEventParams struct {
+	ID     string
+	TeamID string
+	Type   string
+}
+
+// CreateEvent records that the event was received, it returns ErrAlreadyExists when it was received before. Slack
+// sends an event again when it isn't acknowledged in time, with the same id.
+func (q *Queries) CreateEvent(ctx context.Context, p CreateEventParams) error {
+	n, err := q.saveEvent(ctx, saveEventParams{ID: p.ID, TeamID: p.TeamID, Type: p.Type})
+	if err != nil {
+		zapctx.Logger(ctx).Error("unable_to_save_event", zap.Error(err), zap.String("event_id", p.ID))
+		return err
+	}
+	if n == 0 {
+		return ErrAlreadyExists
+	}
+	return nil
+}
+
+// Timestamptz converts t into a value that can be stored in a TIMESTAMPTZ column.
+func Timestamptz(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
+
+// optionalTimestamptz stores the zero time as NULL.
+func optionalTimestamptz(t time.Time) pgtype.Timestamptz {
+	if t.IsZero() {
+		return pgtype.Timestamptz{}
+	}
+	return Timestamptz(t)
+}
+
 func mapError(err error) error {
 	var pgError *pgconn.PgError
 	if errors.As(err, &pgError) {
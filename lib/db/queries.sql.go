@@ -7,10 +7,64 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const createUninstallation = `-- name: CreateUninstallation :exec
+INSERT INTO UNINSTALLATIONS (TEAM_ID, REASON)
+VALUES ($1, $2) ON CONFLICT (TEAM_ID) DO NOTHING
+`
+
+type CreateUninstallationParams struct {
+	TeamID string          `json:"team_id"`
+	Reason UninstallReason `json:"reason"`
+}
+
+func (q *Queries) CreateUninstallation(ctx context.Context, arg CreateUninstallationParams) error {
+	_, err := q.db.Exec(ctx, createUninstallation, arg.TeamID, arg.Reason)
+	return err
+}
+
+const deleteEvents = `-- name: DeleteEvents :exec
+DELETE FROM EVENTS WHERE CREATED_AT < $1
+`
+
+func (q *Queries) DeleteEvents(ctx context.Context, createdAt pgtype.Timestamptz) error {
+	_, err := q.db.Exec(ctx, deleteEvents, createdAt)
+	return err
+}
+
+const deleteUninstallation = `-- name: DeleteUninstallation :exec
+DELETE FROM UNINSTALLATIONS WHERE TEAM_ID = $1
+`
+
+func (q *Queries) DeleteUninstallation(ctx context.Context, teamID string) error {
+	_, err := q.db.Exec(ctx, deleteUninstallation, teamID)
+	return err
+}
+
+const endShifts = `-- name: EndShifts :exec
+UPDATE SHIFTS
+SET ENDS_AT = $1
+WHERE SHIFTS.ROTA_ID = $2
+  AND SHIFTS.STARTS_AT < $1
+  AND SHIFTS.ENDS_AT > $1
+  AND SHIFTS.REASON <> 'skip'
+`
+
+type EndShiftsParams struct {
+	EndsAt pgtype.Timestamptz `json:"ends_at"`
+	RotaID string             `json:"rota_id"`
+}
+
+func (q *Queries) EndShifts(ctx context.Context, arg EndShiftsParams) error {
+	_, err := q.db.Exec(ctx, endShifts, arg.EndsAt, arg.RotaID)
+	return err
+}
+
 const findRotaByID = `-- name: FindRotaByID :one
-SELECT rotas.id, rotas.team_id, rotas.channel_id, rotas.name, rotas.metadata, rotas.created_at, rotas.updated_at
+SELECT rotas.id, rotas.team_id, rotas.channel_id, rotas.name, rotas.metadata, rotas.created_at, rotas.updated_at, rotas.state, rotas.status, rotas.starts_at, rotas.ends_at
 FROM ROTAS
 WHERE ID = $1
 `
@@ -26,12 +80,193 @@ func (q *Queries) FindRotaByID(ctx context.Context, id string) (Rota, error) {
 		&i.Metadata,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.State,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
 	)
 	return i, err
 }
 
+const findRotaByIDForUpdate = `-- name: FindRotaByIDForUpdate :one
+SELECT rotas.id, rotas.team_id, rotas.channel_id, rotas.name, rotas.metadata, rotas.created_at, rotas.updated_at, rotas.state, rotas.status, rotas.starts_at, rotas.ends_at
+FROM ROTAS
+WHERE ID = $1 FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) FindRotaByIDForUpdate(ctx context.Context, id string) (Rota, error) {
+	row := q.db.QueryRow(ctx, findRotaByIDForUpdate, id)
+	var i Rota
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.ChannelID,
+		&i.Name,
+		&i.Metadata,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.State,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+	)
+	return i, err
+}
+
+const findSwapByIDForUpdate = `-- name: FindSwapByIDForUpdate :one
+SELECT swaps.id, swaps.rota_id, swaps.requester_id, swaps.requester_starts_at, swaps.requester_ends_at, swaps.recipient_id, swaps.recipient_starts_at, swaps.recipient_ends_at, swaps.status, swaps.expires_at, swaps.metadata, swaps.created_at, swaps.updated_at
+FROM SWAPS
+WHERE ID = $1 FOR UPDATE
+`
+
+func (q *Queries) FindSwapByIDForUpdate(ctx context.Context, id string) (Swap, error) {
+	row := q.db.QueryRow(ctx, findSwapByIDForUpdate, id)
+	var i Swap
+	err := row.Scan(
+		&i.ID,
+		&i.RotaID,
+		&i.RequesterID,
+		&i.RequesterStartsAt,
+		&i.RequesterEndsAt,
+		&i.RecipientID,
+		&i.RecipientStartsAt,
+		&i.RecipientEndsAt,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.Metadata,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listMembersByRotaID = `-- name: ListMembersByRotaID :many
+SELECT members.id, members.rota_id, members.user_id, members.metadata, members.created_at, members.updated_at, members.position
+FROM MEMBERS
+WHERE MEMBERS.ROTA_ID = $1
+ORDER BY MEMBERS.POSITION, MEMBERS.CREATED_AT, MEMBERS.ID
+`
+
+func (q *Queries) ListMembersByRotaID(ctx context.Context, rotaID string) ([]Member, error) {
+	rows, err := q.db.Query(ctx, listMembersByRotaID, rotaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Member{}
+	for rows.Next() {
+		var i Member
+		if err := rows.Scan(
+			&i.ID,
+			&i.RotaID,
+			&i.UserID,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Position,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOverridesByRotaID = `-- name: ListOverridesByRotaID :many
+SELECT overrides.id, overrides.rota_id, overrides.user_id, overrides.starts_at, overrides.ends_at, overrides.metadata, overrides.created_at, overrides.updated_at
+FROM OVERRIDES
+WHERE OVERRIDES.ROTA_ID = $1
+  AND OVERRIDES.STARTS_AT < $2
+  AND OVERRIDES.ENDS_AT > $3
+ORDER BY OVERRIDES.CREATED_AT
+`
+
+type ListOverridesByRotaIDParams struct {
+	RotaID string             `json:"rota_id"`
+	Until  pgtype.Timestamptz `json:"until"`
+	Since  pgtype.Timestamptz `json:"since"`
+}
+
+func (q *Queries) ListOverridesByRotaID(ctx context.Context, arg ListOverridesByRotaIDParams) ([]Override, error) {
+	rows, err := q.db.Query(ctx, listOverridesByRotaID, arg.RotaID, arg.Until, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Override{}
+	for rows.Next() {
+		var i Override
+		if err := rows.Scan(
+			&i.ID,
+			&i.RotaID,
+			&i.UserID,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRotas = `-- name: ListRotas :many
+SELECT rotas.id, rotas.team_id, rotas.channel_id, rotas.name, rotas.metadata, rotas.created_at, rotas.updated_at, rotas.state, rotas.status, rotas.starts_at, rotas.ends_at
+FROM ROTAS
+WHERE ID > $1
+  AND TEAM_ID NOT IN (SELECT UNINSTALLATIONS.TEAM_ID FROM UNINSTALLATIONS)
+ORDER BY ID
+LIMIT $2
+`
+
+type ListRotasParams struct {
+	ID    string `json:"id"`
+	Limit int32  `json:"limit"`
+}
+
+func (q *Queries) ListRotas(ctx context.Context, arg ListRotasParams) ([]Rota, error) {
+	rows, err := q.db.Query(ctx, listRotas, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Rota{}
+	for rows.Next() {
+		var i Rota
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.ChannelID,
+			&i.Name,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.State,
+			&i.Status,
+			&i.StartsAt,
+			&i.EndsAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listRotasByChannel = `-- name: ListRotasByChannel :many
-SELECT rotas.id, rotas.team_id, rotas.channel_id, rotas.name, rotas.metadata, rotas.created_at, rotas.updated_at
+SELECT rotas.id, rotas.team_id, rotas.channel_id, rotas.name, rotas.metadata, rotas.created_at, rotas.updated_at, rotas.state, rotas.status, rotas.starts_at, rotas.ends_at
 FROM ROTAS
 WHERE ROTAS.CHANNEL_ID = $1
   AND ROTAS.TEAM_ID = $2
@@ -59,6 +294,267 @@ func (q *Queries) ListRotasByChannel(ctx context.Context, arg ListRotasByChannel
 			&i.Metadata,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.State,
+			&i.Status,
+			&i.StartsAt,
+			&i.EndsAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRotasByUserID = `-- name: ListRotasByUserID :many
+SELECT rotas.id, rotas.team_id, rotas.channel_id, rotas.name, rotas.metadata, rotas.created_at, rotas.updated_at, rotas.state, rotas.status, rotas.starts_at, rotas.ends_at
+FROM ROTAS
+WHERE ROTAS.TEAM_ID = $2
+  AND ROTAS.ID IN (SELECT MEMBERS.ROTA_ID FROM MEMBERS WHERE MEMBERS.USER_ID = $1)
+ORDER BY ROTAS.NAME, ROTAS.ID
+`
+
+type ListRotasByUserIDParams struct {
+	UserID string `json:"user_id"`
+	TeamID string `json:"team_id"`
+}
+
+func (q *Queries) ListRotasByUserID(ctx context.Context, arg ListRotasByUserIDParams) ([]Rota, error) {
+	rows, err := q.db.Query(ctx, listRotasByUserID, arg.UserID, arg.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Rota{}
+	for rows.Next() {
+		var i Rota
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.ChannelID,
+			&i.Name,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.State,
+			&i.Status,
+			&i.StartsAt,
+			&i.EndsAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShiftsByRotaID = `-- name: ListShiftsByRotaID :many
+SELECT shifts.id, shifts.rota_id, shifts.user_id, shifts.starts_at, shifts.ends_at, shifts.reason, shifts.metadata, shifts.created_at, shifts.updated_at
+FROM SHIFTS
+WHERE SHIFTS.ROTA_ID = $1
+  AND SHIFTS.STARTS_AT < $2
+  AND SHIFTS.ENDS_AT > $3
+ORDER BY SHIFTS.STARTS_AT
+`
+
+type ListShiftsByRotaIDParams struct {
+	RotaID string             `json:"rota_id"`
+	Until  pgtype.Timestamptz `json:"until"`
+	Since  pgtype.Timestamptz `json:"since"`
+}
+
+func (q *Queries) ListShiftsByRotaID(ctx context.Context, arg ListShiftsByRotaIDParams) ([]Shift, error) {
+	rows, err := q.db.Query(ctx, listShiftsByRotaID, arg.RotaID, arg.Until, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Shift{}
+	for rows.Next() {
+		var i Shift
+		if err := rows.Scan(
+			&i.ID,
+			&i.RotaID,
+			&i.UserID,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Reason,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShiftsByUserID = `-- name: ListShiftsByUserID :many
+SELECT shifts.id, shifts.rota_id, shifts.user_id, shifts.starts_at, shifts.ends_at, shifts.reason, shifts.metadata, shifts.created_at, shifts.updated_at
+FROM SHIFTS
+WHERE SHIFTS.USER_ID = $1
+  AND SHIFTS.STARTS_AT < $2
+  AND SHIFTS.ENDS_AT > $3
+ORDER BY SHIFTS.STARTS_AT
+`
+
+type ListShiftsByUserIDParams struct {
+	UserID string             `json:"user_id"`
+	Until  pgtype.Timestamptz `json:"until"`
+	Since  pgtype.Timestamptz `json:"since"`
+}
+
+func (q *Queries) ListShiftsByUserID(ctx context.Context, arg ListShiftsByUserIDParams) ([]Shift, error) {
+	rows, err := q.db.Query(ctx, listShiftsByUserID, arg.UserID, arg.Until, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Shift{}
+	for rows.Next() {
+		var i Shift
+		if err := rows.Scan(
+			&i.ID,
+			&i.RotaID,
+			&i.UserID,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Reason,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSkipsByRotaID = `-- name: ListSkipsByRotaID :many
+SELECT shifts.id, shifts.rota_id, shifts.user_id, shifts.starts_at, shifts.ends_at, shifts.reason, shifts.metadata, shifts.created_at, shifts.updated_at
+FROM SHIFTS
+WHERE SHIFTS.ROTA_ID = $1
+  AND SHIFTS.REASON = 'skip'
+ORDER BY SHIFTS.STARTS_AT
+`
+
+func (q *Queries) ListSkipsByRotaID(ctx context.Context, rotaID string) ([]Shift, error) {
+	rows, err := q.db.Query(ctx, listSkipsByRotaID, rotaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Shift{}
+	for rows.Next() {
+		var i Shift
+		if err := rows.Scan(
+			&i.ID,
+			&i.RotaID,
+			&i.UserID,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Reason,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnavailabilitiesByUserIDs = `-- name: ListUnavailabilitiesByUserIDs :many
+SELECT unavailabilities.id, unavailabilities.team_id, unavailabilities.user_id, unavailabilities.starts_at, unavailabilities.ends_at, unavailabilities.metadata, unavailabilities.created_at, unavailabilities.updated_at
+FROM UNAVAILABILITIES
+WHERE UNAVAILABILITIES.TEAM_ID = $1
+  AND UNAVAILABILITIES.USER_ID = ANY ($2::TEXT[])
+  AND UNAVAILABILITIES.STARTS_AT < $3
+  AND UNAVAILABILITIES.ENDS_AT > $4
+ORDER BY UNAVAILABILITIES.STARTS_AT
+`
+
+type ListUnavailabilitiesByUserIDsParams struct {
+	TeamID  string             `json:"team_id"`
+	UserIds []string           `json:"user_ids"`
+	Until   pgtype.Timestamptz `json:"until"`
+	Since   pgtype.Timestamptz `json:"since"`
+}
+
+func (q *Queries) ListUnavailabilitiesByUserIDs(ctx context.Context, arg ListUnavailabilitiesByUserIDsParams) ([]Unavailability, error) {
+	rows, err := q.db.Query(ctx, listUnavailabilitiesByUserIDs,
+		arg.TeamID,
+		arg.UserIds,
+		arg.Until,
+		arg.Since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Unavailability{}
+	for rows.Next() {
+		var i Unavailability
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeamID,
+			&i.UserID,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUninstallations = `-- name: ListUninstallations :many
+SELECT uninstallations.team_id, uninstallations.reason, uninstallations.uninstalled_at, uninstallations.created_at, uninstallations.updated_at
+FROM UNINSTALLATIONS
+WHERE UNINSTALLATIONS.UNINSTALLED_AT <= $1
+ORDER BY UNINSTALLATIONS.UNINSTALLED_AT
+`
+
+func (q *Queries) ListUninstallations(ctx context.Context, uninstalledAt pgtype.Timestamptz) ([]Uninstallation, error) {
+	rows, err := q.db.Query(ctx, listUninstallations, uninstalledAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Uninstallation{}
+	for rows.Next() {
+		var i Uninstallation
+		if err := rows.Scan(
+			&i.TeamID,
+			&i.Reason,
+			&i.UninstalledAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -74,6 +570,7 @@ const listUserIDsByRotaID = `-- name: ListUserIDsByRotaID :many
 SELECT MEMBERS.USER_ID
 FROM MEMBERS
 WHERE MEMBERS.ROTA_ID = $1
+ORDER BY MEMBERS.POSITION, MEMBERS.CREATED_AT, MEMBERS.ID
 `
 
 func (q *Queries) ListUserIDsByRotaID(ctx context.Context, rotaID string) ([]string, error) {
@@ -96,18 +593,133 @@ func (q *Queries) ListUserIDsByRotaID(ctx context.Context, rotaID string) ([]str
 	return items, nil
 }
 
+const lockRotaByID = `-- name: LockRotaByID :one
+SELECT rotas.id, rotas.team_id, rotas.channel_id, rotas.name, rotas.metadata, rotas.created_at, rotas.updated_at, rotas.state, rotas.status, rotas.starts_at, rotas.ends_at
+FROM ROTAS
+WHERE ID = $1 FOR UPDATE
+`
+
+func (q *Queries) LockRotaByID(ctx context.Context, id string) (Rota, error) {
+	row := q.db.QueryRow(ctx, lockRotaByID, id)
+	var i Rota
+	err := row.Scan(
+		&i.ID,
+		&i.TeamID,
+		&i.ChannelID,
+		&i.Name,
+		&i.Metadata,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.State,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+	)
+	return i, err
+}
+
+const updateRotaState = `-- name: UpdateRotaState :exec
+UPDATE ROTAS
+SET STATE = $1
+WHERE ID = $2
+`
+
+type UpdateRotaStateParams struct {
+	State RotaState `json:"state"`
+	ID    string    `json:"id"`
+}
+
+func (q *Queries) UpdateRotaState(ctx context.Context, arg UpdateRotaStateParams) error {
+	_, err := q.db.Exec(ctx, updateRotaState, arg.State, arg.ID)
+	return err
+}
+
+const updateRotaStatus = `-- name: UpdateRotaStatus :exec
+UPDATE ROTAS
+SET STATUS = $1
+WHERE ID = $2
+`
+
+type UpdateRotaStatusParams struct {
+	Status RotaStatus `json:"status"`
+	ID     string     `json:"id"`
+}
+
+func (q *Queries) UpdateRotaStatus(ctx context.Context, arg UpdateRotaStatusParams) error {
+	_, err := q.db.Exec(ctx, updateRotaStatus, arg.Status, arg.ID)
+	return err
+}
+
+const updateSwapStatus = `-- name: UpdateSwapStatus :exec
+UPDATE SWAPS
+SET STATUS = $1
+WHERE ID = $2
+`
+
+type UpdateSwapStatusParams struct {
+	Status SwapStatus `json:"status"`
+	ID     string     `json:"id"`
+}
+
+func (q *Queries) UpdateSwapStatus(ctx context.Context, arg UpdateSwapStatusParams) error {
+	_, err := q.db.Exec(ctx, updateSwapStatus, arg.Status, arg.ID)
+	return err
+}
+
+const deleteRotasByTeamID = `-- name: deleteRotasByTeamID :exec
+DELETE FROM ROTAS WHERE TEAM_ID = $1
+`
+
+func (q *Queries) deleteRotasByTeamID(ctx context.Context, teamID string) error {
+	_, err := q.db.Exec(ctx, deleteRotasByTeamID, teamID)
+	return err
+}
+
+const deleteUnavailabilitiesByTeamID = `-- name: deleteUnavailabilitiesByTeamID :exec
+DELETE FROM UNAVAILABILITIES WHERE TEAM_ID = $1
+`
+
+func (q *Queries) deleteUnavailabilitiesByTeamID(ctx context.Context, teamID string) error {
+	_, err := q.db.Exec(ctx, deleteUnavailabilitiesByTeamID, teamID)
+	return err
+}
+
 const deleteMember = `-- name: deleteMember :exec
-DELETE FROM MEMBERS WHERE USER_ID = $1
+DELETE FROM MEMBERS WHERE ROTA_ID = $1 AND USER_ID = $2
 `
 
-func (q *Queries) deleteMember(ctx context.Context, userID string) error {
-	_, err := q.db.Exec(ctx, deleteMember, userID)
+type deleteMemberParams struct {
+	RotaID string `json:"rota_id"`
+	UserID string `json:"user_id"`
+}
+
+func (q *Queries) deleteMember(ctx context.Context, arg deleteMemberParams) error {
+	_, err := q.db.Exec(ctx, deleteMember, arg.RotaID, arg.UserID)
 	return err
 }
 
+const saveEvent = `-- name: saveEvent :execrows
+INSERT INTO EVENTS (ID, TEAM_ID, TYPE)
+VALUES ($1, $2, $3) ON CONFLICT (ID) DO NOTHING
+`
+
+type saveEventParams struct {
+	ID     string `json:"id"`
+	TeamID string `json:"team_id"`
+	Type   string `json:"type"`
+}
+
+func (q *Queries) saveEvent(ctx context.Context, arg saveEventParams) (int64, error) {
+	result, err := q.db.Exec(ctx, saveEvent, arg.ID, arg.TeamID, arg.Type)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const saveMember = `-- name: saveMember :one
-INSERT INTO MEMBERS (ROTA_ID, USER_ID, METADATA)
-VALUES ($1, $2, $3) RETURNING ID
+INSERT INTO MEMBERS (ROTA_ID, USER_ID, METADATA, POSITION)
+VALUES ($1, $2, $3, (SELECT COALESCE(MAX(POSITION) + 1, 0) FROM MEMBERS WHERE ROTA_ID = $1)) RETURNING ID
 `
 
 type saveMemberParams struct {
@@ -123,16 +735,44 @@ func (q *Queries) saveMember(ctx context.Context, arg saveMemberParams) (string,
 	return id, err
 }
 
+const saveOverride = `-- name: saveOverride :one
+INSERT INTO OVERRIDES (ROTA_ID, USER_ID, STARTS_AT, ENDS_AT, METADATA)
+VALUES ($1, $2, $3, $4, $5) RETURNING ID
+`
+
+type saveOverrideParams struct {
+	RotaID   string             `json:"rota_id"`
+	UserID   string             `json:"user_id"`
+	StartsAt pgtype.Timestamptz `json:"starts_at"`
+	EndsAt   pgtype.Timestamptz `json:"ends_at"`
+	Metadata OverrideMetadata   `json:"metadata"`
+}
+
+func (q *Queries) saveOverride(ctx context.Context, arg saveOverrideParams) (string, error) {
+	row := q.db.QueryRow(ctx, saveOverride,
+		arg.RotaID,
+		arg.UserID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Metadata,
+	)
+	var id string
+	err := row.Scan(&id)
+	return id, err
+}
+
 const saveRota = `-- name: saveRota :one
-INSERT INTO ROTAS (TEAM_ID, CHANNEL_ID, NAME, METADATA)
-VALUES ($1, $2, $3, $4) RETURNING ID
+INSERT INTO ROTAS (TEAM_ID, CHANNEL_ID, NAME, METADATA, STARTS_AT, ENDS_AT)
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING ID
 `
 
 type saveRotaParams struct {
-	TeamID    string       `json:"team_id"`
-	ChannelID string       `json:"channel_id"`
-	Name      string       `json:"name"`
-	Metadata  RotaMetadata `json:"metadata"`
+	TeamID    string             `json:"team_id"`
+	ChannelID string             `json:"channel_id"`
+	Name      string             `json:"name"`
+	Metadata  RotaMetadata       `json:"metadata"`
+	StartsAt  pgtype.Timestamptz `json:"starts_at"`
+	EndsAt    pgtype.Timestamptz `json:"ends_at"`
 }
 
 func (q *Queries) saveRota(ctx context.Context, arg saveRotaParams) (string, error) {
@@ -141,27 +781,146 @@ func (q *Queries) saveRota(ctx context.Context, arg saveRotaParams) (string, err
 		arg.ChannelID,
 		arg.Name,
 		arg.Metadata,
+		arg.StartsAt,
+		arg.EndsAt,
+	)
+	var id string
+	err := row.Scan(&id)
+	return id, err
+}
+
+const saveShift = `-- name: saveShift :one
+INSERT INTO SHIFTS (ROTA_ID, USER_ID, STARTS_AT, ENDS_AT, REASON, METADATA)
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING ID
+`
+
+type saveShiftParams struct {
+	RotaID   string             `json:"rota_id"`
+	UserID   string             `json:"user_id"`
+	StartsAt pgtype.Timestamptz `json:"starts_at"`
+	EndsAt   pgtype.Timestamptz `json:"ends_at"`
+	Reason   ShiftReason        `json:"reason"`
+	Metadata ShiftMetadata      `json:"metadata"`
+}
+
+func (q *Queries) saveShift(ctx context.Context, arg saveShiftParams) (string, error) {
+	row := q.db.QueryRow(ctx, saveShift,
+		arg.RotaID,
+		arg.UserID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Reason,
+		arg.Metadata,
+	)
+	var id string
+	err := row.Scan(&id)
+	return id, err
+}
+
+const saveSwap = `-- name: saveSwap :one
+INSERT INTO SWAPS (ROTA_ID, REQUESTER_ID, REQUESTER_STARTS_AT, REQUESTER_ENDS_AT, RECIPIENT_ID, RECIPIENT_STARTS_AT,
+                   RECIPIENT_ENDS_AT, STATUS, EXPIRES_AT, METADATA)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING ID
+`
+
+type saveSwapParams struct {
+	RotaID            string             `json:"rota_id"`
+	RequesterID       string             `json:"requester_id"`
+	RequesterStartsAt pgtype.Timestamptz `json:"requester_starts_at"`
+	RequesterEndsAt   pgtype.Timestamptz `json:"requester_ends_at"`
+	RecipientID       string             `json:"recipient_id"`
+	RecipientStartsAt pgtype.Timestamptz `json:"recipient_starts_at"`
+	RecipientEndsAt   pgtype.Timestamptz `json:"recipient_ends_at"`
+	Status            SwapStatus         `json:"status"`
+	ExpiresAt         pgtype.Timestamptz `json:"expires_at"`
+	Metadata          SwapMetadata       `json:"metadata"`
+}
+
+func (q *Queries) saveSwap(ctx context.Context, arg saveSwapParams) (string, error) {
+	row := q.db.QueryRow(ctx, saveSwap,
+		arg.RotaID,
+		arg.RequesterID,
+		arg.RequesterStartsAt,
+		arg.RequesterEndsAt,
+		arg.RecipientID,
+		arg.RecipientStartsAt,
+		arg.RecipientEndsAt,
+		arg.Status,
+		arg.ExpiresAt,
+		arg.Metadata,
 	)
 	var id string
 	err := row.Scan(&id)
 	return id, err
 }
 
+const saveUnavailability = `-- name: saveUnavailability :one
+INSERT INTO UNAVAILABILITIES (TEAM_ID, USER_ID, STARTS_AT, ENDS_AT, METADATA)
+VALUES ($1, $2, $3, $4, $5) RETURNING ID
+`
+
+type saveUnavailabilityParams struct {
+	TeamID   string                 `json:"team_id"`
+	UserID   string                 `json:"user_id"`
+	StartsAt pgtype.Timestamptz     `json:"starts_at"`
+	EndsAt   pgtype.Timestamptz     `json:"ends_at"`
+	Metadata UnavailabilityMetadata `json:"metadata"`
+}
+
+func (q *Queries) saveUnavailability(ctx context.Context, arg saveUnavailabilityParams) (string, error) {
+	row := q.db.QueryRow(ctx, saveUnavailability,
+		arg.TeamID,
+		arg.UserID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Metadata,
+	)
+	var id string
+	err := row.Scan(&id)
+	return id, err
+}
+
+const updateMemberPosition = `-- name: updateMemberPosition :exec
+UPDATE MEMBERS
+SET POSITION = $1
+WHERE ID = $2
+`
+
+type updateMemberPositionParams struct {
+	Position int32  `json:"position"`
+	ID       string `json:"id"`
+}
+
+func (q *Queries) updateMemberPosition(ctx context.Context, arg updateMemberPositionParams) error {
+	_, err := q.db.Exec(ctx, updateMemberPosition, arg.Position, arg.ID)
+	return err
+}
+
 const updateRota = `-- name: updateRota :one
 UPDATE ROTAS
-SET NAME     = $1,
-    METADATA = $2
-WHERE ID = $3 RETURNING ID
+SET NAME      = $1,
+    METADATA  = $2,
+    STARTS_AT = $3,
+    ENDS_AT   = $4
+WHERE ID = $5 RETURNING ID
 `
 
 type updateRotaParams struct {
-	Name     string       `json:"name"`
-	Metadata RotaMetadata `json:"metadata"`
-	ID       string       `json:"id"`
+	Name     string             `json:"name"`
+	Metadata RotaMetadata       `json:"metadata"`
+	StartsAt pgtype.Timestamptz `json:"starts_at"`
+	EndsAt   pgtype.Timestamptz `json:"ends_at"`
+	ID       string             `json:"id"`
 }
 
 func (q *Queries) updateRota(ctx context.Context, arg updateRotaParams) (string, error) {
-	row := q.db.QueryRow(ctx, updateRota, arg.Name, arg.Metadata, arg.ID)
+	row := q.db.QueryRow(ctx, updateRota,
+		arg.Name,
+		arg.Metadata,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.ID,
+	)
 	var id string
 	err := row.Scan(&id)
 	return id, err
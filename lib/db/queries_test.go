@@ -5,6 +5,7 @@ import (
 	"errors"
 	"path/filepath"
 	"sort"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgconn"
 
@@ -97,6 +98,44 @@ var _ = Describe("Rotas", func() {
 			Expect(rota.Name).To(Equal("bazbaz"))
 		})
 
+		It("stores when the rota starts and ends", func() {
+			startsAt := time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)
+			endsAt := time.Date(2023, time.March, 15, 0, 0, 0, 0, time.UTC)
+			id, err := q.CreateOrUpdateRota(ctx, CreateOrUpdateRotaParams{
+				ChannelID: "foo",
+				TeamID:    "bar",
+				Name:      "baz",
+				StartsAt:  startsAt,
+				EndsAt:    endsAt,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			rota, err := q.FindRotaByID(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.Status).To(Equal(RTActive))
+			Expect(rota.StartsAt.Time).To(BeTemporally("==", startsAt))
+			Expect(rota.EndsAt.Time).To(BeTemporally("==", endsAt))
+
+			_, err = q.CreateOrUpdateRota(ctx, CreateOrUpdateRotaParams{RotaID: id, Name: "baz"})
+			Expect(err).ToNot(HaveOccurred())
+
+			rota, err = q.FindRotaByID(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.StartsAt.Valid).To(BeFalse())
+			Expect(rota.EndsAt.Valid).To(BeFalse())
+		})
+
+		It("fails when the rota ends before it starts", func() {
+			_, err := q.CreateOrUpdateRota(ctx, CreateOrUpdateRotaParams{
+				ChannelID: "foo",
+				TeamID:    "bar",
+				Name:      "baz",
+				StartsAt:  time.Date(2023, time.March, 15, 0, 0, 0, 0, time.UTC),
+				EndsAt:    time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC),
+			})
+			Expect(err).To(HaveOccurred())
+		})
+
 		It("Should fail to update something it does not exist", func() {
 			_, err := q.CreateOrUpdateRota(ctx, CreateOrUpdateRotaParams{
 				RotaID: "not_found",
@@ -137,6 +176,27 @@ var _ = Describe("Rotas", func() {
 		})
 	})
 
+	Describe("UpdateRotaStatus", func() {
+		It("pauses and resumes the rota", func() {
+			id, err := q.CreateOrUpdateRota(ctx, CreateOrUpdateRotaParams{
+				ChannelID: "foo",
+				TeamID:    "bar",
+				Name:      "baz",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(q.UpdateRotaStatus(ctx, UpdateRotaStatusParams{ID: id, Status: RTPaused})).To(Succeed())
+			rota, err := q.FindRotaByID(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.Status).To(Equal(RTPaused))
+
+			Expect(q.UpdateRotaStatus(ctx, UpdateRotaStatusParams{ID: id, Status: RTActive})).To(Succeed())
+			rota, err = q.FindRotaByID(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.Status).To(Equal(RTActive))
+		})
+	})
+
 	Describe("UpdateRotaMembers", func() {
 		var rotaId string
 
@@ -276,6 +336,95 @@ var _ = Describe("Rotas", func() {
 				Expect(members[0]).To(Equal("12345"))
 				Expect(members[1]).To(Equal("98765"))
 			})
+
+			It("keeps existing members in place and adds new ones at the end", func() {
+				for _, userIDs := range [][]string{{"12345", "67891"}, {"67891", "98765", "12345"}} {
+					members := []Member{}
+					for _, userID := range userIDs {
+						members = append(members, Member{RotaID: rotaId, UserID: userID})
+					}
+					Expect(q.UpdateRotaMembers(ctx, members)).To(Succeed())
+				}
+
+				members, err := q.ListUserIDsByRotaID(ctx, rotaId)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(members).To(Equal([]string{"12345", "67891", "98765"}))
+			})
+		})
+	})
+
+	Describe("MoveMember", func() {
+		var rotaId string
+
+		BeforeEach(func() {
+			var err error
+			rotaId, err = q.CreateOrUpdateRota(ctx, CreateOrUpdateRotaParams{
+				ChannelID: "foo",
+				TeamID:    "bar",
+				Name:      "baz",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(q.UpdateRotaMembers(ctx, []Member{
+				{RotaID: rotaId, UserID: "alice"},
+				{RotaID: rotaId, UserID: "bob"},
+				{RotaID: rotaId, UserID: "carol"},
+			})).To(Succeed())
+		})
+
+		It("moves the member up and down the rota", func() {
+			Expect(q.MoveMember(ctx, MoveMemberParams{RotaID: rotaId, UserID: "carol", Offset: -1})).To(Succeed())
+			members, err := q.ListUserIDsByRotaID(ctx, rotaId)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(members).To(Equal([]string{"alice", "carol", "bob"}))
+
+			Expect(q.MoveMember(ctx, MoveMemberParams{RotaID: rotaId, UserID: "alice", Offset: 1})).To(Succeed())
+			members, err = q.ListUserIDsByRotaID(ctx, rotaId)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(members).To(Equal([]string{"carol", "alice", "bob"}))
+		})
+
+		It("keeps members at either end of the rota in place", func() {
+			Expect(q.MoveMember(ctx, MoveMemberParams{RotaID: rotaId, UserID: "alice", Offset: -1})).To(Succeed())
+			Expect(q.MoveMember(ctx, MoveMemberParams{RotaID: rotaId, UserID: "carol", Offset: 1})).To(Succeed())
+			members, err := q.ListUserIDsByRotaID(ctx, rotaId)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(members).To(Equal([]string{"alice", "bob", "carol"}))
+		})
+
+		It("fails when the user isn't a member of the rota", func() {
+			err := q.MoveMember(ctx, MoveMemberParams{RotaID: rotaId, UserID: "dave", Offset: 1})
+			Expect(err).To(MatchError(ErrNotFound))
+		})
+	})
+
+	Describe("RemoveMember", func() {
+		var rotaId string
+
+		BeforeEach(func() {
+			var err error
+			rotaId, err = q.CreateOrUpdateRota(ctx, CreateOrUpdateRotaParams{
+				ChannelID: "foo",
+				TeamID:    "bar",
+				Name:      "baz",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(q.UpdateRotaMembers(ctx, []Member{
+				{RotaID: rotaId, UserID: "alice"},
+				{RotaID: rotaId, UserID: "bob"},
+			})).To(Succeed())
+		})
+
+		It("removes members down to the last one", func() {
+			Expect(q.RemoveMember(ctx, RemoveMemberParams{RotaID: rotaId, UserID: "alice"})).To(Succeed())
+			Expect(q.RemoveMember(ctx, RemoveMemberParams{RotaID: rotaId, UserID: "bob"})).To(Succeed())
+			members, err := q.ListUserIDsByRotaID(ctx, rotaId)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(members).To(BeEmpty())
+		})
+
+		It("fails when the user isn't a member of the rota", func() {
+			err := q.RemoveMember(ctx, RemoveMemberParams{RotaID: rotaId, UserID: "dave"})
+			Expect(err).To(MatchError(ErrNotFound))
 		})
 	})
 
@@ -330,6 +479,47 @@ var _ = Describe("Rotas", func() {
 		})
 	})
 
+	Describe("ListRotasByUserID", func() {
+		saveRota := func(name, teamID string, userIDs ...string) string {
+			id, err := q.saveRota(ctx, saveRotaParams{
+				ChannelID: "C123",
+				TeamID:    teamID,
+				Name:      name,
+				Metadata: RotaMetadata{
+					Frequency:      RFDaily,
+					SchedulingType: RSRandom,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			members := []Member{}
+			for _, userID := range userIDs {
+				members = append(members, Member{RotaID: id, UserID: userID, Metadata: MemberMetadata{}})
+			}
+			Expect(q.UpdateRotaMembers(ctx, members)).To(Succeed())
+			return id
+		}
+
+		It("should return the rotas the user is a member of by name", func() {
+			deploys := saveRota("deploys", "T123", "U1", "U2")
+			onCall := saveRota("on call", "T123", "U2", "U1")
+			saveRota("support", "T123", "U2")
+
+			rotas, err := q.ListRotasByUserID(ctx, ListRotasByUserIDParams{UserID: "U1", TeamID: "T123"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rotas).To(HaveLen(2))
+			Expect(rotas[0].ID).To(Equal(deploys))
+			Expect(rotas[1].ID).To(Equal(onCall))
+		})
+
+		It("should return nothing when the rota is on another team", func() {
+			saveRota("deploys", "another_team", "U1")
+
+			rotas, err := q.ListRotasByUserID(ctx, ListRotasByUserIDParams{UserID: "U1", TeamID: "T123"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rotas).To(BeEmpty())
+		})
+	})
+
 	Describe("SaveRota", func() {
 		var (
 			channelID string
@@ -456,7 +646,7 @@ var _ = Describe("Rotas", func() {
 			})
 			Expect(err).ToNot(HaveOccurred())
 
-			err = q.deleteMember(ctx, "12345")
+			err = q.deleteMember(ctx, deleteMemberParams{RotaID: rotaId, UserID: "12345"})
 			Expect(err).ToNot(HaveOccurred())
 
 			list, err := q.ListUserIDsByRotaID(ctx, rotaId)
@@ -464,8 +654,27 @@ var _ = Describe("Rotas", func() {
 			Expect(len(list)).To(Equal(0))
 		})
 
+		It("keeps the member on other rotas", func() {
+			otherId, err := q.CreateOrUpdateRota(ctx, CreateOrUpdateRotaParams{
+				ChannelID: "foo",
+				TeamID:    "bar",
+				Name:      "qux",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			for _, id := range []string{rotaId, otherId} {
+				Expect(q.UpdateRotaMembers(ctx, []Member{{RotaID: id, UserID: "12345"}})).To(Succeed())
+			}
+
+			err = q.deleteMember(ctx, deleteMemberParams{RotaID: rotaId, UserID: "12345"})
+			Expect(err).ToNot(HaveOccurred())
+
+			list, err := q.ListUserIDsByRotaID(ctx, otherId)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(list).To(Equal([]string{"12345"}))
+		})
+
 		It("should not fail delete member when it does not exist", func() {
-			err := q.deleteMember(ctx, "12345")
+			err := q.deleteMember(ctx, deleteMemberParams{RotaID: rotaId, UserID: "12345"})
 			Expect(err).ToNot(HaveOccurred())
 
 			list, err := q.ListUserIDsByRotaID(ctx, rotaId)
@@ -524,4 +733,346 @@ var _ = Describe("Rotas", func() {
 			Expect(len(list)).To(Equal(0))
 		})
 	})
+
+	Describe("Shifts", func() {
+		var rotaId string
+		day := time.Date(2023, time.October, 2, 0, 0, 0, 0, time.UTC)
+
+		BeforeEach(func() {
+			var err error
+			rotaId, err = q.CreateOrUpdateRota(ctx, CreateOrUpdateRotaParams{
+				ChannelID: "foo",
+				TeamID:    "bar",
+				Name:      "baz",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			for i, userId := range []string{"U1", "U2", "U1"} {
+				_, err = q.CreateShift(ctx, CreateShiftParams{
+					RotaID:   rotaId,
+					UserID:   userId,
+					StartsAt: day.AddDate(0, 0, i),
+					EndsAt:   day.AddDate(0, 0, i+1),
+					Reason:   SRScheduled,
+					Metadata: ShiftMetadata{},
+				})
+				Expect(err).ToNot(HaveOccurred())
+			}
+		})
+
+		Describe("ListShiftsByRotaID", func() {
+			It("returns the shifts overlapping the window in order", func() {
+				shifts, err := q.ListShiftsByRotaID(ctx, ListShiftsByRotaIDParams{
+					RotaID: rotaId,
+					Since:  Timestamptz(day.Add(12 * time.Hour)),
+					Until:  Timestamptz(day.AddDate(0, 0, 2)),
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(shifts)).To(Equal(2))
+				Expect(shifts[0].UserID).To(Equal("U1"))
+				Expect(shifts[0].StartsAt.Time).To(BeTemporally("==", day))
+				Expect(shifts[1].UserID).To(Equal("U2"))
+				Expect(shifts[1].Reason).To(Equal(SRScheduled))
+			})
+
+			It("keeps the history of members that left the rota", func() {
+				err := q.UpdateRotaMembers(ctx, []Member{{RotaID: rotaId, UserID: "U1", Metadata: MemberMetadata{}}})
+				Expect(err).ToNot(HaveOccurred())
+				err = q.deleteMember(ctx, deleteMemberParams{RotaID: rotaId, UserID: "U1"})
+				Expect(err).ToNot(HaveOccurred())
+
+				shifts, err := q.ListShiftsByRotaID(ctx, ListShiftsByRotaIDParams{
+					RotaID: rotaId,
+					Since:  Timestamptz(day),
+					Until:  Timestamptz(day.AddDate(0, 0, 3)),
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(shifts)).To(Equal(3))
+			})
+		})
+
+		Describe("ListShiftsByUserID", func() {
+			It("returns the shifts of the user only", func() {
+				shifts, err := q.ListShiftsByUserID(ctx, ListShiftsByUserIDParams{
+					UserID: "U1",
+					Since:  Timestamptz(day),
+					Until:  Timestamptz(day.AddDate(0, 0, 3)),
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(shifts)).To(Equal(2))
+				Expect(shifts[0].StartsAt.Time).To(BeTemporally("==", day))
+				Expect(shifts[1].StartsAt.Time).To(BeTemporally("==", day.AddDate(0, 0, 2)))
+			})
+		})
+
+		Describe("ListSkipsByRotaID", func() {
+			It("returns only the skipped shifts together with who skipped them and why", func() {
+				_, err := q.CreateShift(ctx, CreateShiftParams{
+					RotaID:   rotaId,
+					UserID:   "U2",
+					StartsAt: day.AddDate(0, 0, 1).Add(time.Hour),
+					EndsAt:   day.AddDate(0, 0, 2),
+					Reason:   SRSkip,
+					Metadata: ShiftMetadata{CreatedBy: "U3", Reason: "Out sick"},
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				skips, err := q.ListSkipsByRotaID(ctx, rotaId)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(skips)).To(Equal(1))
+				Expect(skips[0].UserID).To(Equal("U2"))
+				Expect(skips[0].Reason).To(Equal(SRSkip))
+				Expect(skips[0].Metadata).To(Equal(ShiftMetadata{CreatedBy: "U3", Reason: "Out sick"}))
+			})
+		})
+
+		Describe("EndShifts", func() {
+			It("ends the shift taking place without touching the others", func() {
+				at := day.AddDate(0, 0, 1).Add(6 * time.Hour)
+				Expect(q.EndShifts(ctx, EndShiftsParams{RotaID: rotaId, EndsAt: Timestamptz(at)})).To(Succeed())
+
+				shifts, err := q.ListShiftsByRotaID(ctx, ListShiftsByRotaIDParams{
+					RotaID: rotaId,
+					Since:  Timestamptz(day),
+					Until:  Timestamptz(day.AddDate(0, 0, 3)),
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(shifts)).To(Equal(3))
+				Expect(shifts[0].EndsAt.Time).To(BeTemporally("==", day.AddDate(0, 0, 1)))
+				Expect(shifts[1].EndsAt.Time).To(BeTemporally("==", at))
+				Expect(shifts[2].EndsAt.Time).To(BeTemporally("==", day.AddDate(0, 0, 3)))
+			})
+		})
+	})
+
+	Describe("Overrides", func() {
+		var rotaId string
+		friday := time.Date(2023, time.October, 6, 14, 0, 0, 0, time.UTC)
+
+		BeforeEach(func() {
+			var err error
+			rotaId, err = q.CreateOrUpdateRota(ctx, CreateOrUpdateRotaParams{
+				ChannelID: "foo",
+				TeamID:    "bar",
+				Name:      "baz",
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns the overrides overlapping the window", func() {
+			_, err := q.CreateOverride(ctx, CreateOverrideParams{
+				RotaID:   rotaId,
+				UserID:   "U1",
+				StartsAt: friday,
+				EndsAt:   friday.Add(4 * time.Hour),
+				Metadata: OverrideMetadata{CreatedBy: "U2"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			overrides, err := q.ListOverridesByRotaID(ctx, ListOverridesByRotaIDParams{
+				RotaID: rotaId,
+				Since:  Timestamptz(friday.Add(2 * time.Hour)),
+				Until:  Timestamptz(friday.AddDate(0, 0, 1)),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(overrides).To(HaveLen(1))
+			Expect(overrides[0].UserID).To(Equal("U1"))
+			Expect(overrides[0].EndsAt.Time).To(BeTemporally("==", friday.Add(4*time.Hour)))
+			Expect(overrides[0].Metadata.CreatedBy).To(Equal("U2"))
+
+			overrides, err = q.ListOverridesByRotaID(ctx, ListOverridesByRotaIDParams{
+				RotaID: rotaId,
+				Since:  Timestamptz(friday.Add(4 * time.Hour)),
+				Until:  Timestamptz(friday.AddDate(0, 0, 1)),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(overrides).To(BeEmpty())
+		})
+
+		It("fails when the override ends before it starts", func() {
+			_, err := q.CreateOverride(ctx, CreateOverrideParams{
+				RotaID:   rotaId,
+				UserID:   "U1",
+				StartsAt: friday,
+				EndsAt:   friday.Add(-time.Hour),
+				Metadata: OverrideMetadata{},
+			})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Swaps", func() {
+		var rotaId string
+		friday := time.Date(2023, time.October, 6, 14, 0, 0, 0, time.UTC)
+
+		BeforeEach(func() {
+			var err error
+			rotaId, err = q.CreateOrUpdateRota(ctx, CreateOrUpdateRotaParams{
+				ChannelID: "foo",
+				TeamID:    "bar",
+				Name:      "baz",
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("saves a pending swap and updates its status", func() {
+			swapId, err := q.CreateSwap(ctx, CreateSwapParams{
+				RotaID:            rotaId,
+				RequesterID:       "U1",
+				RequesterStartsAt: friday,
+				RequesterEndsAt:   friday.AddDate(0, 0, 1),
+				RecipientID:       "U2",
+				RecipientStartsAt: friday.AddDate(0, 0, 7),
+				RecipientEndsAt:   friday.AddDate(0, 0, 8),
+				ExpiresAt:         friday,
+				Metadata:          SwapMetadata{},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(swapId).To(HavePrefix("SW"))
+
+			swap, err := q.FindSwapByIDForUpdate(ctx, swapId)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(swap.Status).To(Equal(SSPending))
+			Expect(swap.RequesterID).To(Equal("U1"))
+			Expect(swap.RecipientID).To(Equal("U2"))
+			Expect(swap.RecipientStartsAt.Time).To(BeTemporally("==", friday.AddDate(0, 0, 7)))
+
+			Expect(q.UpdateSwapStatus(ctx, UpdateSwapStatusParams{ID: swapId, Status: SSAccepted})).To(Succeed())
+
+			swap, err = q.FindSwapByIDForUpdate(ctx, swapId)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(swap.Status).To(Equal(SSAccepted))
+		})
+	})
+
+	Describe("Unavailabilities", func() {
+		monday := time.Date(2023, time.October, 9, 0, 0, 0, 0, time.UTC)
+
+		It("lists the unavailabilities of the given users that overlap the window", func() {
+			for _, p := range []CreateUnavailabilityParams{
+				{TeamID: "T1", UserID: "U1", StartsAt: monday, EndsAt: monday.AddDate(0, 0, 5)},
+				{TeamID: "T1", UserID: "U2", StartsAt: monday.AddDate(0, 0, 14), EndsAt: monday.AddDate(0, 0, 15)},
+				{TeamID: "T1", UserID: "U3", StartsAt: monday, EndsAt: monday.AddDate(0, 0, 1)},
+				{TeamID: "T2", UserID: "U1", StartsAt: monday, EndsAt: monday.AddDate(0, 0, 1)},
+			} {
+				id, err := q.CreateUnavailability(ctx, p)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(id).To(HavePrefix("UA"))
+			}
+
+			unavailabilities, err := q.ListUnavailabilitiesByUserIDs(ctx, ListUnavailabilitiesByUserIDsParams{
+				TeamID:  "T1",
+				UserIds: []string{"U1", "U2"},
+				Since:   Timestamptz(monday.AddDate(0, 0, 2)),
+				Until:   Timestamptz(monday.AddDate(0, 0, 9)),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unavailabilities).To(HaveLen(1))
+			Expect(unavailabilities[0].UserID).To(Equal("U1"))
+			Expect(unavailabilities[0].EndsAt.Time).To(BeTemporally("==", monday.AddDate(0, 0, 5)))
+		})
+
+		It("rejects unavailabilities that end before they start", func() {
+			_, err := q.CreateUnavailability(ctx, CreateUnavailabilityParams{
+				TeamID:   "T1",
+				UserID:   "U1",
+				StartsAt: monday,
+				EndsAt:   monday.Add(-time.Hour),
+			})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Uninstallations", func() {
+		var rotaId string
+
+		BeforeEach(func() {
+			var err error
+			rotaId, err = q.CreateOrUpdateRota(ctx, CreateOrUpdateRotaParams{ChannelID: "C1", TeamID: "T1", Name: "On Call"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(q.UpdateRotaMembers(ctx, []Member{{RotaID: rotaId, UserID: "U1"}})).To(Succeed())
+			_, err = q.CreateOrUpdateRota(ctx, CreateOrUpdateRotaParams{ChannelID: "C1", TeamID: "T2", Name: "On Call"})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = q.CreateUnavailability(ctx, CreateUnavailabilityParams{
+				TeamID:   "T1",
+				UserID:   "U1",
+				StartsAt: time.Now(),
+				EndsAt:   time.Now().Add(time.Hour),
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("leaves the rotas of uninstalled teams out of ListRotas until they're reinstalled", func() {
+			Expect(q.CreateUninstallation(ctx, CreateUninstallationParams{TeamID: "T1", Reason: URAppUninstalled})).To(Succeed())
+			rotas, err := q.ListRotas(ctx, ListRotasParams{Limit: 10})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rotas).To(HaveLen(1))
+			Expect(rotas[0].TeamID).To(Equal("T2"))
+
+			Expect(q.DeleteUninstallation(ctx, "T1")).To(Succeed())
+			rotas, err = q.ListRotas(ctx, ListRotasParams{Limit: 10})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rotas).To(HaveLen(2))
+		})
+
+		It("keeps the reason and time of the first uninstallation", func() {
+			Expect(q.CreateUninstallation(ctx, CreateUninstallationParams{TeamID: "T1", Reason: URTokensRevoked})).To(Succeed())
+			Expect(q.CreateUninstallation(ctx, CreateUninstallationParams{TeamID: "T1", Reason: URAppUninstalled})).To(Succeed())
+
+			uninstallations, err := q.ListUninstallations(ctx, Timestamptz(time.Now().Add(time.Minute)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uninstallations).To(HaveLen(1))
+			Expect(uninstallations[0].Reason).To(Equal(URTokensRevoked))
+
+			uninstallations, err = q.ListUninstallations(ctx, Timestamptz(time.Now().Add(-time.Minute)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uninstallations).To(BeEmpty())
+		})
+
+		It("purges everything that belongs to the team", func() {
+			Expect(q.CreateUninstallation(ctx, CreateUninstallationParams{TeamID: "T1", Reason: URAppUninstalled})).To(Succeed())
+			Expect(q.PurgeTeam(ctx, "T1")).To(Succeed())
+
+			_, err := q.FindRotaByID(ctx, rotaId)
+			Expect(err).To(MatchError(pgx.ErrNoRows))
+			members, err := q.ListUserIDsByRotaID(ctx, rotaId)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(members).To(BeEmpty())
+			unavailabilities, err := q.ListUnavailabilitiesByUserIDs(ctx, ListUnavailabilitiesByUserIDsParams{
+				TeamID:  "T1",
+				UserIds: []string{"U1"},
+				Since:   Timestamptz(time.Now().Add(-time.Hour)),
+				Until:   Timestamptz(time.Now().Add(2 * time.Hour)),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unavailabilities).To(BeEmpty())
+			uninstallations, err := q.ListUninstallations(ctx, Timestamptz(time.Now().Add(time.Minute)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uninstallations).To(BeEmpty())
+
+			rotas, err := q.ListRotas(ctx, ListRotasParams{Limit: 10})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rotas).To(HaveLen(1))
+		})
+	})
+
+	Describe("Events", func() {
+		It("tells when the event was received before", func() {
+			params := CreateEventParams{ID: "Ev1", TeamID: "T1", Type: "app_mention"}
+			Expect(q.CreateEvent(ctx, params)).To(Succeed())
+			Expect(q.CreateEvent(ctx, params)).To(MatchError(ErrAlreadyExists))
+			Expect(q.CreateEvent(ctx, CreateEventParams{ID: "Ev2", TeamID: "T1", Type: "app_mention"})).To(Succeed())
+		})
+
+		It("forgets the events received before the given time", func() {
+			params := CreateEventParams{ID: "Ev1", TeamID: "T1", Type: "app_mention"}
+			Expect(q.CreateEvent(ctx, params)).To(Succeed())
+
+			Expect(q.DeleteEvents(ctx, Timestamptz(time.Now().Add(-time.Minute)))).To(Succeed())
+			Expect(q.CreateEvent(ctx, params)).To(MatchError(ErrAlreadyExists))
+
+			Expect(q.DeleteEvents(ctx, Timestamptz(time.Now().Add(time.Minute)))).To(Succeed())
+			Expect(q.CreateEvent(ctx, params)).To(Succeed())
+		})
+	})
 })
@@ -4,6 +4,11 @@ package db
 import (
 	"context"
 	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/rotabot-io/rotabot/lib/calendar"
 )
 
 var (
@@ -18,26 +23,186 @@ type RotaSchedule string
 // RotaFrequency is the type that defines how long a rota lasts
 type RotaFrequency string
 
+// NonWorkingDayPolicy is the type that defines what happens to the shifts that fall on non-working days
+type NonWorkingDayPolicy string
+
+// ShiftReason is the type that defines why a member was on duty during a shift
+type ShiftReason string
+
+// SwapStatus is the type that defines where a request to swap shifts is at
+type SwapStatus string
+
+// RotaStatus is the type that defines whether a rota hands over to its members
+type RotaStatus string
+
+// UninstallReason is the type that defines how a workspace stopped using rotabot
+type UninstallReason string
+
 const (
 	RFDaily   = RotaFrequency("Daily")
 	RFWeekly  = RotaFrequency("Weekly")
 	RFMonthly = RotaFrequency("Monthly")
+	RFCron    = RotaFrequency("Cron")
 
 	RSCreated = RotaSchedule("Created At")
 	RSRandom  = RotaSchedule("Randomly")
+	RSFair    = RotaSchedule("Least Served")
+
+	NWSkip   = NonWorkingDayPolicy("Skip the shift")
+	NWExtend = NonWorkingDayPolicy("Extend the previous shift")
+
+	SRScheduled = ShiftReason("scheduled")
+	SROverride  = ShiftReason("override")
+	SRSkip      = ShiftReason("skip")
+
+	SSPending  = SwapStatus("pending")
+	SSAccepted = SwapStatus("accepted")
+	SSDeclined = SwapStatus("declined")
+	SSExpired  = SwapStatus("expired")
+
+	RTActive = RotaStatus("active")
+	RTPaused = RotaStatus("paused")
+	RTEnded  = RotaStatus("ended")
+	// RTArchived rotas are those whose channel was archived or deleted, they're kept for their history.
+	RTArchived = RotaStatus("archived")
+
+	URAppUninstalled = UninstallReason("app_uninstalled")
+	URTokensRevoked  = UninstallReason("tokens_revoked")
 )
 
+// SchedulingTypes are the scheduling types users can pick from.
+var SchedulingTypes = []RotaSchedule{RSCreated, RSRandom, RSFair}
+
 type RotaMetadata struct {
 	Frequency      RotaFrequency `json:"frequency"`
 	SchedulingType RotaSchedule  `json:"scheduling_type"`
+	// Seed decides the order in which members are picked when the rota is scheduled randomly.
+	Seed int64 `json:"seed,omitempty"`
+	// Cadence is nil for rotas that hand over at midnight of the day they were created on.
+	Cadence *Cadence `json:"cadence,omitempty"`
+	// Cron is the expression that decides when rotas with the Cron frequency hand over.
+	Cron string `json:"cron,omitempty"`
+	// TimeZone is the IANA name of the time zone the rota hands over in.
+	TimeZone string `json:"time_zone,omitempty"`
+	// WorkingDays is nil for rotas that hand over every day.
+	WorkingDays *WorkingDays `json:"working_days,omitempty"`
+	// Tiers is how many members are on duty at the same time, e.g. 2 for a primary and a secondary. Rotas
+	// created before tiers existed have 0, which is the same as 1.
+	Tiers int `json:"tiers,omitempty"`
+	// Windows is nil for rotas that have a single rotation around the clock.
+	Windows []Window `json:"windows,omitempty"`
+	// CreatedBy is the slack user id of whoever created the rota, they're told about the changes made to it that
+	// can't be announced in its channel. Rotas created before it was recorded have none.
+	CreatedBy string `json:"created_by,omitempty"`
+	// RemoveLeavers takes members out of the rota when they leave its channel.
+	RemoveLeavers bool `json:"remove_leavers,omitempty"`
+}
+
+// Window is a time of the day during which a pool of members of a follow-the-sun rota is on duty, e.g. EMEA from
+// 08:00 until 16:00. Each window lasts until the next one starts, so together they cover the whole day.
+type Window struct {
+	Name string `json:"name"`
+	// Start is the time of the day, formatted as HH:MM, at which the window starts.
+	Start string `json:"start"`
+	// UserIDs are the members of the rota that take turns during the window.
+	UserIDs []string `json:"user_ids"`
+}
+
+// Cadence defines when a rota hands over. Rotas hand over every few days, weeks or months depending on their
+// frequency.
+type Cadence struct {
+	Every int `json:"every"`
+	// Weekday is the day of the week weekly rotas hand over on.
+	Weekday time.Weekday `json:"weekday"`
+	// Time is the time of the day, formatted as HH:MM, at which the rota hands over.
+	Time string `json:"time"`
+}
+
+// WorkingDays defines the days on which a rota doesn't hand over.
+type WorkingDays struct {
+	SkipWeekends bool `json:"skip_weekends"`
+	// Calendar is the name of one of the holiday calendars shipped with rotabot.
+	Calendar string `json:"calendar,omitempty"`
+	// CalendarFile is the link to the file the Holidays were uploaded from.
+	CalendarFile string             `json:"calendar_file,omitempty"`
+	Holidays     []calendar.Holiday `json:"holidays,omitempty"`
+	// Policy decides whether shifts that start on a non-working day are skipped, leaving nobody on duty, or
+	// whether the shift before them goes on until the next working day.
+	Policy NonWorkingDayPolicy `json:"policy"`
+}
+
+// RotaState is what the scheduler knows about the last handover that happened for a rota.
+// Unlike RotaMetadata this is never set by users, it only changes when the rota hands over to someone else.
+type RotaState struct {
+	UserID     string    `json:"user_id"`
+	ShiftStart time.Time `json:"shift_start"`
+	ShiftEnd   time.Time `json:"shift_end"`
+	// ScheduledUserID is who the rotation picked for the shift, before anyone covered for them, and Offset how
+	// many skips it had moved forward by. The rotation carries on from them, so changing the members of the rota
+	// never hands the current shift to someone else.
+	ScheduledUserID string `json:"scheduled_user_id,omitempty"`
+	Offset          int    `json:"offset,omitempty"`
+	// Handover is how many times the cron expression of the rota fired before the shift, at HandoverAt. Working
+	// out who is on duty walks from there instead of from when the rota started.
+	Handover   int       `json:"handover,omitempty"`
+	HandoverAt time.Time `json:"handover_at,omitempty"`
+	// Shift is the index of the shift among those that start on a working day, for rotas that only hand over
+	// on working days. The shift starts with Handover, which is set for them whatever their cadence.
+	Shift int `json:"shift,omitempty"`
 }
 
 type MemberMetadata struct{}
 
+// ShiftMetadata is only set for the shifts that were changed by hand, like those skipped with SRSkip.
+type ShiftMetadata struct {
+	// CreatedBy is the slack user id of whoever changed the shift.
+	CreatedBy string `json:"created_by,omitempty"`
+	// Reason is why the shift was changed, as given by whoever changed it.
+	Reason string `json:"reason,omitempty"`
+}
+
+type OverrideMetadata struct {
+	// CreatedBy is the slack user id of whoever set up the override.
+	CreatedBy string `json:"created_by,omitempty"`
+	// SwapID is the id of the swap the override comes from, if any.
+	SwapID string `json:"swap_id,omitempty"`
+}
+
+type SwapMetadata struct{}
+
+type UnavailabilityMetadata struct{}
+
 type Repository interface {
 	CreateOrUpdateRota(ctx context.Context, p CreateOrUpdateRotaParams) (string, error)
 	UpdateRotaMembers(ctx context.Context, members []Member) error
 	FindRotaByID(ctx context.Context, id string) (Rota, error)
+	FindRotaByIDForUpdate(ctx context.Context, id string) (Rota, error)
+	LockRotaByID(ctx context.Context, id string) (Rota, error)
+	ListRotas(ctx context.Context, args ListRotasParams) ([]Rota, error)
 	ListRotasByChannel(ctx context.Context, args ListRotasByChannelParams) ([]Rota, error)
+	ListRotasByUserID(ctx context.Context, args ListRotasByUserIDParams) ([]Rota, error)
 	ListUserIDsByRotaID(ctx context.Context, rotaID string) ([]string, error)
+	ListMembersByRotaID(ctx context.Context, rotaID string) ([]Member, error)
+	MoveMember(ctx context.Context, p MoveMemberParams) error
+	RemoveMember(ctx context.Context, p RemoveMemberParams) error
+	UpdateRotaState(ctx context.Context, args UpdateRotaStateParams) error
+	UpdateRotaStatus(ctx context.Context, args UpdateRotaStatusParams) error
+	CreateShift(ctx context.Context, p CreateShiftParams) (string, error)
+	EndShifts(ctx context.Context, args EndShiftsParams) error
+	ListShiftsByRotaID(ctx context.Context, args ListShiftsByRotaIDParams) ([]Shift, error)
+	ListShiftsByUserID(ctx context.Context, args ListShiftsByUserIDParams) ([]Shift, error)
+	ListSkipsByRotaID(ctx context.Context, rotaID string) ([]Shift, error)
+	CreateOverride(ctx context.Context, p CreateOverrideParams) (string, error)
+	ListOverridesByRotaID(ctx context.Context, args ListOverridesByRotaIDParams) ([]Override, error)
+	CreateSwap(ctx context.Context, p CreateSwapParams) (string, error)
+	FindSwapByIDForUpdate(ctx context.Context, id string) (Swap, error)
+	UpdateSwapStatus(ctx context.Context, args UpdateSwapStatusParams) error
+	CreateUnavailability(ctx context.Context, p CreateUnavailabilityParams) (string, error)
+	ListUnavailabilitiesByUserIDs(ctx context.Context, args ListUnavailabilitiesByUserIDsParams) ([]Unavailability, error)
+	CreateUninstallation(ctx context.Context, args CreateUninstallationParams) error
+	DeleteUninstallation(ctx context.Context, teamID string) error
+	ListUninstallations(ctx context.Context, uninstalledAt pgtype.Timestamptz) ([]Uninstallation, error)
+	PurgeTeam(ctx context.Context, teamID string) error
+	CreateEvent(ctx context.Context, p CreateEventParams) error
+	DeleteEvents(ctx context.Context, createdAt pgtype.Timestamptz) error
 }
@@ -43,3 +43,13 @@ var AppTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 	Name: "rotabot_app_total",
 	Help: "Number of apps being run with a given version",
 }, []string{"app_name", "sha"})
+
+var HandoversTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "rotabot_handovers_total",
+	Help: "Number of times a rota has handed over to the next member",
+}, []string{"status"})
+
+var UnhandledEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "rotabot_unhandled_events_total",
+	Help: "Number of events received from slack's Events API that nothing handles",
+}, []string{"type"})
@@ -0,0 +1,78 @@
+package rotas
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+)
+
+// RemovalReason is why a member was taken out of a rota without anyone asking for it.
+type RemovalReason string
+
+// ArchiveReason is why a rota was archived without anyone asking for it.
+type ArchiveReason string
+
+const (
+	RRLeftChannel = RemovalReason("they left the channel")
+	RRDeactivated = RemovalReason("their account was deactivated")
+
+	ARChannelArchived = ArchiveReason("its channel was archived")
+	ARChannelDeleted  = ArchiveReason("its channel was deleted")
+)
+
+// RemoveMember takes the user out of the rota, and out of its windows when it follows the sun, so they aren't put
+// on duty anymore. It reports whether the user was a member of the rota in the first place.
+func RemoveMember(ctx context.Context, repo db.Repository, rota db.Rota, userID string) (bool, error) {
+	l := zapctx.Logger(ctx).With(zap.String("rota_id", rota.ID), zap.String("user_id", userID))
+	err := repo.RemoveMember(ctx, db.RemoveMemberParams{RotaID: rota.ID, UserID: userID})
+	if errors.Is(err, db.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		l.Error("failed_to_remove_member", zap.Error(err))
+		return false, err
+	}
+
+	metadata := rota.Metadata
+	metadata.Windows = slices.Clone(rota.Metadata.Windows)
+	changed := false
+	for i, w := range metadata.Windows {
+		if slices.Contains(w.UserIDs, userID) {
+			metadata.Windows[i].UserIDs = slices.DeleteFunc(slices.Clone(w.UserIDs), func(id string) bool { return id == userID })
+			changed = true
+		}
+	}
+	if changed {
+		_, err = repo.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+			RotaID:   rota.ID,
+			Name:     rota.Name,
+			Metadata: metadata,
+			StartsAt: rota.StartsAt.Time,
+			EndsAt:   rota.EndsAt.Time,
+		})
+		if err != nil {
+			l.Error("failed_to_create_or_update_rota", zap.Error(err))
+			return false, err
+		}
+	}
+	l.Info("removed_member")
+	return true, nil
+}
+
+// ArchiveRota stops the rota for good once its channel is gone, nobody is put on duty for it anymore but its
+// history is kept.
+func ArchiveRota(ctx context.Context, repo db.Repository, rota db.Rota) error {
+	l := zapctx.Logger(ctx).With(zap.String("rota_id", rota.ID))
+	err := repo.UpdateRotaStatus(ctx, db.UpdateRotaStatusParams{ID: rota.ID, Status: db.RTArchived})
+	if err != nil {
+		l.Error("failed_to_update_rota_status", zap.Error(err))
+		return err
+	}
+	l.Info("archived_rota")
+	return nil
+}
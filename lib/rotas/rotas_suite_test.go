@@ -0,0 +1,13 @@
+package rotas
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRotas(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rotas Suite")
+}
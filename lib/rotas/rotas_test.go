@@ -0,0 +1,250 @@
+package rotas
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	"github.com/rotabot-io/rotabot/internal"
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/rotation"
+)
+
+var _ = Describe("Rotas", func() {
+	var (
+		ctx    context.Context
+		conn   *pgxpool.Pool
+		rotaID string
+		now    time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		container, err := internal.RunContainer(ctx,
+			postgres.WithInitScripts(filepath.Join("..", "..", "assets", "structure.sql")),
+			testcontainers.WithWaitStrategy(internal.DefaultWaitStrategy()),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		connString, err := container.ConnectionString(ctx, "sslmode=disable")
+		Expect(err).ToNot(HaveOccurred())
+
+		conn, err = pgxpool.New(ctx, connString)
+		Expect(err).ToNot(HaveOccurred())
+
+		DeferCleanup(func() {
+			_ = container.Terminate(ctx)
+			conn.Close()
+		})
+
+		rotaID, err = db.New(conn).CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+			TeamID:    "T123",
+			ChannelID: "C123",
+			Name:      "On Call",
+			Metadata: db.RotaMetadata{
+				Frequency:      db.RFDaily,
+				SchedulingType: db.RSCreated,
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		// Two days after the rota was created, so the third shift is taking place.
+		now = time.Now().UTC().Add(48 * time.Hour)
+	})
+
+	addMembers := func(userIDs ...string) {
+		members := []db.Member{}
+		for _, userID := range userIDs {
+			members = append(members, db.Member{RotaID: rotaID, UserID: userID, Metadata: db.MemberMetadata{}})
+		}
+		Expect(db.New(conn).UpdateRotaMembers(ctx, members)).To(Succeed())
+	}
+
+	findRota := func() db.Rota {
+		rota, err := db.New(conn).FindRotaByID(ctx, rotaID)
+		Expect(err).ToNot(HaveOccurred())
+		return rota
+	}
+
+	listShifts := func() []db.Shift {
+		shifts, err := db.New(conn).ListShiftsByRotaID(ctx, db.ListShiftsByRotaIDParams{
+			RotaID: rotaID,
+			Since:  db.Timestamptz(now.Add(-365 * 24 * time.Hour)),
+			Until:  db.Timestamptz(now.Add(365 * 24 * time.Hour)),
+		})
+		Expect(err).ToNot(HaveOccurred())
+		return shifts
+	}
+
+	Describe("SkipShift", func() {
+		skip := func(params SkipParams) (Skipped, error) {
+			params.Clock = rotation.FixedClock(now)
+			return SkipShift(ctx, db.New(conn), findRota(), params)
+		}
+
+		It("hands over straight away when whoever is on duty is skipped", func() {
+			addMembers("U1", "U2", "U3")
+
+			skipped, err := skip(SkipParams{CreatedBy: "U9", Reason: "Out sick"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(skipped.Now).To(BeTrue())
+			Expect(skipped.Shift.UserID).To(Equal("U3"))
+			Expect(skipped.Instead.UserID).To(Equal("U1"))
+			Expect(findRota().State.UserID).To(Equal("U1"))
+
+			shifts := listShifts()
+			Expect(shifts).To(HaveLen(2))
+			Expect(shifts).To(ContainElement(SatisfyAll(
+				HaveField("UserID", "U3"),
+				HaveField("Reason", db.SRSkip),
+				HaveField("Metadata", db.ShiftMetadata{CreatedBy: "U9", Reason: "Out sick"}),
+			)))
+			Expect(shifts).To(ContainElement(SatisfyAll(HaveField("UserID", "U1"), HaveField("Reason", db.SRScheduled))))
+		})
+
+		It("skips the next shift of a member that isn't on duty", func() {
+			addMembers("U1", "U2", "U3")
+
+			skipped, err := skip(SkipParams{UserID: "U1", CreatedBy: "U1"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(skipped.Now).To(BeFalse())
+			Expect(skipped.Shift.UserID).To(Equal("U1"))
+			Expect(skipped.Shift.Start.After(now)).To(BeTrue())
+			Expect(skipped.Instead.UserID).To(Equal("U2"))
+			Expect(skipped.Instead.Start).To(BeTemporally("==", skipped.Shift.Start))
+
+			engine, err := rotation.Load(ctx, db.New(conn), findRota(), rotation.FixedClock(now))
+			Expect(err).ToNot(HaveOccurred())
+			upcoming, err := engine.Upcoming(3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(upcoming[0].UserID).To(Equal("U2"))
+			Expect(upcoming[1].UserID).To(Equal("U3"))
+			Expect(upcoming[2].UserID).To(Equal("U1"))
+		})
+
+		It("fails when the member isn't part of the rota", func() {
+			addMembers("U1", "U2")
+
+			_, err := skip(SkipParams{UserID: "U9", CreatedBy: "U1"})
+			Expect(err).To(MatchError(ErrNoUpcomingShift))
+			Expect(listShifts()).To(BeEmpty())
+		})
+
+		It("fails when the rota is paused", func() {
+			addMembers("U1", "U2")
+			err := db.New(conn).UpdateRotaStatus(ctx, db.UpdateRotaStatusParams{ID: rotaID, Status: db.RTPaused})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = skip(SkipParams{CreatedBy: "U1"})
+			Expect(err).To(MatchError(ErrInactive))
+		})
+
+		It("carries on from the handover that happened since the rota was read", func() {
+			addMembers("U1", "U2", "U3")
+			rota := findRota()
+
+			// The scheduler hands the rota over the same way, see scheduler.handover.
+			engine, err := rotation.Load(ctx, db.New(conn), rota, rotation.FixedClock(now))
+			Expect(err).ToNot(HaveOccurred())
+			current, err := engine.Current()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = db.New(conn).CreateShift(ctx, db.CreateShiftParams{
+				RotaID:   rotaID,
+				UserID:   current.UserID,
+				StartsAt: current.Start,
+				EndsAt:   current.End,
+				Reason:   current.Reason,
+				Metadata: db.ShiftMetadata{},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			err = db.New(conn).UpdateRotaState(ctx, db.UpdateRotaStateParams{ID: rotaID, State: engine.Handover(current)})
+			Expect(err).ToNot(HaveOccurred())
+
+			skipped, err := SkipShift(ctx, db.New(conn), rota, SkipParams{CreatedBy: "U9", Clock: rotation.FixedClock(now)})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(skipped.Shift.UserID).To(Equal("U3"))
+			Expect(skipped.Instead.UserID).To(Equal("U1"))
+			Expect(findRota().State.UserID).To(Equal("U1"))
+			Expect(findRota().State.ShiftStart).To(BeTemporally("~", current.Start, time.Millisecond))
+
+			// Whoever was handed the rota is on duty until they're skipped, there's a single shift for each of them.
+			shifts := listShifts()
+			Expect(shifts).To(HaveLen(3))
+			Expect(shifts).To(ContainElement(SatisfyAll(
+				HaveField("UserID", "U3"),
+				HaveField("Reason", db.SRScheduled),
+				HaveField("EndsAt.Time", BeTemporally("~", now, time.Millisecond)),
+			)))
+			Expect(shifts).To(ContainElement(SatisfyAll(HaveField("UserID", "U3"), HaveField("Reason", db.SRSkip))))
+			Expect(shifts).To(ContainElement(SatisfyAll(HaveField("UserID", "U1"), HaveField("Reason", db.SRScheduled))))
+		})
+
+		It("fails when the rota ended since it was read", func() {
+			addMembers("U1", "U2")
+			rota := findRota()
+			err := db.New(conn).UpdateRotaStatus(ctx, db.UpdateRotaStatusParams{ID: rotaID, Status: db.RTEnded})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = SkipShift(ctx, db.New(conn), rota, SkipParams{CreatedBy: "U1", Clock: rotation.FixedClock(now)})
+			Expect(err).To(MatchError(ErrInactive))
+			Expect(listShifts()).To(BeEmpty())
+		})
+	})
+
+	Describe("Membership", func() {
+		It("removes the member from the rota and its windows", func() {
+			addMembers("U1", "U2", "U3")
+			rota := findRota()
+			rota.Metadata.Windows = []db.Window{
+				{Name: "EMEA", Start: "00:00", UserIDs: []string{"U1", "U2"}},
+				{Name: "AMER", Start: "12:00", UserIDs: []string{"U3"}},
+			}
+			_, err := db.New(conn).CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{RotaID: rotaID, Name: rota.Name, Metadata: rota.Metadata})
+			Expect(err).ToNot(HaveOccurred())
+
+			removed, err := RemoveMember(ctx, db.New(conn), findRota(), "U1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removed).To(BeTrue())
+
+			userIDs, err := db.New(conn).ListUserIDsByRotaID(ctx, rotaID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(userIDs).To(Equal([]string{"U2", "U3"}))
+			Expect(findRota().Metadata.Windows[0].UserIDs).To(Equal([]string{"U2"}))
+		})
+
+		It("tells when the user isn't a member of the rota", func() {
+			addMembers("U1")
+
+			removed, err := RemoveMember(ctx, db.New(conn), findRota(), "U9")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removed).To(BeFalse())
+		})
+
+		It("archives the rota and keeps its history", func() {
+			addMembers("U1")
+
+			Expect(ArchiveRota(ctx, db.New(conn), findRota())).To(Succeed())
+			Expect(findRota().Status).To(Equal(db.RTArchived))
+		})
+	})
+
+	Describe("Uninstall", func() {
+		It("keeps the reason the team was first uninstalled for", func() {
+			Expect(Uninstall(ctx, db.New(conn), "T123", db.URAppUninstalled)).To(Succeed())
+			Expect(Uninstall(ctx, db.New(conn), "T123", db.URTokensRevoked)).To(Succeed())
+
+			uninstallations, err := db.New(conn).ListUninstallations(ctx, db.Timestamptz(now))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uninstallations).To(HaveLen(1))
+			Expect(uninstallations[0].TeamID).To(Equal("T123"))
+			Expect(uninstallations[0].Reason).To(Equal(db.URAppUninstalled))
+		})
+	})
+})
@@ -0,0 +1,168 @@
+// Package rotas changes rotas on behalf of their members, or because something happened to their channel or
+// workspace in slack. Whoever calls them is expected to let the rota's channel know, see the announce package.
+package rotas
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+)
+
+var (
+	ErrInactive        = errors.New("rota is paused or has ended")
+	ErrOverridden      = errors.New("shift is covered by an override")
+	ErrNoUpcomingShift = errors.New("member has no upcoming shift")
+)
+
+// skipLookahead is how far ahead the shift of a member is looked for when skipping them.
+const skipLookahead = 180 * 24 * time.Hour
+
+type SkipParams struct {
+	// UserID is the member to skip, whoever is on duty right now is skipped when it's empty.
+	UserID string
+	// CreatedBy is the slack user id of whoever skipped the member.
+	CreatedBy string
+	// Reason is why the member was skipped, it's optional.
+	Reason string
+	Clock  rotation.Clock
+}
+
+// Skipped is a shift that was skipped by hand.
+type Skipped struct {
+	// Shift is the shift the member was skipped from, as it was scheduled.
+	Shift rotation.Shift
+	// Instead is who is on duty in place of the member that was skipped.
+	Instead rotation.Shift
+	// Now reports whether the member was on duty when they were skipped, the rota was handed over straight away.
+	Now       bool
+	CreatedBy string
+	Reason    string
+}
+
+// SkipShift skips the next shift of a member of the rota, or the one taking place when they're on duty right now. The
+// rotation moves forward by one member, see rotation.WithSkips, and the skip is kept in the rota's history together
+// with whoever made it and why so the history shows why the order of the rotation changed.
+//
+// Skipping whoever is on duty hands the rota over straight away, the same way the scheduler would, so it isn't
+// announced twice. Callers are expected to let the channel know, see announce.Skipped.
+//
+// The rota is locked and read again before anything is worked out, waiting for the scheduler if it's handing it
+// over, so the skip never works from a state that is out of date. The repository is expected to be a transaction.
+func SkipShift(ctx context.Context, repo db.Repository, rota db.Rota, params SkipParams) (Skipped, error) {
+	l := zapctx.Logger(ctx).With(zap.String("rota_id", rota.ID))
+	rota, err := repo.LockRotaByID(ctx, rota.ID)
+	if err != nil {
+		l.Error("failed_to_lock_rota", zap.Error(err))
+		return Skipped{}, err
+	}
+	clock := params.Clock
+	if clock == nil {
+		clock = rotation.SystemClock
+	}
+	now := clock.Now()
+	if rota.Status != db.RTActive || (rota.EndsAt.Valid && !now.Before(rota.EndsAt.Time)) {
+		return Skipped{}, ErrInactive
+	}
+
+	engine, err := rotation.Load(ctx, repo, rota, clock)
+	if err != nil {
+		return Skipped{}, err
+	}
+	skipped := Skipped{CreatedBy: params.CreatedBy, Reason: params.Reason}
+	current, err := engine.Current()
+	if err != nil && (params.UserID == "" || !errors.Is(err, rotation.ErrNonWorkingDay)) {
+		return Skipped{}, err
+	}
+	startsAt := now
+	switch {
+	case err == nil && (params.UserID == "" || params.UserID == current.UserID):
+		if current.Reason == db.SROverride {
+			return Skipped{}, ErrOverridden
+		}
+		skipped.Shift, skipped.Now = current, true
+	default:
+		shifts, err := engine.Between(now, now.Add(skipLookahead))
+		if err != nil {
+			return Skipped{}, err
+		}
+		i := -1
+		for k, s := range shifts {
+			if s.UserID == params.UserID && s.Reason == db.SRScheduled {
+				i = k
+				break
+			}
+		}
+		if i == -1 {
+			return Skipped{}, ErrNoUpcomingShift
+		}
+		skipped.Shift = shifts[i]
+		startsAt = skipped.Shift.Start
+	}
+	skipped.Shift.End = rotation.Until(rota, skipped.Shift.End)
+
+	_, err = repo.CreateShift(ctx, db.CreateShiftParams{
+		RotaID:   rota.ID,
+		UserID:   skipped.Shift.UserID,
+		StartsAt: startsAt,
+		EndsAt:   skipped.Shift.End,
+		Reason:   db.SRSkip,
+		Metadata: db.ShiftMetadata{CreatedBy: params.CreatedBy, Reason: params.Reason},
+	})
+	if err != nil {
+		l.Error("failed_to_create_skip", zap.Error(err))
+		return Skipped{}, err
+	}
+
+	engine, err = rotation.Load(ctx, repo, rota, clock)
+	if err != nil {
+		return Skipped{}, err
+	}
+	skipped.Instead, err = engine.At(startsAt)
+	if err != nil {
+		return Skipped{}, err
+	}
+	skipped.Instead.End = rotation.Until(rota, skipped.Instead.End)
+	l.Info("shift_skipped",
+		zap.String("user_id", skipped.Shift.UserID),
+		zap.String("instead", skipped.Instead.UserID),
+		zap.String("created_by", params.CreatedBy),
+		zap.Time("shift_start", skipped.Shift.Start),
+	)
+	if !skipped.Now {
+		return skipped, nil
+	}
+
+	// The shift of whoever was skipped ends now, so the history doesn't have them on duty together with who took over.
+	err = repo.EndShifts(ctx, db.EndShiftsParams{RotaID: rota.ID, EndsAt: db.Timestamptz(now)})
+	if err != nil {
+		l.Error("failed_to_end_shifts", zap.Error(err))
+		return Skipped{}, err
+	}
+	_, err = repo.CreateShift(ctx, db.CreateShiftParams{
+		RotaID:   rota.ID,
+		UserID:   skipped.Instead.UserID,
+		StartsAt: now,
+		EndsAt:   skipped.Instead.End,
+		Reason:   skipped.Instead.Reason,
+		Metadata: db.ShiftMetadata{},
+	})
+	if err != nil {
+		l.Error("failed_to_create_shift", zap.Error(err))
+		return Skipped{}, err
+	}
+	err = repo.UpdateRotaState(ctx, db.UpdateRotaStateParams{
+		ID:    rota.ID,
+		State: engine.Handover(skipped.Instead),
+	})
+	if err != nil {
+		l.Error("failed_to_update_rota_state", zap.Error(err))
+		return Skipped{}, err
+	}
+	return skipped, nil
+}
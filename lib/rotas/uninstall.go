@@ -0,0 +1,24 @@
+package rotas
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+)
+
+// Uninstall tombstones the team, its rotas stop handing over straight away and are deleted by the scheduler once the
+// grace period has passed. Tombstoning a team twice keeps the time it was first uninstalled at.
+// Rotabot talks to every workspace with the same token, so there are no credentials of the team to delete.
+func Uninstall(ctx context.Context, repo db.Repository, teamID string, reason db.UninstallReason) error {
+	l := zapctx.Logger(ctx).With(zap.String("team_id", teamID))
+	err := repo.CreateUninstallation(ctx, db.CreateUninstallationParams{TeamID: teamID, Reason: reason})
+	if err != nil {
+		l.Error("failed_to_create_uninstallation", zap.Error(err))
+		return err
+	}
+	l.Info("team_uninstalled", zap.String("reason", string(reason)))
+	return nil
+}
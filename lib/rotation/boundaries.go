@@ -0,0 +1,244 @@
+package rotation
+
+import (
+	"time"
+
+	"github.com/rotabot-io/rotabot/lib/cron"
+	"github.com/rotabot-io/rotabot/lib/db"
+)
+
+// boundaries knows when each of the shifts of a rota starts. Handovers happen every few days, weeks or months
+// at the time of the day set by the rota's cadence, counting from the day the rota started. Shift 0 is the
+// one that was taking place when the rota started, which is when it was created unless it was given a start date.
+//
+// Everything is worked out in the rota's time zone, so a rota that hands over at 09:00 keeps doing so when
+// daylight saving time starts or ends.
+//
+// Rotas scheduled with a cron expression hand over whenever the expression fires, their first shift starts
+// when the rota started.
+//
+// Rotas that only hand over on working days number their shifts using the handovers that fall on a working
+// day, see workingShifts.
+type boundaries struct {
+	origin    time.Time
+	offset    int
+	every     int
+	frequency db.RotaFrequency
+	anchor    time.Time
+	cron      *cronTimes
+	working   *workingShifts
+}
+
+// startOf returns when the first shift of the rota starts.
+func startOf(rota db.Rota) time.Time {
+	if rota.StartsAt.Valid {
+		return rota.StartsAt.Time
+	}
+	return rota.CreatedAt.Time
+}
+
+func newBoundaries(rota db.Rota) (boundaries, error) {
+	loc, err := Location(rota)
+	if err != nil {
+		return boundaries{}, err
+	}
+	started := startOf(rota).In(loc)
+	switch rota.Metadata.Frequency {
+	case db.RFDaily, db.RFWeekly, db.RFMonthly:
+	case db.RFCron:
+		schedule, err := cron.Parse(rota.Metadata.Cron)
+		if err != nil {
+			return boundaries{}, ErrInvalidCadence
+		}
+		anchor := started.Truncate(time.Minute)
+		if schedule.Next(anchor).IsZero() {
+			return boundaries{}, ErrInvalidCadence
+		}
+		b := boundaries{
+			frequency: db.RFCron,
+			anchor:    anchor,
+			cron:      &cronTimes{schedule: schedule, anchor: anchor, times: []time.Time{anchor}},
+		}
+		b.cron.seed(rota.State)
+		return b.withWorkingDays(rota)
+	default:
+		return boundaries{}, ErrUnsupportedFrequency
+	}
+
+	cadence := cadenceOf(rota, started)
+	if cadence.Every < 1 {
+		return boundaries{}, ErrInvalidCadence
+	}
+	handover, err := time.Parse("15:04", cadence.Time)
+	if err != nil {
+		return boundaries{}, ErrInvalidCadence
+	}
+
+	day := started.Day()
+	if rota.Metadata.Frequency == db.RFWeekly {
+		day -= int(started.Weekday()-cadence.Weekday+7) % 7
+	}
+	b := boundaries{
+		origin:    time.Date(started.Year(), started.Month(), day, handover.Hour(), handover.Minute(), 0, 0, loc),
+		every:     cadence.Every,
+		frequency: rota.Metadata.Frequency,
+	}
+	if b.origin.After(started) {
+		// The first handover happens after the rota started, whoever is first is on duty until then.
+		b.offset = -1
+	}
+	b.anchor = b.handover(0)
+	return b.withWorkingDays(rota)
+}
+
+// cadenceOf returns the cadence of the rota, rotas that don't have one hand over at midnight of the day they
+// were created on.
+func cadenceOf(rota db.Rota, created time.Time) db.Cadence {
+	if rota.Metadata.Cadence != nil {
+		return *rota.Metadata.Cadence
+	}
+	return db.Cadence{Every: 1, Weekday: created.Weekday(), Time: "00:00"}
+}
+
+// Location returns the time zone the rota hands over in, rotas without one hand over in UTC.
+func Location(rota db.Rota) (*time.Location, error) {
+	if rota.Metadata.TimeZone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(rota.Metadata.TimeZone)
+	if err != nil {
+		return nil, ErrInvalidTimeZone
+	}
+	return loc, nil
+}
+
+// start returns the time at which the shift with the given index starts.
+func (b boundaries) start(index int) time.Time {
+	if b.working != nil {
+		return b.handover(b.working.handover(b, index))
+	}
+	return b.handover(index)
+}
+
+// end returns the time at which the shift with the given index ends.
+func (b boundaries) end(index int) time.Time {
+	if b.working == nil {
+		return b.handover(index + 1)
+	}
+	if b.working.policy == db.NWSkip {
+		return b.handover(b.working.handover(b, index) + 1)
+	}
+	return b.handover(b.working.handover(b, index+1))
+}
+
+// handover returns the time of the handover with the given index, regardless of whether it falls on a
+// working day.
+func (b boundaries) handover(index int) time.Time {
+	if b.cron != nil {
+		return b.cron.at(index)
+	}
+	steps := (index + b.offset) * b.every
+	switch b.frequency { // nolint:exhaustive
+	case db.RFDaily:
+		return b.origin.AddDate(0, 0, steps)
+	case db.RFWeekly:
+		return b.origin.AddDate(0, 0, 7*steps)
+	default:
+		return addMonths(b.origin, steps)
+	}
+}
+
+// indexAt returns the index of the last shift that started at or before t. Unless the rota skips non-working
+// days, that's the shift that covers t.
+func (b boundaries) indexAt(t time.Time) (int, error) {
+	index, err := b.handoverAt(t)
+	if err != nil || b.working == nil {
+		return index, err
+	}
+	return b.working.indexOf(b, index)
+}
+
+// handoverAt returns the index of the last handover that happened at or before t.
+func (b boundaries) handoverAt(t time.Time) (int, error) {
+	if t.Before(b.anchor) {
+		return 0, ErrNotStarted
+	}
+	t = t.In(b.anchor.Location())
+
+	// Start with a guess that is never past the right answer and walk forward from there,
+	// this keeps the maths simple for frequencies whose length changes, like months.
+	var index int
+	switch b.frequency { // nolint:exhaustive
+	case db.RFCron:
+		// There's no way to guess how many times a cron expression fired, walk from the last handover.
+		index = b.cron.from(t)
+	case db.RFDaily:
+		index = int(t.Sub(b.anchor)/(time.Duration(b.every)*24*time.Hour)) - 1
+	case db.RFWeekly:
+		index = int(t.Sub(b.anchor)/(time.Duration(b.every)*7*24*time.Hour)) - 1
+	default:
+		index = ((t.Year()-b.anchor.Year())*12+int(t.Month()-b.anchor.Month()))/b.every - 1
+	}
+	if index < 0 {
+		index = 0
+	}
+	for !b.handover(index + 1).After(t) {
+		index++
+	}
+	return index, nil
+}
+
+// addMonths adds months to t clamping the day to the last day of the resulting month,
+// so a rota created on the 31st hands over on the 30th in April rather than on the 1st of May.
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	day := t.Day()
+	if lastDay := first.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// cronTimes remembers the times at which a cron expression fired so walking through the shifts of a rota
+// doesn't evaluate the expression over and over again. Rotas that handed over already start from their last
+// handover rather than from when they started, see seed.
+type cronTimes struct {
+	schedule cron.Schedule
+	anchor   time.Time
+	// first is the index of the handover times starts with.
+	first int
+	times []time.Time
+}
+
+// seed starts the walk from the last handover of the rota, as long as the expression still fires at that time.
+func (c *cronTimes) seed(state db.RotaState) {
+	at := state.HandoverAt
+	if state.Handover <= 0 || !at.After(c.anchor) || !c.schedule.Next(at.Add(-time.Minute)).Equal(at) {
+		return
+	}
+	c.first, c.times = state.Handover, []time.Time{at.In(c.anchor.Location())}
+}
+
+// from returns the index of a handover that happened at or before t to walk from.
+func (c *cronTimes) from(t time.Time) int {
+	if t.Before(c.times[0]) {
+		return 0
+	}
+	return c.first
+}
+
+func (c *cronTimes) at(index int) time.Time {
+	if index < c.first {
+		// Shifts before the last handover are rarely asked about, walk from the first shift again.
+		c.first, c.times = 0, []time.Time{c.anchor}
+	}
+	for len(c.times) <= index-c.first {
+		next := c.schedule.Next(c.times[len(c.times)-1])
+		if next.IsZero() {
+			// The expression stopped firing, the last shift never ends.
+			next = time.Date(9999, time.December, 31, 0, 0, 0, 0, c.times[0].Location())
+		}
+		c.times = append(c.times, next)
+	}
+	return c.times[index-c.first]
+}
@@ -0,0 +1,23 @@
+package rotation
+
+import "time"
+
+// Clock tells the engine what time it is. This exists so that tests can pin "now" to a known instant.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function into a Clock.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the clock used by default, backed by time.Now.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// FixedClock returns a clock that is always at t.
+func FixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time { return t })
+}
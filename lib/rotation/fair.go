@@ -0,0 +1,158 @@
+package rotation
+
+import (
+	"slices"
+	"time"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+)
+
+// fairSchedule picks whoever has spent the least time on duty. Shifts that were already handed over are taken
+// from the history and the ones after them are simulated, so previews line up with what will actually happen
+// as long as nothing changes in between.
+//
+// Members that haven't served at all within the history, because they just joined or were away for a long
+// time, are treated as having served as much as the least served member. Otherwise they would stay on duty
+// until they caught up with everyone else. Members that are unavailable are left out of the shifts that are
+// simulated, they catch up on them once they're back. So are members skipped by hand, even from shifts that were
+// already handed over.
+type fairSchedule struct {
+	b          boundaries
+	members    []db.Member
+	available  func(userID string, start, end time.Time) bool
+	skipped    func(index int) []string
+	recorded   map[int]string
+	last       int
+	served     map[string]time.Duration
+	lastServed map[string]time.Time
+	simulated  []int
+}
+
+func newFairSchedule(
+	b boundaries,
+	members []db.Member,
+	history []db.Shift,
+	available func(userID string, start, end time.Time) bool,
+	skipped func(index int) []string,
+) *fairSchedule {
+	s := &fairSchedule{
+		b:          b,
+		members:    members,
+		available:  available,
+		skipped:    skipped,
+		recorded:   map[int]string{},
+		last:       -1,
+		served:     map[string]time.Duration{},
+		lastServed: map[string]time.Time{},
+	}
+
+	// When a shift was handed over more than once the last handover wins.
+	ordered := slices.DeleteFunc(slices.Clone(history), func(shift db.Shift) bool {
+		return shift.Reason == db.SRSkip
+	})
+	slices.SortStableFunc(ordered, func(a, b db.Shift) int {
+		return a.StartsAt.Time.Compare(b.StartsAt.Time)
+	})
+	for i, shift := range ordered {
+		// Whoever took over halfway through a shift ended it, even if the history says otherwise.
+		end := shift.EndsAt.Time
+		if i+1 < len(ordered) && ordered[i+1].StartsAt.Time.Before(end) {
+			end = ordered[i+1].StartsAt.Time
+		}
+		s.served[shift.UserID] += end.Sub(shift.StartsAt.Time)
+		s.lastServed[shift.UserID] = shift.StartsAt.Time
+		if shift.Reason == db.SROverride {
+			// Covering for someone counts towards the time served but doesn't change the rotation.
+			continue
+		}
+		index, err := b.indexAt(shift.StartsAt.Time)
+		if err != nil {
+			continue
+		}
+		s.recorded[index] = shift.UserID
+		if index > s.last {
+			s.last = index
+		}
+	}
+
+	var baseline time.Duration
+	found := false
+	for _, m := range members {
+		if d, ok := s.served[m.UserID]; ok && (!found || d < baseline) {
+			baseline, found = d, true
+		}
+	}
+	for _, m := range members {
+		if _, ok := s.served[m.UserID]; !ok {
+			s.served[m.UserID] = baseline
+		}
+	}
+	return s
+}
+
+// pick returns the position of the member on duty for the shift with the given index.
+func (s *fairSchedule) pick(index int) int {
+	if userID, ok := s.recorded[index]; ok {
+		if slices.Contains(s.skipped(index), userID) {
+			// Whoever was handed over to got skipped afterwards.
+			return s.next(index)
+		}
+		if i := slices.IndexFunc(s.members, func(m db.Member) bool { return m.UserID == userID }); i != -1 {
+			return i
+		}
+	}
+	if index <= s.last {
+		// There's nothing fair to compute for a past shift we know nothing about, fall back to the order in which
+		// members joined.
+		return index % len(s.members)
+	}
+
+	for i := s.last + 1 + len(s.simulated); i <= index; i++ {
+		pick := s.next(i)
+		userID := s.members[pick].UserID
+		s.served[userID] += s.b.end(i).Sub(s.b.start(i))
+		s.lastServed[userID] = s.b.start(i)
+		s.simulated = append(s.simulated, pick)
+	}
+	return s.simulated[index-s.last-1]
+}
+
+// next returns the position of the member available for the shift with the given index that has served the least,
+// ties are broken by whoever served the longest time ago and then by the order in which members joined. When
+// nobody is available everyone is considered.
+func (s *fairSchedule) next(index int) int {
+	start, end, skipped := s.b.start(index), s.b.end(index), s.skipped(index)
+	pick := -1
+	for i := range s.members {
+		if !s.available(s.members[i].UserID, start, end) || slices.Contains(skipped, s.members[i].UserID) {
+			continue
+		}
+		if pick == -1 || s.less(i, pick) {
+			pick = i
+		}
+	}
+	if pick != -1 {
+		return pick
+	}
+
+	pick = 0
+	for i := 1; i < len(s.members); i++ {
+		if s.less(i, pick) {
+			pick = i
+		}
+	}
+	return pick
+}
+
+// less reports whether the member at position i should be on duty before the one at position j.
+func (s *fairSchedule) less(i, j int) bool {
+	candidate, best := s.members[i].UserID, s.members[j].UserID
+	switch {
+	case s.served[candidate] < s.served[best]:
+		return true
+	case s.served[candidate] == s.served[best] && s.lastServed[candidate].Before(s.lastServed[best]):
+		return true
+	default:
+		return false
+	}
+}
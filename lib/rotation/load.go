@@ -0,0 +1,103 @@
+package rotation
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+)
+
+// horizon is how far ahead overrides and unavailabilities are looked up, those further away than this don't
+// affect the shifts anyone looks at.
+const horizon = 366 * 24 * time.Hour
+
+// Load returns the engine of the rota together with everything it needs from the repository: its members, the
+// history of rotas scheduled by least served, the shifts members were skipped from, and the overrides and
+// unavailabilities of the shifts from the last handover onwards.
+func Load(ctx context.Context, repo db.Repository, rota db.Rota, clock Clock) (*Engine, error) {
+	members, err := repo.ListMembersByRotaID(ctx, rota.ID)
+	if err != nil {
+		zapctx.Logger(ctx).Error("failed_to_list_members", zap.Error(err))
+		return nil, err
+	}
+	return LoadWith(ctx, repo, rota, members, clock)
+}
+
+// LoadWith is like Load but the rota has the given members instead of the ones it has in the repository, which is
+// how changes to a rota are previewed before they're saved.
+func LoadWith(ctx context.Context, repo db.Repository, rota db.Rota, members []db.Member, clock Clock) (*Engine, error) {
+	l := zapctx.Logger(ctx)
+	now := clock.Now()
+
+	var err error
+	history := []db.Shift{}
+	if rota.Metadata.SchedulingType == db.RSFair {
+		history, err = repo.ListShiftsByRotaID(ctx, db.ListShiftsByRotaIDParams{
+			RotaID: rota.ID,
+			Since:  db.Timestamptz(now.Add(-FairnessWindow)),
+			Until:  db.Timestamptz(now),
+		})
+		if err != nil {
+			l.Error("failed_to_list_shifts", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	// Every skip moves the rotation forward for good, so they're all needed no matter how long ago they happened.
+	skips, err := repo.ListSkipsByRotaID(ctx, rota.ID)
+	if err != nil {
+		l.Error("failed_to_list_skips", zap.Error(err))
+		return nil, err
+	}
+
+	// Overrides and unavailabilities that ended after the last handover are needed to catch up on the shifts that
+	// were missed since.
+	since := now
+	if !rota.State.ShiftEnd.IsZero() && rota.State.ShiftEnd.Before(since) {
+		since = rota.State.ShiftEnd
+	}
+	overrides, err := repo.ListOverridesByRotaID(ctx, db.ListOverridesByRotaIDParams{
+		RotaID: rota.ID,
+		Since:  db.Timestamptz(since),
+		Until:  db.Timestamptz(now.Add(horizon)),
+	})
+	if err != nil {
+		l.Error("failed_to_list_overrides", zap.Error(err))
+		return nil, err
+	}
+
+	// Whoever is on duty depends on who was available when the shift started, not only from now on.
+	from := since
+	if b, err := newBoundaries(rota); err == nil {
+		if index, err := b.indexAt(since); err == nil && b.start(index).Before(from) {
+			from = b.start(index)
+		}
+	}
+	userIDs := make([]string, 0, len(members))
+	for _, m := range members {
+		userIDs = append(userIDs, m.UserID)
+	}
+	unavailabilities, err := repo.ListUnavailabilitiesByUserIDs(ctx, db.ListUnavailabilitiesByUserIDsParams{
+		TeamID:  rota.TeamID,
+		UserIds: userIDs,
+		Since:   db.Timestamptz(from),
+		Until:   db.Timestamptz(now.Add(horizon)),
+	})
+	if err != nil {
+		l.Error("failed_to_list_unavailabilities", zap.Error(err))
+		return nil, err
+	}
+
+	return New(
+		rota,
+		members,
+		WithClock(clock),
+		WithHistory(history),
+		WithOverrides(overrides),
+		WithUnavailabilities(unavailabilities),
+		WithSkips(skips),
+	), nil
+}
@@ -0,0 +1,67 @@
+package rotation
+
+import (
+	"slices"
+	"time"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+)
+
+// cover splits base into the shifts of whoever is on duty during it. The time belongs to the user of base
+// unless an override covers part of it, overrides created later win over earlier ones when they overlap.
+// Parts of base nobody is on duty for, because it has no user, are left out.
+func (e *Engine) cover(base Shift) []Shift {
+	from, to := base.Start, base.End
+	cuts := []time.Time{from, to}
+	for _, o := range e.overrides {
+		for _, t := range []time.Time{o.StartsAt.Time, o.EndsAt.Time} {
+			if t.After(from) && t.Before(to) {
+				cuts = append(cuts, t)
+			}
+		}
+	}
+	slices.SortFunc(cuts, func(a, b time.Time) int { return a.Compare(b) })
+	cuts = slices.CompactFunc(cuts, func(a, b time.Time) bool { return a.Equal(b) })
+
+	shifts := []Shift{}
+	for i := 0; i+1 < len(cuts); i++ {
+		shift := base
+		if o, ok := e.overrideAt(cuts[i]); ok {
+			shift = Shift{UserID: o.UserID, Reason: db.SROverride, OverrideID: o.ID}
+		}
+		if shift.UserID == "" {
+			continue
+		}
+		shift.Start, shift.End = cuts[i], cuts[i+1]
+		shifts = appendShift(shifts, shift)
+	}
+	return shifts
+}
+
+// overrideAt returns the override that covers t. Overrides only cover for the primary tier, the other tiers
+// always follow the rotation.
+func (e *Engine) overrideAt(t time.Time) (db.Override, bool) {
+	if e.tier > 0 {
+		return db.Override{}, false
+	}
+	for i := len(e.overrides) - 1; i >= 0; i-- {
+		o := e.overrides[i]
+		if !t.Before(o.StartsAt.Time) && t.Before(o.EndsAt.Time) {
+			return o, true
+		}
+	}
+	return db.Override{}, false
+}
+
+// appendShift adds s to the end of shifts, joining it with the last shift when both are part of the same
+// override. This happens when an override spans more than one shift of the rotation.
+func appendShift(shifts []Shift, s Shift) []Shift {
+	if n := len(shifts); n > 0 {
+		last := shifts[n-1]
+		if s.OverrideID != "" && last.OverrideID == s.OverrideID && last.End.Equal(s.Start) {
+			shifts[n-1].End = s.End
+			return shifts
+		}
+	}
+	return append(shifts, s)
+}
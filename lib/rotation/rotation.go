@@ -0,0 +1,435 @@
+package rotation
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+)
+
+var (
+	ErrNoMembers                 = errors.New("rota has no members")
+	ErrNotStarted                = errors.New("rota has not started yet")
+	ErrUnsupportedFrequency      = errors.New("unsupported rota frequency")
+	ErrInvalidCadence            = errors.New("invalid rota cadence")
+	ErrInvalidTimeZone           = errors.New("invalid rota time zone")
+	ErrInvalidWorkingDays        = errors.New("rota never hands over on a working day")
+	ErrNonWorkingDay             = errors.New("nobody is on duty on non-working days")
+	ErrUnsupportedSchedulingType = errors.New("unsupported rota scheduling type")
+	ErrUnknownTier               = errors.New("rota has no such tier")
+	ErrNotEnoughMembers          = errors.New("rota has fewer members than tiers")
+	ErrInvalidWindows            = errors.New("invalid rota windows")
+)
+
+// Shift is a window of time in which a single member of the rota is on duty.
+// Start is inclusive and End is exclusive.
+type Shift struct {
+	UserID string
+	Start  time.Time
+	End    time.Time
+	// Reason is SROverride when someone covers for whoever the rotation picked.
+	Reason db.ShiftReason
+	// OverrideID is the id of the override the shift comes from, if any.
+	OverrideID string
+	// Tier is 0 for the primary, 1 for the secondary and so on.
+	Tier int
+	// Window is the name of the window of follow-the-sun rotas the shift falls in.
+	Window string
+}
+
+// Covers reports whether t falls within the shift.
+func (s Shift) Covers(t time.Time) bool {
+	return !t.Before(s.Start) && t.Before(s.End)
+}
+
+// FairnessWindow is how far back the history of a rota should go when it's scheduled fairly.
+const FairnessWindow = 180 * 24 * time.Hour
+
+// Engine computes who is on duty for a rota. It's a pure function of the rota, its members, their history and
+// the time being asked about, so two engines built from the same inputs will always agree with each other.
+// Engines are not safe for concurrent use.
+type Engine struct {
+	rota             db.Rota
+	members          []db.Member
+	history          []db.Shift
+	overrides        []db.Override
+	unavailabilities []db.Unavailability
+	skips            []db.Shift
+	clock            Clock
+	fair             *fairSchedule
+	skipAt           map[int][]string
+	tier             int
+	windows          []window
+}
+
+type Option func(e *Engine)
+
+// WithClock overrides the clock used to resolve "now", by default the system clock is used.
+func WithClock(c Clock) Option {
+	return func(e *Engine) {
+		e.clock = c
+	}
+}
+
+// WithHistory gives the engine the shifts that already took place, rotas that are scheduled fairly use them to
+// work out who has served the least.
+func WithHistory(shifts []db.Shift) Option {
+	return func(e *Engine) {
+		e.history = shifts
+	}
+}
+
+// WithOverrides gives the engine the windows of time in which someone covers for whoever the rotation picked.
+// Overrides don't change the order of the rotation, when two of them overlap the one created last wins.
+func WithOverrides(overrides []db.Override) Option {
+	return func(e *Engine) {
+		e.overrides = slices.Clone(overrides)
+		slices.SortStableFunc(e.overrides, func(a, b db.Override) int {
+			return a.CreatedAt.Time.Compare(b.CreatedAt.Time)
+		})
+	}
+}
+
+// Until returns t, or the time at which the rota ends when that comes sooner. Nobody is on duty once the rota
+// ends, so that's when the last shift ends.
+func Until(rota db.Rota, t time.Time) time.Time {
+	if rota.EndsAt.Valid && rota.EndsAt.Time.Before(t) {
+		return rota.EndsAt.Time
+	}
+	return t
+}
+
+// Tiers returns how many members of the rota are on duty at the same time.
+func Tiers(rota db.Rota) int {
+	if rota.Metadata.Tiers < 1 {
+		return 1
+	}
+	return rota.Metadata.Tiers
+}
+
+// TierName returns how the tier is called when it's shown to users.
+func TierName(tier int) string {
+	switch tier {
+	case 0:
+		return "Primary"
+	case 1:
+		return "Secondary"
+	case 2:
+		return "Tertiary"
+	default:
+		return fmt.Sprintf("Tier %d", tier+1)
+	}
+}
+
+func New(rota db.Rota, members []db.Member, opts ...Option) *Engine {
+	e := &Engine{
+		rota:    rota,
+		members: orderMembers(members),
+		clock:   SystemClock,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.windows = e.newWindows()
+	return e
+}
+
+// Tier returns an engine that answers for the given tier of the rota instead of the primary one, tier 0. Each
+// tier is on duty at the same time as the others and hands over at the same time, it's the member that follows
+// whoever is on duty in the tier above it, skipping those that are unavailable. Overrides only ever cover for
+// the primary tier.
+func (e *Engine) Tier(tier int) (*Engine, error) {
+	if tier < 0 || tier >= Tiers(e.rota) {
+		return nil, ErrUnknownTier
+	}
+	t := *e
+	t.tier = tier
+	t.windows = make([]window, 0, len(e.windows))
+	for _, w := range e.windows {
+		sub := *w.engine
+		sub.tier = tier
+		w.engine = &sub
+		t.windows = append(t.windows, w)
+	}
+	return &t, nil
+}
+
+// Now returns what time it is according to the clock of the engine.
+func (e *Engine) Now() time.Time {
+	return e.clock.Now()
+}
+
+// Current returns the shift that is taking place right now.
+func (e *Engine) Current() (Shift, error) {
+	return e.At(e.clock.Now())
+}
+
+// Upcoming returns the next n shifts after the current one.
+func (e *Engine) Upcoming(n int) ([]Shift, error) {
+	now := e.clock.Now()
+	shifts, err := e.Shifts(now, n+1)
+	if err != nil {
+		return nil, err
+	}
+	if shifts[0].Start.After(now) {
+		// Nobody is on duty right now, so the first shift is already an upcoming one.
+		return shifts[:n], nil
+	}
+	return shifts[1:], nil
+}
+
+// At returns the shift that covers t, rotas that skip non-working days have nobody on duty on those and
+// return ErrNonWorkingDay.
+func (e *Engine) At(t time.Time) (Shift, error) {
+	shifts, err := e.Shifts(t, 1)
+	if err != nil {
+		return Shift{}, err
+	}
+	if shifts[0].Start.After(t) {
+		return Shift{}, ErrNonWorkingDay
+	}
+	return shifts[0], nil
+}
+
+// Shifts returns n consecutive shifts, starting with the one that covers t or, when nobody is on duty at t,
+// the one after it.
+func (e *Engine) Shifts(t time.Time, n int) ([]Shift, error) {
+	b, err := e.boundaries()
+	if err != nil {
+		return nil, err
+	}
+	index, err := b.indexAt(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// One more shift than needed is worked out so the last one is complete when an override spans more than one
+	// shift of the rotation.
+	shifts := make([]Shift, 0, n+1)
+	for i := index; len(shifts) <= n; i++ {
+		for _, s := range e.pieces(b, i) {
+			if s.End.After(t) {
+				shifts = appendShift(shifts, s)
+			}
+		}
+	}
+	return shifts[:n], nil
+}
+
+// Between returns the shifts that start at or after from and before to.
+func (e *Engine) Between(from, to time.Time) ([]Shift, error) {
+	b, err := e.boundaries()
+	if err != nil {
+		return nil, err
+	}
+	if first := b.start(0); from.Before(first) {
+		from = first
+	}
+	index, err := b.indexAt(from)
+	if err != nil {
+		return nil, err
+	}
+
+	shifts := []Shift{}
+	for i := index; b.start(i).Before(to); i++ {
+		for _, s := range e.pieces(b, i) {
+			if !s.Start.Before(from) && s.Start.Before(to) {
+				shifts = appendShift(shifts, s)
+			}
+		}
+	}
+	return shifts, nil
+}
+
+func (e *Engine) boundaries() (boundaries, error) {
+	if len(e.members) == 0 {
+		return boundaries{}, ErrNoMembers
+	}
+	if e.tier >= len(e.members) {
+		return boundaries{}, ErrNotEnoughMembers
+	}
+	if !slices.Contains(supportedSchedulingTypes, e.rota.Metadata.SchedulingType) {
+		return boundaries{}, ErrUnsupportedSchedulingType
+	}
+	if err := e.validWindows(); err != nil {
+		return boundaries{}, err
+	}
+	return newBoundaries(e.rota)
+}
+
+// scheduled returns the shifts with the given index as the rotation scheduled them. Rotas that follow the sun
+// have one for each of the windows the shift spans.
+func (e *Engine) scheduled(b boundaries, index int) []Shift {
+	start, end := b.start(index), b.end(index)
+	if len(e.windows) > 0 {
+		return e.windowShifts(b, index, start, end)
+	}
+	return []Shift{{
+		UserID: e.member(b, index).UserID,
+		Start:  start,
+		End:    end,
+		Reason: db.SRScheduled,
+		Tier:   e.tier,
+	}}
+}
+
+// pieces returns who is on duty from the start of the shift with the given index until the next one starts,
+// once overrides are taken into account.
+func (e *Engine) pieces(b boundaries, index int) []Shift {
+	scheduled := e.scheduled(b, index)
+	last := scheduled[len(scheduled)-1]
+	if !last.End.After(scheduled[0].Start) {
+		// Rotas whose cron expression stopped firing end with shifts that never start.
+		return scheduled
+	}
+	shifts := []Shift{}
+	for _, shift := range scheduled {
+		for _, s := range e.cover(shift) {
+			shifts = appendShift(shifts, s)
+		}
+	}
+	// Rotas that skip non-working days have nobody on duty until the next shift, unless someone covers for it.
+	if next := b.start(index + 1); next.After(last.End) {
+		for _, s := range e.cover(Shift{Start: last.End, End: next}) {
+			shifts = appendShift(shifts, s)
+		}
+	}
+	return shifts
+}
+
+// member returns who is on duty in the engine's tier for the shift with the given index. Each tier is on duty
+// with the next member after the one of the tier above it, so nobody is on duty in two tiers at once.
+func (e *Engine) member(b boundaries, index int) db.Member {
+	start, end := b.start(index), b.end(index)
+	pick := e.primary(b, index)
+	taken := []int{pick}
+	for t := 1; t <= e.tier; t++ {
+		pick = e.firstAvailable(pick+1, start, end, taken)
+		taken = append(taken, pick)
+	}
+	return e.members[pick]
+}
+
+// primary returns the position of the member on duty in the primary tier for the shift with the given index,
+// members that are unavailable during the shift are skipped and so are those skipped by hand, see WithSkips.
+func (e *Engine) primary(b boundaries, index int) int {
+	if e.rota.Metadata.SchedulingType == db.RSFair {
+		return e.rotated(b, index)
+	}
+	return e.firstAvailable(e.rotated(b, index), b.start(index), b.end(index), nil)
+}
+
+// rotated returns the position of the member the rotation picks for the shift with the given index, before
+// skipping those that are unavailable. Rotas carry on from whoever they last handed over to, see anchor.
+func (e *Engine) rotated(b boundaries, index int) int {
+	if e.rota.Metadata.SchedulingType == db.RSFair {
+		if e.fair == nil {
+			e.fair = newFairSchedule(b, e.members, e.history, e.Available, func(index int) []string {
+				return e.skipped(b, index)
+			})
+		}
+		return e.fair.pick(index)
+	}
+
+	at, pos, anchored := e.anchor(b)
+	if anchored && at == index && e.offset(b, at) == e.rota.State.Offset {
+		return pos
+	}
+	if e.rota.Metadata.SchedulingType == db.RSRandom {
+		return newShuffleBag(e.rota.Metadata.Seed, e.rota.ID, len(e.members)).pick(index + e.offset(b, index))
+	}
+	if !anchored {
+		return (index + e.offset(b, index)) % len(e.members)
+	}
+	n := len(e.members)
+	steps := index - at + e.offset(b, index) - e.rota.State.Offset
+	return ((pos+steps)%n + n) % n
+}
+
+// anchor returns the index of the shift the rota last handed over in and the position of whoever the rotation
+// picked for it. The rotation carries on from there, so members joining, leaving or being reordered only change
+// who is on duty from the next handover on. Rotas that never handed over, or whose member left, aren't anchored.
+func (e *Engine) anchor(b boundaries) (int, int, bool) {
+	state := e.rota.State
+	if state.ScheduledUserID == "" {
+		return 0, 0, false
+	}
+	pos := slices.IndexFunc(e.members, func(m db.Member) bool { return m.UserID == state.ScheduledUserID })
+	if pos < 0 {
+		return 0, 0, false
+	}
+	index, err := b.indexAt(state.ShiftStart)
+	if err != nil {
+		return 0, 0, false
+	}
+	return index, pos, true
+}
+
+// Handover returns the state the rota is in once it's handed over to the given shift, it remembers who the
+// rotation picked for the shift so the rotation carries on from them.
+func (e *Engine) Handover(shift Shift) db.RotaState {
+	state := db.RotaState{UserID: shift.UserID, ShiftStart: shift.Start, ShiftEnd: shift.End}
+	if len(e.windows) > 0 {
+		// Each window keeps its own order, see newWindows.
+		return state
+	}
+	b, err := e.boundaries()
+	if err != nil {
+		return state
+	}
+	index, err := b.indexAt(shift.Start)
+	if err != nil {
+		return state
+	}
+	state.ScheduledUserID = e.members[e.rotated(b, index)].UserID
+	state.Offset = e.offset(b, index)
+	switch {
+	case b.working != nil:
+		state.Shift, state.Handover = index, b.working.handover(b, index)
+		state.HandoverAt = b.handover(state.Handover)
+	case b.cron != nil:
+		if state.Handover, err = b.handoverAt(shift.Start); err == nil {
+			state.HandoverAt = b.handover(state.Handover)
+		}
+	}
+	return state
+}
+
+// HolidaysKnown reports whether the holidays of the day t falls on, in the rota's time zone, are known. The
+// calendars shipped with rotabot only list the holidays of a few years, rotas hand over on the holidays after
+// them as if they were working days until the calendars are updated.
+func (e *Engine) HolidaysKnown(t time.Time) bool {
+	b, err := newBoundaries(e.rota)
+	if err != nil || b.working == nil {
+		return true
+	}
+	return b.working.known(t.In(b.anchor.Location()))
+}
+
+var supportedSchedulingTypes = []db.RotaSchedule{db.RSCreated, db.RSRandom, db.RSFair}
+
+// orderMembers sorts the members by their position in the rota, falling back to the time they joined and then to
+// their id so the order is stable even when two members were added within the same transaction.
+func orderMembers(members []db.Member) []db.Member {
+	ordered := slices.Clone(members)
+	slices.SortStableFunc(ordered, func(a, b db.Member) int {
+		if c := cmp.Compare(a.Position, b.Position); c != 0 {
+			return c
+		}
+		if c := a.CreatedAt.Time.Compare(b.CreatedAt.Time); c != 0 {
+			return c
+		}
+		switch {
+		case a.ID < b.ID:
+			return -1
+		case a.ID > b.ID:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return ordered
+}
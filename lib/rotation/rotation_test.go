@@ -0,0 +1,1213 @@
+package rotation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rotabot-io/rotabot/lib/calendar"
+	"github.com/rotabot-io/rotabot/lib/db"
+)
+
+func timestamp(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
+
+func date(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+var _ = Describe("Engine", func() {
+	var (
+		rota    db.Rota
+		members []db.Member
+	)
+
+	BeforeEach(func() {
+		rota = db.Rota{
+			ID:   "RT123",
+			Name: "On Call",
+			Metadata: db.RotaMetadata{
+				Frequency:      db.RFWeekly,
+				SchedulingType: db.RSCreated,
+			},
+			CreatedAt: timestamp(date(2023, time.January, 4, 15)),
+		}
+		// Members are purposely out of order to ensure the engine sorts them by the time they joined.
+		members = []db.Member{
+			{ID: "RM3", UserID: "carol", CreatedAt: timestamp(date(2023, time.January, 4, 17))},
+			{ID: "RM1", UserID: "alice", CreatedAt: timestamp(date(2023, time.January, 4, 16))},
+			{ID: "RM2", UserID: "bob", CreatedAt: timestamp(date(2023, time.January, 4, 16))},
+		}
+	})
+
+	Describe("At", func() {
+		It("fails when the rota has no members", func() {
+			_, err := New(rota, []db.Member{}).At(date(2023, time.January, 5, 0))
+			Expect(err).To(MatchError(ErrNoMembers))
+		})
+
+		It("fails when asking for a time before the rota was created", func() {
+			_, err := New(rota, members).At(date(2023, time.January, 3, 0))
+			Expect(err).To(MatchError(ErrNotStarted))
+		})
+
+		It("counts from the start date when the rota has one", func() {
+			rota.StartsAt = timestamp(date(2023, time.February, 1, 0))
+
+			_, err := New(rota, members).At(date(2023, time.January, 20, 0))
+			Expect(err).To(MatchError(ErrNotStarted))
+
+			shift, err := New(rota, members).At(date(2023, time.February, 1, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("alice"))
+			Expect(shift.Start).To(Equal(date(2023, time.February, 1, 0)))
+		})
+
+		It("fails when the frequency is unknown", func() {
+			rota.Metadata.Frequency = "Hourly"
+			_, err := New(rota, members).At(date(2023, time.January, 5, 0))
+			Expect(err).To(MatchError(ErrUnsupportedFrequency))
+		})
+
+		It("fails when the scheduling type is unknown", func() {
+			rota.Metadata.SchedulingType = "Alphabetically"
+			_, err := New(rota, members).At(date(2023, time.January, 5, 0))
+			Expect(err).To(MatchError(ErrUnsupportedSchedulingType))
+		})
+
+		It("follows the position of the members over the time they joined", func() {
+			members[0].Position = 0
+			members[1].Position = 2
+			members[2].Position = 1
+
+			shifts, err := New(rota, members).Shifts(date(2023, time.January, 4, 15), 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("carol"))
+			Expect(shifts[1].UserID).To(Equal("bob"))
+			Expect(shifts[2].UserID).To(Equal("alice"))
+		})
+
+		It("does not modify the members it was given", func() {
+			_, err := New(rota, members).At(date(2023, time.January, 5, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(members[0].UserID).To(Equal("carol"))
+		})
+
+		DescribeTable("returns who is on duty",
+			func(frequency db.RotaFrequency, at time.Time, userID string, start, end time.Time) {
+				rota.Metadata.Frequency = frequency
+
+				shift, err := New(rota, members).At(at)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shift.UserID).To(Equal(userID))
+				Expect(shift.Start).To(Equal(start))
+				Expect(shift.End).To(Equal(end))
+				Expect(shift.Covers(at)).To(BeTrue())
+			},
+			Entry("daily on the day it was created", db.RFDaily,
+				date(2023, time.January, 4, 20), "alice", date(2023, time.January, 4, 0), date(2023, time.January, 5, 0)),
+			Entry("daily at the handover", db.RFDaily,
+				date(2023, time.January, 5, 0), "bob", date(2023, time.January, 5, 0), date(2023, time.January, 6, 0)),
+			Entry("daily wraps around the members", db.RFDaily,
+				date(2023, time.January, 7, 12), "alice", date(2023, time.January, 7, 0), date(2023, time.January, 8, 0)),
+			Entry("weekly within the first week", db.RFWeekly,
+				date(2023, time.January, 10, 23), "alice", date(2023, time.January, 4, 0), date(2023, time.January, 11, 0)),
+			Entry("weekly a year later", db.RFWeekly,
+				date(2024, time.January, 4, 0), "bob", date(2024, time.January, 3, 0), date(2024, time.January, 10, 0)),
+			Entry("monthly within the first month", db.RFMonthly,
+				date(2023, time.February, 3, 23), "alice", date(2023, time.January, 4, 0), date(2023, time.February, 4, 0)),
+			Entry("monthly across a year", db.RFMonthly,
+				date(2024, time.January, 4, 0), "alice", date(2024, time.January, 4, 0), date(2024, time.February, 4, 0)),
+		)
+
+		It("clamps monthly handovers to the end of shorter months", func() {
+			rota.Metadata.Frequency = db.RFMonthly
+			rota.CreatedAt = timestamp(date(2023, time.January, 31, 9))
+
+			shift, err := New(rota, members).At(date(2023, time.March, 1, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("bob"))
+			Expect(shift.Start).To(Equal(date(2023, time.February, 28, 0)))
+			Expect(shift.End).To(Equal(date(2023, time.March, 31, 0)))
+		})
+	})
+
+	Describe("Current", func() {
+		It("uses the clock to decide what now is", func() {
+			clock := FixedClock(date(2023, time.January, 12, 0))
+
+			shift, err := New(rota, members, WithClock(clock)).Current()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("bob"))
+		})
+	})
+
+	Describe("Upcoming", func() {
+		It("returns the shifts after the current one", func() {
+			clock := FixedClock(date(2023, time.January, 12, 0))
+
+			shifts, err := New(rota, members, WithClock(clock)).Upcoming(3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts).To(HaveLen(3))
+			Expect(shifts[0].UserID).To(Equal("carol"))
+			Expect(shifts[1].UserID).To(Equal("alice"))
+			Expect(shifts[2].UserID).To(Equal("bob"))
+
+			Expect(shifts[0].Start).To(Equal(date(2023, time.January, 18, 0)))
+			for i := 1; i < len(shifts); i++ {
+				Expect(shifts[i].Start).To(Equal(shifts[i-1].End))
+			}
+		})
+
+		It("returns nothing when asked for no shifts", func() {
+			shifts, err := New(rota, members).Upcoming(0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts).To(BeEmpty())
+		})
+	})
+
+	Describe("Between", func() {
+		BeforeEach(func() {
+			rota.Metadata.Frequency = db.RFDaily
+		})
+
+		It("returns the shifts that start within the window", func() {
+			shifts, err := New(rota, members).Between(date(2023, time.January, 5, 12), date(2023, time.January, 8, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts).To(HaveLen(2))
+			Expect(shifts[0].UserID).To(Equal("carol"))
+			Expect(shifts[0].Start).To(Equal(date(2023, time.January, 6, 0)))
+			Expect(shifts[1].UserID).To(Equal("alice"))
+			Expect(shifts[1].Start).To(Equal(date(2023, time.January, 7, 0)))
+		})
+
+		It("includes a shift starting exactly at the beginning of the window", func() {
+			shifts, err := New(rota, members).Between(date(2023, time.January, 5, 0), date(2023, time.January, 6, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts).To(HaveLen(1))
+			Expect(shifts[0].UserID).To(Equal("bob"))
+		})
+
+		It("starts from the first shift when the window starts before the rota", func() {
+			shifts, err := New(rota, members).Between(date(2022, time.January, 1, 0), date(2023, time.January, 5, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts).To(HaveLen(1))
+			Expect(shifts[0].UserID).To(Equal("alice"))
+		})
+
+		It("returns nothing for an empty window", func() {
+			shifts, err := New(rota, members).Between(date(2023, time.January, 5, 12), date(2023, time.January, 5, 12))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts).To(BeEmpty())
+		})
+	})
+
+	Describe("Randomly", func() {
+		var start time.Time
+
+		BeforeEach(func() {
+			rota.Metadata.Frequency = db.RFDaily
+			rota.Metadata.SchedulingType = db.RSRandom
+			rota.Metadata.Seed = 42
+			members = append(members,
+				db.Member{ID: "RM4", UserID: "dave", CreatedAt: timestamp(date(2023, time.January, 4, 18))},
+				db.Member{ID: "RM5", UserID: "erin", CreatedAt: timestamp(date(2023, time.January, 4, 19))},
+			)
+			start = date(2023, time.January, 4, 0)
+		})
+
+		userIDs := func(shifts []Shift) []string {
+			ids := []string{}
+			for _, s := range shifts {
+				ids = append(ids, s.UserID)
+			}
+			return ids
+		}
+
+		It("schedules everyone once before anyone repeats", func() {
+			shifts, err := New(rota, members).Shifts(start, 10*len(members))
+			Expect(err).ToNot(HaveOccurred())
+			for i := 0; i < len(shifts); i += len(members) {
+				Expect(userIDs(shifts[i : i+len(members)])).To(ConsistOf("alice", "bob", "carol", "dave", "erin"))
+			}
+		})
+
+		It("never schedules the same member twice in a row", func() {
+			for _, seed := range []int64{1, 2, 3, 42, 1337} {
+				rota.Metadata.Seed = seed
+				shifts, err := New(rota, members).Shifts(start, 20*len(members))
+				Expect(err).ToNot(HaveOccurred())
+				for i := 1; i < len(shifts); i++ {
+					Expect(shifts[i].UserID).ToNot(Equal(shifts[i-1].UserID))
+				}
+			}
+		})
+
+		It("alternates when the rota has two members", func() {
+			shifts, err := New(rota, members[:2]).Shifts(start, 6)
+			Expect(err).ToNot(HaveOccurred())
+			ids := userIDs(shifts)
+			Expect(ids[0]).ToNot(Equal(ids[1]))
+			Expect(ids).To(Equal([]string{ids[0], ids[1], ids[0], ids[1], ids[0], ids[1]}))
+		})
+
+		It("reshuffles every cycle", func() {
+			shifts, err := New(rota, members).Shifts(start, 10*len(members))
+			Expect(err).ToNot(HaveOccurred())
+			orders := map[string]bool{}
+			for i := 0; i < len(shifts); i += len(members) {
+				orders[fmt.Sprint(userIDs(shifts[i:i+len(members)]))] = true
+			}
+			Expect(len(orders)).To(BeNumerically(">", 1))
+		})
+
+		It("returns the same order for the same seed", func() {
+			first, err := New(rota, members).Shifts(start, 20)
+			Expect(err).ToNot(HaveOccurred())
+			second, err := New(rota, members).Shifts(date(2023, time.January, 10, 0), 14)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(second).To(Equal(first[6:]))
+		})
+
+		It("derives a stable order from the rota when it has no seed", func() {
+			rota.Metadata.Seed = 0
+			first, err := New(rota, members).Shifts(start, 10)
+			Expect(err).ToNot(HaveOccurred())
+			second, err := New(rota, members).Shifts(start, 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(second).To(Equal(first))
+		})
+	})
+
+	Describe("Least Served", func() {
+		var history []db.Shift
+
+		served := func(userID string, start time.Time, days int) db.Shift {
+			return db.Shift{
+				UserID:   userID,
+				StartsAt: timestamp(start),
+				EndsAt:   timestamp(start.AddDate(0, 0, days)),
+				Reason:   db.SRScheduled,
+			}
+		}
+
+		BeforeEach(func() {
+			rota.Metadata.Frequency = db.RFDaily
+			rota.Metadata.SchedulingType = db.RSFair
+			history = []db.Shift{
+				served("alice", date(2023, time.January, 4, 0), 1),
+				served("bob", date(2023, time.January, 5, 0), 1),
+				served("carol", date(2023, time.January, 6, 0), 1),
+				served("alice", date(2023, time.January, 7, 0), 1),
+			}
+		})
+
+		It("keeps whoever was handed over to", func() {
+			shift, err := New(rota, members, WithHistory(history)).At(date(2023, time.January, 7, 12))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("alice"))
+		})
+
+		It("picks whoever served the least, then whoever served the longest time ago", func() {
+			shifts, err := New(rota, members, WithHistory(history)).Shifts(date(2023, time.January, 8, 0), 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("bob"))
+			Expect(shifts[1].UserID).To(Equal("carol"))
+			Expect(shifts[2].UserID).To(Equal("alice"))
+			Expect(shifts[3].UserID).To(Equal("bob"))
+		})
+
+		It("balances someone that served less", func() {
+			history = append(history, served("bob", date(2023, time.January, 8, 0), 1), served("alice", date(2023, time.January, 9, 0), 1))
+			shifts, err := New(rota, members, WithHistory(history)).Shifts(date(2023, time.January, 10, 0), 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("carol"))
+			Expect(shifts[1].UserID).To(Equal("bob"))
+			Expect(shifts[2].UserID).To(Equal("carol"))
+		})
+
+		It("doesn't make new members catch up with everyone else", func() {
+			members = append(members, db.Member{ID: "RM4", UserID: "dave", CreatedAt: timestamp(date(2023, time.January, 8, 0))})
+			shifts, err := New(rota, members, WithHistory(history)).Shifts(date(2023, time.January, 8, 0), 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("dave"))
+			Expect(shifts[1].UserID).To(Equal("bob"))
+			Expect(shifts[2].UserID).To(Equal("carol"))
+			Expect(shifts[3].UserID).To(Equal("alice"))
+		})
+
+		It("doesn't count skipped shifts", func() {
+			history[1].Reason = db.SRSkip
+			shift, err := New(rota, members, WithHistory(history)).At(date(2023, time.January, 8, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("bob"))
+		})
+
+		It("doesn't count time twice when someone took over halfway through a shift", func() {
+			history[1].EndsAt = timestamp(date(2023, time.January, 7, 0))
+			shift, err := New(rota, members, WithHistory(history)).At(date(2023, time.January, 8, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("bob"))
+		})
+
+		It("follows the order in which members joined without history", func() {
+			shifts, err := New(rota, members).Shifts(date(2023, time.January, 4, 0), 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("alice"))
+			Expect(shifts[1].UserID).To(Equal("bob"))
+			Expect(shifts[2].UserID).To(Equal("carol"))
+			Expect(shifts[3].UserID).To(Equal("alice"))
+		})
+	})
+
+	Describe("Cadence", func() {
+		It("hands over every few days at the given time", func() {
+			rota.Metadata.Frequency = db.RFDaily
+			rota.Metadata.Cadence = &db.Cadence{Every: 3, Time: "18:00"}
+
+			shifts, err := New(rota, members).Shifts(date(2023, time.January, 4, 16), 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].Start).To(Equal(date(2023, time.January, 1, 18)))
+			Expect(shifts[0].End).To(Equal(date(2023, time.January, 4, 18)))
+			Expect(shifts[1].Start).To(Equal(date(2023, time.January, 4, 18)))
+			Expect(shifts[2].End).To(Equal(date(2023, time.January, 10, 18)))
+		})
+
+		It("hands over every few weeks on the given day", func() {
+			rota.Metadata.Cadence = &db.Cadence{Every: 2, Weekday: time.Monday, Time: "09:00"}
+
+			shifts, err := New(rota, members).Shifts(date(2023, time.January, 4, 16), 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("alice"))
+			Expect(shifts[0].Start).To(Equal(date(2023, time.January, 2, 9)))
+			Expect(shifts[0].End).To(Equal(date(2023, time.January, 16, 9)))
+			Expect(shifts[1].UserID).To(Equal("bob"))
+			Expect(shifts[1].End).To(Equal(date(2023, time.January, 30, 9)))
+		})
+
+		It("starts with the shift covering the creation of the rota", func() {
+			rota.Metadata.Cadence = &db.Cadence{Every: 1, Weekday: time.Wednesday, Time: "17:00"}
+
+			shift, err := New(rota, members).At(rota.CreatedAt.Time)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("alice"))
+			Expect(shift.Start).To(Equal(date(2022, time.December, 28, 17)))
+			Expect(shift.End).To(Equal(date(2023, time.January, 4, 17)))
+		})
+
+		It("hands over every few months", func() {
+			rota.Metadata.Frequency = db.RFMonthly
+			rota.Metadata.Cadence = &db.Cadence{Every: 2, Time: "00:00"}
+
+			shift, err := New(rota, members).At(date(2023, time.March, 10, 0))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("bob"))
+			Expect(shift.Start).To(Equal(date(2023, time.March, 4, 0)))
+			Expect(shift.End).To(Equal(date(2023, time.May, 4, 0)))
+		})
+
+		It("fails when the cadence is invalid", func() {
+			rota.Metadata.Cadence = &db.Cadence{Every: 0, Time: "09:00"}
+			_, err := New(rota, members).At(date(2023, time.January, 5, 0))
+			Expect(err).To(MatchError(ErrInvalidCadence))
+
+			rota.Metadata.Cadence = &db.Cadence{Every: 1, Time: "nine"}
+			_, err = New(rota, members).At(date(2023, time.January, 5, 0))
+			Expect(err).To(MatchError(ErrInvalidCadence))
+		})
+	})
+
+	Describe("Cron", func() {
+		BeforeEach(func() {
+			rota.Metadata.Frequency = db.RFCron
+			rota.Metadata.Cron = "0 10 * * MON,THU"
+		})
+
+		It("hands over whenever the expression fires", func() {
+			shifts, err := New(rota, members).Shifts(rota.CreatedAt.Time, 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("alice"))
+			Expect(shifts[0].Start).To(Equal(date(2023, time.January, 4, 15)))
+			Expect(shifts[0].End).To(Equal(date(2023, time.January, 5, 10)))
+			Expect(shifts[1].UserID).To(Equal("bob"))
+			Expect(shifts[1].End).To(Equal(date(2023, time.January, 9, 10)))
+			Expect(shifts[2].UserID).To(Equal("carol"))
+			Expect(shifts[2].End).To(Equal(date(2023, time.January, 12, 10)))
+		})
+
+		It("works out who is on duty long after the rota was created", func() {
+			shift, err := New(rota, members).At(date(2023, time.March, 1, 0))
+			Expect(err).ToNot(HaveOccurred())
+			// 16 handovers happened by then.
+			Expect(shift.UserID).To(Equal("bob"))
+			Expect(shift.Start).To(Equal(date(2023, time.February, 27, 10)))
+		})
+
+		It("walks from the last handover instead of from when the rota was created", func() {
+			engine := New(rota, members)
+			shift, err := engine.At(date(2023, time.March, 1, 0))
+			Expect(err).ToNot(HaveOccurred())
+			rota.State = engine.Handover(shift)
+			Expect(rota.State.Handover).To(Equal(16))
+			Expect(rota.State.HandoverAt).To(Equal(date(2023, time.February, 27, 10)))
+
+			b, err := newBoundaries(rota)
+			Expect(err).ToNot(HaveOccurred())
+			index, err := b.handoverAt(date(2023, time.March, 6, 12))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(index).To(Equal(18))
+			Expect(b.cron.times[0]).To(Equal(date(2023, time.February, 27, 10)))
+
+			shifts, err := New(rota, members).Shifts(date(2023, time.March, 1, 0), 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("bob"))
+			Expect(shifts[1].UserID).To(Equal("carol"))
+			Expect(shifts[1].Start).To(Equal(date(2023, time.March, 2, 10)))
+			Expect(shifts[2].UserID).To(Equal("alice"))
+		})
+
+		It("walks from when the rota was created once the expression changed", func() {
+			engine := New(rota, members)
+			shift, err := engine.At(date(2023, time.March, 1, 0))
+			Expect(err).ToNot(HaveOccurred())
+			rota.State = engine.Handover(shift)
+			rota.Metadata.Cron = "0 9 * * MON,THU"
+
+			b, err := newBoundaries(rota)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.cron.times[0]).To(Equal(date(2023, time.January, 4, 15)))
+		})
+
+		It("fails when the expression is invalid", func() {
+			rota.Metadata.Cron = "every monday"
+			_, err := New(rota, members).At(date(2023, time.January, 5, 0))
+			Expect(err).To(MatchError(ErrInvalidCadence))
+		})
+	})
+
+	Describe("Working days", func() {
+		BeforeEach(func() {
+			rota.Metadata.Frequency = db.RFDaily
+			rota.Metadata.Cadence = &db.Cadence{Every: 1, Time: "09:00"}
+		})
+
+		It("extends the shift before the weekend until monday", func() {
+			rota.Metadata.WorkingDays = &db.WorkingDays{SkipWeekends: true, Policy: db.NWExtend}
+
+			shifts, err := New(rota, members).Shifts(rota.CreatedAt.Time, 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[1].UserID).To(Equal("bob"))
+			Expect(shifts[1].End).To(Equal(date(2023, time.January, 6, 9)))
+			Expect(shifts[2].UserID).To(Equal("carol"))
+			Expect(shifts[2].Start).To(Equal(date(2023, time.January, 6, 9)))
+			Expect(shifts[2].End).To(Equal(date(2023, time.January, 9, 9)))
+			Expect(shifts[3].UserID).To(Equal("alice"))
+
+			shift, err := New(rota, members).At(date(2023, time.January, 8, 12))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("carol"))
+		})
+
+		It("leaves nobody on duty over the weekend when shifts are skipped", func() {
+			rota.Metadata.WorkingDays = &db.WorkingDays{SkipWeekends: true, Policy: db.NWSkip}
+			sunday := date(2023, time.January, 8, 12)
+
+			shifts, err := New(rota, members).Shifts(rota.CreatedAt.Time, 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[2].UserID).To(Equal("carol"))
+			Expect(shifts[2].End).To(Equal(date(2023, time.January, 7, 9)))
+			Expect(shifts[3].UserID).To(Equal("alice"))
+			Expect(shifts[3].Start).To(Equal(date(2023, time.January, 9, 9)))
+
+			_, err = New(rota, members).At(sunday)
+			Expect(err).To(MatchError(ErrNonWorkingDay))
+
+			upcoming, err := New(rota, members, WithClock(FixedClock(sunday))).Upcoming(1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(upcoming[0].UserID).To(Equal("alice"))
+			Expect(upcoming[0].Start).To(Equal(date(2023, time.January, 9, 9)))
+
+			between, err := New(rota, members).Between(date(2023, time.January, 6, 9), date(2023, time.January, 10, 9))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(between).To(HaveLen(2))
+			Expect(between[1].UserID).To(Equal("alice"))
+		})
+
+		It("doesn't hand over on holidays", func() {
+			rota.Metadata.WorkingDays = &db.WorkingDays{
+				Holidays: []calendar.Holiday{{Date: "2023-01-05", Name: "Founders' Day"}},
+				Policy:   db.NWExtend,
+			}
+
+			shifts, err := New(rota, members).Shifts(rota.CreatedAt.Time, 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("alice"))
+			Expect(shifts[0].End).To(Equal(date(2023, time.January, 6, 9)))
+			Expect(shifts[1].UserID).To(Equal("bob"))
+		})
+
+		It("uses the holidays of the calendars shipped with rotabot", func() {
+			rota.CreatedAt = timestamp(date(2024, time.December, 24, 12))
+			rota.Metadata.WorkingDays = &db.WorkingDays{Calendar: "England and Wales", Policy: db.NWExtend}
+
+			shift, err := New(rota, members).At(date(2024, time.December, 25, 12))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.Start).To(Equal(date(2024, time.December, 24, 9)))
+			Expect(shift.End).To(Equal(date(2024, time.December, 27, 9)))
+		})
+
+		It("walks from the shift the rota was last handed over to", func() {
+			rota.Metadata.WorkingDays = &db.WorkingDays{SkipWeekends: true, Policy: db.NWExtend}
+			engine := New(rota, members)
+			shift, err := engine.At(date(2023, time.March, 1, 12))
+			Expect(err).ToNot(HaveOccurred())
+			rota.State = engine.Handover(shift)
+			Expect(rota.State.Shift).To(Equal(40))
+			Expect(rota.State.Handover).To(Equal(56))
+			Expect(rota.State.HandoverAt).To(Equal(date(2023, time.March, 1, 9)))
+
+			b, err := newBoundaries(rota)
+			Expect(err).ToNot(HaveOccurred())
+			index, err := b.indexAt(date(2023, time.March, 6, 12))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(index).To(Equal(43))
+			Expect(b.working.first).To(Equal(40))
+
+			shifts, err := New(rota, members).Shifts(date(2023, time.March, 1, 12), 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("bob"))
+			Expect(shifts[1].UserID).To(Equal("carol"))
+			Expect(shifts[2].UserID).To(Equal("alice"))
+			Expect(shifts[2].Start).To(Equal(date(2023, time.March, 3, 9)))
+			Expect(shifts[2].End).To(Equal(date(2023, time.March, 6, 9)))
+
+			// Shifts before the last handover are worked out from the first one again.
+			shift, err = New(rota, members).At(date(2023, time.January, 8, 12))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("carol"))
+		})
+
+		It("walks from the first shift once the working days changed", func() {
+			rota.Metadata.WorkingDays = &db.WorkingDays{SkipWeekends: true, Policy: db.NWExtend}
+			engine := New(rota, members)
+			shift, err := engine.At(date(2023, time.March, 1, 12))
+			Expect(err).ToNot(HaveOccurred())
+			rota.State = engine.Handover(shift)
+			rota.Metadata.WorkingDays.Holidays = []calendar.Holiday{{Date: "2023-03-01"}}
+
+			b, err := newBoundaries(rota)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.working.first).To(BeZero())
+		})
+
+		It("tells whether the holidays of the calendar are known", func() {
+			rota.Metadata.WorkingDays = &db.WorkingDays{Calendar: "England and Wales", Policy: db.NWExtend}
+			engine := New(rota, members)
+			Expect(engine.HolidaysKnown(date(2027, time.December, 31, 12))).To(BeTrue())
+			Expect(engine.HolidaysKnown(date(2028, time.January, 1, 12))).To(BeFalse())
+
+			rota.Metadata.WorkingDays = &db.WorkingDays{SkipWeekends: true, Policy: db.NWExtend}
+			Expect(New(rota, members).HolidaysKnown(date(2030, time.January, 1, 12))).To(BeTrue())
+		})
+
+		It("fails when the rota never hands over on a working day", func() {
+			rota.Metadata.Frequency = db.RFWeekly
+			rota.Metadata.Cadence = &db.Cadence{Every: 1, Weekday: time.Saturday, Time: "09:00"}
+			rota.Metadata.WorkingDays = &db.WorkingDays{SkipWeekends: true, Policy: db.NWSkip}
+			_, err := New(rota, members).At(date(2023, time.January, 20, 0))
+			Expect(err).To(MatchError(ErrInvalidWorkingDays))
+
+			rota.Metadata.WorkingDays = &db.WorkingDays{Calendar: "Atlantis"}
+			_, err = New(rota, members).At(date(2023, time.January, 20, 0))
+			Expect(err).To(MatchError(ErrInvalidWorkingDays))
+		})
+	})
+
+	Describe("Overrides", func() {
+		override := func(id, userID string, start, end time.Time, created int) db.Override {
+			return db.Override{
+				ID:        id,
+				RotaID:    rota.ID,
+				UserID:    userID,
+				StartsAt:  timestamp(start),
+				EndsAt:    timestamp(end),
+				CreatedAt: timestamp(date(2023, time.January, 4, 18).Add(time.Duration(created) * time.Minute)),
+			}
+		}
+
+		BeforeEach(func() {
+			rota.Metadata.Frequency = db.RFDaily
+			rota.Metadata.Cadence = &db.Cadence{Every: 1, Time: "09:00"}
+		})
+
+		It("hands the shift back once the override ends", func() {
+			overrides := []db.Override{override("OV1", "dave", date(2023, time.January, 5, 12), date(2023, time.January, 5, 15), 0)}
+
+			shifts, err := New(rota, members, WithOverrides(overrides)).Shifts(date(2023, time.January, 5, 10), 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0]).To(Equal(Shift{
+				UserID: "bob",
+				Start:  date(2023, time.January, 5, 9),
+				End:    date(2023, time.January, 5, 12),
+				Reason: db.SRScheduled,
+			}))
+			Expect(shifts[1]).To(Equal(Shift{
+				UserID:     "dave",
+				Start:      date(2023, time.January, 5, 12),
+				End:        date(2023, time.January, 5, 15),
+				Reason:     db.SROverride,
+				OverrideID: "OV1",
+			}))
+			Expect(shifts[2].UserID).To(Equal("bob"))
+			Expect(shifts[2].Start).To(Equal(date(2023, time.January, 5, 15)))
+			Expect(shifts[2].End).To(Equal(date(2023, time.January, 6, 9)))
+			Expect(shifts[3].UserID).To(Equal("carol"))
+
+			shift, err := New(rota, members, WithOverrides(overrides)).At(date(2023, time.January, 5, 13))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("dave"))
+		})
+
+		It("keeps a single shift when the override spans a handover", func() {
+			overrides := []db.Override{override("OV1", "dave", date(2023, time.January, 5, 12), date(2023, time.January, 7, 12), 0)}
+
+			shifts, err := New(rota, members, WithOverrides(overrides)).Shifts(date(2023, time.January, 5, 13), 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("dave"))
+			Expect(shifts[0].Start).To(Equal(date(2023, time.January, 5, 12)))
+			Expect(shifts[0].End).To(Equal(date(2023, time.January, 7, 12)))
+			Expect(shifts[1].UserID).To(Equal("alice"))
+			Expect(shifts[1].Start).To(Equal(date(2023, time.January, 7, 12)))
+			Expect(shifts[1].End).To(Equal(date(2023, time.January, 8, 9)))
+		})
+
+		It("prefers the override created last when they overlap", func() {
+			overrides := []db.Override{
+				override("OV2", "erin", date(2023, time.January, 5, 12), date(2023, time.January, 5, 14), 1),
+				override("OV1", "dave", date(2023, time.January, 5, 10), date(2023, time.January, 5, 16), 0),
+			}
+
+			shifts, err := New(rota, members, WithOverrides(overrides)).Between(date(2023, time.January, 5, 9), date(2023, time.January, 6, 9))
+			Expect(err).ToNot(HaveOccurred())
+			users := []string{}
+			for _, s := range shifts {
+				users = append(users, s.UserID)
+			}
+			Expect(users).To(Equal([]string{"bob", "dave", "erin", "dave", "bob"}))
+		})
+
+		It("puts someone on duty when the rota skips the day", func() {
+			rota.Metadata.WorkingDays = &db.WorkingDays{SkipWeekends: true, Policy: db.NWSkip}
+			overrides := []db.Override{override("OV1", "dave", date(2023, time.January, 7, 9), date(2023, time.January, 8, 9), 0)}
+			saturday := date(2023, time.January, 7, 12)
+
+			shift, err := New(rota, members, WithOverrides(overrides)).At(saturday)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("dave"))
+
+			upcoming, err := New(rota, members, WithOverrides(overrides), WithClock(FixedClock(saturday))).Upcoming(1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(upcoming[0].UserID).To(Equal("alice"))
+			Expect(upcoming[0].Start).To(Equal(date(2023, time.January, 9, 9)))
+		})
+
+		It("doesn't change who is next in the rotation", func() {
+			overrides := []db.Override{override("OV1", "bob", date(2023, time.January, 4, 9), date(2023, time.January, 5, 9), 0)}
+
+			shifts, err := New(rota, members, WithOverrides(overrides)).Shifts(date(2023, time.January, 4, 16), 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("bob"))
+			Expect(shifts[0].Reason).To(Equal(db.SROverride))
+			Expect(shifts[1].UserID).To(Equal("bob"))
+			Expect(shifts[1].Reason).To(Equal(db.SRScheduled))
+		})
+	})
+
+	Describe("Unavailabilities", func() {
+		out := func(userID string, start, end time.Time) db.Unavailability {
+			return db.Unavailability{UserID: userID, StartsAt: timestamp(start), EndsAt: timestamp(end)}
+		}
+
+		BeforeEach(func() {
+			rota.Metadata.Frequency = db.RFDaily
+			rota.Metadata.Cadence = &db.Cadence{Every: 1, Time: "09:00"}
+		})
+
+		It("skips members that are unavailable during part of their shift", func() {
+			unavailabilities := []db.Unavailability{out("bob", date(2023, time.January, 5, 15), date(2023, time.January, 5, 18))}
+
+			shifts, err := New(rota, members, WithUnavailabilities(unavailabilities)).Shifts(date(2023, time.January, 5, 9), 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("carol"))
+			Expect(shifts[0].Reason).To(Equal(db.SRScheduled))
+			Expect(shifts[1].UserID).To(Equal("carol"))
+			Expect(shifts[2].UserID).To(Equal("alice"))
+		})
+
+		It("keeps members whose unavailability ends as their shift starts", func() {
+			unavailabilities := []db.Unavailability{out("bob", date(2023, time.January, 4, 9), date(2023, time.January, 5, 9))}
+
+			shift, err := New(rota, members, WithUnavailabilities(unavailabilities)).At(date(2023, time.January, 5, 9))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("bob"))
+		})
+
+		It("pulls forward the next member that is available", func() {
+			unavailabilities := []db.Unavailability{
+				out("bob", date(2023, time.January, 5, 0), date(2023, time.January, 6, 0)),
+				out("carol", date(2023, time.January, 5, 0), date(2023, time.January, 6, 0)),
+			}
+
+			shift, err := New(rota, members, WithUnavailabilities(unavailabilities)).At(date(2023, time.January, 5, 9))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("alice"))
+		})
+
+		It("keeps whoever the rotation picked when everyone is unavailable", func() {
+			unavailabilities := []db.Unavailability{}
+			for _, m := range members {
+				unavailabilities = append(unavailabilities, out(m.UserID, date(2023, time.January, 5, 0), date(2023, time.January, 6, 0)))
+			}
+
+			shift, err := New(rota, members, WithUnavailabilities(unavailabilities)).At(date(2023, time.January, 5, 9))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("bob"))
+		})
+
+		It("never picks unavailable members when scheduled randomly", func() {
+			rota.Metadata.SchedulingType = db.RSRandom
+			rota.Metadata.Seed = 42
+			unavailabilities := []db.Unavailability{out("alice", date(2023, time.January, 4, 0), date(2023, time.January, 20, 0))}
+
+			shifts, err := New(rota, members, WithUnavailabilities(unavailabilities)).Between(date(2023, time.January, 5, 9), date(2023, time.January, 19, 9))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts).To(HaveLen(14))
+			for _, s := range shifts {
+				Expect(s.UserID).ToNot(Equal("alice"))
+			}
+		})
+
+		It("has members scheduled by least served catch up once they're back", func() {
+			rota.Metadata.SchedulingType = db.RSFair
+			unavailabilities := []db.Unavailability{out("alice", date(2023, time.January, 4, 0), date(2023, time.January, 8, 0))}
+
+			shifts, err := New(rota, members, WithUnavailabilities(unavailabilities)).Shifts(date(2023, time.January, 4, 9), 7)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("bob"))
+			Expect(shifts[1].UserID).To(Equal("carol"))
+			Expect(shifts[2].UserID).To(Equal("bob"))
+			Expect(shifts[3].UserID).To(Equal("carol"))
+			Expect(shifts[4].UserID).To(Equal("alice"))
+			Expect(shifts[5].UserID).To(Equal("alice"))
+			Expect(shifts[6].UserID).To(Equal("bob"))
+		})
+	})
+
+	Describe("Skips", func() {
+		skip := func(userID string, at time.Time) db.Shift {
+			return db.Shift{UserID: userID, StartsAt: timestamp(at), EndsAt: timestamp(at.AddDate(0, 0, 1)), Reason: db.SRSkip}
+		}
+
+		BeforeEach(func() {
+			rota.Metadata.Frequency = db.RFDaily
+			rota.Metadata.Cadence = &db.Cadence{Every: 1, Time: "09:00"}
+		})
+
+		It("moves the rotation forward from the shift that was skipped", func() {
+			skips := []db.Shift{skip("bob", date(2023, time.January, 5, 9))}
+
+			shifts, err := New(rota, members, WithSkips(skips)).Shifts(date(2023, time.January, 4, 9), 4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("alice"))
+			Expect(shifts[1].UserID).To(Equal("carol"))
+			Expect(shifts[2].UserID).To(Equal("alice"))
+			Expect(shifts[3].UserID).To(Equal("bob"))
+		})
+
+		It("hands over straight away when whoever is on duty is skipped", func() {
+			skips := []db.Shift{skip("bob", date(2023, time.January, 5, 13)), skip("carol", date(2023, time.January, 5, 14))}
+
+			shift, err := New(rota, members, WithSkips(skips)).At(date(2023, time.January, 5, 15))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("alice"))
+		})
+
+		It("leaves whoever was skipped out of the shift when scheduled by least served", func() {
+			rota.Metadata.SchedulingType = db.RSFair
+			history := []db.Shift{
+				{UserID: "alice", StartsAt: timestamp(date(2023, time.January, 4, 9)), EndsAt: timestamp(date(2023, time.January, 5, 9)), Reason: db.SRScheduled},
+				{UserID: "bob", StartsAt: timestamp(date(2023, time.January, 5, 9)), EndsAt: timestamp(date(2023, time.January, 6, 9)), Reason: db.SRScheduled},
+			}
+
+			engine := New(rota, members, WithHistory(history), WithSkips([]db.Shift{skip("bob", date(2023, time.January, 5, 13))}))
+			shift, err := engine.At(date(2023, time.January, 5, 15))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("carol"))
+
+			engine = New(rota, members, WithHistory(history), WithSkips([]db.Shift{skip("carol", date(2023, time.January, 6, 9))}))
+			shift, err = engine.At(date(2023, time.January, 6, 9))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("alice"))
+		})
+
+		It("only moves the rotation of the window whoever was skipped belongs to", func() {
+			rota.Metadata.Windows = []db.Window{
+				{Name: "EMEA", Start: "08:00", UserIDs: []string{"alice", "bob"}},
+				{Name: "AMER", Start: "16:00", UserIDs: []string{"carol"}},
+			}
+			skips := []db.Shift{skip("bob", date(2023, time.January, 5, 9))}
+
+			shifts, err := New(rota, members, WithSkips(skips)).Shifts(date(2023, time.January, 5, 9), 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("alice"))
+			Expect(shifts[0].Window).To(Equal("EMEA"))
+			Expect(shifts[1].UserID).To(Equal("carol"))
+			Expect(shifts[1].Window).To(Equal("AMER"))
+		})
+	})
+
+	Describe("Tiers", func() {
+		BeforeEach(func() {
+			rota.Metadata.Frequency = db.RFDaily
+			rota.Metadata.Cadence = &db.Cadence{Every: 1, Time: "09:00"}
+			rota.Metadata.Tiers = 2
+		})
+
+		tier := func(e *Engine, t int) *Engine {
+			engine, err := e.Tier(t)
+			Expect(err).ToNot(HaveOccurred())
+			return engine
+		}
+
+		It("puts the member after the primary on duty in the secondary tier", func() {
+			shifts, err := tier(New(rota, members), 1).Shifts(date(2023, time.January, 5, 9), 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("carol"))
+			Expect(shifts[0].Tier).To(Equal(1))
+			Expect(shifts[0].Start).To(Equal(date(2023, time.January, 5, 9)))
+			Expect(shifts[1].UserID).To(Equal("alice"))
+			Expect(shifts[2].UserID).To(Equal("bob"))
+		})
+
+		It("fails for tiers the rota doesn't have", func() {
+			_, err := New(rota, members).Tier(2)
+			Expect(err).To(MatchError(ErrUnknownTier))
+			_, err = New(rota, members).Tier(-1)
+			Expect(err).To(MatchError(ErrUnknownTier))
+		})
+
+		It("treats rotas without tiers as having a primary only", func() {
+			rota.Metadata.Tiers = 0
+			Expect(Tiers(rota)).To(Equal(1))
+			_, err := New(rota, members).Tier(1)
+			Expect(err).To(MatchError(ErrUnknownTier))
+		})
+
+		It("fails when there are fewer members than tiers", func() {
+			rota.Metadata.Tiers = 4
+			_, err := tier(New(rota, members), 3).At(date(2023, time.January, 5, 9))
+			Expect(err).To(MatchError(ErrNotEnoughMembers))
+		})
+
+		It("never puts the same member on duty in two tiers", func() {
+			rota.Metadata.Tiers = 3
+			rota.Metadata.SchedulingType = db.RSRandom
+			rota.Metadata.Seed = 42
+			unavailabilities := []db.Unavailability{{
+				UserID:   "alice",
+				StartsAt: timestamp(date(2023, time.January, 6, 0)),
+				EndsAt:   timestamp(date(2023, time.January, 8, 0)),
+			}}
+			e := New(rota, members, WithUnavailabilities(unavailabilities))
+
+			for i := 5; i < 12; i++ {
+				onDuty := map[string]bool{}
+				for t := 0; t < 3; t++ {
+					shift, err := tier(e, t).At(date(2023, time.January, i, 9))
+					Expect(err).ToNot(HaveOccurred())
+					onDuty[shift.UserID] = true
+				}
+				Expect(onDuty).To(HaveLen(3))
+			}
+		})
+
+		It("skips members that are unavailable in the secondary tier", func() {
+			unavailabilities := []db.Unavailability{{
+				UserID:   "carol",
+				StartsAt: timestamp(date(2023, time.January, 5, 12)),
+				EndsAt:   timestamp(date(2023, time.January, 5, 13)),
+			}}
+
+			shift, err := tier(New(rota, members, WithUnavailabilities(unavailabilities)), 1).At(date(2023, time.January, 5, 9))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("alice"))
+		})
+
+		It("only lets overrides cover for the primary tier", func() {
+			overrides := []db.Override{{
+				ID:       "OV1",
+				UserID:   "dave",
+				StartsAt: timestamp(date(2023, time.January, 5, 9)),
+				EndsAt:   timestamp(date(2023, time.January, 6, 9)),
+			}}
+			e := New(rota, members, WithOverrides(overrides))
+
+			primary, err := e.At(date(2023, time.January, 5, 9))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(primary.UserID).To(Equal("dave"))
+
+			secondary, err := tier(e, 1).At(date(2023, time.January, 5, 9))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(secondary.UserID).To(Equal("carol"))
+			Expect(secondary.Reason).To(Equal(db.SRScheduled))
+		})
+
+		It("names the tiers", func() {
+			Expect(TierName(0)).To(Equal("Primary"))
+			Expect(TierName(1)).To(Equal("Secondary"))
+			Expect(TierName(3)).To(Equal("Tier 4"))
+		})
+	})
+
+	Describe("Windows", func() {
+		BeforeEach(func() {
+			rota.Metadata.Frequency = db.RFDaily
+			rota.Metadata.Cadence = &db.Cadence{Every: 1, Time: "09:00"}
+			rota.Metadata.Windows = []db.Window{
+				{Name: "EMEA", Start: "08:00", UserIDs: []string{"bob", "erin"}},
+				{Name: "APAC", Start: "00:00", UserIDs: []string{"alice", "dave"}},
+				{Name: "AMER", Start: "16:00", UserIDs: []string{"carol"}},
+			}
+			members = append(members,
+				db.Member{ID: "RM5", UserID: "erin", CreatedAt: timestamp(date(2023, time.January, 4, 19))},
+				db.Member{ID: "RM4", UserID: "dave", CreatedAt: timestamp(date(2023, time.January, 4, 18))},
+			)
+		})
+
+		It("splits each shift into the windows it spans", func() {
+			shifts, err := New(rota, members).Shifts(date(2023, time.January, 5, 9), 5)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(shifts[0]).To(Equal(Shift{UserID: "erin", Start: date(2023, time.January, 5, 9), End: date(2023, time.January, 5, 16), Reason: db.SRScheduled, Window: "EMEA"}))
+			Expect(shifts[1]).To(Equal(Shift{UserID: "carol", Start: date(2023, time.January, 5, 16), End: date(2023, time.January, 6, 0), Reason: db.SRScheduled, Window: "AMER"}))
+			Expect(shifts[2]).To(Equal(Shift{UserID: "dave", Start: date(2023, time.January, 6, 0), End: date(2023, time.January, 6, 8), Reason: db.SRScheduled, Window: "APAC"}))
+			Expect(shifts[3]).To(Equal(Shift{UserID: "erin", Start: date(2023, time.January, 6, 8), End: date(2023, time.January, 6, 9), Reason: db.SRScheduled, Window: "EMEA"}))
+			// Each window rotates through its own members when the rota hands over.
+			Expect(shifts[4]).To(Equal(Shift{UserID: "bob", Start: date(2023, time.January, 6, 9), End: date(2023, time.January, 6, 16), Reason: db.SRScheduled, Window: "EMEA"}))
+		})
+
+		It("starts the windows at the same time of the day in the rota's time zone", func() {
+			rota.Metadata.TimeZone = "America/New_York"
+			newYork, err := time.LoadLocation("America/New_York")
+			Expect(err).ToNot(HaveOccurred())
+
+			shift, err := New(rota, members).At(time.Date(2023, time.March, 13, 17, 0, 0, 0, newYork))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.Window).To(Equal("AMER"))
+			Expect(shift.Start).To(Equal(time.Date(2023, time.March, 13, 16, 0, 0, 0, newYork)))
+			Expect(shift.End).To(Equal(time.Date(2023, time.March, 14, 0, 0, 0, 0, newYork)))
+		})
+
+		It("lets overrides cover part of a window", func() {
+			overrides := []db.Override{{
+				ID:       "OV1",
+				UserID:   "frank",
+				StartsAt: timestamp(date(2023, time.January, 5, 15)),
+				EndsAt:   timestamp(date(2023, time.January, 5, 17)),
+			}}
+
+			shifts, err := New(rota, members, WithOverrides(overrides)).Shifts(date(2023, time.January, 5, 9), 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("erin"))
+			Expect(shifts[0].End).To(Equal(date(2023, time.January, 5, 15)))
+			Expect(shifts[1].UserID).To(Equal("frank"))
+			Expect(shifts[1].Reason).To(Equal(db.SROverride))
+			Expect(shifts[1].End).To(Equal(date(2023, time.January, 5, 17)))
+			Expect(shifts[2].UserID).To(Equal("carol"))
+			Expect(shifts[2].Start).To(Equal(date(2023, time.January, 5, 17)))
+		})
+
+		It("fails when a window has no members", func() {
+			rota.Metadata.Windows[2].UserIDs = []string{"zoe"}
+			_, err := New(rota, members).At(date(2023, time.January, 5, 9))
+			Expect(err).To(MatchError(ErrNoMembers))
+		})
+
+		It("fails when a window doesn't have enough members for a tier", func() {
+			rota.Metadata.Tiers = 2
+			e, err := New(rota, members).Tier(1)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = e.At(date(2023, time.January, 5, 9))
+			Expect(err).To(MatchError(ErrNotEnoughMembers))
+		})
+
+		It("fails when the windows can't be told apart", func() {
+			rota.Metadata.Windows[1].Start = "08:00"
+			_, err := New(rota, members).At(date(2023, time.January, 5, 9))
+			Expect(err).To(MatchError(ErrInvalidWindows))
+
+			rota.Metadata.Windows = rota.Metadata.Windows[:1]
+			_, err = New(rota, members).At(date(2023, time.January, 5, 9))
+			Expect(err).To(MatchError(ErrInvalidWindows))
+		})
+	})
+
+	Describe("Time zones", func() {
+		var london, newYork *time.Location
+
+		BeforeEach(func() {
+			var err error
+			london, err = time.LoadLocation("Europe/London")
+			Expect(err).ToNot(HaveOccurred())
+			newYork, err = time.LoadLocation("America/New_York")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("hands over on the rota's monday", func() {
+			rota.Metadata.TimeZone = "America/New_York"
+			rota.Metadata.Cadence = &db.Cadence{Every: 1, Weekday: time.Monday, Time: "09:00"}
+			// It's already Tuesday in London but still Monday in New York.
+			rota.CreatedAt = timestamp(date(2023, time.January, 10, 2))
+
+			shift, err := New(rota, members).At(rota.CreatedAt.Time)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.Start).To(Equal(time.Date(2023, time.January, 9, 9, 0, 0, 0, newYork)))
+			Expect(shift.End).To(Equal(time.Date(2023, time.January, 16, 9, 0, 0, 0, newYork)))
+		})
+
+		It("keeps handing over at the same time when daylight saving time starts", func() {
+			rota.Metadata.TimeZone = "Europe/London"
+			rota.Metadata.Cadence = &db.Cadence{Every: 1, Weekday: time.Monday, Time: "09:00"}
+			rota.CreatedAt = timestamp(date(2023, time.March, 20, 12))
+
+			shifts, err := New(rota, members).Shifts(rota.CreatedAt.Time, 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].Start).To(Equal(time.Date(2023, time.March, 20, 9, 0, 0, 0, london)))
+			Expect(shifts[0].Start.UTC().Hour()).To(Equal(9))
+			Expect(shifts[1].Start).To(Equal(time.Date(2023, time.March, 27, 9, 0, 0, 0, london)))
+			Expect(shifts[1].Start.UTC().Hour()).To(Equal(8))
+			Expect(shifts[0].End.Sub(shifts[0].Start)).To(Equal(7*24*time.Hour - time.Hour))
+		})
+
+		It("works out who is on duty on the days daylight saving time ends", func() {
+			rota.Metadata.Frequency = db.RFDaily
+			rota.Metadata.TimeZone = "America/New_York"
+			rota.Metadata.Cadence = &db.Cadence{Every: 1, Time: "00:00"}
+			rota.CreatedAt = timestamp(time.Date(2023, time.November, 1, 12, 0, 0, 0, newYork))
+
+			shift, err := New(rota, members).At(time.Date(2023, time.November, 5, 23, 30, 0, 0, newYork))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.Start).To(Equal(time.Date(2023, time.November, 5, 0, 0, 0, 0, newYork)))
+			Expect(shift.End.Sub(shift.Start)).To(Equal(25 * time.Hour))
+			Expect(shift.UserID).To(Equal("bob"))
+		})
+
+		It("evaluates cron expressions in the rota's time zone", func() {
+			rota.Metadata.Frequency = db.RFCron
+			rota.Metadata.Cron = "0 10 * * MON"
+			rota.Metadata.TimeZone = "Europe/London"
+			rota.CreatedAt = timestamp(date(2023, time.July, 4, 12))
+
+			shift, err := New(rota, members).At(rota.CreatedAt.Time)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.End).To(Equal(time.Date(2023, time.July, 10, 10, 0, 0, 0, london)))
+			Expect(shift.End.UTC().Hour()).To(Equal(9))
+		})
+
+		It("fails when the time zone is unknown", func() {
+			rota.Metadata.TimeZone = "Mars/Olympus_Mons"
+			_, err := New(rota, members).At(date(2023, time.January, 5, 0))
+			Expect(err).To(MatchError(ErrInvalidTimeZone))
+		})
+	})
+
+	Describe("Handover", func() {
+		BeforeEach(func() {
+			rota.Metadata.Frequency = db.RFDaily
+			rota.Metadata.Cadence = &db.Cadence{Every: 1, Time: "09:00"}
+		})
+
+		handOver := func(at time.Time) {
+			engine := New(rota, members)
+			shift, err := engine.At(at)
+			Expect(err).ToNot(HaveOccurred())
+			rota.State = engine.Handover(shift)
+		}
+
+		It("remembers who the rotation picked", func() {
+			overrides := []db.Override{{ID: "OV1", UserID: "carol", StartsAt: timestamp(date(2023, time.January, 5, 12)), EndsAt: timestamp(date(2023, time.January, 5, 18))}}
+			engine := New(rota, members, WithOverrides(overrides))
+			shift, err := engine.At(date(2023, time.January, 5, 15))
+			Expect(err).ToNot(HaveOccurred())
+
+			state := engine.Handover(shift)
+			Expect(state.UserID).To(Equal("carol"))
+			Expect(state.ShiftStart).To(Equal(date(2023, time.January, 5, 12)))
+			Expect(state.ScheduledUserID).To(Equal("bob"))
+		})
+
+		It("keeps the current shift when a member that isn't on duty leaves", func() {
+			handOver(date(2023, time.January, 5, 10))
+
+			shifts, err := New(rota, []db.Member{members[0], members[2]}).Shifts(date(2023, time.January, 5, 10), 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("bob"))
+			Expect(shifts[1].UserID).To(Equal("carol"))
+			Expect(shifts[2].UserID).To(Equal("bob"))
+		})
+
+		It("keeps the current shift when a member joins", func() {
+			handOver(date(2023, time.January, 6, 10))
+			members = append(members, db.Member{ID: "RM0", UserID: "dave", CreatedAt: timestamp(date(2023, time.January, 1, 0))})
+
+			shifts, err := New(rota, members).Shifts(date(2023, time.January, 6, 10), 3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("carol"))
+			Expect(shifts[1].UserID).To(Equal("dave"))
+			Expect(shifts[2].UserID).To(Equal("alice"))
+		})
+
+		It("keeps the current shift when members are reordered", func() {
+			handOver(date(2023, time.January, 5, 10))
+			members[0].Position = 1
+			members[1].Position = 2
+			members[2].Position = 0
+
+			for _, at := range []time.Time{date(2023, time.January, 5, 10), date(2023, time.January, 5, 20)} {
+				shift, err := New(rota, members).At(at)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shift.UserID).To(Equal("bob"))
+			}
+			shift, err := New(rota, members).At(date(2023, time.January, 6, 10))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("carol"))
+		})
+
+		It("moves forward when whoever is on duty is skipped after the handover", func() {
+			handOver(date(2023, time.January, 5, 10))
+			skips := []db.Shift{{UserID: "bob", StartsAt: timestamp(date(2023, time.January, 5, 13)), EndsAt: timestamp(date(2023, time.January, 6, 9)), Reason: db.SRSkip}}
+
+			engine := New(rota, members, WithSkips(skips))
+			shift, err := engine.At(date(2023, time.January, 5, 15))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("carol"))
+
+			rota.State = engine.Handover(shift)
+			shifts, err := New(rota, members, WithSkips(skips)).Shifts(date(2023, time.January, 5, 15), 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shifts[0].UserID).To(Equal("carol"))
+			Expect(shifts[1].UserID).To(Equal("alice"))
+		})
+
+		It("starts over when whoever the rotation picked left", func() {
+			handOver(date(2023, time.January, 5, 10))
+
+			shift, err := New(rota, []db.Member{members[0], members[1]}).At(date(2023, time.January, 5, 10))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shift.UserID).To(Equal("carol"))
+		})
+	})
+})
@@ -0,0 +1,49 @@
+package rotation
+
+import (
+	"hash/fnv"
+	"math/rand"
+)
+
+// shuffleBag hands out the members of a rota in a random order where everyone serves once before anyone serves
+// again. Each cycle through the members uses its own permutation, derived from the rota's seed, so the order
+// can be previewed ahead of time and every instance of rotabot agrees on it.
+type shuffleBag struct {
+	seed int64
+	size int
+}
+
+func newShuffleBag(seed int64, rotaID string, size int) shuffleBag {
+	if seed == 0 {
+		// Rotas created before seeds were stored still need an order that doesn't change between restarts.
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(rotaID))
+		seed = int64(h.Sum64())
+	}
+	return shuffleBag{seed: seed, size: size}
+}
+
+// pick returns the position of the member on duty for the shift with the given index.
+func (s shuffleBag) pick(index int) int {
+	switch s.size {
+	case 1:
+		return 0
+	case 2:
+		// With two members the only order that never repeats someone is to alternate.
+		return s.permutation(0)[index%2]
+	}
+
+	cycle := index / s.size
+	perm := s.permutation(cycle)
+	if cycle > 0 {
+		// A new cycle must not start with whoever closed the previous one, otherwise they'd serve twice in a row.
+		if previous := s.permutation(cycle - 1); perm[0] == previous[s.size-1] {
+			perm[0], perm[1] = perm[1], perm[0]
+		}
+	}
+	return perm[index%s.size]
+}
+
+func (s shuffleBag) permutation(cycle int) []int {
+	return rand.New(rand.NewSource(s.seed + int64(cycle))).Perm(s.size) // nolint:gosec
+}
@@ -0,0 +1,46 @@
+package rotation
+
+import (
+	"github.com/rotabot-io/rotabot/lib/db"
+)
+
+// WithSkips gives the engine the shifts members were skipped from by hand, see db.SRSkip. Each skip moves the
+// rotation forward by one member from the shift it took effect in onwards, so whoever was skipped loses their turn
+// and everyone else keeps their order. Rotas scheduled by least served leave whoever was skipped out of that shift
+// instead, since they haven't served they're picked again as soon as possible.
+func WithSkips(skips []db.Shift) Option {
+	return func(e *Engine) {
+		e.skips = skips
+	}
+}
+
+// skipped returns the users that were skipped from the shift with the given index.
+func (e *Engine) skipped(b boundaries, index int) []string {
+	if len(e.skips) == 0 {
+		return nil
+	}
+	if e.skipAt == nil {
+		e.skipAt = map[int][]string{}
+		for _, skip := range e.skips {
+			i, err := b.indexAt(skip.StartsAt.Time)
+			if err != nil {
+				continue
+			}
+			e.skipAt[i] = append(e.skipAt[i], skip.UserID)
+		}
+	}
+	return e.skipAt[index]
+}
+
+// offset returns how many members the rotation moved forward by, because of skips, as of the shift with the
+// given index.
+func (e *Engine) offset(b boundaries, index int) int {
+	e.skipped(b, index)
+	offset := 0
+	for i, userIDs := range e.skipAt {
+		if i <= index {
+			offset += len(userIDs)
+		}
+	}
+	return offset
+}
@@ -0,0 +1,50 @@
+package rotation
+
+import (
+	"slices"
+	"time"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+)
+
+// WithUnavailabilities gives the engine the periods in which members can't be on duty. Members that are
+// unavailable at any point of a shift are skipped and the next member that is available is on duty instead.
+func WithUnavailabilities(unavailabilities []db.Unavailability) Option {
+	return func(e *Engine) {
+		e.unavailabilities = unavailabilities
+	}
+}
+
+// Available reports whether the user can be on duty for the whole of the window between start and end.
+func (e *Engine) Available(userID string, start, end time.Time) bool {
+	for _, u := range e.unavailabilities {
+		if u.UserID != userID {
+			continue
+		}
+		if u.StartsAt.Time.Before(end) && u.EndsAt.Time.After(start) {
+			return false
+		}
+	}
+	return true
+}
+
+// firstAvailable returns the position of the first member, starting from the given one and following the order
+// in which members joined, that is available for the whole window. Members whose position is taken, because
+// they're already on duty in another tier, are skipped. When nobody is available, the first member that isn't
+// taken stays on duty rather than leaving the rota without anyone.
+func (e *Engine) firstAvailable(pick int, start, end time.Time, taken []int) int {
+	fallback := -1
+	for k := 0; k < len(e.members); k++ {
+		i := (pick + k) % len(e.members)
+		if slices.Contains(taken, i) {
+			continue
+		}
+		if fallback < 0 {
+			fallback = i
+		}
+		if e.Available(e.members[i].UserID, start, end) {
+			return i
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,151 @@
+package rotation
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+)
+
+// window is a time of the day during which the rotation of its own pool of members is on duty. Each window has
+// an engine of its own, restricted to the members of its pool, so the pools take turns independently of each
+// other while handing over at the same time.
+type window struct {
+	name   string
+	start  time.Time
+	engine *Engine
+}
+
+// newWindows splits the engine into one engine per window of the rota, sorted by the time they start.
+func (e *Engine) newWindows() []window {
+	if len(e.rota.Metadata.Windows) == 0 {
+		return nil
+	}
+	ordered := slices.Clone(e.rota.Metadata.Windows)
+	slices.SortStableFunc(ordered, func(a, b db.Window) int {
+		return strings.Compare(a.Start, b.Start)
+	})
+
+	windows := make([]window, 0, len(ordered))
+	for _, w := range ordered {
+		sub := *e
+		sub.rota.Metadata.Windows = nil
+		sub.windows = nil
+		sub.fair = nil
+		sub.skipAt = nil
+		// The rota only remembers who it last handed over to in one of the windows, each pool keeps its own order.
+		sub.rota.State = db.RotaState{}
+		sub.members = []db.Member{}
+		for _, m := range e.members {
+			if slices.Contains(w.UserIDs, m.UserID) {
+				sub.members = append(sub.members, m)
+			}
+		}
+		// Members scheduled by least served only compete with those in the same pool.
+		sub.history = []db.Shift{}
+		for _, s := range e.history {
+			if slices.Contains(w.UserIDs, s.UserID) {
+				sub.history = append(sub.history, s)
+			}
+		}
+		// Skipping a member only moves the rotation of their own pool forward.
+		sub.skips = []db.Shift{}
+		for _, s := range e.skips {
+			if slices.Contains(w.UserIDs, s.UserID) {
+				sub.skips = append(sub.skips, s)
+			}
+		}
+		start, _ := time.Parse("15:04", w.Start)
+		windows = append(windows, window{name: w.Name, start: start, engine: &sub})
+	}
+	return windows
+}
+
+// validWindows checks that the windows of the rota can be scheduled: there must be more than one, each starts at
+// a different time and has enough members for the tier of the engine.
+func (e *Engine) validWindows() error {
+	if len(e.rota.Metadata.Windows) == 0 {
+		return nil
+	}
+	if len(e.rota.Metadata.Windows) == 1 {
+		return ErrInvalidWindows
+	}
+	starts := []string{}
+	for _, w := range e.rota.Metadata.Windows {
+		if _, err := time.Parse("15:04", w.Start); err != nil || slices.Contains(starts, w.Start) {
+			return ErrInvalidWindows
+		}
+		starts = append(starts, w.Start)
+	}
+	for _, w := range e.windows {
+		if len(w.engine.members) == 0 {
+			return ErrNoMembers
+		}
+		if e.tier >= len(w.engine.members) {
+			return ErrNotEnoughMembers
+		}
+	}
+	return nil
+}
+
+// windowShifts splits the shift with the given index, between start and end, into the windows it spans. Each
+// window is on duty with whoever its own rotation picked for the shift.
+func (e *Engine) windowShifts(b boundaries, index int, start, end time.Time) []Shift {
+	userIDs := make([]string, len(e.windows))
+	for i, w := range e.windows {
+		userIDs[i] = w.engine.member(b, index).UserID
+	}
+
+	// Shifts are worked out in the rota's time zone, so windows start at the same time of the day all year round.
+	loc := start.Location()
+	at := func(day time.Time, offset int, w window) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day()+offset, w.start.Hour(), w.start.Minute(), 0, 0, loc)
+	}
+
+	shifts := []Shift{}
+	y, m, d := start.Date()
+	// The last window of the day before may still be going on when the shift starts.
+	for day := time.Date(y, m, d-1, 0, 0, 0, 0, loc); day.Before(end); day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc) {
+		for i, w := range e.windows {
+			from := at(day, 0, w)
+			to := at(day, 1, e.windows[0])
+			if i+1 < len(e.windows) {
+				to = at(day, 0, e.windows[i+1])
+			}
+			if from.Before(start) {
+				from = start
+			}
+			if to.After(end) {
+				to = end
+			}
+			if !to.After(from) {
+				continue
+			}
+			shifts = append(shifts, Shift{
+				UserID: userIDs[i],
+				Start:  from,
+				End:    to,
+				Reason: db.SRScheduled,
+				Tier:   e.tier,
+				Window: w.name,
+			})
+		}
+	}
+	if len(shifts) == 0 {
+		// Shifts that never start still belong to the window they would have started in.
+		i := len(e.windows) - 1
+		for i > 0 && at(start, 0, e.windows[i]).After(start) {
+			i--
+		}
+		shifts = append(shifts, Shift{
+			UserID: userIDs[i],
+			Start:  start,
+			End:    end,
+			Reason: db.SRScheduled,
+			Tier:   e.tier,
+			Window: e.windows[i].name,
+		})
+	}
+	return shifts
+}
@@ -0,0 +1,134 @@
+package rotation
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rotabot-io/rotabot/lib/calendar"
+	"github.com/rotabot-io/rotabot/lib/db"
+)
+
+// workingShifts maps the shifts of a rota that only hands over on working days onto the handovers of its
+// cadence. A handover is on a working day when the day it happens on, in the rota's time zone, is neither a
+// weekend, when those are skipped, nor a holiday.
+//
+// Shift i starts with the i-th handover that falls on a working day. When the rota skips non-working days the
+// shift ends with the handover right after it and nobody is on duty until the next working day, otherwise the
+// shift goes on until then.
+//
+// Rotas that handed over already start walking from their last handover rather than from the first one, see seed.
+type workingShifts struct {
+	skipWeekends bool
+	calendar     calendar.Calendar
+	// lastYear is the last year the calendar shipped with rotabot lists holidays for, 0 when the rota uses none.
+	lastYear int
+	policy   db.NonWorkingDayPolicy
+	// first is the index of the shift handovers starts with.
+	first     int
+	handovers []int
+}
+
+// maxNonWorking is how many handovers in a row can fall on non-working days before we decide that the rota
+// never hands over on a working day.
+const maxNonWorking = 1000
+
+func (b boundaries) withWorkingDays(rota db.Rota) (boundaries, error) {
+	wd := rota.Metadata.WorkingDays
+	if wd == nil || !wd.SkipWeekends && wd.Calendar == "" && len(wd.Holidays) == 0 {
+		return b, nil
+	}
+	embedded := []calendar.Holiday{}
+	if wd.Calendar != "" {
+		var err error
+		if embedded, err = calendar.Embedded(wd.Calendar); err != nil {
+			return boundaries{}, ErrInvalidWorkingDays
+		}
+	}
+	b.working = &workingShifts{
+		skipWeekends: wd.SkipWeekends,
+		calendar:     calendar.New(wd.Holidays, embedded),
+		lastYear:     calendar.LastYear(embedded),
+		policy:       wd.Policy,
+	}
+	if _, ok := b.working.next(b, 0); !ok {
+		return boundaries{}, ErrInvalidWorkingDays
+	}
+	b.working.seed(b, rota.State)
+	return b, nil
+}
+
+// seed starts the walk from the shift the rota was last handed over to, as long as its handover still falls on
+// a working day at the same time.
+func (w *workingShifts) seed(b boundaries, state db.RotaState) {
+	if state.Shift <= 0 || state.Handover < state.Shift {
+		return
+	}
+	at := b.handover(state.Handover)
+	if !at.Equal(state.HandoverAt) || !w.isWorking(at) {
+		return
+	}
+	w.first, w.handovers = state.Shift, []int{state.Handover}
+}
+
+// restart walks from the first shift again, shifts before the last handover are rarely asked about.
+func (w *workingShifts) restart() {
+	w.first, w.handovers = 0, nil
+}
+
+// known reports whether the holidays of the day t falls on are known.
+func (w *workingShifts) known(t time.Time) bool {
+	return w.lastYear == 0 || t.Year() <= w.lastYear
+}
+
+func (w *workingShifts) isWorking(t time.Time) bool {
+	if w.skipWeekends && (t.Weekday() == time.Saturday || t.Weekday() == time.Sunday) {
+		return false
+	}
+	return !w.calendar.IsHoliday(t)
+}
+
+// next returns the index of the first handover at or after from that falls on a working day.
+func (w *workingShifts) next(b boundaries, from int) (int, bool) {
+	for i := from; i < from+maxNonWorking; i++ {
+		if w.isWorking(b.handover(i)) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// handover returns the index of the handover the shift with the given index starts with.
+func (w *workingShifts) handover(b boundaries, index int) int {
+	if index < w.first {
+		w.restart()
+	}
+	for len(w.handovers) <= index-w.first {
+		from := 0
+		if len(w.handovers) > 0 {
+			from = w.handovers[len(w.handovers)-1] + 1
+		}
+		next, ok := w.next(b, from)
+		if !ok {
+			// The cadence stopped lining up with working days, like a cron expression that only fires on
+			// weekends from some point on. Treating the handover as a working one keeps the rota going.
+			next = from
+		}
+		w.handovers = append(w.handovers, next)
+	}
+	return w.handovers[index-w.first]
+}
+
+// indexOf returns the index of the last shift that started with the given handover or before it.
+func (w *workingShifts) indexOf(b boundaries, handover int) (int, error) {
+	if len(w.handovers) > 0 && handover < w.handovers[0] {
+		w.restart()
+	}
+	for len(w.handovers) == 0 || w.handovers[len(w.handovers)-1] <= handover {
+		w.handover(b, w.first+len(w.handovers))
+	}
+	index := w.first + sort.SearchInts(w.handovers, handover+1) - 1
+	if index < 0 {
+		return 0, ErrNotStarted
+	}
+	return index, nil
+}
@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+)
+
+// DefaultGracePeriod is how long the rotas of a workspace that uninstalled rotabot are kept for, so reinstalling it
+// by mistake doesn't lose them.
+const DefaultGracePeriod = 30 * 24 * time.Hour
+
+// eventRetention is how long the events that were handled are remembered for, slack stops retrying an event a few
+// hours after it was first sent at the latest.
+const eventRetention = 24 * time.Hour
+
+// Purge deletes everything rotabot knows about the teams that were uninstalled longer than the grace period ago,
+// and forgets the events slack won't send again. Each team is deleted on its own so one failing doesn't hold back
+// the others.
+func (s *Scheduler) Purge(ctx context.Context) error {
+	l := zapctx.Logger(ctx)
+	var errs []error
+	if err := db.New(s.conn).DeleteEvents(ctx, db.Timestamptz(s.clock.Now().Add(-eventRetention))); err != nil {
+		l.Error("failed_to_delete_events", zap.Error(err))
+		sentry.CaptureException(err)
+		errs = append(errs, err)
+	}
+	uninstallations, err := db.New(s.conn).ListUninstallations(ctx, db.Timestamptz(s.clock.Now().Add(-s.gracePeriod)))
+	if err != nil {
+		l.Error("failed_to_list_uninstallations", zap.Error(err))
+		return errors.Join(append(errs, err)...)
+	}
+	for _, u := range uninstallations {
+		if err = s.purge(ctx, u); err != nil {
+			sentry.CaptureException(err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Scheduler) purge(ctx context.Context, u db.Uninstallation) error {
+	l := zapctx.Logger(ctx).With(zap.String("team_id", u.TeamID))
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		l.Error("failed_to_begin_transaction", zap.Error(err))
+		return err
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			l.Error("failed_to_rollback_transaction", zap.Error(err))
+		}
+	}(tx, ctx)
+
+	if err = db.New(tx).PurgeTeam(ctx, u.TeamID); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		l.Error("failed_to_commit_transaction", zap.Error(err))
+		return err
+	}
+	l.Info("team_purged", zap.Time("uninstalled_at", u.UninstalledAt.Time))
+	return nil
+}
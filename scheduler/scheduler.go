@@ -0,0 +1,321 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/metrics"
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+	"github.com/rotabot-io/rotabot/slack/announce"
+)
+
+const (
+	// DefaultInterval is the longest the scheduler sleeps for, this bounds how long it takes for a new rota
+	// to be picked up.
+	DefaultInterval = time.Minute
+
+	pageSize = 100
+)
+
+type Scheduler struct {
+	conn        *pgxpool.Pool
+	clock       rotation.Clock
+	interval    time.Duration
+	gracePeriod time.Duration
+}
+
+type Option func(s *Scheduler)
+
+// WithClock overrides the clock used to decide which shift is taking place.
+func WithClock(c rotation.Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = c
+	}
+}
+
+// WithInterval overrides the longest time the scheduler sleeps between runs.
+func WithInterval(d time.Duration) Option {
+	return func(s *Scheduler) {
+		s.interval = d
+	}
+}
+
+// WithGracePeriod overrides how long the rotas of a workspace that uninstalled rotabot are kept for.
+func WithGracePeriod(d time.Duration) Option {
+	return func(s *Scheduler) {
+		s.gracePeriod = d
+	}
+}
+
+func New(pool *pgxpool.Pool, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		conn:        pool,
+		clock:       rotation.SystemClock,
+		interval:    DefaultInterval,
+		gracePeriod: DefaultGracePeriod,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run hands over rotas to their next member until the context is cancelled.
+// It wakes up at the earliest shift boundary across all rotas, or after the configured interval when that comes
+// sooner. Handovers are worked out from the current time rather than from the time the scheduler expected to wake
+// up, so rotas that crossed a boundary while rotabot was down catch up on the first run after a restart.
+// Workspaces whose grace period is over are purged before each run.
+func (s *Scheduler) Run(ctx context.Context) error {
+	l := zapctx.Logger(ctx)
+	for {
+		_ = s.Purge(ctx)
+		wait := s.interval
+		if next := s.Tick(ctx); !next.IsZero() {
+			if untilNext := next.Sub(s.clock.Now()); untilNext < wait {
+				wait = untilNext
+			}
+		}
+
+		l.Debug("scheduler_sleeping", zap.Duration("wait", wait))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// Tick hands over every rota whose current shift has changed since the last time it was looked at.
+// It returns the earliest time at which one of the rotas will need to hand over again, or the zero time when no
+// rota can be scheduled.
+func (s *Scheduler) Tick(ctx context.Context) time.Time {
+	l := zapctx.Logger(ctx)
+	var next time.Time
+	cursor := ""
+	for {
+		rotas, err := db.New(s.conn).ListRotas(ctx, db.ListRotasParams{ID: cursor, Limit: pageSize})
+		if err != nil {
+			l.Error("failed_to_list_rotas", zap.Error(err))
+			return next
+		}
+		for _, rota := range rotas {
+			end, err := s.handover(ctx, rota.ID)
+			if err != nil {
+				sentry.CaptureException(err)
+				continue
+			}
+			if !end.IsZero() && (next.IsZero() || end.Before(next)) {
+				next = end
+			}
+		}
+		if len(rotas) < pageSize {
+			return next
+		}
+		cursor = rotas[len(rotas)-1].ID
+	}
+}
+
+// handover records the member that is currently on duty for the rota when it differs from the last one we know of.
+// The rota is locked while this happens so only one instance of rotabot announces each handover.
+// It returns the time at which the current shift ends.
+func (s *Scheduler) handover(ctx context.Context, rotaID string) (time.Time, error) {
+	ctx = zapctx.WithLogger(ctx, zapctx.Logger(ctx).With(zap.String("rota_id", rotaID)))
+	l := zapctx.Logger(ctx)
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		l.Error("failed_to_begin_transaction", zap.Error(err))
+		return time.Time{}, err
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			l.Error("failed_to_rollback_transaction", zap.Error(err))
+		}
+	}(tx, ctx)
+
+	repo := db.New(tx)
+	rota, err := repo.FindRotaByIDForUpdate(ctx, rotaID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		l.Debug("rota_locked_or_deleted")
+		return time.Time{}, nil
+	}
+	if err != nil {
+		l.Error("failed_to_find_rota", zap.Error(err))
+		return time.Time{}, err
+	}
+
+	if rota.Status != db.RTActive {
+		// Paused and ended rotas don't hand over until someone resumes them.
+		return time.Time{}, nil
+	}
+	if rota.EndsAt.Valid && !s.clock.Now().Before(rota.EndsAt.Time) {
+		return time.Time{}, s.end(ctx, tx, repo, rota)
+	}
+
+	engine, err := rotation.Load(ctx, repo, rota, s.clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	shift, err := engine.Current()
+	if errors.Is(err, rotation.ErrNotStarted) && rota.StartsAt.Valid {
+		// The rota was given a start date, nobody is on duty until then.
+		return rota.StartsAt.Time, nil
+	}
+	if errors.Is(err, rotation.ErrNonWorkingDay) {
+		// Nobody is on duty until the next working day, there's nothing to hand over before then.
+		upcoming, err := engine.Upcoming(1)
+		if err != nil {
+			l.Error("failed_to_compute_upcoming_shifts", zap.Error(err))
+			return time.Time{}, err
+		}
+		return rotation.Until(rota, upcoming[0].Start), nil
+	}
+	if err != nil {
+		// These are rotas without members or with a configuration we can't schedule, there's nothing to hand over.
+		l.Debug("unable_to_schedule_rota", zap.Error(err))
+		return time.Time{}, nil
+	}
+	shift.End = rotation.Until(rota, shift.End)
+	if rota.State.UserID == shift.UserID && rota.State.ShiftStart.Equal(shift.Start) {
+		return shift.End, nil
+	}
+
+	if err = s.recordShifts(ctx, repo, engine, rota, shift); err != nil {
+		return time.Time{}, err
+	}
+	err = repo.UpdateRotaState(ctx, db.UpdateRotaStateParams{
+		ID:    rota.ID,
+		State: engine.Handover(shift),
+	})
+	if err != nil {
+		l.Error("failed_to_update_rota_state", zap.Error(err))
+		return time.Time{}, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		l.Error("failed_to_commit_transaction", zap.Error(err))
+		return time.Time{}, err
+	}
+	l.Info("rota_handed_over",
+		zap.String("from", rota.State.UserID),
+		zap.String("to", shift.UserID),
+		zap.Time("shift_start", shift.Start),
+		zap.Time("shift_end", shift.End),
+	)
+	if !engine.HolidaysKnown(shift.End) {
+		// The calendar shipped with rotabot needs the holidays of the coming years, until it gets them the rota
+		// hands over on holidays.
+		l.Warn("holidays_unknown",
+			zap.String("calendar", rota.Metadata.WorkingDays.Calendar),
+			zap.Time("shift_end", shift.End),
+		)
+		sentry.CaptureMessage(fmt.Sprintf("the %s calendar doesn't know the holidays after %s", rota.Metadata.WorkingDays.Calendar, shift.End.Format(time.DateOnly)))
+	}
+
+	// The handover is committed before announcing it, if slack is having a bad day we'd rather miss an
+	// announcement than announce the same handover over and over again.
+	status := "success"
+	if err = announce.Handover(ctx, rota, shift, backups(ctx, engine, rota, shift)); err != nil {
+		l.Error("failed_to_announce_handover", zap.Error(err))
+		sentry.CaptureException(err)
+		status = "announce_failed"
+	}
+	metrics.HandoversTotal.With(prometheus.Labels{"status": status}).Inc()
+	return shift.End, nil
+}
+
+// end marks the rota as ended once its end date has passed and lets its channel know that nobody is on duty anymore.
+func (s *Scheduler) end(ctx context.Context, tx pgx.Tx, repo db.Repository, rota db.Rota) error {
+	l := zapctx.Logger(ctx)
+	err := repo.UpdateRotaStatus(ctx, db.UpdateRotaStatusParams{ID: rota.ID, Status: db.RTEnded})
+	if err != nil {
+		l.Error("failed_to_update_rota_status", zap.Error(err))
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		l.Error("failed_to_commit_transaction", zap.Error(err))
+		return err
+	}
+	l.Info("rota_ended", zap.Time("ends_at", rota.EndsAt.Time))
+
+	if err = announce.End(ctx, rota); err != nil {
+		l.Error("failed_to_announce_end", zap.Error(err))
+		sentry.CaptureException(err)
+	}
+	return nil
+}
+
+// backups returns who is on duty in each of the tiers after the primary one when the shift starts. Tiers
+// nobody can be on duty for, because the rota has fewer members than tiers, are left out.
+func backups(ctx context.Context, engine *rotation.Engine, rota db.Rota, shift rotation.Shift) []rotation.Shift {
+	l := zapctx.Logger(ctx)
+	shifts := []rotation.Shift{}
+	for tier := 1; tier < rotation.Tiers(rota); tier++ {
+		e, err := engine.Tier(tier)
+		if err != nil {
+			l.Debug("unable_to_schedule_tier", zap.Int("tier", tier), zap.Error(err))
+			continue
+		}
+		backup, err := e.At(shift.Start)
+		if err != nil {
+			l.Debug("unable_to_schedule_tier", zap.Int("tier", tier), zap.Error(err))
+			continue
+		}
+		shifts = append(shifts, backup)
+	}
+	return shifts
+}
+
+// recordShifts adds the current shift to the rota's history together with any shift that started while
+// rotabot was down, so the history doesn't have gaps.
+func (s *Scheduler) recordShifts(ctx context.Context, repo db.Repository, engine *rotation.Engine, rota db.Rota, current rotation.Shift) error {
+	l := zapctx.Logger(ctx)
+	shifts := []rotation.Shift{}
+	if !rota.State.ShiftEnd.IsZero() && rota.State.ShiftEnd.Before(current.Start) {
+		missed, err := engine.Between(rota.State.ShiftEnd, current.Start)
+		if err != nil {
+			l.Error("failed_to_compute_missed_shifts", zap.Error(err))
+			return err
+		}
+		l.Info("catching_up_on_missed_shifts", zap.Int("missed", len(missed)))
+		shifts = append(shifts, missed...)
+	}
+	if rota.State.ShiftStart.Equal(current.Start) {
+		// Someone else took over halfway through the shift, most likely because the members changed.
+		current.Start = s.clock.Now()
+	}
+	shifts = append(shifts, current)
+
+	// Whoever was on duty until now stops being so, the history never has two people on duty at once.
+	err := repo.EndShifts(ctx, db.EndShiftsParams{RotaID: rota.ID, EndsAt: db.Timestamptz(s.clock.Now())})
+	if err != nil {
+		l.Error("failed_to_end_shifts", zap.Error(err))
+		return err
+	}
+	for _, shift := range shifts {
+		_, err := repo.CreateShift(ctx, db.CreateShiftParams{
+			RotaID:   rota.ID,
+			UserID:   shift.UserID,
+			StartsAt: shift.Start,
+			EndsAt:   shift.End,
+			Reason:   shift.Reason,
+			Metadata: db.ShiftMetadata{},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,434 @@
+package scheduler
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"go.uber.org/mock/gomock"
+
+	"github.com/rotabot-io/rotabot/internal"
+	"github.com/rotabot-io/rotabot/lib/calendar"
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/rotas"
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+	"github.com/rotabot-io/rotabot/slack/slackclient/mock_slackclient"
+)
+
+var _ = Describe("Scheduler", func() {
+	var (
+		ctx    context.Context
+		sc     *mock_slackclient.MockSlackClient
+		conn   *pgxpool.Pool
+		rotaID string
+		now    time.Time
+		s      *Scheduler
+	)
+
+	channelID := "C123"
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		container, err := internal.RunContainer(ctx,
+			postgres.WithInitScripts(filepath.Join("..", "assets", "structure.sql")),
+			testcontainers.WithWaitStrategy(internal.DefaultWaitStrategy()),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		connString, err := container.ConnectionString(ctx, "sslmode=disable")
+		Expect(err).ToNot(HaveOccurred())
+
+		conn, err = pgxpool.New(ctx, connString)
+		Expect(err).ToNot(HaveOccurred())
+
+		DeferCleanup(func() {
+			_ = container.Terminate(ctx)
+			conn.Close()
+		})
+
+		rotaID, err = db.New(conn).CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+			TeamID:    "T123",
+			ChannelID: channelID,
+			Name:      "On Call",
+			Metadata: db.RotaMetadata{
+				Frequency:      db.RFDaily,
+				SchedulingType: db.RSCreated,
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		// Two days after the rota was created, so the third shift is taking place.
+		now = time.Now().UTC().Add(48 * time.Hour)
+		s = New(conn, WithClock(rotation.FixedClock(now)))
+	})
+
+	// Create a mock and assign it to the sc variable at the start of each test
+	slackclient.MockSlackClient(&ctx, &sc, nil)
+
+	addMembers := func(userIDs ...string) {
+		members := []db.Member{}
+		for _, userID := range userIDs {
+			members = append(members, db.Member{RotaID: rotaID, UserID: userID, Metadata: db.MemberMetadata{}})
+		}
+		Expect(db.New(conn).UpdateRotaMembers(ctx, members)).To(Succeed())
+	}
+
+	listShifts := func() []db.Shift {
+		shifts, err := db.New(conn).ListShiftsByRotaID(ctx, db.ListShiftsByRotaIDParams{
+			RotaID: rotaID,
+			Since:  db.Timestamptz(now.Add(-365 * 24 * time.Hour)),
+			Until:  db.Timestamptz(now.Add(365 * 24 * time.Hour)),
+		})
+		Expect(err).ToNot(HaveOccurred())
+		return shifts
+	}
+
+	Describe("Tick", func() {
+		It("does nothing when the rota has no members", func() {
+			next := s.Tick(ctx)
+			Expect(next.IsZero()).To(BeTrue())
+
+			rota, err := db.New(conn).FindRotaByID(ctx, rotaID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.State).To(Equal(db.RotaState{}))
+		})
+
+		It("hands over to the member currently on duty and announces it", func() {
+			addMembers("U1")
+			sc.EXPECT().PostMessageContext(gomock.Any(), channelID, gomock.Any()).Return("", "", nil).Times(1)
+
+			next := s.Tick(ctx)
+			Expect(next.After(now)).To(BeTrue())
+			Expect(next.Sub(now)).To(BeNumerically("<=", 24*time.Hour))
+
+			rota, err := db.New(conn).FindRotaByID(ctx, rotaID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.State.UserID).To(Equal("U1"))
+			Expect(rota.State.ShiftEnd).To(BeTemporally("==", next))
+
+			shifts := listShifts()
+			Expect(shifts).To(HaveLen(1))
+			Expect(shifts[0].UserID).To(Equal("U1"))
+			Expect(shifts[0].Reason).To(Equal(db.SRScheduled))
+			Expect(shifts[0].EndsAt.Time).To(BeTemporally("==", next))
+		})
+
+		It("announces who is on duty in every tier but only records the primary one", func() {
+			addMembers("U1", "U2", "U3")
+			_, err := db.New(conn).CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				RotaID:    rotaID,
+				TeamID:    "T123",
+				ChannelID: channelID,
+				Name:      "On Call",
+				Metadata: db.RotaMetadata{
+					Frequency:      db.RFDaily,
+					SchedulingType: db.RSCreated,
+					Tiers:          2,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			sc.EXPECT().PostMessageContext(gomock.Any(), channelID, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ string, options ...slack.MsgOption) (string, string, error) {
+					_, values, err := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(values.Get("text")).To(HavePrefix(":rotating_light: <@U3> is now on duty for *On Call*"))
+					Expect(values.Get("text")).To(HaveSuffix("\n:busts_in_silhouette: <@U1> is on duty as *Secondary*"))
+					return "", "", nil
+				},
+			).Times(1)
+
+			s.Tick(ctx)
+
+			rota, err := db.New(conn).FindRotaByID(ctx, rotaID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.State.UserID).To(Equal("U3"))
+
+			shifts := listShifts()
+			Expect(shifts).To(HaveLen(1))
+			Expect(shifts[0].UserID).To(Equal("U3"))
+		})
+
+		It("does not hand over twice within the same shift", func() {
+			addMembers("U1", "U2")
+			sc.EXPECT().PostMessageContext(gomock.Any(), channelID, gomock.Any()).Return("", "", nil).Times(1)
+
+			first := s.Tick(ctx)
+			second := s.Tick(ctx)
+			Expect(second).To(BeTemporally("==", first))
+			Expect(listShifts()).To(HaveLen(1))
+		})
+
+		It("waits for the next working day when nobody is on duty", func() {
+			addMembers("U1")
+			_, err := db.New(conn).CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				RotaID: rotaID,
+				Name:   "On Call",
+				Metadata: db.RotaMetadata{
+					Frequency:      db.RFDaily,
+					SchedulingType: db.RSCreated,
+					WorkingDays: &db.WorkingDays{
+						Holidays: []calendar.Holiday{{Date: now.Format("2006-01-02")}},
+						Policy:   db.NWSkip,
+					},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			next := s.Tick(ctx)
+			Expect(next.After(now)).To(BeTrue())
+			Expect(next.Sub(now)).To(BeNumerically("<=", 24*time.Hour))
+			Expect(listShifts()).To(BeEmpty())
+		})
+
+		It("does not hand over while the rota is paused", func() {
+			addMembers("U1")
+			err := db.New(conn).UpdateRotaStatus(ctx, db.UpdateRotaStatusParams{ID: rotaID, Status: db.RTPaused})
+			Expect(err).ToNot(HaveOccurred())
+
+			next := s.Tick(ctx)
+			Expect(next.IsZero()).To(BeTrue())
+			Expect(listShifts()).To(BeEmpty())
+		})
+
+		It("does not hand over once the rota is archived", func() {
+			addMembers("U1")
+			rota, err := db.New(conn).FindRotaByID(ctx, rotaID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rotas.ArchiveRota(ctx, db.New(conn), rota)).To(Succeed())
+
+			Expect(s.Tick(ctx).IsZero()).To(BeTrue())
+			Expect(listShifts()).To(BeEmpty())
+		})
+
+		It("waits for the rota to start", func() {
+			addMembers("U1")
+			startsAt := now.Add(72 * time.Hour).Truncate(time.Second)
+			_, err := db.New(conn).CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				RotaID: rotaID,
+				Name:   "On Call",
+				Metadata: db.RotaMetadata{
+					Frequency:      db.RFDaily,
+					SchedulingType: db.RSCreated,
+				},
+				StartsAt: startsAt,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			next := s.Tick(ctx)
+			Expect(next).To(BeTemporally("==", startsAt))
+			Expect(listShifts()).To(BeEmpty())
+		})
+
+		It("cuts the last shift short when the rota ends", func() {
+			addMembers("U1")
+			endsAt := now.Add(time.Hour).Truncate(time.Second)
+			_, err := db.New(conn).CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				RotaID: rotaID,
+				Name:   "On Call",
+				Metadata: db.RotaMetadata{
+					Frequency:      db.RFDaily,
+					SchedulingType: db.RSCreated,
+				},
+				EndsAt: endsAt,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			sc.EXPECT().PostMessageContext(gomock.Any(), channelID, gomock.Any()).Return("", "", nil).Times(1)
+
+			next := s.Tick(ctx)
+			Expect(next).To(BeTemporally("==", endsAt))
+
+			shifts := listShifts()
+			Expect(shifts).To(HaveLen(1))
+			Expect(shifts[0].EndsAt.Time).To(BeTemporally("==", endsAt))
+		})
+
+		It("ends the rota once its end date has passed and announces it", func() {
+			addMembers("U1")
+			_, err := db.New(conn).CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				RotaID: rotaID,
+				Name:   "On Call",
+				Metadata: db.RotaMetadata{
+					Frequency:      db.RFDaily,
+					SchedulingType: db.RSCreated,
+				},
+				EndsAt: now.Add(-time.Hour),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			sc.EXPECT().PostMessageContext(gomock.Any(), channelID, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ string, options ...slack.MsgOption) (string, string, error) {
+					_, values, err := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(values.Get("text")).To(Equal(":checkered_flag: *On Call* has ended, nobody is on duty anymore"))
+					return "", "", nil
+				},
+			).Times(1)
+
+			next := s.Tick(ctx)
+			Expect(next.IsZero()).To(BeTrue())
+			Expect(listShifts()).To(BeEmpty())
+
+			rota, err := db.New(conn).FindRotaByID(ctx, rotaID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.Status).To(Equal(db.RTEnded))
+
+			// Ended rotas are left alone from then on.
+			Expect(s.Tick(ctx).IsZero()).To(BeTrue())
+		})
+
+		It("hands over to whoever covers the rota and back once they're done", func() {
+			addMembers("U1")
+			_, err := db.New(conn).CreateOverride(ctx, db.CreateOverrideParams{
+				RotaID:   rotaID,
+				UserID:   "U2",
+				StartsAt: now.Add(-time.Hour),
+				EndsAt:   now.Add(time.Hour),
+				Metadata: db.OverrideMetadata{CreatedBy: "U1"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			sc.EXPECT().PostMessageContext(gomock.Any(), channelID, gomock.Any()).Return("", "", nil).Times(2)
+
+			next := s.Tick(ctx)
+			Expect(next).To(BeTemporally("~", now.Add(time.Hour), time.Millisecond))
+
+			rota, err := db.New(conn).FindRotaByID(ctx, rotaID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.State.UserID).To(Equal("U2"))
+
+			s = New(conn, WithClock(rotation.FixedClock(next)))
+			s.Tick(ctx)
+
+			shifts := listShifts()
+			Expect(shifts).To(HaveLen(2))
+			Expect(shifts[0].UserID).To(Equal("U2"))
+			Expect(shifts[0].Reason).To(Equal(db.SROverride))
+			Expect(shifts[1].UserID).To(Equal("U1"))
+			Expect(shifts[1].Reason).To(Equal(db.SRScheduled))
+			Expect(shifts[1].StartsAt.Time).To(BeTemporally("==", next))
+		})
+
+		It("catches up on the handovers it missed", func() {
+			addMembers("U1", "U2")
+			err := db.New(conn).UpdateRotaState(ctx, db.UpdateRotaStateParams{
+				ID: rotaID,
+				State: db.RotaState{
+					UserID:     "U2",
+					ShiftStart: now.Add(-30 * 24 * time.Hour),
+					ShiftEnd:   now.Add(-29 * 24 * time.Hour),
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			sc.EXPECT().PostMessageContext(gomock.Any(), channelID, gomock.Any()).Return("", "", nil).Times(1)
+
+			s.Tick(ctx)
+
+			rota, err := db.New(conn).FindRotaByID(ctx, rotaID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.State.UserID).To(Equal("U1"))
+			Expect(rota.State.ShiftEnd.After(now)).To(BeTrue())
+
+			// The two shifts that happened while the scheduler wasn't running are recorded as well as the current one.
+			shifts := listShifts()
+			Expect(shifts).To(HaveLen(3))
+			Expect(shifts[0].UserID).To(Equal("U1"))
+			Expect(shifts[1].UserID).To(Equal("U2"))
+			Expect(shifts[2].UserID).To(Equal("U1"))
+			Expect(shifts[1].EndsAt).To(Equal(shifts[2].StartsAt))
+		})
+
+		It("ends the shift of whoever was on duty when someone takes over halfway through it", func() {
+			addMembers("U1", "U2", "U3")
+			sc.EXPECT().PostMessageContext(gomock.Any(), channelID, gomock.Any()).Return("", "", nil).Times(2)
+			s.Tick(ctx)
+			rota, err := db.New(conn).FindRotaByID(ctx, rotaID)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(db.New(conn).RemoveMember(ctx, db.RemoveMemberParams{RotaID: rotaID, UserID: rota.State.UserID})).To(Succeed())
+			later := now.Add(time.Hour)
+			New(conn, WithClock(rotation.FixedClock(later))).Tick(ctx)
+
+			shifts := listShifts()
+			Expect(shifts).To(HaveLen(2))
+			Expect(shifts[0].UserID).To(Equal(rota.State.UserID))
+			Expect(shifts[0].EndsAt.Time).To(BeTemporally("==", later))
+			Expect(shifts[1].UserID).ToNot(Equal(rota.State.UserID))
+			Expect(shifts[1].StartsAt.Time).To(BeTemporally("==", later))
+			for i := 1; i < len(shifts); i++ {
+				Expect(shifts[i-1].EndsAt.Time).To(BeTemporally("<=", shifts[i].StartsAt.Time))
+			}
+		})
+
+		It("ends the shift of whoever is skipped and doesn't announce the handover a second time", func() {
+			addMembers("U1", "U2", "U3")
+			sc.EXPECT().PostMessageContext(gomock.Any(), channelID, gomock.Any()).Return("", "", nil).Times(1)
+			s.Tick(ctx)
+
+			rota, err := db.New(conn).FindRotaByID(ctx, rotaID)
+			Expect(err).ToNot(HaveOccurred())
+			skipped, err := rotas.SkipShift(ctx, db.New(conn), rota, rotas.SkipParams{CreatedBy: "U9", Clock: rotation.FixedClock(now)})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(skipped.Instead.UserID).To(Equal("U1"))
+
+			s.Tick(ctx)
+			shifts := listShifts()
+			Expect(shifts).To(HaveLen(3))
+			Expect(shifts).To(ContainElement(SatisfyAll(
+				HaveField("UserID", "U3"),
+				HaveField("Reason", db.SRScheduled),
+				HaveField("EndsAt.Time", BeTemporally("==", now)),
+			)))
+		})
+	})
+
+	Describe("Uninstall", func() {
+		BeforeEach(func() {
+			addMembers("U1")
+			Expect(rotas.Uninstall(ctx, db.New(conn), "T123", db.URAppUninstalled)).To(Succeed())
+		})
+
+		It("stops handing over the rotas of the team", func() {
+			Expect(s.Tick(ctx).IsZero()).To(BeTrue())
+		})
+
+		It("keeps the rotas of the team until the grace period is over", func() {
+			Expect(s.Purge(ctx)).To(Succeed())
+			_, err := db.New(conn).FindRotaByID(ctx, rotaID)
+			Expect(err).ToNot(HaveOccurred())
+
+			s = New(conn, WithClock(rotation.FixedClock(now)), WithGracePeriod(24*time.Hour))
+			Expect(s.Purge(ctx)).To(Succeed())
+			_, err = db.New(conn).FindRotaByID(ctx, rotaID)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Purge", func() {
+		It("forgets the events slack won't send again", func() {
+			params := db.CreateEventParams{ID: "Ev123", TeamID: "T123", Type: "app_mention"}
+			Expect(db.New(conn).CreateEvent(ctx, params)).To(Succeed())
+
+			Expect(s.Purge(ctx)).To(Succeed())
+			Expect(db.New(conn).CreateEvent(ctx, params)).To(Succeed())
+		})
+	})
+
+	Describe("Run", func() {
+		It("stops when the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(ctx)
+			errc := make(chan error, 1)
+			go func() {
+				errc <- s.Run(ctx)
+			}()
+
+			cancel()
+			Eventually(errc).Should(Receive(BeNil()))
+		})
+	})
+})
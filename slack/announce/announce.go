@@ -0,0 +1,140 @@
+// Package announce writes the messages rotabot posts about rotas, so they read the same whether they're posted by
+// the scheduler, in answer to a command or from a modal.
+//
+// Dates use slack's date formatting so they're displayed in the time zone of whoever is reading the message, the
+// fallback text shown by clients that can't format dates is in UTC.
+// See https://api.slack.com/reference/surfaces/formatting#date-formatting
+package announce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/rotas"
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+)
+
+// Handover lets the rota's channel know who is now on duty, together with whoever backs them up in the other
+// tiers of the rota. Rotas that follow the sun announce each window as it starts.
+func Handover(ctx context.Context, rota db.Rota, shift rotation.Shift, backups []rotation.Shift) error {
+	client, err := slackclient.ClientFor(ctx, rota.TeamID)
+	if err != nil {
+		return err
+	}
+
+	format := ":rotating_light: <@%s> is now on duty for %s until %s"
+	if shift.Reason == db.SROverride {
+		format = ":arrows_counterclockwise: <@%s> is covering %s until %s"
+	}
+	text := fmt.Sprintf(format, shift.UserID, ShiftName(rota, shift), FormatTime(shift.End))
+	for _, backup := range backups {
+		text += fmt.Sprintf("\n:busts_in_silhouette: <@%s> is on duty as *%s*", backup.UserID, rotation.TierName(backup.Tier))
+	}
+	_, _, err = client.PostMessageContext(ctx, rota.ChannelID, slack.MsgOptionText(text, false))
+	return err
+}
+
+// End lets the rota's channel know that the rota reached its end date and nobody is on duty anymore.
+func End(ctx context.Context, rota db.Rota) error {
+	client, err := slackclient.ClientFor(ctx, rota.TeamID)
+	if err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf(":checkered_flag: *%s* has ended, nobody is on duty anymore", rota.Name)
+	_, _, err = client.PostMessageContext(ctx, rota.ChannelID, slack.MsgOptionText(text, false))
+	return err
+}
+
+// Skipped lets the rota's channel know that a member was skipped, by whom and who is on duty instead.
+func Skipped(ctx context.Context, rota db.Rota, skipped rotas.Skipped) error {
+	client, err := slackclient.ClientFor(ctx, rota.TeamID)
+	if err != nil {
+		return err
+	}
+	_, _, err = client.PostMessageContext(ctx, rota.ChannelID, slack.MsgOptionText(SkippedText(rota, skipped), false))
+	return err
+}
+
+// SkippedText is the message that lets the rota's channel know about the skip.
+func SkippedText(rota db.Rota, s rotas.Skipped) string {
+	text := fmt.Sprintf(
+		":fast_forward: <@%s> skipped <@%s> on %s, <@%s> is now on duty until %s",
+		s.CreatedBy,
+		s.Shift.UserID,
+		ShiftName(rota, s.Shift),
+		s.Instead.UserID,
+		FormatTime(s.Instead.End),
+	)
+	if !s.Now {
+		text = fmt.Sprintf(
+			":fast_forward: <@%s> skipped the shift of <@%s> on %s starting %s, <@%s> is on duty instead",
+			s.CreatedBy,
+			s.Shift.UserID,
+			ShiftName(rota, s.Shift),
+			FormatTime(s.Shift.Start),
+			s.Instead.UserID,
+		)
+	}
+	if s.Reason != "" {
+		text += "\n>" + s.Reason
+	}
+	return text
+}
+
+// ErrNoOwner is returned when there's nobody to tell about a rota whose channel is gone, rotas created before
+// their owner was recorded have none.
+var ErrNoOwner = errors.New("rota has no owner")
+
+// Removal lets the rota's channel know that a member was taken out of the rota and why. When the channel
+// can't be posted to, e.g. because rotabot was removed from it, the owner of the rota is told instead.
+func Removal(ctx context.Context, rota db.Rota, userID string, reason rotas.RemovalReason) error {
+	client, err := slackclient.ClientFor(ctx, rota.TeamID)
+	if err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf(":wave: <@%s> was removed from *%s* because %s", userID, rota.Name, reason)
+	_, _, err = client.PostMessageContext(ctx, rota.ChannelID, slack.MsgOptionText(text, false))
+	if err == nil || rota.Metadata.CreatedBy == "" {
+		return err
+	}
+	_, _, err = client.PostMessageContext(ctx, rota.Metadata.CreatedBy, slack.MsgOptionText(text, false))
+	return err
+}
+
+// Archive lets the owner of the rota know that it was archived, its channel can't be posted to anymore.
+func Archive(ctx context.Context, rota db.Rota, reason rotas.ArchiveReason) error {
+	if rota.Metadata.CreatedBy == "" {
+		return ErrNoOwner
+	}
+	client, err := slackclient.ClientFor(ctx, rota.TeamID)
+	if err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf(":file_cabinet: *%s* was archived because %s, nobody is on duty anymore", rota.Name, reason)
+	_, _, err = client.PostMessageContext(ctx, rota.Metadata.CreatedBy, slack.MsgOptionText(text, false))
+	return err
+}
+
+// ShiftName is how the rota a shift belongs to is called in messages, shifts of rotas that follow the sun are
+// named after their window too.
+func ShiftName(rota db.Rota, shift rotation.Shift) string {
+	name := "*" + rota.Name + "*"
+	if shift.Window != "" {
+		name += " (" + shift.Window + ")"
+	}
+	return name
+}
+
+// FormatTime uses slack's date formatting so times are displayed in the time zone of whoever is reading them.
+func FormatTime(t time.Time) string {
+	return fmt.Sprintf("<!date^%d^{date_short_pretty} at {time}|%s>", t.Unix(), t.UTC().Format("Mon 2 Jan 15:04 MST"))
+}
@@ -0,0 +1,13 @@
+package announce
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAnnounce(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Announce Suite")
+}
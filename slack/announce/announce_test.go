@@ -0,0 +1,119 @@
+package announce
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+	"go.uber.org/mock/gomock"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/rotas"
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+	"github.com/rotabot-io/rotabot/slack/slackclient/mock_slackclient"
+)
+
+var _ = Describe("Announce", func() {
+	var (
+		ctx context.Context
+		sc  *mock_slackclient.MockSlackClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	slackclient.MockSlackClient(&ctx, &sc, nil)
+
+	rota := db.Rota{ID: "RT123", TeamID: "T123", ChannelID: "C123", Name: "On Call"}
+	london := time.FixedZone("BST", 60*60)
+
+	text := func(options []slack.MsgOption) string {
+		_, values, err := slack.UnsafeApplyMsgOptions("", "C123", "", options...)
+		Expect(err).ToNot(HaveOccurred())
+		return values.Get("text")
+	}
+
+	Describe("Handover", func() {
+		It("announces who is on duty and who backs them up", func() {
+			shift := rotation.Shift{UserID: "U1", End: time.Date(2023, time.July, 3, 9, 0, 0, 0, london), Window: "EMEA"}
+			backups := []rotation.Shift{{UserID: "U2", Tier: 1}}
+			sc.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ string, options ...slack.MsgOption) (string, string, error) {
+					Expect(text(options)).To(Equal(
+						":rotating_light: <@U1> is now on duty for *On Call* (EMEA) until <!date^1688371200^{date_short_pretty} at {time}|Mon 3 Jul 08:00 UTC>\n" +
+							":busts_in_silhouette: <@U2> is on duty as *Secondary*",
+					))
+					return "", "", nil
+				},
+			)
+
+			Expect(Handover(ctx, rota, shift, backups)).To(Succeed())
+		})
+	})
+
+	Describe("SkippedText", func() {
+		It("tells who is on duty now when whoever was on duty is skipped", func() {
+			skipped := rotas.Skipped{
+				Shift:     rotation.Shift{UserID: "U3"},
+				Instead:   rotation.Shift{UserID: "U1", End: time.Date(2023, time.July, 3, 9, 0, 0, 0, london)},
+				Now:       true,
+				CreatedBy: "U9",
+				Reason:    "Out sick",
+			}
+			Expect(SkippedText(rota, skipped)).To(Equal(
+				":fast_forward: <@U9> skipped <@U3> on *On Call*, <@U1> is now on duty until <!date^1688371200^{date_short_pretty} at {time}|Mon 3 Jul 08:00 UTC>\n>Out sick",
+			))
+		})
+
+		It("tells when the skipped shift starts when it's an upcoming one", func() {
+			skipped := rotas.Skipped{
+				Shift:     rotation.Shift{UserID: "U1", Start: time.Date(2023, time.July, 3, 9, 0, 0, 0, london)},
+				Instead:   rotation.Shift{UserID: "U2"},
+				CreatedBy: "U1",
+			}
+			Expect(SkippedText(rota, skipped)).To(Equal(
+				":fast_forward: <@U1> skipped the shift of <@U1> on *On Call* starting <!date^1688371200^{date_short_pretty} at {time}|Mon 3 Jul 08:00 UTC>, <@U2> is on duty instead",
+			))
+		})
+	})
+
+	Describe("Archive", func() {
+		It("tells the owner of the rota", func() {
+			Expect(Archive(ctx, rota, rotas.ARChannelDeleted)).To(MatchError(ErrNoOwner))
+
+			owned := rota
+			owned.Metadata.CreatedBy = "U9"
+			sc.EXPECT().PostMessageContext(gomock.Any(), "U9", gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ string, options ...slack.MsgOption) (string, string, error) {
+					Expect(text(options)).To(Equal(":file_cabinet: *On Call* was archived because its channel was deleted, nobody is on duty anymore"))
+					return "", "", nil
+				},
+			)
+			Expect(Archive(ctx, owned, rotas.ARChannelDeleted)).To(Succeed())
+		})
+	})
+
+	Describe("Removal", func() {
+		It("tells the owner of the rota when the channel can't be posted to", func() {
+			owned := rota
+			owned.Metadata.CreatedBy = "U9"
+			gomock.InOrder(
+				sc.EXPECT().PostMessageContext(gomock.Any(), "C123", gomock.Any()).Return("", "", slack.SlackErrorResponse{Err: "not_in_channel"}),
+				sc.EXPECT().PostMessageContext(gomock.Any(), "U9", gomock.Any()).Return("", "", nil),
+			)
+			Expect(Removal(ctx, owned, "U1", rotas.RRLeftChannel)).To(Succeed())
+		})
+	})
+
+	Describe("FormatTime", func() {
+		It("falls back to UTC whatever the time zone of the time", func() {
+			t := time.Date(2023, time.July, 3, 9, 0, 0, 0, london)
+			Expect(FormatTime(t)).To(Equal(FormatTime(t.UTC())))
+			Expect(FormatTime(t)).To(HaveSuffix("|Mon 3 Jul 08:00 UTC>"))
+		})
+	})
+})
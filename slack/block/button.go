@@ -5,6 +5,9 @@ import "github.com/slack-go/slack"
 type Button struct {
 	ActionID string
 	Text     string
+	// Value is sent back together with the action when the button is clicked.
+	Value string
+	Style slack.Style
 }
 
 func NewButton(b Button) *slack.ButtonBlockElement {
@@ -12,5 +15,7 @@ func NewButton(b Button) *slack.ButtonBlockElement {
 		Type:     slack.METButton,
 		ActionID: b.ActionID,
 		Text:     NewDefaultText(b.Text),
+		Value:    b.Value,
+		Style:    b.Style,
 	}
 }
@@ -16,5 +16,18 @@ var _ = Describe("Button", func() {
 		Expect(b.Type).To(Equal(slack.METButton))
 		Expect(b.Text.Text).To(Equal("Click me!"))
 		Expect(b.ActionID).To(Equal("awesome_button"))
+		Expect(b.Value).To(BeEmpty())
+	})
+
+	It("Generates Slack Button with a value and a style", func() {
+		b := NewButton(Button{
+			ActionID: "awesome_button",
+			Text:     "Accept",
+			Value:    "SW123",
+			Style:    slack.StylePrimary,
+		})
+
+		Expect(b.Value).To(Equal("SW123"))
+		Expect(b.Style).To(Equal(slack.StylePrimary))
 	})
 })
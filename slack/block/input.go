@@ -1,25 +1,38 @@
 package block
 
-import "github.com/slack-go/slack"
+import (
+	"time"
+
+	"github.com/slack-go/slack"
+)
 
 type TextInput struct {
 	BlockID string
 	Label   string
 	Hint    string
 	Value   string
+	// DispatchOnEnter sends a block action when the user presses enter within the input.
+	DispatchOnEnter bool
+	Optional        bool
 }
 
 func NewTextInput(input TextInput) *slack.InputBlock {
+	element := &slack.PlainTextInputBlockElement{
+		Type:         slack.METPlainTextInput,
+		ActionID:     input.BlockID,
+		Placeholder:  NewDefaultText(input.Hint),
+		InitialValue: input.Value,
+	}
+	if input.DispatchOnEnter {
+		element.DispatchActionConfig = &slack.DispatchActionConfig{TriggerActionsOn: []string{"on_enter_pressed"}}
+	}
 	return &slack.InputBlock{
-		Type:    slack.MBTInput,
-		BlockID: input.BlockID,
-		Element: &slack.PlainTextInputBlockElement{
-			Type:         slack.METPlainTextInput,
-			ActionID:     input.BlockID,
-			Placeholder:  NewDefaultText(input.Hint),
-			InitialValue: input.Value,
-		},
-		Label: NewDefaultText(input.Label),
+		Type:           slack.MBTInput,
+		BlockID:        input.BlockID,
+		Element:        element,
+		Label:          NewDefaultText(input.Label),
+		DispatchAction: input.DispatchOnEnter,
+		Optional:       input.Optional,
 	}
 }
 
@@ -32,6 +45,8 @@ type StaticSelect struct {
 
 type StaticSelectOption struct {
 	Text string
+	// Value defaults to the text of the option.
+	Value string
 }
 
 func NewStaticSelect(input StaticSelect) *slack.SectionBlock {
@@ -56,9 +71,13 @@ func NewStaticSelect(input StaticSelect) *slack.SectionBlock {
 }
 
 func staticSelectOption(option StaticSelectOption) *slack.OptionBlockObject {
+	value := option.Value
+	if value == "" {
+		value = option.Text
+	}
 	return &slack.OptionBlockObject{
 		Text:  NewDefaultText(option.Text),
-		Value: option.Text,
+		Value: value,
 	}
 }
 
@@ -82,3 +101,130 @@ func NewUserSelect(input UserSelect) *slack.SectionBlock {
 		),
 	}
 }
+
+type NumberInput struct {
+	BlockID  string
+	Label    string
+	Hint     string
+	Value    string
+	MinValue string
+}
+
+func NewNumberInput(input NumberInput) *slack.InputBlock {
+	return &slack.InputBlock{
+		Type:    slack.MBTInput,
+		BlockID: input.BlockID,
+		Element: &slack.NumberInputBlockElement{
+			Type:         slack.METNumber,
+			ActionID:     input.BlockID,
+			Placeholder:  NewDefaultText(input.Hint),
+			InitialValue: input.Value,
+			MinValue:     input.MinValue,
+		},
+		Label: NewDefaultText(input.Label),
+	}
+}
+
+type TimePicker struct {
+	BlockID string
+	Label   string
+	Time    string
+}
+
+func NewTimePicker(input TimePicker) *slack.InputBlock {
+	return &slack.InputBlock{
+		Type:    slack.MBTInput,
+		BlockID: input.BlockID,
+		Element: &slack.TimePickerBlockElement{
+			Type:        slack.METTimepicker,
+			ActionID:    input.BlockID,
+			InitialTime: input.Time,
+		},
+		Label: NewDefaultText(input.Label),
+	}
+}
+
+type DateTimePicker struct {
+	BlockID string
+	Label   string
+	Time    time.Time
+}
+
+func NewDateTimePicker(input DateTimePicker) *slack.InputBlock {
+	element := &slack.DateTimePickerBlockElement{
+		Type:     slack.METDatetimepicker,
+		ActionID: input.BlockID,
+	}
+	if !input.Time.IsZero() {
+		element.InitialDateTime = input.Time.Unix()
+	}
+	return &slack.InputBlock{
+		Type:    slack.MBTInput,
+		BlockID: input.BlockID,
+		Element: element,
+		Label:   NewDefaultText(input.Label),
+	}
+}
+
+type DatePicker struct {
+	BlockID string
+	Label   string
+	// Date is formatted as YYYY-MM-DD, the picker is left empty without one.
+	Date     string
+	Optional bool
+}
+
+func NewDatePicker(input DatePicker) *slack.InputBlock {
+	return &slack.InputBlock{
+		Type:    slack.MBTInput,
+		BlockID: input.BlockID,
+		Element: &slack.DatePickerBlockElement{
+			Type:        slack.METDatepicker,
+			ActionID:    input.BlockID,
+			InitialDate: input.Date,
+		},
+		Label:    NewDefaultText(input.Label),
+		Optional: input.Optional,
+	}
+}
+
+type UserInput struct {
+	BlockID string
+	Label   string
+	UserID  string
+	// DispatchAction sends a block action as soon as a user is picked.
+	DispatchAction bool
+}
+
+func NewUserInput(input UserInput) *slack.InputBlock {
+	return &slack.InputBlock{
+		Type:    slack.MBTInput,
+		BlockID: input.BlockID,
+		Element: &slack.SelectBlockElement{
+			Type:        slack.OptTypeUser,
+			ActionID:    input.BlockID,
+			InitialUser: input.UserID,
+		},
+		Label:          NewDefaultText(input.Label),
+		DispatchAction: input.DispatchAction,
+	}
+}
+
+type MultiUserInput struct {
+	BlockID string
+	Label   string
+	UserIDs []string
+}
+
+func NewMultiUserInput(input MultiUserInput) *slack.InputBlock {
+	return &slack.InputBlock{
+		Type:    slack.MBTInput,
+		BlockID: input.BlockID,
+		Element: &slack.MultiSelectBlockElement{
+			Type:         slack.MultiOptTypeUser,
+			ActionID:     input.BlockID,
+			InitialUsers: input.UserIDs,
+		},
+		Label: NewDefaultText(input.Label),
+	}
+}
@@ -1,6 +1,8 @@
 package block
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/slack-go/slack"
@@ -27,6 +29,28 @@ var _ = Describe("Input", func() {
 			Expect(accessory.ActionID).To(Equal("blockId"))
 			Expect(accessory.Placeholder.Type).To(Equal(slack.PlainTextType))
 			Expect(accessory.Placeholder.Text).To(Equal("hint"))
+			Expect(accessory.DispatchActionConfig).To(BeNil())
+			Expect(i.DispatchAction).To(BeFalse())
+			Expect(i.Optional).To(BeFalse())
+		})
+
+		It("Generates text input that can be left empty", func() {
+			i := NewTextInput(TextInput{BlockID: "blockId", Label: "label", Optional: true})
+			Expect(i.Optional).To(BeTrue())
+		})
+
+		It("Generates text input that dispatches an action on enter", func() {
+			i := NewTextInput(TextInput{
+				BlockID:         "blockId",
+				Label:           "label",
+				Hint:            "hint",
+				DispatchOnEnter: true,
+			})
+
+			Expect(i.DispatchAction).To(BeTrue())
+			accessory, ok := i.Element.(*slack.PlainTextInputBlockElement)
+			Expect(ok).To(BeTrue())
+			Expect(accessory.DispatchActionConfig.TriggerActionsOn).To(Equal([]string{"on_enter_pressed"}))
 		})
 	})
 
@@ -84,6 +108,19 @@ var _ = Describe("Input", func() {
 			Expect(s.Accessory.SelectElement.Options[0].Text.Text).To(Equal("option1"))
 			Expect(s.Accessory.SelectElement.Options[0].Value).To(Equal("option1"))
 		})
+
+		It("generates static select with options whose value differs from their text", func() {
+			s := NewStaticSelect(StaticSelect{
+				BlockID:       "blockId",
+				Label:         "label",
+				InitialOption: StaticSelectOption{Text: "Monday", Value: "1"},
+				Options:       []StaticSelectOption{{Text: "Monday", Value: "1"}},
+			})
+
+			Expect(s.Accessory.SelectElement.InitialOption.Value).To(Equal("1"))
+			Expect(s.Accessory.SelectElement.Options[0].Text.Text).To(Equal("Monday"))
+			Expect(s.Accessory.SelectElement.Options[0].Value).To(Equal("1"))
+		})
 	})
 
 	Describe("NewUserSelect", func() {
@@ -129,4 +166,151 @@ var _ = Describe("Input", func() {
 			Expect(s.Accessory.MultiSelectElement.InitialUsers[1]).To(Equal("option2"))
 		})
 	})
+
+	Describe("NewNumberInput", func() {
+		It("generates a number input that doesn't allow decimals", func() {
+			i := NewNumberInput(NumberInput{
+				BlockID:  "blockId",
+				Label:    "label",
+				Hint:     "hint",
+				Value:    "2",
+				MinValue: "1",
+			})
+
+			Expect(i.Type).To(Equal(slack.MBTInput))
+			Expect(i.BlockID).To(Equal("blockId"))
+			Expect(i.Label.Text).To(Equal("label"))
+			Expect(i.Element.ElementType()).To(Equal(slack.METNumber))
+
+			element, ok := i.Element.(*slack.NumberInputBlockElement)
+			Expect(ok).To(BeTrue())
+			Expect(element.ActionID).To(Equal("blockId"))
+			Expect(element.IsDecimalAllowed).To(BeFalse())
+			Expect(element.Placeholder.Text).To(Equal("hint"))
+			Expect(element.InitialValue).To(Equal("2"))
+			Expect(element.MinValue).To(Equal("1"))
+		})
+	})
+
+	Describe("NewTimePicker", func() {
+		It("generates a time picker", func() {
+			i := NewTimePicker(TimePicker{
+				BlockID: "blockId",
+				Label:   "label",
+				Time:    "09:30",
+			})
+
+			Expect(i.Type).To(Equal(slack.MBTInput))
+			Expect(i.BlockID).To(Equal("blockId"))
+			Expect(i.Label.Text).To(Equal("label"))
+			Expect(i.Element.ElementType()).To(Equal(slack.METTimepicker))
+
+			element, ok := i.Element.(*slack.TimePickerBlockElement)
+			Expect(ok).To(BeTrue())
+			Expect(element.ActionID).To(Equal("blockId"))
+			Expect(element.InitialTime).To(Equal("09:30"))
+		})
+	})
+
+	Describe("NewDateTimePicker", func() {
+		It("generates a date and time picker", func() {
+			t := time.Date(2023, time.July, 10, 9, 30, 0, 0, time.UTC)
+			i := NewDateTimePicker(DateTimePicker{
+				BlockID: "blockId",
+				Label:   "label",
+				Time:    t,
+			})
+
+			Expect(i.Type).To(Equal(slack.MBTInput))
+			Expect(i.BlockID).To(Equal("blockId"))
+			Expect(i.Label.Text).To(Equal("label"))
+			Expect(i.Element.ElementType()).To(Equal(slack.METDatetimepicker))
+
+			element, ok := i.Element.(*slack.DateTimePickerBlockElement)
+			Expect(ok).To(BeTrue())
+			Expect(element.ActionID).To(Equal("blockId"))
+			Expect(element.InitialDateTime).To(Equal(t.Unix()))
+		})
+
+		It("leaves the picker empty without a time", func() {
+			i := NewDateTimePicker(DateTimePicker{BlockID: "blockId", Label: "label"})
+
+			element, ok := i.Element.(*slack.DateTimePickerBlockElement)
+			Expect(ok).To(BeTrue())
+			Expect(element.InitialDateTime).To(BeZero())
+		})
+	})
+
+	Describe("NewDatePicker", func() {
+		It("generates an optional date picker", func() {
+			i := NewDatePicker(DatePicker{
+				BlockID:  "blockId",
+				Label:    "label",
+				Date:     "2023-07-10",
+				Optional: true,
+			})
+
+			Expect(i.Type).To(Equal(slack.MBTInput))
+			Expect(i.BlockID).To(Equal("blockId"))
+			Expect(i.Label.Text).To(Equal("label"))
+			Expect(i.Optional).To(BeTrue())
+			Expect(i.Element.ElementType()).To(Equal(slack.METDatepicker))
+
+			element, ok := i.Element.(*slack.DatePickerBlockElement)
+			Expect(ok).To(BeTrue())
+			Expect(element.ActionID).To(Equal("blockId"))
+			Expect(element.InitialDate).To(Equal("2023-07-10"))
+		})
+	})
+
+	Describe("NewUserInput", func() {
+		It("generates a select of a single user", func() {
+			i := NewUserInput(UserInput{
+				BlockID: "blockId",
+				Label:   "label",
+				UserID:  "U123",
+			})
+
+			Expect(i.Type).To(Equal(slack.MBTInput))
+			Expect(i.BlockID).To(Equal("blockId"))
+			Expect(i.Label.Text).To(Equal("label"))
+
+			element, ok := i.Element.(*slack.SelectBlockElement)
+			Expect(ok).To(BeTrue())
+			Expect(element.Type).To(Equal(slack.OptTypeUser))
+			Expect(element.ActionID).To(Equal("blockId"))
+			Expect(element.InitialUser).To(Equal("U123"))
+			Expect(i.DispatchAction).To(BeFalse())
+		})
+
+		It("generates a select of a single user that dispatches an action", func() {
+			i := NewUserInput(UserInput{
+				BlockID:        "blockId",
+				Label:          "label",
+				DispatchAction: true,
+			})
+
+			Expect(i.DispatchAction).To(BeTrue())
+		})
+	})
+
+	Describe("NewMultiUserInput", func() {
+		It("generates a select of many users", func() {
+			i := NewMultiUserInput(MultiUserInput{
+				BlockID: "blockId",
+				Label:   "label",
+				UserIDs: []string{"U1", "U2"},
+			})
+
+			Expect(i.Type).To(Equal(slack.MBTInput))
+			Expect(i.BlockID).To(Equal("blockId"))
+			Expect(i.Label.Text).To(Equal("label"))
+
+			element, ok := i.Element.(*slack.MultiSelectBlockElement)
+			Expect(ok).To(BeTrue())
+			Expect(element.Type).To(Equal(slack.MultiOptTypeUser))
+			Expect(element.ActionID).To(Equal("blockId"))
+			Expect(element.InitialUsers).To(Equal([]string{"U1", "U2"}))
+		})
+	})
 })
@@ -30,3 +30,10 @@ func NewDefaultText(text string) *slack.TextBlockObject {
 		Text: text,
 	}
 }
+
+func NewMarkdownText(text string) *slack.TextBlockObject {
+	return &slack.TextBlockObject{
+		Type: slack.MarkdownType,
+		Text: text,
+	}
+}
@@ -15,4 +15,11 @@ var _ = Describe("Text", func() {
 		Expect(t.Emoji).To(BeFalse())
 		Expect(t.Verbatim).To(BeFalse())
 	})
+
+	It("Generates a markdown text", func() {
+		t := NewMarkdownText("*Hello* World")
+
+		Expect(t.Type).To(Equal(slack.MarkdownType))
+		Expect(t.Text).To(Equal("*Hello* World"))
+	})
 })
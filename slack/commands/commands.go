@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Name is the first word of the text typed after the slash command, it picks what the command does.
+type Name string
+
+const (
+	// CHome opens the home modal of the channel, it's what the command does when no subcommand is given.
+	CHome   = Name("")
+	CWho    = Name("who")
+	CNext   = Name("next")
+	CList   = Name("list")
+	CCreate = Name("create")
+	CHelp   = Name("help")
+	CSkip   = Name("skip")
+)
+
+var ErrUnknownCommand = errors.New("unknown command")
+
+// Command is the text typed after the slash command, e.g. `/rotabot who "On Call"`.
+type Command struct {
+	Name Name
+	// Args are the words that follow the subcommand, those in quotes are kept together as a single one.
+	Args []string
+}
+
+// Rota is the name of the rota the command is about, as typed by the user. Names don't need to be quoted so all the
+// arguments make up the name.
+func (c Command) Rota() string {
+	return strings.Join(c.Args, " ")
+}
+
+// SkipArgs are the arguments of the skip subcommand, e.g. `/rotabot skip "On Call" @alice because she's sick`.
+type SkipArgs struct {
+	Rota string
+	// UserID is the member that was mentioned, whoever is on duty is skipped when nobody was.
+	UserID string
+	// Reason is whatever follows the word "because".
+	Reason string
+}
+
+// mention matches users as slack escapes them in the text of commands, `<@U123|alice>`. Slack only does so for
+// commands that escape users, mentions are plain text otherwise.
+var mention = regexp.MustCompile(`^<@([UW][A-Z0-9]+)(\|[^>]*)?>$`)
+
+// Skip splits the arguments of the skip subcommand into the rota, the member to skip and why they're skipped. The
+// first member mentioned before the reason is the one skipped, every other word before the reason is part of the
+// name of the rota.
+func (c Command) Skip() SkipArgs {
+	args := SkipArgs{}
+	rota, reason := []string{}, []string(nil)
+	for _, word := range c.Args {
+		switch {
+		case reason != nil:
+			reason = append(reason, word)
+		case strings.EqualFold(word, "because"):
+			reason = []string{}
+		case args.UserID == "" && mention.MatchString(word):
+			args.UserID = mention.FindStringSubmatch(word)[1]
+		default:
+			rota = append(rota, word)
+		}
+	}
+	args.Rota = strings.Join(rota, " ")
+	args.Reason = strings.Join(reason, " ")
+	return args
+}
+
+// Parse splits the text of a slash command into its subcommand and arguments. Subcommands are case-insensitive and
+// ErrUnknownCommand is returned, together with whatever was parsed, when the subcommand isn't one of ours.
+func Parse(text string) (Command, error) {
+	return parse(split(text))
+}
+
+// ParseMention parses the text of a message that mentions rotabot, e.g. `@rotabot who is on call for deploys?`, with
+// the same grammar as the slash command. The mentions the message starts with are left out and questions about who
+// is on duty are read as the subcommand they ask for.
+func ParseMention(text string) (Command, error) {
+	words := split(text)
+	for len(words) > 0 && mention.MatchString(words[0]) {
+		words = words[1:]
+	}
+	if len(words) > 0 {
+		// Contractions are spelled out so "who's on call" reads the same as "who is on call".
+		for _, suffix := range []string{"'s", "’s"} {
+			if first, ok := strings.CutSuffix(strings.ToLower(words[0]), suffix); ok {
+				words = append([]string{first, "is"}, words[1:]...)
+				break
+			}
+		}
+		if last := strings.TrimRight(words[len(words)-1], "?"); last != "" {
+			words[len(words)-1] = last
+		} else {
+			words = words[:len(words)-1]
+		}
+	}
+	c, err := parse(words)
+	if err != nil || (c.Name != CWho && c.Name != CNext) {
+		return c, err
+	}
+	return c.question(), nil
+}
+
+// questionPrefixes are the words that can come between the subcommand and the name of the rota in a question, the
+// longest ones come first so they win over the shorter ones they start with.
+var questionPrefixes = [][]string{
+	{"is", "on", "call", "for"},
+	{"is", "on", "duty", "for"},
+	{"is", "next", "for"},
+	{"is", "next", "on"},
+	{"is", "on", "call"},
+	{"is", "on", "duty"},
+	{"is", "next"},
+	{"is"},
+	{"for"},
+}
+
+// question strips the words that turn the command into a question, e.g. "who is next for deploys?" becomes
+// `next deploys`.
+func (c Command) question() Command {
+	for _, prefix := range questionPrefixes {
+		if len(c.Args) < len(prefix) || !slices.EqualFunc(c.Args[:len(prefix)], prefix, strings.EqualFold) {
+			continue
+		}
+		if slices.Contains(prefix, "next") {
+			c.Name = CNext
+		}
+		c.Args = c.Args[len(prefix):]
+		break
+	}
+	return c
+}
+
+// parse builds the command out of the words typed after the slash command.
+func parse(words []string) (Command, error) {
+	if len(words) == 0 {
+		return Command{Name: CHome, Args: []string{}}, nil
+	}
+	c := Command{Name: Name(strings.ToLower(words[0])), Args: words[1:]}
+	switch c.Name {
+	case CWho, CNext, CList, CCreate, CHelp, CSkip:
+		return c, nil
+	default:
+		return c, ErrUnknownCommand
+	}
+}
+
+// split breaks the text into words separated by spaces, text within straight or curly double quotes is a single
+// word. Slack clients replace straight quotes with curly ones as people type, so both are handled the same way.
+// A quote that is never closed runs until the end of the text.
+func split(text string) []string {
+	words := []string{}
+	var word strings.Builder
+	quoted, inWord := false, false
+	flush := func() {
+		if inWord {
+			words = append(words, word.String())
+		}
+		word.Reset()
+		inWord = false
+	}
+	for _, r := range text {
+		switch {
+		case r == '"' || r == '“' || r == '”':
+			if quoted {
+				flush()
+			}
+			quoted = !quoted
+			inWord = quoted
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	flush()
+	return words
+}
+
+// Help lists the subcommands of the slash command.
+func Help(command string) string {
+	return strings.Join([]string{
+		"Here's what I can do:",
+		"• `" + command + "` opens the rotas of this channel",
+		"• `" + command + " who [rota]` tells you who is on duty right now",
+		"• `" + command + " next [rota]` tells you who is on duty next",
+		"• `" + command + " list` lists the rotas of this channel",
+		"• `" + command + " create <name>` creates a new rota in this channel",
+		"• `" + command + " skip [rota] [@member] [because <reason>]` skips the next shift of a member, or whoever is on duty when nobody is mentioned",
+		"• `" + command + " help` shows this message",
+		"Rota names don't need to be typed in full and can be wrapped in quotes.",
+	}, "\n")
+}
+
+// MentionHelp lists what rotabot answers when it's mentioned, everything else is done with the slash command.
+func MentionHelp(command string) string {
+	return strings.Join([]string{
+		"Here's what you can ask me:",
+		"• `@rotabot who is on call for [rota]?` tells you who is on duty right now and who is next",
+		"• `@rotabot who is next for [rota]?` tells you who is on duty next",
+		"• `@rotabot list` lists the rotas of this channel",
+		"• `@rotabot skip [rota] [@member] [because <reason>]` skips the next shift of a member, or whoever is on duty when nobody is mentioned",
+		"Rotas are created and changed with `" + command + "`.",
+	}, "\n")
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Commands", func() {
+	Describe("Parse", func() {
+		It("opens the home modal when there's no subcommand", func() {
+			c, err := Parse("   ")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(Equal(Command{Name: CHome, Args: []string{}}))
+		})
+
+		It("ignores the case of the subcommand", func() {
+			c, err := Parse("WHO on call")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(Equal(Command{Name: CWho, Args: []string{"on", "call"}}))
+			Expect(c.Rota()).To(Equal("on call"))
+		})
+
+		It("keeps quoted names together", func() {
+			c, err := Parse(`create "On Call"  Rota`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.Args).To(Equal([]string{"On Call", "Rota"}))
+
+			c, err = Parse("next “Front end” ")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.Args).To(Equal([]string{"Front end"}))
+		})
+
+		It("runs an unterminated quote until the end of the text", func() {
+			c, err := Parse(`who "On Call`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.Args).To(Equal([]string{"On Call"}))
+		})
+
+		It("returns an error for unknown subcommands", func() {
+			c, err := Parse("dance now")
+			Expect(err).To(MatchError(ErrUnknownCommand))
+			Expect(c.Name).To(Equal(Name("dance")))
+		})
+	})
+
+	Describe("ParseMention", func() {
+		It("leaves out the mentions the message starts with", func() {
+			c, err := ParseMention("<@U0BOT> <@U0BOT> list")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(Equal(Command{Name: CList, Args: []string{}}))
+		})
+
+		It("reads questions about who is on duty as the subcommand they ask for", func() {
+			c, err := ParseMention("<@U0BOT> who is on call for deploys?")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(Equal(Command{Name: CWho, Args: []string{"deploys"}}))
+
+			c, err = ParseMention("<@U0BOT> Who’s next for “On Call”?")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(Equal(Command{Name: CNext, Args: []string{"On Call"}}))
+
+			c, err = ParseMention("<@U0BOT> who?")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(Equal(Command{Name: CWho, Args: []string{}}))
+		})
+
+		It("parses everything else like the slash command", func() {
+			c, err := ParseMention("<@U0BOT> skip deploys <@U123> because she's out")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.Skip()).To(Equal(SkipArgs{Rota: "deploys", UserID: "U123", Reason: "she's out"}))
+
+			_, err = ParseMention("<@U0BOT> dance")
+			Expect(err).To(MatchError(ErrUnknownCommand))
+		})
+	})
+
+	Describe("Skip", func() {
+		It("splits the rota, the member mentioned and the reason", func() {
+			c, err := Parse(`skip "On Call" <@U123|alice> because she's out sick`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.Skip()).To(Equal(SkipArgs{Rota: "On Call", UserID: "U123", Reason: "she's out sick"}))
+		})
+
+		It("skips whoever is on duty when nobody is mentioned", func() {
+			c, err := Parse("skip deploys Because busy")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.Skip()).To(Equal(SkipArgs{Rota: "deploys", Reason: "busy"}))
+		})
+
+		It("keeps mentions in the reason", func() {
+			c, err := Parse("skip <@U123> because <@U456> asked")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.Skip()).To(Equal(SkipArgs{UserID: "U123", Reason: "<@U456> asked"}))
+		})
+	})
+
+	Describe("Help", func() {
+		It("lists every subcommand", func() {
+			help := Help("/rotabot")
+			for _, c := range []string{"who", "next", "list", "create", "skip", "help"} {
+				Expect(help).To(ContainSubstring("`/rotabot " + c))
+			}
+		})
+	})
+})
@@ -0,0 +1,312 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/rotas"
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+	"github.com/rotabot-io/rotabot/slack/announce"
+)
+
+// Match returns the rotas whose name matches the query, the closest matches win: names equal to the query come
+// first, then those starting with it, then those containing it and finally those containing every word of it.
+// Letter case, punctuation and extra spaces are ignored. Every rota matches an empty query.
+func Match(rotas []db.Rota, query string) []db.Rota {
+	q := normalize(query)
+	if q == "" {
+		return rotas
+	}
+	matchers := []func(name string) bool{
+		func(name string) bool { return name == q },
+		func(name string) bool { return strings.HasPrefix(name, q) },
+		func(name string) bool { return strings.Contains(name, q) },
+		func(name string) bool {
+			for _, word := range strings.Fields(q) {
+				if !strings.Contains(name, word) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+	for _, matches := range matchers {
+		found := []db.Rota{}
+		for _, rota := range rotas {
+			if matches(normalize(rota.Name)) {
+				found = append(found, rota)
+			}
+		}
+		if len(found) > 0 {
+			return found
+		}
+	}
+	return []db.Rota{}
+}
+
+func normalize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`"“”'’.,!?:;`, r) {
+			return -1
+		}
+		return r
+	}, strings.ToLower(s))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Handler answers the subcommands that are about the rotas of a channel, the answers to the slash command are meant
+// to be seen only by whoever typed it unless they changed a rota. Mentions are answered in a thread instead.
+type Handler struct {
+	Repository db.Repository
+	TeamID     string
+	ChannelID  string
+	// UserID is whoever typed the command.
+	UserID string
+	// Command is the slash command that was typed, used to tell the user what to type next.
+	Command string
+	// Clock resolves "now", the system clock is used when it's nil.
+	Clock rotation.Clock
+}
+
+// List lists the rotas of the channel by name.
+func (h Handler) List(ctx context.Context) (string, error) {
+	rotas, err := h.rotas(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(rotas) == 0 {
+		return h.noRotas(), nil
+	}
+	return "The rotas of this channel are:\n" + listRotas(rotas), nil
+}
+
+// Who tells who is on duty right now for the rota matching the query, together with whoever backs them up.
+func (h Handler) Who(ctx context.Context, query string) (string, error) {
+	rota, reply, err := h.find(ctx, query)
+	if err != nil || reply != "" {
+		return reply, err
+	}
+	return h.who(ctx, rota)
+}
+
+// Next tells who goes on duty after whoever is on duty right now for the rota matching the query.
+func (h Handler) Next(ctx context.Context, query string) (string, error) {
+	rota, reply, err := h.find(ctx, query)
+	if err != nil || reply != "" {
+		return reply, err
+	}
+	return h.next(ctx, rota)
+}
+
+// OnDuty tells both who is on duty right now and who goes on duty next for the rota matching the query.
+func (h Handler) OnDuty(ctx context.Context, query string) (string, error) {
+	rota, reply, err := h.find(ctx, query)
+	if err != nil || reply != "" {
+		return reply, err
+	}
+	current, err := h.who(ctx, rota)
+	if err != nil {
+		return "", err
+	}
+	next, err := h.next(ctx, rota)
+	if err != nil {
+		return "", err
+	}
+	if next == current {
+		// Rotas nobody is on duty for give the same reason either way.
+		return current, nil
+	}
+	return current + "\n" + next, nil
+}
+
+func (h Handler) who(ctx context.Context, rota db.Rota) (string, error) {
+	if reply, ok := h.inactive(rota, h.clock().Now()); !ok {
+		return reply, nil
+	}
+	engine, err := rotation.Load(ctx, h.Repository, rota, h.clock())
+	if err != nil {
+		return "", err
+	}
+	shift, err := engine.Current()
+	if err != nil {
+		return h.unscheduled(ctx, rota, err), nil
+	}
+
+	text := fmt.Sprintf(":rotating_light: <@%s> is on duty for %s until %s", shift.UserID, announce.ShiftName(rota, shift), announce.FormatTime(rotation.Until(rota, shift.End)))
+	if shift.Reason == db.SROverride {
+		text = fmt.Sprintf(":arrows_counterclockwise: <@%s> is covering %s until %s", shift.UserID, announce.ShiftName(rota, shift), announce.FormatTime(rotation.Until(rota, shift.End)))
+	}
+	for tier := 1; tier < rotation.Tiers(rota); tier++ {
+		e, err := engine.Tier(tier)
+		if err != nil {
+			continue
+		}
+		backup, err := e.At(shift.Start)
+		if err != nil {
+			continue
+		}
+		text += fmt.Sprintf("\n:busts_in_silhouette: <@%s> is on duty as *%s*", backup.UserID, rotation.TierName(tier))
+	}
+	return text, nil
+}
+
+func (h Handler) next(ctx context.Context, rota db.Rota) (string, error) {
+	if reply, ok := h.inactive(rota, h.clock().Now()); !ok {
+		return reply, nil
+	}
+	engine, err := rotation.Load(ctx, h.Repository, rota, h.clock())
+	if err != nil {
+		return "", err
+	}
+	upcoming, err := engine.Upcoming(1)
+	if err != nil {
+		return h.unscheduled(ctx, rota, err), nil
+	}
+	shift := upcoming[0]
+	if rota.EndsAt.Valid && !shift.Start.Before(rota.EndsAt.Time) {
+		return fmt.Sprintf(":checkered_flag: *%s* ends on %s, nobody is on duty after that", rota.Name, announce.FormatTime(rota.EndsAt.Time)), nil
+	}
+	return fmt.Sprintf(
+		":calendar: <@%s> is next on duty for %s from %s until %s",
+		shift.UserID,
+		announce.ShiftName(rota, shift),
+		announce.FormatTime(shift.Start),
+		announce.FormatTime(rotation.Until(rota, shift.End)),
+	), nil
+}
+
+// Skip skips the next shift of the member mentioned, or whoever is on duty right now when nobody was, for the rota
+// matching the query. It reports whether a shift was skipped, in which case the answer is meant for the channel.
+func (h Handler) Skip(ctx context.Context, args SkipArgs) (string, bool, error) {
+	rota, reply, err := h.find(ctx, args.Rota)
+	if err != nil || reply != "" {
+		return reply, false, err
+	}
+	if reply, ok := h.inactive(rota, h.clock().Now()); !ok {
+		return reply, false, nil
+	}
+	// Rotas that can't be scheduled are explained the same way as for every other subcommand.
+	engine, err := rotation.Load(ctx, h.Repository, rota, h.clock())
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := engine.Current(); err != nil && !errors.Is(err, rotation.ErrNonWorkingDay) {
+		return h.unscheduled(ctx, rota, err), false, nil
+	}
+
+	skipped, err := rotas.SkipShift(ctx, h.Repository, rota, rotas.SkipParams{
+		UserID:    args.UserID,
+		CreatedBy: h.UserID,
+		Reason:    args.Reason,
+		Clock:     h.clock(),
+	})
+	switch {
+	case errors.Is(err, rotation.ErrNonWorkingDay):
+		return fmt.Sprintf("Nobody is on duty for *%s* right now, mention who to skip, e.g. `%s skip \"%s\" @alice`.", rota.Name, h.Command, rota.Name), false, nil
+	case errors.Is(err, rotas.ErrOverridden):
+		return fmt.Sprintf("Somebody is covering *%s* right now, remove their override from `%s` instead.", rota.Name, h.Command), false, nil
+	case errors.Is(err, rotas.ErrNoUpcomingShift):
+		return fmt.Sprintf("<@%s> has no upcoming shift on *%s* to skip.", args.UserID, rota.Name), false, nil
+	case err != nil:
+		return "", false, err
+	}
+	return announce.SkippedText(rota, skipped), true, nil
+}
+
+func (h Handler) clock() rotation.Clock {
+	if h.Clock == nil {
+		return rotation.SystemClock
+	}
+	return h.Clock
+}
+
+func (h Handler) rotas(ctx context.Context) ([]db.Rota, error) {
+	rotas, err := h.Repository.ListRotasByChannel(ctx, db.ListRotasByChannelParams{
+		ChannelID: h.ChannelID,
+		TeamID:    h.TeamID,
+	})
+	if err != nil {
+		zapctx.Logger(ctx).Error("failed_to_list_rotas", zap.Error(err))
+		return nil, err
+	}
+	slices.SortFunc(rotas, func(a, b db.Rota) int { return strings.Compare(a.Name, b.Name) })
+	return rotas, nil
+}
+
+// find returns the rota of the channel that matches the query. When there isn't exactly one, the reply explains why
+// so the user can try again, the query can be left out when the channel has a single rota.
+func (h Handler) find(ctx context.Context, query string) (db.Rota, string, error) {
+	rotas, err := h.rotas(ctx)
+	if err != nil {
+		return db.Rota{}, "", err
+	}
+	if len(rotas) == 0 {
+		return db.Rota{}, h.noRotas(), nil
+	}
+	found := Match(rotas, query)
+	switch {
+	case len(found) == 1:
+		return found[0], "", nil
+	case len(found) == 0:
+		return db.Rota{}, fmt.Sprintf("There's no rota called *%s* in this channel, the rotas of this channel are:\n%s", query, listRotas(rotas)), nil
+	case query == "":
+		return db.Rota{}, "Which rota do you mean? The rotas of this channel are:\n" + listRotas(rotas), nil
+	default:
+		return db.Rota{}, fmt.Sprintf("More than one rota matches *%s*, which one do you mean?\n%s", query, listRotas(found)), nil
+	}
+}
+
+func (h Handler) noRotas() string {
+	return fmt.Sprintf("There are no rotas in this channel yet, create one with `%s create <name>`.", h.Command)
+}
+
+// inactive returns what to reply when the rota is paused, has ended or was archived, nobody is on duty for those.
+func (h Handler) inactive(rota db.Rota, now time.Time) (string, bool) {
+	switch {
+	case rota.Status == db.RTPaused:
+		return fmt.Sprintf(":double_vertical_bar: *%s* is paused, nobody is on duty until it's resumed", rota.Name), false
+	case rota.Status == db.RTEnded, rota.EndsAt.Valid && !now.Before(rota.EndsAt.Time):
+		return fmt.Sprintf(":checkered_flag: *%s* has ended, nobody is on duty anymore", rota.Name), false
+	case rota.Status == db.RTArchived:
+		return fmt.Sprintf(":file_cabinet: *%s* was archived together with its channel, nobody is on duty anymore", rota.Name), false
+	default:
+		return "", true
+	}
+}
+
+// unscheduled explains why the rota has nobody on duty.
+func (h Handler) unscheduled(ctx context.Context, rota db.Rota, err error) string {
+	switch {
+	case errors.Is(err, rotation.ErrNoMembers):
+		return fmt.Sprintf("*%s* has no members yet, add some from `%s`.", rota.Name, h.Command)
+	case errors.Is(err, rotation.ErrNotStarted) && rota.StartsAt.Valid:
+		return fmt.Sprintf("*%s* starts on %s, nobody is on duty until then", rota.Name, announce.FormatTime(rota.StartsAt.Time))
+	case errors.Is(err, rotation.ErrNotStarted):
+		return fmt.Sprintf("*%s* hasn't started yet, nobody is on duty until then", rota.Name)
+	case errors.Is(err, rotation.ErrNonWorkingDay):
+		return fmt.Sprintf("Nobody is on duty for *%s* on non-working days", rota.Name)
+	default:
+		zapctx.Logger(ctx).Debug("unable_to_schedule_rota", zap.Error(err))
+		return fmt.Sprintf("*%s* has nobody to put on duty, make sure its settings are valid from `%s`.", rota.Name, h.Command)
+	}
+}
+
+func listRotas(rotas []db.Rota) string {
+	lines := make([]string, 0, len(rotas))
+	for _, rota := range rotas {
+		line := "• *" + rota.Name + "*"
+		if rota.Status != db.RTActive {
+			line += " (" + string(rota.Status) + ")"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
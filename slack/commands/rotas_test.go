@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	"github.com/rotabot-io/rotabot/internal"
+	"github.com/rotabot-io/rotabot/lib/db"
+)
+
+var _ = Describe("Rotas", func() {
+	Describe("Match", func() {
+		rotas := []db.Rota{{Name: "On Call"}, {Name: "On Call Backend"}, {Name: "Deploys"}, {Name: "Call the plumber"}}
+
+		names := func(rotas []db.Rota) []string {
+			res := []string{}
+			for _, r := range rotas {
+				res = append(res, r.Name)
+			}
+			return res
+		}
+
+		It("prefers names equal to the query", func() {
+			Expect(names(Match(rotas, "on  CALL"))).To(Equal([]string{"On Call"}))
+		})
+
+		It("falls back to names starting with the query", func() {
+			Expect(names(Match(rotas, "dep"))).To(Equal([]string{"Deploys"}))
+			Expect(names(Match(rotas, "on"))).To(Equal([]string{"On Call", "On Call Backend"}))
+		})
+
+		It("falls back to names containing the query", func() {
+			Expect(names(Match(rotas, "backend"))).To(Equal([]string{"On Call Backend"}))
+		})
+
+		It("falls back to names containing every word of the query", func() {
+			Expect(names(Match(rotas, "backend call?"))).To(Equal([]string{"On Call Backend"}))
+		})
+
+		It("matches every rota when the query is empty", func() {
+			Expect(Match(rotas, "")).To(HaveLen(4))
+		})
+
+		It("matches nothing when no name is close", func() {
+			Expect(Match(rotas, "support")).To(BeEmpty())
+		})
+	})
+
+	Describe("Handler", func() {
+		var (
+			ctx     context.Context
+			repo    db.Repository
+			handler Handler
+		)
+
+		createRota := func(name string, userIDs ...string) string {
+			id, err := repo.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      name,
+				TeamID:    "T123",
+				ChannelID: "C123",
+				Metadata: db.RotaMetadata{
+					Frequency:      db.RFDaily,
+					SchedulingType: db.RSCreated,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			members := []db.Member{}
+			for _, userID := range userIDs {
+				members = append(members, db.Member{RotaID: id, UserID: userID, Metadata: db.MemberMetadata{}})
+			}
+			Expect(repo.UpdateRotaMembers(ctx, members)).To(Succeed())
+			return id
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+
+			container, err := internal.RunContainer(ctx,
+				postgres.WithInitScripts(filepath.Join("..", "..", "assets", "structure.sql")),
+				testcontainers.WithWaitStrategy(internal.DefaultWaitStrategy()),
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			connString, err := container.ConnectionString(ctx, "sslmode=disable")
+			Expect(err).ToNot(HaveOccurred())
+
+			conn, err := pgx.Connect(ctx, connString)
+			Expect(err).ToNot(HaveOccurred())
+
+			DeferCleanup(func() {
+				_ = conn.Close(ctx)
+				_ = container.Terminate(ctx)
+			})
+
+			repo = db.New(conn)
+			handler = Handler{
+				Repository: repo,
+				TeamID:     "T123",
+				ChannelID:  "C123",
+				UserID:     "U9",
+				Command:    "/rotabot",
+			}
+		})
+
+		It("tells how to create a rota when the channel has none", func() {
+			res, err := handler.List(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(ContainSubstring("`/rotabot create <name>`"))
+		})
+
+		It("lists the rotas of the channel by name", func() {
+			createRota("On Call")
+			id := createRota("Deploys")
+			Expect(repo.UpdateRotaStatus(ctx, db.UpdateRotaStatusParams{ID: id, Status: db.RTPaused})).To(Succeed())
+
+			res, err := handler.List(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal("The rotas of this channel are:\n• *Deploys* (paused)\n• *On Call*"))
+		})
+
+		It("tells who is on duty for the only rota of the channel", func() {
+			createRota("On Call", "U1", "U2")
+
+			res, err := handler.Who(ctx, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(HavePrefix(":rotating_light: <@U1> is on duty for *On Call* until"))
+		})
+
+		It("tells who is next for the rota matching the query", func() {
+			createRota("On Call", "U1", "U2")
+			createRota("Deploys", "U3", "U4")
+
+			res, err := handler.Next(ctx, "deploy")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(HavePrefix(":calendar: <@U4> is next on duty for *Deploys* from"))
+		})
+
+		It("tells who is on duty right now and who is next", func() {
+			createRota("On Call", "U1", "U2")
+			createRota("Deploys", "U3", "U4")
+
+			res, err := handler.OnDuty(ctx, "deploys")
+			Expect(err).ToNot(HaveOccurred())
+			lines := strings.Split(res, "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(HavePrefix(":rotating_light: <@U3> is on duty for *Deploys* until"))
+			Expect(lines[1]).To(HavePrefix(":calendar: <@U4> is next on duty for *Deploys* from"))
+
+			createRota("Support")
+			res, err = handler.OnDuty(ctx, "support")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal("*Support* has no members yet, add some from `/rotabot`."))
+		})
+
+		It("asks which rota when the query matches several", func() {
+			createRota("On Call")
+			createRota("On Call Backend")
+
+			res, err := handler.Who(ctx, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(HavePrefix("Which rota do you mean?"))
+
+			res, err = handler.Who(ctx, "on")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(HavePrefix("More than one rota matches *on*"))
+		})
+
+		It("tells when no rota matches the query", func() {
+			createRota("On Call")
+
+			res, err := handler.Who(ctx, "support")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal("There's no rota called *support* in this channel, the rotas of this channel are:\n• *On Call*"))
+		})
+
+		It("tells when the rota has no members", func() {
+			createRota("On Call")
+
+			res, err := handler.Who(ctx, "on call")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal("*On Call* has no members yet, add some from `/rotabot`."))
+		})
+
+		It("hands over to the next member when whoever is on duty is skipped", func() {
+			createRota("On Call", "U1", "U2")
+
+			res, skipped, err := handler.Skip(ctx, SkipArgs{Reason: "Out sick"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(skipped).To(BeTrue())
+			Expect(res).To(HavePrefix(":fast_forward: <@U9> skipped <@U1> on *On Call*, <@U2> is now on duty until"))
+
+			res, err = handler.Who(ctx, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(HavePrefix(":rotating_light: <@U2> is on duty"))
+		})
+
+		It("tells when the member has no shift to skip", func() {
+			createRota("On Call", "U1", "U2")
+
+			res, skipped, err := handler.Skip(ctx, SkipArgs{UserID: "U3"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(skipped).To(BeFalse())
+			Expect(res).To(Equal("<@U3> has no upcoming shift on *On Call* to skip."))
+		})
+
+		It("tells when the rota is paused", func() {
+			id := createRota("On Call", "U1")
+			Expect(repo.UpdateRotaStatus(ctx, db.UpdateRotaStatusParams{ID: id, Status: db.RTPaused})).To(Succeed())
+
+			res, err := handler.Who(ctx, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(":double_vertical_bar: *On Call* is paused, nobody is on duty until it's resumed"))
+		})
+	})
+})
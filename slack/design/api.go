@@ -11,6 +11,7 @@ var SlackService = Service("Slack", func() {
 
 	Method("Commands", func() {
 		Payload(commandPayload)
+		Result(commandResponse)
 
 		HTTP(func() {
 			POST("commands")
@@ -76,6 +77,17 @@ var commandPayload = Type("Command", func() {
 	)
 })
 
+var commandResponse = Type("CommandResponse", func() {
+	Description("https://api.slack.com/interactivity/slash-commands#responding_to_commands")
+	Attribute("response_type", String, func() {
+		Enum("ephemeral", "in_channel")
+		Example("ephemeral")
+	})
+	Attribute("text", String, func() {
+		Example("<@U123> is on duty")
+	})
+})
+
 var eventPayload = Type("Event", func() {
 	Description("https://api.slack.com/apis/connections/events-api")
 	Attribute("signature", String)
@@ -85,10 +97,9 @@ var eventPayload = Type("Event", func() {
 	Attribute("challenge", String)
 	Attribute("type", String)
 	Attribute("api_app_id", String)
-	Attribute("event", func() {
-		Description("The actual event information")
-		Attribute("type", String)
-	})
+	Attribute("event_id", String)
+	Attribute("event_time", Int64)
+	Attribute("event", Any, "The actual event information, its fields depend on its type so it's kept as it was sent")
 	Required(
 		"signature",
 		"timestamp",
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/rotabot-io/rotabot/lib/metrics"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+)
+
+var ErrInvalidEvent = errors.New("invalid event")
+
+// Event is an event of slack's Events API, the inner one that says what happened rather than the callback that
+// wraps it. Its fields depend on its type, so they're left to the handler of its type to decode.
+// See https://api.slack.com/apis/connections/events-api#callback-field
+type Event struct {
+	TeamID   string
+	APIAppID string
+	// EventID is unique to each event, slack sends it again when retrying an event that wasn't acknowledged.
+	EventID string
+	Type    slackevents.EventsAPIType
+	// Data is the inner event as slack sent it.
+	Data json.RawMessage
+}
+
+// New builds the event from the inner event of a callback. The request decoder keeps it as the JSON slack sent,
+// anything else, like the maps the tests send, is encoded to JSON.
+func New(teamID, apiAppID, eventID string, inner any) (Event, error) {
+	data, ok := inner.(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(inner); err != nil {
+			return Event{}, fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+		}
+	}
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil || header.Type == "" {
+		return Event{}, fmt.Errorf("%w: event has no type", ErrInvalidEvent)
+	}
+	return Event{
+		TeamID:   teamID,
+		APIAppID: apiAppID,
+		EventID:  eventID,
+		Type:     slackevents.EventsAPIType(header.Type),
+		Data:     data,
+	}, nil
+}
+
+type Handler func(ctx context.Context, e Event) error
+
+// Dispatcher hands each event over to the handler registered for its type. Events nothing handles are counted,
+// so we know which ones slack sends us that we don't need to subscribe to.
+type Dispatcher struct {
+	handlers map[slackevents.EventsAPIType]Handler
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: map[slackevents.EventsAPIType]Handler{}}
+}
+
+// Handle registers the handler for events of the given type, replacing the one registered before if any.
+func (d *Dispatcher) Handle(eventType slackevents.EventsAPIType, h Handler) {
+	d.handlers[eventType] = h
+}
+
+// On registers a handler for events of the given type that receives the event decoded into T, which is usually
+// the struct slack-go has for the type, e.g. slackevents.AppHomeOpenedEvent.
+func On[T any](d *Dispatcher, eventType slackevents.EventsAPIType, h func(ctx context.Context, e Event, data *T) error) {
+	d.Handle(eventType, func(ctx context.Context, e Event) error {
+		data := new(T)
+		if err := json.Unmarshal(e.Data, data); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+		}
+		return h(ctx, e, data)
+	})
+}
+
+// Dispatch runs the handler registered for the type of the event, events nothing handles are ignored.
+func (d *Dispatcher) Dispatch(ctx context.Context, e Event) error {
+	l := zapctx.Logger(ctx)
+	h, ok := d.handlers[e.Type]
+	if !ok {
+		l.Debug("unhandled_event")
+		metrics.UnhandledEventsTotal.With(prometheus.Labels{"type": string(e.Type)}).Inc()
+		return nil
+	}
+	return h(ctx, e)
+}
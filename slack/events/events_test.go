@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/slack-go/slack/slackevents"
+
+	"github.com/rotabot-io/rotabot/lib/metrics"
+)
+
+var _ = Describe("Events", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Describe("New", func() {
+		It("encodes the inner event for the handler of its type", func() {
+			e, err := New("T123", "A123", "Ev123", map[string]any{"type": "app_home_opened", "user": "U123", "tab": "home"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(e.Type).To(Equal(slackevents.AppHomeOpened))
+			Expect(e.TeamID).To(Equal("T123"))
+			Expect(e.EventID).To(Equal("Ev123"))
+			Expect(e.Data).To(MatchJSON(`{"type": "app_home_opened", "user": "U123", "tab": "home"}`))
+		})
+
+		It("keeps the inner event as slack sent it", func() {
+			inner := json.RawMessage(`{"type":"app_mention","user":"U123","event_ts":1700000000.000100,"count":9007199254740993}`)
+			e, err := New("T123", "A123", "Ev123", inner)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(e.Type).To(Equal(slackevents.AppMention))
+			Expect(e.Data).To(Equal(inner))
+		})
+
+		It("returns an error when the event has no type", func() {
+			_, err := New("T123", "A123", "Ev123", map[string]any{"user": "U123"})
+			Expect(err).To(MatchError(ErrInvalidEvent))
+
+			_, err = New("T123", "A123", "Ev123", nil)
+			Expect(err).To(MatchError(ErrInvalidEvent))
+		})
+	})
+
+	Describe("Dispatcher", func() {
+		It("decodes the event for the handler of its type", func() {
+			d := NewDispatcher()
+			var got *slackevents.MemberLeftChannelEvent
+			On(d, slackevents.MemberLeftChannel, func(_ context.Context, _ Event, data *slackevents.MemberLeftChannelEvent) error {
+				got = data
+				return nil
+			})
+
+			e, err := New("T123", "A123", "Ev123", map[string]any{"type": "member_left_channel", "user": "U123", "channel": "C123"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(d.Dispatch(ctx, e)).To(Succeed())
+			Expect(got).ToNot(BeNil())
+			Expect(got.User).To(Equal("U123"))
+			Expect(got.Channel).To(Equal("C123"))
+		})
+
+		It("returns the error of the handler", func() {
+			d := NewDispatcher()
+			failed := errors.New("failed")
+			d.Handle(slackevents.TeamJoin, func(context.Context, Event) error { return failed })
+
+			e, err := New("T123", "A123", "Ev123", map[string]any{"type": "team_join"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(d.Dispatch(ctx, e)).To(MatchError(failed))
+		})
+
+		It("returns an error when the event doesn't match its type", func() {
+			d := NewDispatcher()
+			On(d, slackevents.TeamJoin, func(context.Context, Event, *slackevents.TeamJoinEvent) error { return nil })
+
+			e, err := New("T123", "A123", "Ev123", map[string]any{"type": "team_join", "user": "U123"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(d.Dispatch(ctx, e)).To(MatchError(ErrInvalidEvent))
+		})
+
+		It("counts the events nothing handles", func() {
+			d := NewDispatcher()
+			count := func() float64 {
+				m := &dto.Metric{}
+				Expect(metrics.UnhandledEventsTotal.WithLabelValues("emoji_changed").Write(m)).To(Succeed())
+				return m.GetCounter().GetValue()
+			}
+			before := count()
+
+			e, err := New("T123", "A123", "Ev123", map[string]any{"type": "emoji_changed"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(d.Dispatch(ctx, e)).To(Succeed())
+			Expect(count()).To(Equal(before + 1))
+		})
+	})
+})
@@ -0,0 +1,336 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/jackc/pgx/v5"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/rotas"
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+	"github.com/rotabot-io/rotabot/slack/announce"
+	"github.com/rotabot-io/rotabot/slack/commands"
+	"github.com/rotabot-io/rotabot/slack/events"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+	"github.com/rotabot-io/rotabot/slack/views"
+)
+
+// handle registers the handlers of the events rotabot subscribes to.
+func (s svc) handle(d *events.Dispatcher) {
+	events.On(d, slackevents.AppHomeOpened, s.appHomeOpened)
+	events.On(d, slackevents.AppMention, s.appMention)
+	events.On(d, slackevents.MemberLeftChannel, s.memberLeftChannel)
+	// team_join isn't handled, whoever joins the workspace isn't a member of any rota yet and users that are
+	// deactivated come with user_change.
+	events.On(d, userChange, s.userChange)
+	events.On(d, slackevents.ChannelArchive, s.channelArchive)
+	events.On(d, slackevents.ChannelDeleted, s.channelDeleted)
+	events.On(d, slackevents.AppUninstalled, s.appUninstalled)
+	events.On(d, slackevents.TokensRevoked, s.tokensRevoked)
+}
+
+// userChange isn't one of the types slackevents knows about, its payload is the same as the RTM event's.
+const userChange = slackevents.EventsAPIType("user_change")
+
+// appHomeOpened publishes the Home tab of the user every time they open it, so it's up to date with the rotas
+// they were added to or removed from in the meantime. Building the tab works out the schedule of every rota of the
+// user, which can take longer than slack waits for the event to be acknowledged, so it's published once the event
+// is acknowledged the same way slow commands are answered, see respond.
+func (s svc) appHomeOpened(ctx context.Context, e events.Event, data *slackevents.AppHomeOpenedEvent) error {
+	if data.Tab != "home" {
+		// The messages tab has nothing for us to publish.
+		return nil
+	}
+	ctx = zapctx.WithLogger(context.WithoutCancel(ctx), zapctx.Logger(ctx).With(zap.String("user_id", data.User)))
+	go func() {
+		l := zapctx.Logger(ctx)
+		defer func() {
+			if rawErr := recover(); rawErr != nil {
+				sentry.CurrentHub().RecoverWithContext(ctx, rawErr)
+				l.Error("publish_home_panic", zap.Stack("stacktrace"), zap.Any("panic", rawErr))
+			}
+		}()
+		if err := s.publishHome(ctx, e.TeamID, data.User); err != nil {
+			sentry.CaptureException(err)
+		}
+	}()
+	return nil
+}
+
+func (s svc) publishHome(ctx context.Context, teamID, userID string) error {
+	l := zapctx.Logger(ctx)
+	client, err := slackclient.ClientFor(ctx, teamID)
+	if err != nil {
+		l.Error("failed to get slack client", zap.Error(err))
+		return err
+	}
+	ctx = slackclient.WithClient(ctx, client)
+
+	view := views.NewAppHome(db.New(s.conn), rotation.SystemClock, teamID, userID)
+	props, err := view.BuildProps(ctx)
+	if err != nil {
+		l.Error("failed to build props", zap.Error(err))
+		return err
+	}
+	return view.Render(ctx, props)
+}
+
+// appMention answers messages that mention rotabot in a thread, they're understood the same way as the slash
+// command so `@rotabot who is on call for deploys?` is answered like `/rotabot who deploys`.
+// Slack sends the mention again when we're slow to acknowledge it, dispatch drops those retries by their event id
+// so a `skip` isn't applied twice.
+func (s svc) appMention(ctx context.Context, e events.Event, data *slackevents.AppMentionEvent) error {
+	if data.BotID != "" {
+		// Answering bots risks talking to ourselves forever.
+		return nil
+	}
+	ctx = zapctx.WithLogger(ctx, zapctx.Logger(ctx).
+		With(zap.String("user_id", data.User)).
+		With(zap.String("channel_id", data.Channel)))
+	l := zapctx.Logger(ctx)
+
+	client, err := slackclient.ClientFor(ctx, e.TeamID)
+	if err != nil {
+		l.Error("failed to get slack client", zap.Error(err))
+		return err
+	}
+	ctx = slackclient.WithClient(ctx, client)
+
+	var (
+		text      string
+		broadcast bool
+	)
+	err = s.transaction(ctx, func(repo db.Repository) error {
+		text, broadcast, err = mention(ctx, commands.Handler{
+			Repository: repo,
+			TeamID:     e.TeamID,
+			ChannelID:  data.Channel,
+			UserID:     data.User,
+			Command:    mentionCommand,
+		}, data.Text)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	thread := data.ThreadTimeStamp
+	if thread == "" {
+		thread = data.TimeStamp
+	}
+	opts := []slack.MsgOption{slack.MsgOptionText(text, false), slack.MsgOptionTS(thread)}
+	if broadcast {
+		// Everyone in the channel needs to know who is on duty now, not only those following the thread.
+		opts = append(opts, slack.MsgOptionBroadcast())
+	}
+	if _, _, err = client.PostMessageContext(ctx, data.Channel, opts...); err != nil {
+		l.Error("failed to reply to mention", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// mentionCommand is the slash command people are pointed to for what can't be done by mentioning rotabot.
+const mentionCommand = "/rotabot"
+
+// mention answers the text of a message that mentions rotabot, it reports whether the answer changed a rota and
+// should be seen by the whole channel.
+func mention(ctx context.Context, h commands.Handler, text string) (string, bool, error) {
+	cmd, err := commands.ParseMention(text)
+	if err != nil {
+		zapctx.Logger(ctx).Info("unknown_mention", zap.String("text", text))
+		return fmt.Sprintf("Sorry, I don't know how to `%s`. %s", cmd.Name, commands.MentionHelp(h.Command)), false, nil
+	}
+	switch cmd.Name {
+	case commands.CHome, commands.CList:
+		res, err := h.List(ctx)
+		return res, false, err
+	case commands.CWho:
+		res, err := h.OnDuty(ctx, cmd.Rota())
+		return res, false, err
+	case commands.CNext:
+		res, err := h.Next(ctx, cmd.Rota())
+		return res, false, err
+	case commands.CSkip:
+		return h.Skip(ctx, cmd.Skip())
+	case commands.CCreate:
+		return fmt.Sprintf("Rotas are created with `%s create <name>`, it opens the settings of the new rota.", h.Command), false, nil
+	default:
+		return commands.MentionHelp(h.Command), false, nil
+	}
+}
+
+// memberLeftChannel takes whoever left the channel out of the rotas of the channel that are set to remove leavers.
+func (s svc) memberLeftChannel(ctx context.Context, e events.Event, data *slackevents.MemberLeftChannelEvent) error {
+	ctx = zapctx.WithLogger(ctx, zapctx.Logger(ctx).
+		With(zap.String("user_id", data.User)).
+		With(zap.String("channel_id", data.Channel)))
+	var removed []db.Rota
+	err := s.transaction(ctx, func(repo db.Repository) error {
+		channelRotas, err := repo.ListRotasByChannel(ctx, db.ListRotasByChannelParams{ChannelID: data.Channel, TeamID: e.TeamID})
+		if err != nil {
+			return err
+		}
+		for _, rota := range channelRotas {
+			if !rota.Metadata.RemoveLeavers || rota.Status == db.RTArchived {
+				continue
+			}
+			ok, err := rotas.RemoveMember(ctx, repo, rota, data.User)
+			if err != nil {
+				return err
+			}
+			if ok {
+				removed = append(removed, rota)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	announceRemovals(ctx, removed, data.User, rotas.RRLeftChannel)
+	return nil
+}
+
+func (s svc) userChange(ctx context.Context, e events.Event, data *slack.UserChangeEvent) error {
+	return s.syncUser(ctx, e, data.User)
+}
+
+// syncUser takes users that were deactivated out of every rota of the workspace, so nobody is put on duty that
+// can't be reached anymore.
+func (s svc) syncUser(ctx context.Context, e events.Event, user slack.User) error {
+	if !user.Deleted {
+		return nil
+	}
+	ctx = zapctx.WithLogger(ctx, zapctx.Logger(ctx).With(zap.String("user_id", user.ID)))
+	var removed []db.Rota
+	err := s.transaction(ctx, func(repo db.Repository) error {
+		userRotas, err := repo.ListRotasByUserID(ctx, db.ListRotasByUserIDParams{UserID: user.ID, TeamID: e.TeamID})
+		if err != nil {
+			return err
+		}
+		for _, rota := range userRotas {
+			ok, err := rotas.RemoveMember(ctx, repo, rota, user.ID)
+			if err != nil {
+				return err
+			}
+			if ok {
+				removed = append(removed, rota)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	announceRemovals(ctx, removed, user.ID, rotas.RRDeactivated)
+	return nil
+}
+
+func (s svc) channelArchive(ctx context.Context, e events.Event, data *slackevents.ChannelArchiveEvent) error {
+	return s.archiveRotas(ctx, e, data.Channel, rotas.ARChannelArchived)
+}
+
+func (s svc) channelDeleted(ctx context.Context, e events.Event, data *slackevents.ChannelDeletedEvent) error {
+	return s.archiveRotas(ctx, e, data.Channel, rotas.ARChannelDeleted)
+}
+
+// archiveRotas archives the rotas of a channel that is gone, their owners are told since the channel can't be.
+func (s svc) archiveRotas(ctx context.Context, e events.Event, channelID string, reason rotas.ArchiveReason) error {
+	ctx = zapctx.WithLogger(ctx, zapctx.Logger(ctx).With(zap.String("channel_id", channelID)))
+	l := zapctx.Logger(ctx)
+	var archived []db.Rota
+	err := s.transaction(ctx, func(repo db.Repository) error {
+		channelRotas, err := repo.ListRotasByChannel(ctx, db.ListRotasByChannelParams{ChannelID: channelID, TeamID: e.TeamID})
+		if err != nil {
+			return err
+		}
+		for _, rota := range channelRotas {
+			if rota.Status == db.RTArchived {
+				continue
+			}
+			if err = rotas.ArchiveRota(ctx, repo, rota); err != nil {
+				return err
+			}
+			archived = append(archived, rota)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, rota := range archived {
+		err = announce.Archive(ctx, rota, reason)
+		if errors.Is(err, announce.ErrNoOwner) {
+			l.Warn("nobody_to_announce_archive_to", zap.String("rota_id", rota.ID))
+			continue
+		}
+		if err != nil {
+			l.Error("failed_to_announce_archive", zap.String("rota_id", rota.ID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (s svc) appUninstalled(ctx context.Context, e events.Event, _ *slackevents.AppUninstalledEvent) error {
+	return s.uninstall(ctx, e, db.URAppUninstalled)
+}
+
+// tokensRevoked only cares about the bot tokens, rotabot doesn't act on behalf of users so it has no use for their
+// tokens.
+func (s svc) tokensRevoked(ctx context.Context, e events.Event, data *slackevents.TokensRevokedEvent) error {
+	if len(data.Tokens.Bot) == 0 {
+		return nil
+	}
+	return s.uninstall(ctx, e, db.URTokensRevoked)
+}
+
+// uninstall tombstones the workspace, rotabot can't talk to it anymore so there's nobody to tell.
+func (s svc) uninstall(ctx context.Context, e events.Event, reason db.UninstallReason) error {
+	return s.transaction(ctx, func(repo db.Repository) error {
+		return rotas.Uninstall(ctx, repo, e.TeamID, reason)
+	})
+}
+
+// announceRemovals lets the rotas know that the user was taken out of them. The removals are already committed,
+// failing to announce one doesn't undo them.
+func announceRemovals(ctx context.Context, removed []db.Rota, userID string, reason rotas.RemovalReason) {
+	l := zapctx.Logger(ctx)
+	for _, rota := range removed {
+		if err := announce.Removal(ctx, rota, userID, reason); err != nil {
+			l.Error("failed_to_announce_removal", zap.String("rota_id", rota.ID), zap.Error(err))
+		}
+	}
+}
+
+// transaction runs fn with a repository whose changes are committed once fn returns without an error.
+func (s svc) transaction(ctx context.Context, fn func(repo db.Repository) error) error {
+	l := zapctx.Logger(ctx)
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		l.Error("failed to begin transaction", zap.Error(err))
+		return err
+	}
+	defer func(tx pgx.Tx, ctx context.Context) {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			l.Error("failed to rollback transaction", zap.Error(err))
+		}
+	}(tx, ctx)
+
+	if err = fn(db.New(tx)); err != nil {
+		return err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		l.Error("failed to commit transaction", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap"
+
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+)
+
+// commandWindow is how long a slash command has to be answered in the HTTP response, slack gives up on it after 3
+// seconds so commands that take longer are answered through their response_url instead.
+const commandWindow = 2500 * time.Millisecond
+
+// reply is the answer to a slash command. Ephemeral replies are only seen by whoever typed the command while
+// in-channel ones are posted for everyone in the channel to see.
+// See https://api.slack.com/interactivity/slash-commands#responding_to_commands
+type reply struct {
+	responseType string
+	text         string
+}
+
+func ephemeral(text string) *reply {
+	return &reply{responseType: slack.ResponseTypeEphemeral, text: text}
+}
+
+func inChannel(text string) *reply {
+	return &reply{responseType: slack.ResponseTypeInChannel, text: text}
+}
+
+// response is the HTTP response of the command, commands that have nothing to say, like those opening a modal,
+// have an empty one.
+func (r *reply) response() *gen.CommandResponse {
+	if r == nil {
+		return &gen.CommandResponse{}
+	}
+	return &gen.CommandResponse{ResponseType: &r.responseType, Text: &r.text}
+}
+
+// opened answers a command that opens a modal. Slack only lets a modal be opened within 3 seconds of the command
+// being typed, commands that open one after the window are answered with an error so whoever typed it knows to try
+// again.
+func opened(err error) (*reply, error) {
+	var serr slack.SlackErrorResponse
+	if errors.As(err, &serr) && serr.Err == "expired_trigger_id" {
+		return ephemeral("Sorry, that took too long to open, please try again."), nil
+	}
+	return nil, err
+}
+
+type commandResult struct {
+	reply *reply
+	err   error
+}
+
+// respond runs the command and answers it in the HTTP response when it's done within the window. Otherwise the
+// command is acknowledged straight away and answered through its response_url once it's done, see opened for those
+// that open a modal. Commands carry on after the HTTP request is over, so they don't stop when the request's context
+// is cancelled.
+func (s svc) respond(
+	ctx context.Context,
+	c *gen.Command,
+	run func(ctx context.Context, c *gen.Command) (*reply, error),
+) (*gen.CommandResponse, error) {
+	l := zapctx.Logger(ctx)
+	ctx = context.WithoutCancel(ctx)
+
+	done := make(chan commandResult, 1)
+	go func() {
+		defer func() {
+			if rawErr := recover(); rawErr != nil {
+				sentry.CurrentHub().RecoverWithContext(ctx, rawErr)
+				l.Error("command_panic", zap.Stack("stacktrace"), zap.Any("panic", rawErr))
+				done <- commandResult{err: fmt.Errorf("panic: %v", rawErr)}
+			}
+		}()
+		r, err := run(ctx, c)
+		done <- commandResult{reply: r, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return nil, res.err
+		}
+		return res.reply.response(), nil
+	case <-time.After(s.window):
+		l.Info("answering_command_later")
+		go func() {
+			res := <-done
+			if res.err != nil {
+				res.reply = ephemeral("Sorry, something went wrong while handling your command, please try again.")
+			}
+			if err := deliver(ctx, c, res.reply); err != nil {
+				l.Error("failed_to_answer_command", zap.Error(err))
+				sentry.CaptureException(err)
+			}
+		}()
+		return &gen.CommandResponse{}, nil
+	}
+}
+
+// deliver answers a command through its response_url, which can be used up to five times within 30 minutes of the
+// command being typed.
+func deliver(ctx context.Context, c *gen.Command, r *reply) error {
+	if r == nil {
+		return nil
+	}
+	if c.ResponseURL == nil || *c.ResponseURL == "" {
+		return fmt.Errorf("command has no response url to answer %q", r.text)
+	}
+	return slack.PostWebhookContext(ctx, *c.ResponseURL, &slack.WebhookMessage{
+		ResponseType: r.responseType,
+		Text:         r.text,
+	})
+}
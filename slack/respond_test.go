@@ -0,0 +1,128 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/slack-go/slack"
+
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+)
+
+var _ = Describe("respond", func() {
+	var (
+		ctx      context.Context
+		s        svc
+		c        *gen.Command
+		received chan slack.WebhookMessage
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		s = svc{window: 50 * time.Millisecond}
+
+		received = make(chan slack.WebhookMessage, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var msg slack.WebhookMessage
+			Expect(json.NewDecoder(r.Body).Decode(&msg)).To(Succeed())
+			received <- msg
+		}))
+		DeferCleanup(server.Close)
+
+		c = &gen.Command{Command: "/rotabot", ResponseURL: &server.URL}
+	})
+
+	It("answers in the HTTP response when the command is quick", func() {
+		res, err := s.respond(ctx, c, func(context.Context, *gen.Command) (*reply, error) {
+			return inChannel("<@U1> is on duty"), nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*res.ResponseType).To(Equal(slack.ResponseTypeInChannel))
+		Expect(*res.Text).To(Equal("<@U1> is on duty"))
+		Consistently(received, 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("has an empty response when the command has nothing to say", func() {
+		res, err := s.respond(ctx, c, func(context.Context, *gen.Command) (*reply, error) {
+			return nil, nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(&gen.CommandResponse{}))
+	})
+
+	It("fails when the command fails within the window", func() {
+		_, err := s.respond(ctx, c, func(context.Context, *gen.Command) (*reply, error) {
+			return nil, errors.New("boom")
+		})
+		Expect(err).To(MatchError("boom"))
+	})
+
+	It("answers through the response url when the command is slow", func() {
+		res, err := s.respond(ctx, c, func(context.Context, *gen.Command) (*reply, error) {
+			time.Sleep(100 * time.Millisecond)
+			return ephemeral("<@U1> is on duty"), nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(&gen.CommandResponse{}))
+
+		var msg slack.WebhookMessage
+		Eventually(received).Should(Receive(&msg))
+		Expect(msg.ResponseType).To(Equal(slack.ResponseTypeEphemeral))
+		Expect(msg.Text).To(Equal("<@U1> is on duty"))
+	})
+
+	It("apologises through the response url when the slow command fails", func() {
+		_, err := s.respond(ctx, c, func(context.Context, *gen.Command) (*reply, error) {
+			time.Sleep(100 * time.Millisecond)
+			return nil, errors.New("boom")
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		var msg slack.WebhookMessage
+		Eventually(received).Should(Receive(&msg))
+		Expect(msg.Text).To(HavePrefix("Sorry, something went wrong"))
+	})
+
+	It("apologises through the response url when the modal of the slow command can't be opened anymore", func() {
+		_, err := s.respond(ctx, c, func(context.Context, *gen.Command) (*reply, error) {
+			time.Sleep(100 * time.Millisecond)
+			return opened(slack.SlackErrorResponse{Err: "expired_trigger_id"})
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		var msg slack.WebhookMessage
+		Eventually(received).Should(Receive(&msg))
+		Expect(msg.ResponseType).To(Equal(slack.ResponseTypeEphemeral))
+		Expect(msg.Text).To(Equal("Sorry, that took too long to open, please try again."))
+	})
+
+	It("has nothing to say once the modal is opened", func() {
+		res, err := s.respond(ctx, c, func(context.Context, *gen.Command) (*reply, error) {
+			return opened(nil)
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res).To(Equal(&gen.CommandResponse{}))
+		Consistently(received, 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("keeps running the command once the request is over", func() {
+		reqCtx, cancel := context.WithCancel(ctx)
+		_, err := s.respond(reqCtx, c, func(ctx context.Context, _ *gen.Command) (*reply, error) {
+			time.Sleep(100 * time.Millisecond)
+			return ephemeral(fmt.Sprint(ctx.Err())), nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		cancel()
+
+		var msg slack.WebhookMessage
+		Eventually(received).Should(Receive(&msg))
+		Expect(msg.Text).To(Equal("<nil>"))
+	})
+})
@@ -1,6 +1,9 @@
 package slack
 
 import (
+	"encoding/json"
+	"net/http"
+
 	"github.com/rotabot-io/rotabot/gen/http/slack/server"
 	"github.com/rotabot-io/rotabot/gen/slack"
 	"github.com/rotabot-io/rotabot/lib/codecs"
@@ -14,9 +17,39 @@ func NewServer(mux goahttp.Muxer, service slack.Service) *server.Server {
 	return server.New(
 		endpoints,
 		mux,
-		codecs.RequestDecoderWithLogs,
+		requestDecoder,
 		codecs.ResponseEncoderWithLogs,
 		goaerrors.ErrorHandler(),
 		nil,
 	)
 }
+
+// requestDecoder decodes requests the same way codecs.RequestDecoderWithLogs does, except for the inner event of the
+// Events API which is kept as the JSON slack sent rather than decoded into generic maps, see events.New.
+func requestDecoder(r *http.Request) goahttp.Decoder {
+	return eventDecoder{codecs.RequestDecoderWithLogs(r)}
+}
+
+type eventDecoder struct {
+	goahttp.Decoder
+}
+
+func (d eventDecoder) Decode(v any) error {
+	body, ok := v.(*server.EventsRequestBody)
+	if !ok {
+		return d.Decoder.Decode(v)
+	}
+	var raw struct {
+		server.EventsRequestBody
+		// Event takes the place of the one of the body, fields closer to the top win when decoding JSON.
+		Event json.RawMessage `json:"event,omitempty"`
+	}
+	if err := d.Decoder.Decode(&raw); err != nil {
+		return err
+	}
+	*body = raw.EventsRequestBody
+	if len(raw.Event) > 0 && string(raw.Event) != "null" {
+		body.Event = raw.Event
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rotabot-io/rotabot/gen/http/slack/server"
+)
+
+var _ = Describe("requestDecoder", func() {
+	decode := func(body string, v any) error {
+		req := httptest.NewRequest(http.MethodPost, "/slack/events", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return requestDecoder(req).Decode(v)
+	}
+
+	It("keeps the inner event of the Events API as slack sent it", func() {
+		inner := `{"type":"app_mention","user":"U123","count":9007199254740993}`
+		var body server.EventsRequestBody
+		Expect(decode(`{"token":"T","team_id":"T123","type":"event_callback","api_app_id":"A123","event_id":"Ev123","event":`+inner+`}`, &body)).To(Succeed())
+		Expect(*body.TeamID).To(Equal("T123"))
+		Expect(*body.EventID).To(Equal("Ev123"))
+		Expect(body.Event).To(Equal(json.RawMessage(inner)))
+	})
+
+	It("leaves callbacks without an inner event alone", func() {
+		var body server.EventsRequestBody
+		Expect(decode(`{"token":"T","team_id":"T123","type":"url_verification","api_app_id":"A123","challenge":"abc"}`, &body)).To(Succeed())
+		Expect(*body.Challenge).To(Equal("abc"))
+		Expect(body.Event).To(BeNil())
+	})
+
+	It("decodes every other request as it is", func() {
+		var body map[string]any
+		Expect(decode(`{"foo":"bar"}`, &body)).To(Succeed())
+		Expect(body).To(Equal(map[string]any{"foo": "bar"}))
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/rotabot-io/rotabot/lib/db"
@@ -18,6 +19,8 @@ import (
 
 	"github.com/rotabot-io/rotabot/lib/goaerrors"
 	"github.com/rotabot-io/rotabot/lib/zapctx"
+	"github.com/rotabot-io/rotabot/slack/commands"
+	"github.com/rotabot-io/rotabot/slack/events"
 	"github.com/rotabot-io/rotabot/slack/views"
 	"go.uber.org/zap"
 
@@ -25,49 +28,138 @@ import (
 )
 
 func New(pool *pgxpool.Pool) gen.Service {
-	return &svc{
-		conn: pool,
+	s := &svc{
+		conn:   pool,
+		window: commandWindow,
+		events: events.NewDispatcher(),
 	}
+	s.handle(s.events)
+	return s
 }
 
 type svc struct {
 	conn *pgxpool.Pool
+	// window is how long commands have to be answered in the HTTP response, see respond.
+	window time.Duration
+	// events routes the events of the Events API to the handlers of their type.
+	events *events.Dispatcher
 }
 
-func (s svc) Commands(ctx context.Context, c *gen.Command) error {
+func (s svc) Commands(ctx context.Context, c *gen.Command) (*gen.CommandResponse, error) {
 	ctx = zapctx.WithLogger(ctx, zapctx.Logger(ctx).
 		With(zap.String("cmd", c.Command)).
 		With(zap.String("user_id", c.UserID)).
 		With(zap.String("channel_id", c.ChannelID)).
 		With(zap.String("team_id", c.TeamID)).
 		With(zap.String("trigger_id", c.TriggerID)))
+	return s.respond(ctx, c, s.command)
+}
+
+func (s svc) command(ctx context.Context, c *gen.Command) (*reply, error) {
 	l := zapctx.Logger(ctx)
 
 	client, err := slackclient.ClientFor(ctx, c.TeamID)
 	if err != nil {
 		l.Error("failed to get slack client", zap.Error(err))
-		return goaerrors.NewInternalError()
+		return nil, goaerrors.NewInternalError()
 	}
 	ctx = slackclient.WithClient(ctx, client)
 
 	tx, err := s.conn.Begin(ctx)
 	if err != nil {
 		l.Error("failed to begin transaction", zap.Error(err))
-		return goaerrors.NewInternalError()
+		return nil, goaerrors.NewInternalError()
 	}
 	defer func(tx pgx.Tx, ctx context.Context) {
 		err := tx.Rollback(ctx)
-		if err != nil {
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
 			l.Error("failed to rollback transaction", zap.Error(err))
 		}
 	}(tx, ctx)
 
+	repo := db.New(tx)
+	if err = reinstalled(ctx, repo, c.TeamID); err != nil {
+		return nil, goaerrors.NewInternalError()
+	}
+	r, err := runCommand(ctx, repo, c)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		l.Error("failed to commit transaction", zap.Error(err))
+		return nil, goaerrors.NewInternalError()
+	}
+	return r, nil
+}
+
+// runCommand answers the command, the changes it makes to the repository are committed once it's answered.
+func runCommand(ctx context.Context, repo db.Repository, c *gen.Command) (*reply, error) {
+	l := zapctx.Logger(ctx)
+	text := ""
+	if c.Text != nil {
+		text = *c.Text
+	}
+	cmd, err := commands.Parse(text)
+	if err != nil {
+		l.Info("unknown_command", zap.String("text", text))
+		return ephemeral(fmt.Sprintf("Sorry, I don't know how to `%s`. %s", cmd.Name, commands.Help(c.Command))), nil
+	}
+
+	handler := commands.Handler{
+		Repository: repo,
+		TeamID:     c.TeamID,
+		ChannelID:  c.ChannelID,
+		UserID:     c.UserID,
+		Command:    c.Command,
+	}
+	switch cmd.Name {
+	case commands.CHome:
+		return opened(openHome(ctx, repo, c))
+	case commands.CCreate:
+		if cmd.Rota() == "" {
+			return ephemeral(fmt.Sprintf("Give the rota a name, e.g. `%s create On Call`.", c.Command)), nil
+		}
+		return opened(openCreateRota(ctx, repo, c, cmd.Rota()))
+	case commands.CHelp:
+		return ephemeral(commands.Help(c.Command)), nil
+	case commands.CSkip:
+		res, skipped, err := handler.Skip(ctx, cmd.Skip())
+		if err != nil {
+			l.Error("failed to handle command", zap.Error(err))
+			return nil, goaerrors.NewInternalError()
+		}
+		if !skipped {
+			return ephemeral(res), nil
+		}
+		// Everyone in the channel needs to know who is on duty now.
+		return inChannel(res), nil
+	}
+
+	var res string
+	switch cmd.Name { // nolint:exhaustive
+	case commands.CList:
+		res, err = handler.List(ctx)
+	case commands.CWho:
+		res, err = handler.Who(ctx, cmd.Rota())
+	case commands.CNext:
+		res, err = handler.Next(ctx, cmd.Rota())
+	}
+	if err != nil {
+		l.Error("failed to handle command", zap.Error(err))
+		return nil, goaerrors.NewInternalError()
+	}
+	return ephemeral(res), nil
+}
+
+func openHome(ctx context.Context, repo db.Repository, c *gen.Command) error {
+	l := zapctx.Logger(ctx)
 	view := views.Home{
-		Repository: db.New(tx),
+		Repository: repo,
 		State: &views.HomeState{
 			TriggerID: c.TriggerID,
 			ChannelID: c.ChannelID,
 			TeamID:    c.TeamID,
+			UserID:    c.UserID,
 		},
 	}
 
@@ -85,13 +177,98 @@ func (s svc) Commands(ctx context.Context, c *gen.Command) error {
 	return view.Render(ctx, props)
 }
 
-func (s svc) Events(_ context.Context, event *gen.Event) (*gen.EventResponse, error) {
-	if event.Type == slackevents.URLVerification {
+func openCreateRota(ctx context.Context, repo db.Repository, c *gen.Command, name string) error {
+	l := zapctx.Logger(ctx)
+	view := views.NewCreateRota(repo, views.SaveRotaState{
+		TriggerID: c.TriggerID,
+		ChannelID: c.ChannelID,
+		TeamID:    c.TeamID,
+		UserID:    c.UserID,
+	}, name)
+
+	props, err := view.BuildProps(ctx)
+	if err != nil {
+		l.Error("failed to build props", zap.Error(err))
+		return goaerrors.NewInternalError()
+	}
+	return view.Render(ctx, props)
+}
+
+func (s svc) Events(ctx context.Context, event *gen.Event) (*gen.EventResponse, error) {
+	switch event.Type {
+	case slackevents.URLVerification:
 		return &gen.EventResponse{Challenge: event.Challenge}, nil
+	case slackevents.CallbackEvent:
+		s.dispatch(ctx, event)
 	}
 	return &gen.EventResponse{}, nil
 }
 
+// dispatch hands the inner event of a callback over to its handler. Slack retries events that aren't acknowledged
+// with a 200, retrying won't fix a broken event or handler so failures are only reported. Events are recorded before
+// they're handled, so those slack sends again because they took too long to acknowledge are only handled once.
+func (s svc) dispatch(ctx context.Context, event *gen.Event) {
+	ctx = zapctx.WithLogger(ctx, zapctx.Logger(ctx).
+		With(zap.String("event_id", deref(event.EventID))).
+		With(zap.String("team_id", event.TeamID)))
+	e, err := events.New(event.TeamID, event.APIAppID, deref(event.EventID), event.Event)
+	if err != nil {
+		zapctx.Logger(ctx).Error("failed to decode event", zap.Error(err))
+		sentry.CaptureException(err)
+		return
+	}
+	ctx = zapctx.WithLogger(ctx, zapctx.Logger(ctx).With(zap.String("event_type", string(e.Type))))
+	l := zapctx.Logger(ctx)
+
+	err = s.transaction(ctx, func(repo db.Repository) error {
+		return received(ctx, repo, e)
+	})
+	if errors.Is(err, db.ErrAlreadyExists) {
+		l.Info("event_already_handled")
+		return
+	}
+	if err != nil {
+		sentry.CaptureException(err)
+		return
+	}
+	if err = s.events.Dispatch(ctx, e); err != nil {
+		l.Error("failed to handle event", zap.Error(err))
+		sentry.CaptureException(err)
+	}
+}
+
+// received records the event, it returns db.ErrAlreadyExists when it was handled before. Every event but those
+// telling rotabot it was uninstalled is a sign the workspace still uses it.
+func received(ctx context.Context, repo db.Repository, e events.Event) error {
+	if e.EventID != "" {
+		err := repo.CreateEvent(ctx, db.CreateEventParams{ID: e.EventID, TeamID: e.TeamID, Type: string(e.Type)})
+		if err != nil {
+			return err
+		}
+	}
+	if e.Type == slackevents.AppUninstalled || e.Type == slackevents.TokensRevoked {
+		return nil
+	}
+	return reinstalled(ctx, repo, e.TeamID)
+}
+
+// reinstalled forgets that the team uninstalled rotabot, if it did, as part of the transaction of a request coming
+// from it. Slack doesn't tell us when a workspace installs rotabot again, someone using it is the first sign of it.
+func reinstalled(ctx context.Context, repo db.Repository, teamID string) error {
+	if err := repo.DeleteUninstallation(ctx, teamID); err != nil {
+		zapctx.Logger(ctx).Error("failed to delete uninstallation", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 func (s svc) MessageActions(ctx context.Context, event *gen.Action) (*gen.ActionResponse, error) {
 	action, err := marshallCallback(ctx, event)
 	if err != nil {
@@ -123,8 +300,12 @@ func (s svc) MessageActions(ctx context.Context, event *gen.Action) (*gen.Action
 		}
 	}(tx, ctx)
 
+	repo := db.New(tx)
+	if err = reinstalled(ctx, repo, action.Team.ID); err != nil {
+		return nil, goaerrors.NewInternalError()
+	}
 	view, err := views.Resolve(ctx, views.ResolverParams{
-		Repository: db.New(tx),
+		Repository: repo,
 		Action:     action,
 	})
 	if err != nil {
@@ -3,6 +3,7 @@ package slack
 import (
 	"context"
 	"path/filepath"
+	"time"
 
 	"github.com/testcontainers/testcontainers-go"
 
@@ -22,6 +23,7 @@ import (
 	. "github.com/onsi/gomega"
 	gen "github.com/rotabot-io/rotabot/gen/slack"
 	"github.com/rotabot-io/rotabot/slack/slackclient/mock_slackclient"
+	"github.com/rotabot-io/rotabot/slack/views"
 	"go.uber.org/mock/gomock"
 )
 
@@ -66,7 +68,7 @@ var _ = Describe("Service", func() {
 		It("should open view when command api is called", func() {
 			sc.EXPECT().OpenViewContext(gomock.Any(), "T123", gomock.Any()).Return(nil, nil).Times(1)
 
-			err := svc.Commands(ctx, &gen.Command{
+			res, err := svc.Commands(ctx, &gen.Command{
 				Signature: "TEST",
 				Timestamp: 1234567890,
 				TriggerID: "T123",
@@ -77,6 +79,96 @@ var _ = Describe("Service", func() {
 			})
 
 			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&gen.CommandResponse{}))
+		})
+
+		command := func(text string) *gen.Command {
+			return &gen.Command{
+				Signature: "TEST",
+				Timestamp: 1234567890,
+				TriggerID: "T123",
+				Command:   "/rotabot",
+				Text:      &text,
+				Token:     "TEST",
+				UserID:    "U123",
+				TeamID:    teamId,
+				ChannelID: channelId,
+			}
+		}
+
+		It("should list the subcommands when asked for help", func() {
+			res, err := svc.Commands(ctx, command("help"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*res.ResponseType).To(Equal(slack.ResponseTypeEphemeral))
+			Expect(*res.Text).To(HavePrefix("Here's what I can do:"))
+		})
+
+		It("should reply instead of opening a view when the subcommand is unknown", func() {
+			sc.EXPECT().OpenViewContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			res, err := svc.Commands(ctx, command("dance"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*res.ResponseType).To(Equal(slack.ResponseTypeEphemeral))
+			Expect(*res.Text).To(HavePrefix("Sorry, I don't know how to `dance`."))
+		})
+
+		It("should tell who is on duty for the rota matching the name", func() {
+			id, err := db.New(conn).CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      "On Call",
+				TeamID:    teamId,
+				ChannelID: channelId,
+				Metadata: db.RotaMetadata{
+					Frequency:      db.RFDaily,
+					SchedulingType: db.RSCreated,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(db.New(conn).UpdateRotaMembers(ctx, []db.Member{
+				{RotaID: id, UserID: "U1", Metadata: db.MemberMetadata{}},
+			})).To(Succeed())
+
+			res, err := svc.Commands(ctx, command(`who "on call"`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*res.Text).To(HavePrefix(":rotating_light: <@U1> is on duty for *On Call*"))
+		})
+
+		It("should tell the channel when whoever is on duty is skipped", func() {
+			id, err := db.New(conn).CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      "On Call",
+				TeamID:    teamId,
+				ChannelID: channelId,
+				Metadata: db.RotaMetadata{
+					Frequency:      db.RFDaily,
+					SchedulingType: db.RSCreated,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(db.New(conn).UpdateRotaMembers(ctx, []db.Member{
+				{RotaID: id, UserID: "U1", Metadata: db.MemberMetadata{}},
+				{RotaID: id, UserID: "U2", Metadata: db.MemberMetadata{}},
+			})).To(Succeed())
+
+			res, err := svc.Commands(ctx, command("skip because out sick"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*res.ResponseType).To(Equal(slack.ResponseTypeInChannel))
+			Expect(*res.Text).To(HavePrefix(":fast_forward: <@U123> skipped <@U1> on *On Call*, <@U2> is now on duty"))
+
+			// The skip outlives the command.
+			rota, err := db.New(conn).FindRotaByID(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.State.UserID).To(Equal("U2"))
+		})
+
+		It("should open the create rota view with the name filled in", func() {
+			sc.EXPECT().OpenViewContext(gomock.Any(), "T123", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+					Expect(view.CallbackID).To(Equal("SaveRota"))
+					return nil, nil
+				}).Times(1)
+
+			res, err := svc.Commands(ctx, command("create Deploys"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&gen.CommandResponse{}))
 		})
 	})
 
@@ -100,7 +192,7 @@ var _ = Describe("Service", func() {
 			Expect(responseChallenge).To(Equal(challenge))
 		})
 
-		It("Should return the challenge", func() {
+		It("Should not return a challenge for other events", func() {
 			res, err := svc.Events(ctx, &gen.Event{
 				Signature: "TEST",
 				Timestamp: 1234567890,
@@ -113,6 +205,251 @@ var _ = Describe("Service", func() {
 			Expect(res).ToNot(BeNil())
 			Expect(res.Challenge).To(BeNil())
 		})
+
+		It("Should publish the home tab when the user opens it", func() {
+			published := make(chan struct{}, 1)
+			sc.EXPECT().PublishViewContext(gomock.Any(), "U123", gomock.Cond(func(x any) bool {
+				return x.(slack.HomeTabViewRequest).CallbackID == string(views.VTAppHome)
+			}), "").DoAndReturn(func(context.Context, string, slack.HomeTabViewRequest, string) (*slack.ViewResponse, error) {
+				published <- struct{}{}
+				return nil, nil
+			}).Times(1)
+
+			res, err := svc.Events(ctx, &gen.Event{
+				Signature: "TEST",
+				Timestamp: 1234567890,
+				Token:     "TEST",
+				Type:      slackevents.CallbackEvent,
+				TeamID:    teamId,
+				Event:     map[string]any{"type": "app_home_opened", "user": "U123", "tab": "home"},
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).ToNot(BeNil())
+			// The tab is published once the event is acknowledged.
+			Eventually(published).Should(Receive())
+		})
+
+		It("Should tombstone the workspace when rotabot is uninstalled and forget it once it's used again", func() {
+			res, err := svc.Events(ctx, &gen.Event{
+				Signature: "TEST",
+				Timestamp: 1234567890,
+				Token:     "TEST",
+				Type:      slackevents.CallbackEvent,
+				TeamID:    teamId,
+				Event:     map[string]any{"type": "app_uninstalled"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).ToNot(BeNil())
+
+			uninstallations, err := db.New(conn).ListUninstallations(ctx, db.Timestamptz(time.Now().Add(time.Minute)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uninstallations).To(HaveLen(1))
+			Expect(uninstallations[0].TeamID).To(Equal(teamId))
+			Expect(uninstallations[0].Reason).To(Equal(db.URAppUninstalled))
+
+			text := "help"
+			_, err = svc.Commands(ctx, &gen.Command{
+				Signature: "TEST",
+				Timestamp: 1234567890,
+				Command:   "/rotabot",
+				Text:      &text,
+				Token:     "TEST",
+				TeamID:    teamId,
+				ChannelID: channelId,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			uninstallations, err = db.New(conn).ListUninstallations(ctx, db.Timestamptz(time.Now().Add(time.Minute)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uninstallations).To(BeEmpty())
+		})
+
+		It("Should forget the tombstone of the workspace once an event comes from it", func() {
+			_, err := svc.Events(ctx, &gen.Event{
+				Signature: "TEST",
+				Timestamp: 1234567890,
+				Token:     "TEST",
+				Type:      slackevents.CallbackEvent,
+				TeamID:    teamId,
+				Event:     map[string]any{"type": "app_uninstalled"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			published := make(chan struct{}, 1)
+			sc.EXPECT().PublishViewContext(gomock.Any(), "U123", gomock.Any(), "").DoAndReturn(
+				func(context.Context, string, slack.HomeTabViewRequest, string) (*slack.ViewResponse, error) {
+					published <- struct{}{}
+					return nil, nil
+				}).Times(1)
+			_, err = svc.Events(ctx, &gen.Event{
+				Signature: "TEST",
+				Timestamp: 1234567890,
+				Token:     "TEST",
+				Type:      slackevents.CallbackEvent,
+				TeamID:    teamId,
+				Event:     map[string]any{"type": "app_home_opened", "user": "U123", "tab": "home"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(published).Should(Receive())
+
+			uninstallations, err := db.New(conn).ListUninstallations(ctx, db.Timestamptz(time.Now().Add(time.Minute)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uninstallations).To(BeEmpty())
+		})
+
+		It("Should only handle an event once when slack sends it again", func() {
+			published := make(chan struct{}, 2)
+			sc.EXPECT().PublishViewContext(gomock.Any(), "U123", gomock.Any(), "").DoAndReturn(
+				func(context.Context, string, slack.HomeTabViewRequest, string) (*slack.ViewResponse, error) {
+					published <- struct{}{}
+					return nil, nil
+				}).Times(1)
+
+			eventID := "Ev123"
+			for i := 0; i < 2; i++ {
+				_, err := svc.Events(ctx, &gen.Event{
+					Signature: "TEST",
+					Timestamp: 1234567890,
+					Token:     "TEST",
+					Type:      slackevents.CallbackEvent,
+					TeamID:    teamId,
+					EventID:   &eventID,
+					Event:     map[string]any{"type": "app_home_opened", "user": "U123", "tab": "home"},
+				})
+				Expect(err).ToNot(HaveOccurred())
+			}
+			Eventually(published).Should(Receive())
+			Consistently(published, 100*time.Millisecond).ShouldNot(Receive())
+		})
+
+		It("Should ignore user tokens being revoked", func() {
+			_, err := svc.Events(ctx, &gen.Event{
+				Signature: "TEST",
+				Timestamp: 1234567890,
+				Token:     "TEST",
+				Type:      slackevents.CallbackEvent,
+				TeamID:    teamId,
+				Event:     map[string]any{"type": "tokens_revoked", "tokens": map[string]any{"oauth": []string{"U123"}}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			uninstallations, err := db.New(conn).ListUninstallations(ctx, db.Timestamptz(time.Now().Add(time.Minute)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uninstallations).To(BeEmpty())
+		})
+
+		It("Should answer mentions in a thread", func() {
+			id, err := db.New(conn).CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      "Deploys",
+				TeamID:    teamId,
+				ChannelID: channelId,
+				Metadata: db.RotaMetadata{
+					Frequency:      db.RFDaily,
+					SchedulingType: db.RSCreated,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(db.New(conn).UpdateRotaMembers(ctx, []db.Member{
+				{RotaID: id, UserID: "U1", Metadata: db.MemberMetadata{}},
+				{RotaID: id, UserID: "U2", Metadata: db.MemberMetadata{}},
+			})).To(Succeed())
+
+			sc.EXPECT().PostMessageContext(gomock.Any(), channelId, gomock.Any()).
+				DoAndReturn(func(_ context.Context, channelID string, opts ...slack.MsgOption) (string, string, error) {
+					_, values, err := slack.UnsafeApplyMsgOptions("", channelID, "", opts...)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(values.Get("thread_ts")).To(Equal("1700000000.000100"))
+					Expect(values.Get("text")).To(ContainSubstring("<@U1> is on duty for *Deploys*"))
+					Expect(values.Get("text")).To(ContainSubstring("<@U2> is next on duty for *Deploys*"))
+					return channelID, "1700000000.000200", nil
+				}).Times(1)
+
+			_, err = svc.Events(ctx, &gen.Event{
+				Signature: "TEST",
+				Timestamp: 1234567890,
+				Token:     "TEST",
+				Type:      slackevents.CallbackEvent,
+				TeamID:    teamId,
+				Event: map[string]any{
+					"type":    "app_mention",
+					"user":    "U123",
+					"text":    "<@U0BOT> who is on call for deploys?",
+					"ts":      "1700000000.000100",
+					"channel": channelId,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Should only skip once when slack sends a mention again", func() {
+			id, err := db.New(conn).CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      "Deploys",
+				TeamID:    teamId,
+				ChannelID: channelId,
+				Metadata: db.RotaMetadata{
+					Frequency:      db.RFDaily,
+					SchedulingType: db.RSCreated,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(db.New(conn).UpdateRotaMembers(ctx, []db.Member{
+				{RotaID: id, UserID: "U1", Metadata: db.MemberMetadata{}},
+				{RotaID: id, UserID: "U2", Metadata: db.MemberMetadata{}},
+				{RotaID: id, UserID: "U3", Metadata: db.MemberMetadata{}},
+			})).To(Succeed())
+
+			sc.EXPECT().PostMessageContext(gomock.Any(), channelId, gomock.Any()).Return(channelId, "1700000000.000200", nil).Times(1)
+
+			eventID := "Ev456"
+			for i := 0; i < 2; i++ {
+				_, err = svc.Events(ctx, &gen.Event{
+					Signature: "TEST",
+					Timestamp: 1234567890,
+					Token:     "TEST",
+					Type:      slackevents.CallbackEvent,
+					TeamID:    teamId,
+					EventID:   &eventID,
+					Event: map[string]any{
+						"type":    "app_mention",
+						"user":    "U123",
+						"text":    "<@U0BOT> skip deploys",
+						"ts":      "1700000000.000100",
+						"channel": channelId,
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			shifts, err := db.New(conn).ListShiftsByRotaID(ctx, db.ListShiftsByRotaIDParams{
+				RotaID: id,
+				Until:  db.Timestamptz(time.Now().Add(48 * time.Hour)),
+				Since:  db.Timestamptz(time.Now().Add(-48 * time.Hour)),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			skips := 0
+			for _, shift := range shifts {
+				if shift.Reason == db.SRSkip {
+					skips++
+				}
+			}
+			Expect(skips).To(Equal(1))
+		})
+
+		It("Should acknowledge events nothing handles", func() {
+			res, err := svc.Events(ctx, &gen.Event{
+				Signature: "TEST",
+				Timestamp: 1234567890,
+				Token:     "TEST",
+				Type:      slackevents.CallbackEvent,
+				TeamID:    "T123",
+				Event:     map[string]any{"type": "emoji_changed", "subtype": "add"},
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).ToNot(BeNil())
+			Expect(res.Challenge).To(BeNil())
+		})
 	})
 
 	Describe("Actions", func() {
@@ -0,0 +1,207 @@
+package views
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/slack/block"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+	"github.com/slack-go/slack"
+)
+
+// AddOverride lets someone cover a window of time of a rota, whoever the rotation picked is back on duty once
+// the override ends.
+type AddOverride struct {
+	Repository db.Repository
+	State      *AddOverrideState
+}
+
+type AddOverrideState struct {
+	TriggerID      string
+	ChannelID      string
+	TeamID         string
+	UserID         string
+	rotaID         string
+	overrideUser   string
+	startsAt       time.Time
+	endsAt         time.Time
+	externalID     string
+	previousViewID string
+}
+
+type AddOverrideProps struct {
+	title  *slack.TextBlockObject
+	submit *slack.TextBlockObject
+	close  *slack.TextBlockObject
+	blocks slack.Blocks
+}
+
+func (v AddOverride) CallbackID() ViewType {
+	return VTAddOverride
+}
+
+func (v AddOverride) DefaultState() interface{} {
+	return &AddOverrideState{}
+}
+
+func (v AddOverride) BuildProps(ctx context.Context) (interface{}, error) {
+	l := zapctx.Logger(ctx)
+	rota, err := v.Repository.FindRotaByID(ctx, v.State.rotaID)
+	if err != nil {
+		l.Error("failed_to_find", zap.Error(err))
+		return nil, err
+	}
+
+	// Whoever opens the modal is most likely covering the rota themselves, starting from the next hour.
+	if v.State.overrideUser == "" {
+		v.State.overrideUser = v.State.UserID
+	}
+	if v.State.startsAt.IsZero() {
+		v.State.startsAt = time.Now().Truncate(time.Hour).Add(time.Hour)
+	}
+	if v.State.endsAt.IsZero() {
+		v.State.endsAt = v.State.startsAt.Add(24 * time.Hour)
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			block.NewMarkdownText(fmt.Sprintf("Pick who covers *%s* and when, the rota carries on as usual once they're done.", rota.Name)),
+			nil,
+			nil,
+		),
+		block.NewUserInput(block.UserInput{
+			BlockID: "OVERRIDE_USER",
+			Label:   "Covered by:",
+			UserID:  v.State.overrideUser,
+		}),
+		block.NewDateTimePicker(block.DateTimePicker{
+			BlockID: "OVERRIDE_START",
+			Label:   "From:",
+			Time:    v.State.startsAt,
+		}),
+		block.NewDateTimePicker(block.DateTimePicker{
+			BlockID: "OVERRIDE_END",
+			Label:   "Until:",
+			Time:    v.State.endsAt,
+		}),
+	}
+	return &AddOverrideProps{
+		title:  block.NewDefaultText("Add Override"),
+		submit: block.NewDefaultText("Add"),
+		close:  block.NewDefaultText("Cancel"),
+		blocks: slack.Blocks{BlockSet: blocks},
+	}, nil
+}
+
+func (v AddOverride) OnAction(ctx context.Context) (*gen.ActionResponse, error) {
+	// None of the fields dispatch actions, they're only read once the modal is submitted.
+	return &gen.ActionResponse{}, nil
+}
+
+func (v AddOverride) OnClose(ctx context.Context) (*gen.ActionResponse, error) {
+	zapctx.Logger(ctx).Debug("closing_view")
+	return &gen.ActionResponse{}, nil
+}
+
+func (v AddOverride) OnSubmit(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
+	if errs := v.validate(); len(errs) > 0 {
+		response := string(slack.RAErrors)
+		return &gen.ActionResponse{ResponseAction: &response, Errors: errs}, nil
+	}
+	overrideID, err := v.Repository.CreateOverride(ctx, db.CreateOverrideParams{
+		RotaID:   v.State.rotaID,
+		UserID:   v.State.overrideUser,
+		StartsAt: v.State.startsAt,
+		EndsAt:   v.State.endsAt,
+		Metadata: db.OverrideMetadata{CreatedBy: v.State.UserID},
+	})
+	if err != nil {
+		l.Error("failed_to_create_override", zap.Error(err))
+		return nil, err
+	}
+	l.Info("saved_override", zap.String("overrideId", overrideID))
+
+	h := Home{
+		Repository: v.Repository,
+		State: &HomeState{
+			TriggerID: v.State.TriggerID,
+			ChannelID: v.State.ChannelID,
+			TeamID:    v.State.TeamID,
+			rotaID:    v.State.rotaID,
+		},
+	}
+	if err = h.replace(ctx, v.State.externalID, v.State.previousViewID); err != nil {
+		return nil, err
+	}
+	return &gen.ActionResponse{}, nil
+}
+
+// validate returns the errors to display next to each of the fields of the modal.
+func (v AddOverride) validate() map[string]string {
+	errs := map[string]string{}
+	if v.State.overrideUser == "" {
+		errs["OVERRIDE_USER"] = "Pick who covers the rota."
+	}
+	if v.State.startsAt.IsZero() {
+		errs["OVERRIDE_START"] = "Pick when the override starts."
+	}
+	switch {
+	case v.State.endsAt.IsZero():
+		errs["OVERRIDE_END"] = "Pick when the override ends."
+	case !v.State.endsAt.After(v.State.startsAt):
+		errs["OVERRIDE_END"] = "The override must end after it starts."
+	case !v.State.endsAt.After(time.Now()):
+		errs["OVERRIDE_END"] = "The override must end in the future."
+	}
+	return errs
+}
+
+func (v AddOverride) Render(ctx context.Context, p interface{}) error {
+	l := zapctx.Logger(ctx)
+	props, ok := p.(*AddOverrideProps)
+	if !ok {
+		return errors.New("received invalid props")
+	}
+
+	bytes, err := json.Marshal(Metadata{RotaID: v.State.rotaID, ChannelID: v.State.ChannelID})
+	if err != nil {
+		l.Error("failed_to_marshal_metadata", zap.Error(err))
+		return err
+	}
+
+	view := slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Title:           props.title,
+		Submit:          props.submit,
+		Close:           props.close,
+		Blocks:          props.blocks,
+		CallbackID:      string(v.CallbackID()),
+		NotifyOnClose:   true,
+		ClearOnClose:    true,
+		PrivateMetadata: string(bytes),
+	}
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return err
+	}
+	_, err = client.OpenViewContext(ctx, v.State.TriggerID, view)
+	if err != nil {
+		l.Error("failed_to_open_view", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package views
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+
+	"github.com/rotabot-io/rotabot/internal"
+
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	"github.com/jackc/pgx/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/slack/slackclient/mock_slackclient"
+	"github.com/slack-go/slack"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"go.uber.org/mock/gomock"
+)
+
+var _ = Describe("AddOverride", func() {
+	var (
+		ctx         context.Context
+		sc          *mock_slackclient.MockSlackClient
+		repo        db.Repository
+		addOverride *AddOverride
+		conn        *pgx.Conn
+		rotaID      string
+		channelID   string
+		teamID      string
+		triggerID   string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		container, err := internal.RunContainer(ctx,
+			postgres.WithInitScripts(filepath.Join("..", "..", "assets", "structure.sql")),
+			testcontainers.WithWaitStrategy(internal.DefaultWaitStrategy()),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		connString, err := container.ConnectionString(ctx, "sslmode=disable")
+		Expect(err).ToNot(HaveOccurred())
+
+		conn, err = pgx.Connect(ctx, connString)
+		Expect(err).ToNot(HaveOccurred())
+
+		tx, err := conn.Begin(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		repo = db.New(tx)
+		channelID = "CH123"
+		teamID = "TM123"
+		triggerID = "TR123"
+
+		rotaID, err = repo.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+			Name:      "On Call",
+			TeamID:    teamID,
+			ChannelID: channelID,
+			Metadata: db.RotaMetadata{
+				Frequency:      db.RFWeekly,
+				SchedulingType: db.RSCreated,
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		addOverride = &AddOverride{
+			Repository: repo,
+			State: &AddOverrideState{
+				TriggerID: triggerID,
+				ChannelID: channelID,
+				TeamID:    teamID,
+				UserID:    "U123",
+				rotaID:    rotaID,
+			},
+		}
+
+		DeferCleanup(func() {
+			_ = container.Terminate(ctx)
+			_ = conn.Close(ctx)
+			_ = tx.Rollback(ctx)
+		})
+	})
+
+	// Create a mock and assign it to the sc variable at the start of each test
+	slackclient.MockSlackClient(&ctx, &sc, nil)
+
+	Describe("Callback", func() {
+		It("resolves an add override view", func() {
+			Expect(addOverride.CallbackID()).To(Equal(VTAddOverride))
+		})
+	})
+
+	Describe("BuildProps", func() {
+		It("suggests whoever opened the modal covers the rota from the next hour", func() {
+			p, err := addOverride.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(p).To(BeAssignableToTypeOf(&AddOverrideProps{}))
+			props := p.(*AddOverrideProps)
+			Expect(props.title.Text).To(Equal("Add Override"))
+			Expect(props.submit.Text).To(Equal("Add"))
+			Expect(props.blocks.BlockSet).To(HaveLen(4))
+
+			intro := props.blocks.BlockSet[0].(*slack.SectionBlock)
+			Expect(intro.Text.Text).To(ContainSubstring("*On Call*"))
+
+			user := props.blocks.BlockSet[1].(*slack.InputBlock)
+			Expect(user.BlockID).To(Equal("OVERRIDE_USER"))
+			Expect(user.Element.(*slack.SelectBlockElement).InitialUser).To(Equal("U123"))
+
+			start := props.blocks.BlockSet[2].(*slack.InputBlock)
+			Expect(start.BlockID).To(Equal("OVERRIDE_START"))
+			startsAt := time.Unix(start.Element.(*slack.DateTimePickerBlockElement).InitialDateTime, 0)
+			Expect(startsAt).To(BeTemporally("~", time.Now(), time.Hour))
+
+			end := props.blocks.BlockSet[3].(*slack.InputBlock)
+			Expect(end.BlockID).To(Equal("OVERRIDE_END"))
+			endsAt := time.Unix(end.Element.(*slack.DateTimePickerBlockElement).InitialDateTime, 0)
+			Expect(endsAt.Sub(startsAt)).To(Equal(24 * time.Hour))
+		})
+	})
+
+	Describe("OnSubmit", func() {
+		It("returns an error when the override ends before it starts", func() {
+			addOverride.State.overrideUser = "U456"
+			addOverride.State.startsAt = time.Now().Add(2 * time.Hour)
+			addOverride.State.endsAt = time.Now().Add(time.Hour)
+
+			res, err := addOverride.OnSubmit(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			expectedResAction := string(slack.RAErrors)
+			Expect(res).To(Equal(&gen.ActionResponse{
+				ResponseAction: &expectedResAction,
+				Errors: map[string]string{
+					"OVERRIDE_END": "The override must end after it starts.",
+				},
+			}))
+		})
+
+		It("returns an error when the override already ended", func() {
+			addOverride.State.overrideUser = "U456"
+			addOverride.State.startsAt = time.Now().Add(-2 * time.Hour)
+			addOverride.State.endsAt = time.Now().Add(-time.Hour)
+
+			res, err := addOverride.OnSubmit(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.Errors).To(Equal(map[string]string{
+				"OVERRIDE_END": "The override must end in the future.",
+			}))
+		})
+
+		It("saves the override and goes back to the home view", func() {
+			addOverride.State.overrideUser = "U456"
+			addOverride.State.startsAt = time.Now().Add(time.Hour).Truncate(time.Second)
+			addOverride.State.endsAt = time.Now().Add(25 * time.Hour).Truncate(time.Second)
+			addOverride.State.previousViewID = "V123"
+
+			sc.EXPECT().
+				UpdateViewContext(ctx, gomock.Any(), "", "", "V123").
+				DoAndReturn(func(_ context.Context, r slack.ModalViewRequest, _, _, _ string) (*slack.ViewResponse, error) {
+					var m Metadata
+					Expect(json.Unmarshal([]byte(r.PrivateMetadata), &m)).To(Succeed())
+					Expect(r.CallbackID).To(Equal(string(VTHome)))
+					Expect(m.ChannelID).To(Equal(channelID))
+					return nil, nil
+				}).Times(1)
+
+			res, err := addOverride.OnSubmit(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&gen.ActionResponse{}))
+
+			overrides, err := repo.ListOverridesByRotaID(ctx, db.ListOverridesByRotaIDParams{
+				RotaID: rotaID,
+				Since:  db.Timestamptz(time.Now()),
+				Until:  db.Timestamptz(time.Now().Add(48 * time.Hour)),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(overrides).To(HaveLen(1))
+			Expect(overrides[0].UserID).To(Equal("U456"))
+			Expect(overrides[0].StartsAt.Time).To(BeTemporally("==", addOverride.State.startsAt))
+			Expect(overrides[0].EndsAt.Time).To(BeTemporally("==", addOverride.State.endsAt))
+			Expect(overrides[0].Metadata.CreatedBy).To(Equal("U123"))
+		})
+	})
+})
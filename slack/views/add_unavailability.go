@@ -0,0 +1,244 @@
+package views
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/slack/block"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+	"github.com/slack-go/slack"
+)
+
+// AddUnavailability lets members record when they're out of office. Unavailabilities belong to the member rather
+// than to a rota, so every rota they're a member of skips them while they're away.
+type AddUnavailability struct {
+	Repository db.Repository
+	State      *AddUnavailabilityState
+}
+
+type AddUnavailabilityState struct {
+	TriggerID      string
+	ChannelID      string
+	TeamID         string
+	UserID         string
+	startsAt       time.Time
+	endsAt         time.Time
+	externalID     string
+	previousViewID string
+}
+
+type AddUnavailabilityProps struct {
+	title  *slack.TextBlockObject
+	submit *slack.TextBlockObject
+	close  *slack.TextBlockObject
+	blocks slack.Blocks
+}
+
+func (v AddUnavailability) CallbackID() ViewType {
+	return VTAddUnavailability
+}
+
+func (v AddUnavailability) DefaultState() interface{} {
+	return &AddUnavailabilityState{}
+}
+
+func (v AddUnavailability) BuildProps(ctx context.Context) (interface{}, error) {
+	// Time off is usually booked by the day, so the suggestion starts tomorrow and lasts a week.
+	if v.State.startsAt.IsZero() {
+		v.State.startsAt = time.Now().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	}
+	if v.State.endsAt.IsZero() {
+		v.State.endsAt = v.State.startsAt.Add(7 * 24 * time.Hour)
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			block.NewMarkdownText("Pick when you're away, none of your rotas will put you on duty in the meantime."),
+			nil,
+			nil,
+		),
+		block.NewDateTimePicker(block.DateTimePicker{
+			BlockID: "UNAVAILABILITY_START",
+			Label:   "From:",
+			Time:    v.State.startsAt,
+		}),
+		block.NewDateTimePicker(block.DateTimePicker{
+			BlockID: "UNAVAILABILITY_END",
+			Label:   "Until:",
+			Time:    v.State.endsAt,
+		}),
+	}
+	return &AddUnavailabilityProps{
+		title:  block.NewDefaultText("Out of Office"),
+		submit: block.NewDefaultText("Save"),
+		close:  block.NewDefaultText("Cancel"),
+		blocks: slack.Blocks{BlockSet: blocks},
+	}, nil
+}
+
+func (v AddUnavailability) OnAction(ctx context.Context) (*gen.ActionResponse, error) {
+	// None of the fields dispatch actions, they're only read once the modal is submitted.
+	return &gen.ActionResponse{}, nil
+}
+
+func (v AddUnavailability) OnClose(ctx context.Context) (*gen.ActionResponse, error) {
+	zapctx.Logger(ctx).Debug("closing_view")
+	return &gen.ActionResponse{}, nil
+}
+
+func (v AddUnavailability) OnSubmit(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
+	if errs := v.validate(); len(errs) > 0 {
+		response := string(slack.RAErrors)
+		return &gen.ActionResponse{ResponseAction: &response, Errors: errs}, nil
+	}
+	unavailabilityID, err := v.Repository.CreateUnavailability(ctx, db.CreateUnavailabilityParams{
+		TeamID:   v.State.TeamID,
+		UserID:   v.State.UserID,
+		StartsAt: v.State.startsAt,
+		EndsAt:   v.State.endsAt,
+		Metadata: db.UnavailabilityMetadata{},
+	})
+	if err != nil {
+		l.Error("failed_to_create_unavailability", zap.Error(err))
+		return nil, err
+	}
+	l.Info("saved_unavailability", zap.String("unavailabilityId", unavailabilityID))
+
+	h := Home{
+		Repository: v.Repository,
+		State: &HomeState{
+			TriggerID: v.State.TriggerID,
+			ChannelID: v.State.ChannelID,
+			TeamID:    v.State.TeamID,
+		},
+	}
+	if err = h.replace(ctx, v.State.externalID, v.State.previousViewID); err != nil {
+		return nil, err
+	}
+	return &gen.ActionResponse{}, nil
+}
+
+// validate returns the errors to display next to each of the fields of the modal.
+func (v AddUnavailability) validate() map[string]string {
+	errs := map[string]string{}
+	if v.State.startsAt.IsZero() {
+		errs["UNAVAILABILITY_START"] = "Pick when you're away from."
+	}
+	switch {
+	case v.State.endsAt.IsZero():
+		errs["UNAVAILABILITY_END"] = "Pick when you're back."
+	case !v.State.endsAt.After(v.State.startsAt):
+		errs["UNAVAILABILITY_END"] = "You must be back after you leave."
+	case !v.State.endsAt.After(time.Now()):
+		errs["UNAVAILABILITY_END"] = "You must be back in the future."
+	}
+	return errs
+}
+
+func (v AddUnavailability) Render(ctx context.Context, p interface{}) error {
+	l := zapctx.Logger(ctx)
+	props, ok := p.(*AddUnavailabilityProps)
+	if !ok {
+		return errors.New("received invalid props")
+	}
+
+	bytes, err := json.Marshal(Metadata{ChannelID: v.State.ChannelID})
+	if err != nil {
+		l.Error("failed_to_marshal_metadata", zap.Error(err))
+		return err
+	}
+
+	view := slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Title:           props.title,
+		Submit:          props.submit,
+		Close:           props.close,
+		Blocks:          props.blocks,
+		CallbackID:      string(v.CallbackID()),
+		NotifyOnClose:   true,
+		ClearOnClose:    true,
+		PrivateMetadata: string(bytes),
+	}
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return err
+	}
+	_, err = client.OpenViewContext(ctx, v.State.TriggerID, view)
+	if err != nil {
+		l.Error("failed_to_open_view", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// unavailabilityNotice flags the given users that are out during the current or next shift of the rota, it's
+// empty when none of them are.
+func unavailabilityNotice(ctx context.Context, repo db.Repository, rota db.Rota, userIDs []string) (string, error) {
+	l := zapctx.Logger(ctx)
+	if len(userIDs) == 0 {
+		return "", nil
+	}
+	engine, err := rotation.Load(ctx, repo, rota, rotation.SystemClock)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	shifts, err := engine.Shifts(now, 2)
+	if err != nil {
+		// Rotas that can't be scheduled don't have shifts anyone could be out during.
+		l.Debug("unable_to_schedule_rota", zap.Error(err))
+		return "", nil
+	}
+	current, next := shifts[0], shifts[1]
+	if current.Start.After(now) {
+		// Nobody is on duty right now, so the first shift is already the next one.
+		current, next = rotation.Shift{}, shifts[0]
+	}
+
+	unavailabilities, err := repo.ListUnavailabilitiesByUserIDs(ctx, db.ListUnavailabilitiesByUserIDsParams{
+		TeamID:  rota.TeamID,
+		UserIds: userIDs,
+		Since:   db.Timestamptz(shifts[0].Start),
+		Until:   db.Timestamptz(next.End),
+	})
+	if err != nil {
+		l.Error("failed_to_list_unavailabilities", zap.Error(err))
+		return "", err
+	}
+	out := func(userID string, s rotation.Shift) bool {
+		for _, u := range unavailabilities {
+			if u.UserID == userID && u.StartsAt.Time.Before(s.End) && u.EndsAt.Time.After(s.Start) {
+				return true
+			}
+		}
+		return false
+	}
+
+	lines := []string{}
+	for _, userID := range userIDs {
+		switch outNow, outNext := out(userID, current), out(userID, next); {
+		case outNow && outNext:
+			lines = append(lines, ":palm_tree: <@"+userID+"> is out during the current and next shifts.")
+		case outNow:
+			lines = append(lines, ":palm_tree: <@"+userID+"> is out during the current shift.")
+		case outNext:
+			lines = append(lines, ":palm_tree: <@"+userID+"> is out during the next shift.")
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
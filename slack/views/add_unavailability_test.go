@@ -0,0 +1,156 @@
+package views
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+
+	"github.com/rotabot-io/rotabot/internal"
+
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	"github.com/jackc/pgx/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/slack/slackclient/mock_slackclient"
+	"github.com/slack-go/slack"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"go.uber.org/mock/gomock"
+)
+
+var _ = Describe("AddUnavailability", func() {
+	var (
+		ctx               context.Context
+		sc                *mock_slackclient.MockSlackClient
+		repo              db.Repository
+		addUnavailability *AddUnavailability
+		conn              *pgx.Conn
+		channelID         string
+		teamID            string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		container, err := internal.RunContainer(ctx,
+			postgres.WithInitScripts(filepath.Join("..", "..", "assets", "structure.sql")),
+			testcontainers.WithWaitStrategy(internal.DefaultWaitStrategy()),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		connString, err := container.ConnectionString(ctx, "sslmode=disable")
+		Expect(err).ToNot(HaveOccurred())
+
+		conn, err = pgx.Connect(ctx, connString)
+		Expect(err).ToNot(HaveOccurred())
+
+		tx, err := conn.Begin(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		repo = db.New(tx)
+		channelID = "CH123"
+		teamID = "TM123"
+
+		addUnavailability = &AddUnavailability{
+			Repository: repo,
+			State: &AddUnavailabilityState{
+				TriggerID: "TR123",
+				ChannelID: channelID,
+				TeamID:    teamID,
+				UserID:    "U123",
+			},
+		}
+
+		DeferCleanup(func() {
+			_ = container.Terminate(ctx)
+			_ = conn.Close(ctx)
+			_ = tx.Rollback(ctx)
+		})
+	})
+
+	// Create a mock and assign it to the sc variable at the start of each test
+	slackclient.MockSlackClient(&ctx, &sc, nil)
+
+	Describe("Callback", func() {
+		It("resolves an add unavailability view", func() {
+			Expect(addUnavailability.CallbackID()).To(Equal(VTAddUnavailability))
+		})
+	})
+
+	Describe("BuildProps", func() {
+		It("suggests a week off starting tomorrow", func() {
+			p, err := addUnavailability.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(p).To(BeAssignableToTypeOf(&AddUnavailabilityProps{}))
+			props := p.(*AddUnavailabilityProps)
+			Expect(props.title.Text).To(Equal("Out of Office"))
+			Expect(props.submit.Text).To(Equal("Save"))
+			Expect(props.blocks.BlockSet).To(HaveLen(3))
+
+			start := props.blocks.BlockSet[1].(*slack.InputBlock)
+			Expect(start.BlockID).To(Equal("UNAVAILABILITY_START"))
+			startsAt := time.Unix(start.Element.(*slack.DateTimePickerBlockElement).InitialDateTime, 0)
+			Expect(startsAt).To(BeTemporally(">", time.Now()))
+
+			end := props.blocks.BlockSet[2].(*slack.InputBlock)
+			Expect(end.BlockID).To(Equal("UNAVAILABILITY_END"))
+			endsAt := time.Unix(end.Element.(*slack.DateTimePickerBlockElement).InitialDateTime, 0)
+			Expect(endsAt.Sub(startsAt)).To(Equal(7 * 24 * time.Hour))
+		})
+	})
+
+	Describe("OnSubmit", func() {
+		It("returns an error when the unavailability ends before it starts", func() {
+			addUnavailability.State.startsAt = time.Now().Add(2 * time.Hour)
+			addUnavailability.State.endsAt = time.Now().Add(time.Hour)
+
+			res, err := addUnavailability.OnSubmit(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			expectedResAction := string(slack.RAErrors)
+			Expect(res).To(Equal(&gen.ActionResponse{
+				ResponseAction: &expectedResAction,
+				Errors: map[string]string{
+					"UNAVAILABILITY_END": "You must be back after you leave.",
+				},
+			}))
+		})
+
+		It("saves the unavailability against the user and goes back to the home view", func() {
+			addUnavailability.State.startsAt = time.Now().Add(time.Hour).Truncate(time.Second)
+			addUnavailability.State.endsAt = time.Now().Add(25 * time.Hour).Truncate(time.Second)
+			addUnavailability.State.previousViewID = "V123"
+
+			sc.EXPECT().
+				UpdateViewContext(ctx, gomock.Any(), "", "", "V123").
+				DoAndReturn(func(_ context.Context, r slack.ModalViewRequest, _, _, _ string) (*slack.ViewResponse, error) {
+					var m Metadata
+					Expect(json.Unmarshal([]byte(r.PrivateMetadata), &m)).To(Succeed())
+					Expect(r.CallbackID).To(Equal(string(VTHome)))
+					Expect(m.ChannelID).To(Equal(channelID))
+					return nil, nil
+				}).Times(1)
+
+			res, err := addUnavailability.OnSubmit(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&gen.ActionResponse{}))
+
+			unavailabilities, err := repo.ListUnavailabilitiesByUserIDs(ctx, db.ListUnavailabilitiesByUserIDsParams{
+				TeamID:  teamID,
+				UserIds: []string{"U123"},
+				Since:   db.Timestamptz(time.Now()),
+				Until:   db.Timestamptz(time.Now().Add(48 * time.Hour)),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unavailabilities).To(HaveLen(1))
+			Expect(unavailabilities[0].StartsAt.Time).To(BeTemporally("==", addUnavailability.State.startsAt))
+			Expect(unavailabilities[0].EndsAt.Time).To(BeTemporally("==", addUnavailability.State.endsAt))
+		})
+	})
+})
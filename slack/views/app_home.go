@@ -0,0 +1,251 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rotabot-io/rotabot/slack/announce"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/slack/block"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/goaerrors"
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+	"github.com/slack-go/slack"
+)
+
+// AppHomeAction defines the list of possible actions on the app home tab
+type AppHomeAction string
+
+const (
+	AHEditRota = AppHomeAction("APP_HOME_EDIT_ROTA")
+
+	// appHomeLookahead is how many shifts of each rota are looked through for the next shift of the user.
+	appHomeLookahead = 100
+)
+
+// AppHome is the Home tab of the app, it lists the rotas the user is a member of across every channel of the
+// workspace and when they're next on duty. Unlike the other views it isn't a modal, it's published for the user
+// whenever they open the tab.
+type AppHome struct {
+	Repository db.Repository
+	// Clock resolves "now", to tell whether the user is on duty and when they're next.
+	Clock rotation.Clock
+	State *AppHomeState
+}
+
+type AppHomeState struct {
+	TriggerID string
+	TeamID    string
+	UserID    string
+	rotaID    string
+	action    AppHomeAction
+}
+
+type AppHomeProps struct {
+	blocks slack.Blocks
+}
+
+// NewAppHome returns the Home tab of the given user.
+func NewAppHome(repo db.Repository, clock rotation.Clock, teamID, userID string) AppHome {
+	v := AppHome{Repository: repo, Clock: clock}
+	v.State = v.DefaultState().(*AppHomeState)
+	v.State.TeamID = teamID
+	v.State.UserID = userID
+	return v
+}
+
+func (v AppHome) CallbackID() ViewType {
+	return VTAppHome
+}
+
+func (v AppHome) DefaultState() interface{} {
+	return &AppHomeState{}
+}
+
+func (v AppHome) BuildProps(ctx context.Context) (interface{}, error) {
+	l := zapctx.Logger(ctx)
+	rotas, err := v.Repository.ListRotasByUserID(ctx, db.ListRotasByUserIDParams{UserID: v.State.UserID, TeamID: v.State.TeamID})
+	if err != nil {
+		l.Error("failed to list rotas", zap.Error(err))
+		return nil, errors.New("failed to list rotas")
+	}
+
+	blocks := []slack.Block{block.NewHeader("Your Rotas")}
+	if len(rotas) == 0 {
+		blocks = append(blocks, slack.NewSectionBlock(
+			block.NewMarkdownText("You aren't a member of any rota yet, use `/rotabot` in a channel to create one."),
+			nil,
+			nil,
+		))
+	}
+	for _, rota := range rotas {
+		shift, err := v.shiftNotice(ctx, rota)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, slack.NewSectionBlock(
+			block.NewMarkdownText(fmt.Sprintf("*%s* in <#%s>\n%s", rota.Name, rota.ChannelID, shift)),
+			nil,
+			slack.NewAccessory(block.NewButton(block.Button{
+				Text:     "Edit Rota",
+				ActionID: string(AHEditRota),
+				Value:    rota.ID,
+			})),
+		))
+	}
+	return &AppHomeProps{blocks: slack.Blocks{BlockSet: blocks}}, nil
+}
+
+// shiftNotice tells the user when they're next on duty for the rota, only active rotas have anyone on duty.
+func (v AppHome) shiftNotice(ctx context.Context, rota db.Rota) (string, error) {
+	switch rota.Status {
+	case db.RTPaused:
+		return ":double_vertical_bar: Paused", nil
+	case db.RTEnded:
+		return ":checkered_flag: Ended", nil
+	case db.RTArchived:
+		return ":file_cabinet: Archived", nil
+	}
+	shift, tier, ok, err := nextShift(ctx, v.Repository, v.Clock, rota, v.State.UserID)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return ":zzz: You have no upcoming shift", nil
+	}
+	as := ""
+	if rotation.Tiers(rota) > 1 {
+		as = fmt.Sprintf(" as *%s*", rotation.TierName(tier))
+	}
+	if !shift.Start.After(v.Clock.Now()) {
+		return fmt.Sprintf(":rotating_light: You're on duty%s until %s", as, announce.FormatTime(shift.End)), nil
+	}
+	return fmt.Sprintf(":calendar: You're next on duty%s from %s", as, announce.FormatTime(shift.Start)), nil
+}
+
+// nextShift returns the shift of the user that is taking place right now or, when they aren't on duty, the next
+// one in any tier of the rota. Rotas that can't be scheduled have no shifts.
+func nextShift(ctx context.Context, repo db.Repository, clock rotation.Clock, rota db.Rota, userID string) (rotation.Shift, int, bool, error) {
+	l := zapctx.Logger(ctx)
+	engine, err := rotation.Load(ctx, repo, rota, clock)
+	if err != nil {
+		return rotation.Shift{}, 0, false, err
+	}
+	var (
+		next  rotation.Shift
+		tier  int
+		found bool
+	)
+	for t := 0; t < rotation.Tiers(rota); t++ {
+		e, err := engine.Tier(t)
+		if err != nil {
+			return rotation.Shift{}, 0, false, err
+		}
+		shifts, err := e.Shifts(clock.Now(), appHomeLookahead)
+		if err != nil {
+			l.Debug("unable_to_schedule_rota", zap.String("rota_id", rota.ID), zap.Error(err))
+			return rotation.Shift{}, 0, false, nil
+		}
+		for _, s := range shifts {
+			if s.UserID == userID {
+				if !found || s.Start.Before(next.Start) {
+					next, tier, found = s, t, true
+				}
+				break
+			}
+		}
+	}
+	return next, tier, found, nil
+}
+
+func (v AppHome) OnAction(ctx context.Context) (*gen.ActionResponse, error) {
+	switch v.State.action {
+	case AHEditRota:
+		return v.handleEditRotaAction(ctx)
+	default:
+		zapctx.Logger(ctx).Warn("unknown_action", zap.String("action", string(v.State.action)))
+		sentry.CaptureMessage("unknown_action")
+		return nil, errors.New("unknown_action")
+	}
+}
+
+func (v AppHome) OnClose(ctx context.Context) (*gen.ActionResponse, error) {
+	zapctx.Logger(ctx).Error("closing_app_home")
+	return nil, goaerrors.NewInternalError()
+}
+
+func (v AppHome) OnSubmit(ctx context.Context) (*gen.ActionResponse, error) {
+	zapctx.Logger(ctx).Error("submitting_app_home")
+	return nil, goaerrors.NewInternalError()
+}
+
+// Render publishes the Home tab of the user, replacing whatever it showed before.
+func (v AppHome) Render(ctx context.Context, p interface{}) error {
+	l := zapctx.Logger(ctx)
+	props, ok := p.(*AppHomeProps)
+	if !ok {
+		l.Error("received_invalid_props")
+		return errors.New("received invalid props")
+	}
+
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return err
+	}
+
+	_, err = client.PublishViewContext(ctx, v.State.UserID, slack.HomeTabViewRequest{
+		Type:       slack.VTHomeTab,
+		Blocks:     props.blocks,
+		CallbackID: string(v.CallbackID()),
+	}, "")
+	if err != nil {
+		l.Error("failed_to_publish_view", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// handleEditRotaAction opens the modal to edit the rota. There's no modal to push it on top of, so once it's
+// submitted the user is left on the Home tab.
+func (v AppHome) handleEditRotaAction(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
+	rota, err := v.Repository.FindRotaByID(ctx, v.State.rotaID)
+	if err != nil {
+		l.Error("failed_to_find", zap.Error(err))
+		return nil, err
+	}
+	if rota.TeamID != v.State.TeamID {
+		l.Warn("rota_of_another_team", zap.String("rota_id", rota.ID))
+		return nil, errors.New("rota of another team")
+	}
+
+	view := SaveRota{
+		Repository: v.Repository,
+	}
+	view.State = view.DefaultState().(*SaveRotaState)
+	view.State.TriggerID = v.State.TriggerID
+	view.State.ChannelID = rota.ChannelID
+	view.State.TeamID = v.State.TeamID
+	view.State.UserID = v.State.UserID
+	view.State.rotaID = rota.ID
+
+	props, err := view.BuildProps(ctx)
+	if err != nil {
+		l.Error("failed to build props", zap.Error(err))
+		return nil, errors.New("failed to build save rota props")
+	}
+	if err = view.Render(ctx, props); err != nil {
+		return nil, err
+	}
+	return &gen.ActionResponse{}, nil
+}
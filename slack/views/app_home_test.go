@@ -0,0 +1,175 @@
+package views
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+
+	"github.com/rotabot-io/rotabot/internal"
+
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	"github.com/jackc/pgx/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/slack/slackclient/mock_slackclient"
+	"github.com/slack-go/slack"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"go.uber.org/mock/gomock"
+)
+
+var _ = Describe("AppHome", func() {
+	var (
+		ctx     context.Context
+		sc      *mock_slackclient.MockSlackClient
+		repo    db.Repository
+		appHome AppHome
+		conn    *pgx.Conn
+	)
+
+	createRota := func(name, channelID string, userIDs ...string) string {
+		id, err := repo.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+			Name:      name,
+			TeamID:    "TM123",
+			ChannelID: channelID,
+			Metadata: db.RotaMetadata{
+				Frequency:      db.RFWeekly,
+				SchedulingType: db.RSCreated,
+				TimeZone:       "UTC",
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		members := []db.Member{}
+		for _, userID := range userIDs {
+			members = append(members, db.Member{RotaID: id, UserID: userID})
+		}
+		Expect(repo.UpdateRotaMembers(ctx, members)).To(Succeed())
+		return id
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		container, err := internal.RunContainer(ctx,
+			postgres.WithInitScripts(filepath.Join("..", "..", "assets", "structure.sql")),
+			testcontainers.WithWaitStrategy(internal.DefaultWaitStrategy()),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		connString, err := container.ConnectionString(ctx, "sslmode=disable")
+		Expect(err).ToNot(HaveOccurred())
+
+		conn, err = pgx.Connect(ctx, connString)
+		Expect(err).ToNot(HaveOccurred())
+
+		tx, err := conn.Begin(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		repo = db.New(tx)
+		appHome = NewAppHome(repo, rotation.SystemClock, "TM123", "bob")
+		appHome.State.TriggerID = "TR123"
+
+		DeferCleanup(func() {
+			_ = container.Terminate(ctx)
+			_ = conn.Close(ctx)
+			_ = tx.Rollback(ctx)
+		})
+	})
+
+	// Create a mock and assign it to the sc variable at the start of each test
+	slackclient.MockSlackClient(&ctx, &sc, nil)
+
+	Describe("BuildProps", func() {
+		It("tells how to create a rota when the user is on none", func() {
+			createRota("On Call", "CH123", "alice")
+
+			p, err := appHome.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			blocks := p.(*AppHomeProps).blocks.BlockSet
+			Expect(blocks).To(HaveLen(2))
+			Expect(blocks[1].(*slack.SectionBlock).Text.Text).To(ContainSubstring("`/rotabot`"))
+		})
+
+		It("lists the rotas of the user across channels with their next shift", func() {
+			createRota("On Call", "CH123", "bob", "alice")
+			deploys := createRota("Deploys", "CH456", "alice", "bob")
+			Expect(repo.UpdateRotaStatus(ctx, db.UpdateRotaStatusParams{ID: createRota("Support", "CH123", "bob"), Status: db.RTPaused})).To(Succeed())
+
+			p, err := appHome.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			blocks := p.(*AppHomeProps).blocks.BlockSet
+			Expect(blocks).To(HaveLen(4))
+			Expect(blocks[0].(*slack.SectionBlock).Text.Text).To(Equal("Your Rotas"))
+
+			first := blocks[1].(*slack.SectionBlock)
+			Expect(first.Text.Text).To(HavePrefix("*Deploys* in <#CH456>\n:calendar: You're next on duty from <!date^"))
+			button := first.Accessory.ButtonElement
+			Expect(button.ActionID).To(Equal(string(AHEditRota)))
+			Expect(button.Value).To(Equal(deploys))
+
+			Expect(blocks[2].(*slack.SectionBlock).Text.Text).To(HavePrefix("*On Call* in <#CH123>\n:rotating_light: You're on duty until <!date^"))
+			Expect(blocks[3].(*slack.SectionBlock).Text.Text).To(Equal("*Support* in <#CH123>\n:double_vertical_bar: Paused"))
+		})
+
+		It("tells the user they're on duty once their shift starts", func() {
+			createRota("On Call", "CH123", "bob", "alice")
+			createRota("Deploys", "CH456", "alice", "bob")
+			appHome.Clock = rotation.FixedClock(time.Now().Add(7*24*time.Hour + time.Hour))
+
+			p, err := appHome.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			blocks := p.(*AppHomeProps).blocks.BlockSet
+			Expect(blocks).To(HaveLen(3))
+			Expect(blocks[1].(*slack.SectionBlock).Text.Text).To(HavePrefix("*Deploys* in <#CH456>\n:rotating_light: You're on duty until <!date^"))
+			Expect(blocks[2].(*slack.SectionBlock).Text.Text).To(HavePrefix("*On Call* in <#CH123>\n:calendar: You're next on duty from <!date^"))
+		})
+	})
+
+	Describe("Render", func() {
+		It("publishes the home tab of the user", func() {
+			sc.EXPECT().PublishViewContext(ctx, "bob", gomock.Cond(func(x any) bool {
+				view := x.(slack.HomeTabViewRequest)
+				return Expect(view.Type).To(Equal(slack.VTHomeTab)) &&
+					Expect(view.CallbackID).To(Equal(string(VTAppHome)))
+			}), "").Return(nil, nil).Times(1)
+
+			p, err := appHome.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(appHome.Render(ctx, p)).To(Succeed())
+		})
+	})
+
+	Describe("OnAction", func() {
+		It("opens the modal to edit the rota", func() {
+			appHome.State.action = AHEditRota
+			appHome.State.rotaID = createRota("On Call", "CH123", "bob")
+
+			sc.EXPECT().OpenViewContext(ctx, "TR123", gomock.Cond(func(x any) bool {
+				view := x.(slack.ModalViewRequest)
+				var m Metadata
+				Expect(json.Unmarshal([]byte(view.PrivateMetadata), &m)).To(Succeed())
+				return Expect(view.CallbackID).To(Equal(string(VTSaveRota))) &&
+					Expect(view.Title.Text).To(Equal("Update Rota")) &&
+					Expect(m).To(Equal(Metadata{RotaID: appHome.State.rotaID, ChannelID: "CH123"}))
+			})).Return(nil, nil).Times(1)
+
+			_, err := appHome.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns an error when the action is unknown", func() {
+			appHome.State.action = "unknown"
+
+			_, err := appHome.OnAction(ctx)
+			Expect(err).To(MatchError("unknown_action"))
+		})
+	})
+})
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/rotabot-io/rotabot/slack/slackclient"
 
@@ -13,6 +15,7 @@ import (
 
 	"github.com/rotabot-io/rotabot/lib/db"
 	"github.com/rotabot-io/rotabot/lib/goaerrors"
+	"github.com/rotabot-io/rotabot/lib/rotation"
 	"github.com/rotabot-io/rotabot/lib/zapctx"
 	"github.com/rotabot-io/rotabot/slack/block"
 	"github.com/slack-go/slack"
@@ -26,7 +29,16 @@ type HomeAction string
 type HomeSection string
 
 const (
-	HASaveRota = HomeAction("HOME_SAVE_ROTA")
+	HASaveRota       = HomeAction("HOME_SAVE_ROTA")
+	HASwapShift      = HomeAction("HOME_SWAP_SHIFT")
+	HASkipShift      = HomeAction("HOME_SKIP_SHIFT")
+	HAReorderMembers = HomeAction("HOME_REORDER_MEMBERS")
+	HAViewSchedule   = HomeAction("HOME_VIEW_SCHEDULE")
+	HAPauseRota      = HomeAction("HOME_PAUSE_ROTA")
+	HAResumeRota     = HomeAction("HOME_RESUME_ROTA")
+	HAEndRota        = HomeAction("HOME_END_ROTA")
+	// HAAddUnavailability isn't about any rota in particular, it applies to all the rotas of whoever clicked it.
+	HAAddUnavailability = HomeAction("HOME_ADD_UNAVAILABILITY")
 
 	HSHomeActions = HomeSection("HOME_ACTIONS")
 	HSRota        = HomeSection("ROTA_ELEMENT")
@@ -34,15 +46,19 @@ const (
 
 type Home struct {
 	Repository db.Repository
-	State      *HomeState
+	// Clock resolves "now", the system clock is used when it's nil.
+	Clock rotation.Clock
+	State *HomeState
 }
 
 type HomeState struct {
 	TriggerID string
 	ChannelID string
 	TeamID    string
+	UserID    string
 	action    HomeAction
 	rotaID    string
+	viewID    string
 }
 
 type HomeProps struct {
@@ -71,22 +87,28 @@ func (v Home) BuildProps(ctx context.Context) (interface{}, error) {
 		slack.NewActionBlock(
 			string(HSHomeActions),
 			block.NewButton(block.Button{Text: "Add Rota :heavy_plus_sign:", ActionID: string(HASaveRota)}),
+			block.NewButton(block.Button{Text: "Out of Office :palm_tree:", ActionID: string(HAAddUnavailability)}),
 		),
-		block.NewHeader("Active Rotas:"),
-	}
-	for _, rota := range rotas {
-		blocks = append(
-			blocks, block.NewOverflowSectionElement(
-				block.OverflowSection{
-					ElementID:   rota.ID,
-					ElementName: rota.Name,
-					SectionName: string(HSRota),
-					Actions: []block.OverflowAction{
-						{Name: ":spiral_note_pad: Edit Rota", Action: string(HASaveRota)},
-					},
-				},
-			),
-		)
+	}
+	for _, group := range rotaGroups {
+		grouped := []db.Rota{}
+		for _, rota := range rotas {
+			if rota.Status == group.status {
+				grouped = append(grouped, rota)
+			}
+		}
+		// There's always a header for the active rotas, even when there are none, so the view isn't empty.
+		if len(grouped) == 0 && group.status != db.RTActive {
+			continue
+		}
+		blocks = append(blocks, block.NewHeader(group.header))
+		for _, rota := range grouped {
+			rotaBlocks, err := v.rotaBlocks(ctx, rota)
+			if err != nil {
+				return nil, err
+			}
+			blocks = append(blocks, rotaBlocks...)
+		}
 	}
 	return &HomeProps{
 		title:  block.NewDefaultText("Rotabot Home"),
@@ -94,10 +116,135 @@ func (v Home) BuildProps(ctx context.Context) (interface{}, error) {
 	}, nil
 }
 
+// rotaGroups are the sections rotas are listed under in the home view, depending on their status.
+var rotaGroups = []struct {
+	status db.RotaStatus
+	header string
+}{
+	{status: db.RTActive, header: "Active Rotas:"},
+	{status: db.RTPaused, header: "Paused Rotas:"},
+	{status: db.RTEnded, header: "Ended Rotas:"},
+	{status: db.RTArchived, header: "Archived Rotas:"},
+}
+
+// rotaBlocks returns the blocks listing the rota in the home view. Only active rotas have someone on duty, so
+// they're the only ones that tell who is on duty and who is out of office.
+func (v Home) rotaBlocks(ctx context.Context, rota db.Rota) ([]slack.Block, error) {
+	l := zapctx.Logger(ctx)
+	blocks := []slack.Block{
+		block.NewOverflowSectionElement(
+			block.OverflowSection{
+				ElementID:   rota.ID,
+				ElementName: rota.Name,
+				SectionName: string(HSRota),
+				Actions:     rotaActions(rota),
+			},
+		),
+	}
+	if rota.Status != db.RTActive {
+		return blocks, nil
+	}
+	tiers, err := tiersNotice(ctx, v.Repository, v.clock(), rota)
+	if err != nil {
+		return nil, err
+	}
+	if tiers != "" {
+		blocks = append(blocks, slack.NewContextBlock("", block.NewMarkdownText(tiers)))
+	}
+	userIDs, err := v.Repository.ListUserIDsByRotaID(ctx, rota.ID)
+	if err != nil {
+		l.Error("failed_to_list_members", zap.Error(err))
+		return nil, err
+	}
+	notice, err := unavailabilityNotice(ctx, v.Repository, rota, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	if notice != "" {
+		blocks = append(blocks, slack.NewContextBlock("", block.NewMarkdownText(notice)))
+	}
+	return blocks, nil
+}
+
+// rotaActions returns what can be done with the rota given its status. Slack allows at most 5 options in an
+// overflow menu, so active rotas are paused before they can be ended by hand and have their members reordered, or
+// overrides added, from their schedule.
+func rotaActions(rota db.Rota) []block.OverflowAction {
+	edit := block.OverflowAction{Name: ":spiral_note_pad: Edit Rota", Action: string(HASaveRota)}
+	reorder := block.OverflowAction{Name: ":arrow_up_down: Reorder Members", Action: string(HAReorderMembers)}
+	resume := block.OverflowAction{Name: ":arrow_forward: Resume Rota", Action: string(HAResumeRota)}
+	switch rota.Status {
+	case db.RTPaused:
+		return []block.OverflowAction{
+			edit,
+			reorder,
+			resume,
+			{Name: ":checkered_flag: End Rota", Action: string(HAEndRota)},
+		}
+	case db.RTEnded:
+		return []block.OverflowAction{edit, resume}
+	case db.RTArchived:
+		// The channel of archived rotas is gone, there's nowhere to announce their handovers if they were resumed.
+		return []block.OverflowAction{edit}
+	default:
+		return []block.OverflowAction{
+			edit,
+			{Name: ":calendar: View Schedule", Action: string(HAViewSchedule)},
+			{Name: ":left_right_arrow: Swap Shift", Action: string(HASwapShift)},
+			{Name: ":fast_forward: Skip Shift", Action: string(HASkipShift)},
+			{Name: ":double_vertical_bar: Pause Rota", Action: string(HAPauseRota)},
+		}
+	}
+}
+
+// tiersNotice lists who is on duty right now in each tier of the rota, it's empty for rotas with a single tier
+// since there's nothing to tell apart.
+func tiersNotice(ctx context.Context, repo db.Repository, clock rotation.Clock, rota db.Rota) (string, error) {
+	l := zapctx.Logger(ctx)
+	if rotation.Tiers(rota) < 2 {
+		return "", nil
+	}
+	engine, err := rotation.Load(ctx, repo, rota, clock)
+	if err != nil {
+		return "", err
+	}
+	lines := []string{}
+	for tier := 0; tier < rotation.Tiers(rota); tier++ {
+		e, err := engine.Tier(tier)
+		if err != nil {
+			return "", err
+		}
+		shift, err := e.Current()
+		if err != nil {
+			// Rotas that can't be scheduled, or have nobody on duty right now, have no one to list in the tier.
+			l.Debug("unable_to_schedule_tier", zap.Int("tier", tier), zap.Error(err))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf(":busts_in_silhouette: *%s:* <@%s>", rotation.TierName(tier), shift.UserID))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 func (v Home) OnAction(ctx context.Context) (*gen.ActionResponse, error) {
 	switch v.State.action {
 	case HASaveRota:
 		return v.handleAddRotaAction(ctx)
+	case HASwapShift:
+		return v.handleSwapShiftAction(ctx)
+	case HASkipShift:
+		return v.handleSkipShiftAction(ctx)
+	case HAReorderMembers:
+		return v.handleReorderMembersAction(ctx)
+	case HAViewSchedule:
+		return v.handleViewScheduleAction(ctx)
+	case HAAddUnavailability:
+		return v.handleAddUnavailabilityAction(ctx)
+	case HAPauseRota:
+		return v.handleStatusAction(ctx, db.RTPaused)
+	case HAResumeRota:
+		return v.handleResumeRotaAction(ctx)
+	case HAEndRota:
+		return v.handleStatusAction(ctx, db.RTEnded)
 	default:
 		zapctx.Logger(ctx).Warn("unknown_action", zap.String("action", string(v.State.action)))
 		sentry.CaptureMessage("unknown_action")
@@ -160,6 +307,7 @@ func (v Home) handleAddRotaAction(ctx context.Context) (*gen.ActionResponse, err
 	view.State = view.DefaultState().(*SaveRotaState)
 	view.State.ChannelID = v.State.ChannelID
 	view.State.TeamID = v.State.TeamID
+	view.State.UserID = v.State.UserID
 	view.State.rotaID = v.State.rotaID
 
 	p, err := view.BuildProps(ctx)
@@ -173,6 +321,261 @@ func (v Home) handleAddRotaAction(ctx context.Context) (*gen.ActionResponse, err
 		return nil, errors.New("received invalid props")
 	}
 
+	return v.push(ctx, slack.ModalViewRequest{
+		Title:      props.title,
+		Close:      props.close,
+		Submit:     props.submit,
+		Blocks:     props.blocks,
+		CallbackID: string(view.CallbackID()),
+	})
+}
+
+func (v Home) handleAddOverrideAction(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
+	view := AddOverride{
+		Repository: v.Repository,
+	}
+	view.State = view.DefaultState().(*AddOverrideState)
+	view.State.ChannelID = v.State.ChannelID
+	view.State.TeamID = v.State.TeamID
+	view.State.UserID = v.State.UserID
+	view.State.rotaID = v.State.rotaID
+
+	p, err := view.BuildProps(ctx)
+	if err != nil {
+		l.Error("failed to build props", zap.Error(err))
+		return nil, errors.New("failed to build add override props")
+	}
+	props, ok := p.(*AddOverrideProps)
+	if !ok {
+		l.Error("received_invalid_props")
+		return nil, errors.New("received invalid props")
+	}
+
+	return v.push(ctx, slack.ModalViewRequest{
+		Title:      props.title,
+		Close:      props.close,
+		Submit:     props.submit,
+		Blocks:     props.blocks,
+		CallbackID: string(view.CallbackID()),
+	})
+}
+
+func (v Home) handleSwapShiftAction(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
+	view := SwapShift{
+		Repository: v.Repository,
+	}
+	view.State = view.DefaultState().(*SwapShiftState)
+	view.State.ChannelID = v.State.ChannelID
+	view.State.TeamID = v.State.TeamID
+	view.State.UserID = v.State.UserID
+	view.State.rotaID = v.State.rotaID
+
+	p, err := view.BuildProps(ctx)
+	if err != nil {
+		l.Error("failed to build props", zap.Error(err))
+		return nil, errors.New("failed to build swap shift props")
+	}
+	props, ok := p.(*SwapShiftProps)
+	if !ok {
+		l.Error("received_invalid_props")
+		return nil, errors.New("received invalid props")
+	}
+
+	return v.push(ctx, slack.ModalViewRequest{
+		Title:      props.title,
+		Close:      props.close,
+		Submit:     props.submit,
+		Blocks:     props.blocks,
+		CallbackID: string(view.CallbackID()),
+	})
+}
+
+func (v Home) handleSkipShiftAction(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
+	view := SkipShift{
+		Repository: v.Repository,
+	}
+	view.State = view.DefaultState().(*SkipShiftState)
+	view.State.ChannelID = v.State.ChannelID
+	view.State.TeamID = v.State.TeamID
+	view.State.UserID = v.State.UserID
+	view.State.rotaID = v.State.rotaID
+
+	p, err := view.BuildProps(ctx)
+	if err != nil {
+		l.Error("failed to build props", zap.Error(err))
+		return nil, errors.New("failed to build skip shift props")
+	}
+	props, ok := p.(*SkipShiftProps)
+	if !ok {
+		l.Error("received_invalid_props")
+		return nil, errors.New("received invalid props")
+	}
+
+	return v.push(ctx, slack.ModalViewRequest{
+		Title:      props.title,
+		Close:      props.close,
+		Submit:     props.submit,
+		Blocks:     props.blocks,
+		CallbackID: string(view.CallbackID()),
+	})
+}
+
+func (v Home) handleReorderMembersAction(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
+	view := ReorderMembers{
+		Repository: v.Repository,
+	}
+	view.State = view.DefaultState().(*ReorderMembersState)
+	view.State.ChannelID = v.State.ChannelID
+	view.State.TeamID = v.State.TeamID
+	view.State.UserID = v.State.UserID
+	view.State.rotaID = v.State.rotaID
+
+	p, err := view.BuildProps(ctx)
+	if err != nil {
+		l.Error("failed to build props", zap.Error(err))
+		return nil, errors.New("failed to build reorder members props")
+	}
+	props, ok := p.(*ReorderMembersProps)
+	if !ok {
+		l.Error("received_invalid_props")
+		return nil, errors.New("received invalid props")
+	}
+
+	return v.push(ctx, slack.ModalViewRequest{
+		Title:      props.title,
+		Close:      props.close,
+		Blocks:     props.blocks,
+		CallbackID: string(view.CallbackID()),
+	})
+}
+
+func (v Home) handleViewScheduleAction(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
+	view := Schedule{
+		Repository: v.Repository,
+	}
+	view.State = view.DefaultState().(*ScheduleState)
+	view.State.ChannelID = v.State.ChannelID
+	view.State.TeamID = v.State.TeamID
+	view.State.UserID = v.State.UserID
+	view.State.rotaID = v.State.rotaID
+
+	p, err := view.BuildProps(ctx)
+	if err != nil {
+		l.Error("failed to build props", zap.Error(err))
+		return nil, errors.New("failed to build schedule props")
+	}
+	props, ok := p.(*ScheduleProps)
+	if !ok {
+		l.Error("received_invalid_props")
+		return nil, errors.New("received invalid props")
+	}
+
+	return v.push(ctx, slack.ModalViewRequest{
+		Title:      props.title,
+		Close:      props.close,
+		Blocks:     props.blocks,
+		CallbackID: string(view.CallbackID()),
+	})
+}
+
+func (v Home) handleAddUnavailabilityAction(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
+	view := AddUnavailability{
+		Repository: v.Repository,
+	}
+	view.State = view.DefaultState().(*AddUnavailabilityState)
+	view.State.ChannelID = v.State.ChannelID
+	view.State.TeamID = v.State.TeamID
+	view.State.UserID = v.State.UserID
+
+	p, err := view.BuildProps(ctx)
+	if err != nil {
+		l.Error("failed to build props", zap.Error(err))
+		return nil, errors.New("failed to build add unavailability props")
+	}
+	props, ok := p.(*AddUnavailabilityProps)
+	if !ok {
+		l.Error("received_invalid_props")
+		return nil, errors.New("received invalid props")
+	}
+
+	return v.push(ctx, slack.ModalViewRequest{
+		Title:      props.title,
+		Close:      props.close,
+		Submit:     props.submit,
+		Blocks:     props.blocks,
+		CallbackID: string(view.CallbackID()),
+	})
+}
+
+// handleStatusAction pauses or ends the rota and shows the home view again with the rota in its new section.
+func (v Home) handleStatusAction(ctx context.Context, status db.RotaStatus) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
+	err := v.Repository.UpdateRotaStatus(ctx, db.UpdateRotaStatusParams{ID: v.State.rotaID, Status: status})
+	if err != nil {
+		l.Error("failed_to_update_rota_status", zap.Error(err))
+		return nil, err
+	}
+	l.Info("updated_rota_status", zap.String("rota_id", v.State.rotaID), zap.String("status", string(status)))
+	if err = v.replace(ctx, "", v.State.viewID); err != nil {
+		return nil, err
+	}
+	return &gen.ActionResponse{}, nil
+}
+
+// handleResumeRotaAction puts a paused or ended rota back into rotation, archived rotas stay archived. The shifts that would have taken place
+// in the meantime are forgotten so the scheduler doesn't catch up on them, and rotas that ended because their end
+// date passed lose it, otherwise they'd end again straight away.
+func (v Home) handleResumeRotaAction(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
+	rota, err := v.Repository.FindRotaByID(ctx, v.State.rotaID)
+	if err != nil {
+		l.Error("failed_to_find", zap.Error(err))
+		return nil, err
+	}
+	if rota.Status == db.RTArchived {
+		// Home tabs published before the rota was archived still offer to resume it.
+		l.Warn("unable_to_resume_archived_rota", zap.String("rota_id", rota.ID))
+		if err = v.replace(ctx, "", v.State.viewID); err != nil {
+			return nil, err
+		}
+		return &gen.ActionResponse{}, nil
+	}
+	if rota.EndsAt.Valid && !rota.EndsAt.Time.After(v.clock().Now()) {
+		_, err = v.Repository.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+			RotaID:   rota.ID,
+			Name:     rota.Name,
+			Metadata: rota.Metadata,
+			StartsAt: rota.StartsAt.Time,
+		})
+		if err != nil {
+			l.Error("failed_to_create_or_update_rota", zap.Error(err))
+			return nil, err
+		}
+	}
+	if err = v.Repository.UpdateRotaState(ctx, db.UpdateRotaStateParams{ID: rota.ID, State: db.RotaState{}}); err != nil {
+		l.Error("failed_to_update_rota_state", zap.Error(err))
+		return nil, err
+	}
+	return v.handleStatusAction(ctx, db.RTActive)
+}
+
+func (v Home) clock() rotation.Clock {
+	if v.Clock == nil {
+		return rotation.SystemClock
+	}
+	return v.Clock
+}
+
+// push opens the given modal on top of the home view, the modal knows which rota and channel it is about through
+// its private metadata.
+func (v Home) push(ctx context.Context, r slack.ModalViewRequest) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
 	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
 	if err != nil {
 		l.Error("failed_to_get_client", zap.Error(err))
@@ -186,17 +589,58 @@ func (v Home) handleAddRotaAction(ctx context.Context) (*gen.ActionResponse, err
 		return nil, err
 	}
 
-	_, err = client.PushViewContext(ctx, v.State.TriggerID, slack.ModalViewRequest{
+	r.Type = slack.VTModal
+	r.NotifyOnClose = true
+	r.ClearOnClose = true
+	r.PrivateMetadata = string(bytes)
+	_, err = client.PushViewContext(ctx, v.State.TriggerID, r)
+	response := string(slack.RAClear)
+	return &gen.ActionResponse{ResponseAction: &response}, err
+}
+
+// replace takes the user back to the home view once they're done with a modal that was pushed on top of it.
+// Slack does not recommend using the update view API when a modal has been submitted but in our case
+// it's the only way to go back to the home view.
+// See https://slack.dev/java-slack-sdk/guides/modals
+func (v Home) replace(ctx context.Context, externalID, previousViewID string) error {
+	l := zapctx.Logger(ctx)
+	p, err := v.BuildProps(ctx)
+	if err != nil {
+		l.Error("failed_to_build_home_props", zap.Error(err))
+		return err
+	}
+	props, ok := p.(*HomeProps)
+	if !ok {
+		l.Error("received_invalid_props")
+		return errors.New("received invalid props")
+	}
+
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return err
+	}
+
+	bytes, err := json.Marshal(Metadata{RotaID: v.State.rotaID, ChannelID: v.State.ChannelID})
+	if err != nil {
+		l.Error("failed_to_marshal_metadata", zap.Error(err))
+		return err
+	}
+
+	r := slack.ModalViewRequest{
 		Type:            slack.VTModal,
 		Title:           props.title,
-		Close:           props.close,
-		Submit:          props.submit,
 		Blocks:          props.blocks,
-		CallbackID:      string(view.CallbackID()),
+		CallbackID:      string(v.CallbackID()),
 		NotifyOnClose:   true,
 		ClearOnClose:    true,
 		PrivateMetadata: string(bytes),
-	})
-	response := string(slack.RAClear)
-	return &gen.ActionResponse{ResponseAction: &response}, err
+	}
+	emptyHash := "" // This is empty to avoid slack thinking this view is outdated (and fail with a hash_conflict error)
+	if _, err = client.UpdateViewContext(ctx, r, externalID, emptyHash, previousViewID); err != nil {
+		l.Error("failed_to_home_view", zap.Error(err))
+		return err
+	}
+	return nil
 }
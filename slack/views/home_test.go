@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"path/filepath"
+	"time"
 
 	"go.uber.org/mock/gomock"
 
@@ -15,6 +16,7 @@ import (
 	gen "github.com/rotabot-io/rotabot/gen/slack"
 	"github.com/rotabot-io/rotabot/internal"
 	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/rotation"
 	"github.com/rotabot-io/rotabot/slack/slackclient"
 	"github.com/rotabot-io/rotabot/slack/slackclient/mock_slackclient"
 	"github.com/slack-go/slack"
@@ -101,10 +103,12 @@ var _ = Describe("Home", func() {
 
 			actionBlock := props.blocks.BlockSet[0].(*slack.ActionBlock)
 			Expect(actionBlock.BlockID).To(Equal("HOME_ACTIONS"))
-			Expect(actionBlock.Elements.ElementSet).To(HaveLen(1))
+			Expect(actionBlock.Elements.ElementSet).To(HaveLen(2))
 			Expect(actionBlock.Elements.ElementSet[0]).To(BeAssignableToTypeOf(&slack.ButtonBlockElement{}))
 			button := actionBlock.Elements.ElementSet[0].(*slack.ButtonBlockElement)
 			Expect(button.Text.Text).To(Equal("Add Rota :heavy_plus_sign:"))
+			button = actionBlock.Elements.ElementSet[1].(*slack.ButtonBlockElement)
+			Expect(button.ActionID).To(Equal(string(HAAddUnavailability)))
 
 			sectionBlock := props.blocks.BlockSet[1].(*slack.SectionBlock)
 			Expect(sectionBlock.Text.Text).To(Equal("Active Rotas:"))
@@ -137,10 +141,12 @@ var _ = Describe("Home", func() {
 
 			actionBlock := props.blocks.BlockSet[0].(*slack.ActionBlock)
 			Expect(actionBlock.BlockID).To(Equal("HOME_ACTIONS"))
-			Expect(actionBlock.Elements.ElementSet).To(HaveLen(1))
+			Expect(actionBlock.Elements.ElementSet).To(HaveLen(2))
 			Expect(actionBlock.Elements.ElementSet[0]).To(BeAssignableToTypeOf(&slack.ButtonBlockElement{}))
 			button := actionBlock.Elements.ElementSet[0].(*slack.ButtonBlockElement)
 			Expect(button.Text.Text).To(Equal("Add Rota :heavy_plus_sign:"))
+			button = actionBlock.Elements.ElementSet[1].(*slack.ButtonBlockElement)
+			Expect(button.ActionID).To(Equal(string(HAAddUnavailability)))
 
 			sectionBlock := props.blocks.BlockSet[1].(*slack.SectionBlock)
 			Expect(sectionBlock.Text.Text).To(Equal("Active Rotas:"))
@@ -149,6 +155,111 @@ var _ = Describe("Home", func() {
 			Expect(sectionBlock.Text.Text).To(Equal("Test Rota"))
 			Expect(sectionBlock.BlockID).To(Equal(id))
 		})
+
+		It("flags members that are out during the current shift", func() {
+			id, err := repo.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      "Test Rota",
+				ChannelID: home.State.ChannelID,
+				TeamID:    home.State.TeamID,
+				Metadata: db.RotaMetadata{
+					Frequency:      db.RFWeekly,
+					SchedulingType: db.RSCreated,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repo.UpdateRotaMembers(ctx, []db.Member{
+				{RotaID: id, UserID: "U1"},
+				{RotaID: id, UserID: "U2"},
+			})).To(Succeed())
+			_, err = repo.CreateUnavailability(ctx, db.CreateUnavailabilityParams{
+				TeamID:   home.State.TeamID,
+				UserID:   "U2",
+				StartsAt: time.Now().Add(-time.Minute),
+				EndsAt:   time.Now().Add(time.Minute),
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			p, err := home.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			props := p.(*HomeProps)
+			Expect(props.blocks.BlockSet).To(HaveLen(4))
+			notice := props.blocks.BlockSet[3].(*slack.ContextBlock)
+			text := notice.ContextElements.Elements[0].(*slack.TextBlockObject)
+			Expect(text.Text).To(Equal(":palm_tree: <@U2> is out during the current shift."))
+		})
+
+		It("lists who is on duty in each tier", func() {
+			id, err := repo.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      "Test Rota",
+				ChannelID: home.State.ChannelID,
+				TeamID:    home.State.TeamID,
+				Metadata: db.RotaMetadata{
+					Frequency:      db.RFWeekly,
+					SchedulingType: db.RSCreated,
+					Tiers:          2,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repo.UpdateRotaMembers(ctx, []db.Member{
+				{RotaID: id, UserID: "U1"},
+				{RotaID: id, UserID: "U2"},
+			})).To(Succeed())
+
+			p, err := home.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			props := p.(*HomeProps)
+			Expect(props.blocks.BlockSet).To(HaveLen(4))
+			notice := props.blocks.BlockSet[3].(*slack.ContextBlock)
+			text := notice.ContextElements.Elements[0].(*slack.TextBlockObject)
+			Expect(text.Text).To(Equal(":busts_in_silhouette: *Primary:* <@U1>\n:busts_in_silhouette: *Secondary:* <@U2>"))
+		})
+
+		It("lists paused and ended rotas apart from the active ones", func() {
+			ids := []string{}
+			for _, name := range []string{"Active", "Paused", "Ended"} {
+				id, err := repo.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+					Name:      name,
+					ChannelID: home.State.ChannelID,
+					TeamID:    home.State.TeamID,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				ids = append(ids, id)
+			}
+			Expect(repo.UpdateRotaStatus(ctx, db.UpdateRotaStatusParams{ID: ids[1], Status: db.RTPaused})).To(Succeed())
+			Expect(repo.UpdateRotaStatus(ctx, db.UpdateRotaStatusParams{ID: ids[2], Status: db.RTEnded})).To(Succeed())
+
+			p, err := home.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			props := p.(*HomeProps)
+			Expect(props.blocks.BlockSet).To(HaveLen(7))
+			Expect(props.blocks.BlockSet[1].(*slack.SectionBlock).Text.Text).To(Equal("Active Rotas:"))
+			active := props.blocks.BlockSet[2].(*slack.SectionBlock)
+			Expect(active.BlockID).To(Equal(ids[0]))
+			Expect(active.Accessory.OverflowElement.Options).To(HaveLen(5))
+			Expect(active.Accessory.OverflowElement.Options[3].Value).To(Equal(string(HASkipShift)))
+			Expect(props.blocks.BlockSet[3].(*slack.SectionBlock).Text.Text).To(Equal("Paused Rotas:"))
+
+			paused := props.blocks.BlockSet[4].(*slack.SectionBlock)
+			Expect(paused.BlockID).To(Equal(ids[1]))
+			options := paused.Accessory.OverflowElement.Options
+			Expect(options).To(HaveLen(4))
+			Expect(options[2].Value).To(Equal(string(HAResumeRota)))
+			Expect(options[3].Value).To(Equal(string(HAEndRota)))
+
+			Expect(props.blocks.BlockSet[5].(*slack.SectionBlock).Text.Text).To(Equal("Ended Rotas:"))
+			Expect(props.blocks.BlockSet[6].(*slack.SectionBlock).BlockID).To(Equal(ids[2]))
+		})
+	})
+
+	Describe("rotaActions", func() {
+		It("only lets archived rotas be edited", func() {
+			actions := rotaActions(db.Rota{Status: db.RTArchived})
+			Expect(actions).To(HaveLen(1))
+			Expect(actions[0].Action).To(Equal(string(HASaveRota)))
+		})
 	})
 
 	Describe("OnAction", func() {
@@ -221,6 +332,214 @@ var _ = Describe("Home", func() {
 			_, err = home.OnAction(ctx)
 			Expect(err).ToNot(HaveOccurred())
 		})
+
+		It("calls slack api to push swap_shift modal", func() {
+			home.State.action = HASwapShift
+			home.State.UserID = "U123"
+			id, err := home.Repository.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      "Rota",
+				ChannelID: channelID,
+				TeamID:    teamID,
+				Metadata: db.RotaMetadata{
+					Frequency:      db.RFDaily,
+					SchedulingType: db.RSCreated,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			home.State.rotaID = id
+			sc.EXPECT().PushViewContext(ctx, triggerID, gomock.Cond(func(x any) bool {
+				view := x.(slack.ModalViewRequest)
+				Expect(view.CallbackID).To(Equal(string(VTSwapShift)))
+				// Nobody is on the rota, so there is nothing to swap.
+				return Expect(view.Submit).To(BeNil())
+			})).Return(nil, nil).Times(1)
+
+			_, err = home.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("calls slack api to push skip_shift modal", func() {
+			home.State.action = HASkipShift
+			id, err := home.Repository.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      "Rota",
+				ChannelID: channelID,
+				TeamID:    teamID,
+				Metadata: db.RotaMetadata{
+					Frequency:      db.RFDaily,
+					SchedulingType: db.RSCreated,
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			home.State.rotaID = id
+			sc.EXPECT().PushViewContext(ctx, triggerID, gomock.Cond(func(x any) bool {
+				view := x.(slack.ModalViewRequest)
+
+				var m Metadata
+				err := json.Unmarshal([]byte(view.PrivateMetadata), &m)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(view.CallbackID).To(Equal(string(VTSkipShift)))
+				return Expect(m.RotaID).To(Equal(id))
+			})).Return(nil, nil).Times(1)
+
+			_, err = home.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("calls slack api to push reorder_members modal", func() {
+			home.State.action = HAReorderMembers
+			id, err := home.Repository.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      "Rota",
+				ChannelID: channelID,
+				TeamID:    teamID,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			home.State.rotaID = id
+			sc.EXPECT().PushViewContext(ctx, triggerID, gomock.Cond(func(x any) bool {
+				view := x.(slack.ModalViewRequest)
+				Expect(view.CallbackID).To(Equal(string(VTReorderMembers)))
+				return Expect(view.Submit).To(BeNil())
+			})).Return(nil, nil).Times(1)
+
+			_, err = home.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("calls slack api to push schedule modal", func() {
+			home.State.action = HAViewSchedule
+			id, err := home.Repository.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      "Rota",
+				ChannelID: channelID,
+				TeamID:    teamID,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			home.State.rotaID = id
+			sc.EXPECT().PushViewContext(ctx, triggerID, gomock.Cond(func(x any) bool {
+				view := x.(slack.ModalViewRequest)
+				Expect(view.CallbackID).To(Equal(string(VTSchedule)))
+				return Expect(view.Submit).To(BeNil())
+			})).Return(nil, nil).Times(1)
+
+			_, err = home.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("pauses the rota and shows the home view again", func() {
+			id, err := home.Repository.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      "Rota",
+				ChannelID: channelID,
+				TeamID:    teamID,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			home.State.action = HAPauseRota
+			home.State.rotaID = id
+			home.State.viewID = "V123"
+			sc.EXPECT().UpdateViewContext(ctx, gomock.Any(), "", "", "V123").Return(nil, nil).Times(1)
+
+			res, err := home.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&gen.ActionResponse{}))
+
+			rota, err := home.Repository.FindRotaByID(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.Status).To(Equal(db.RTPaused))
+		})
+
+		It("resumes a rota that ended and forgets its end date", func() {
+			id, err := home.Repository.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      "Rota",
+				ChannelID: channelID,
+				TeamID:    teamID,
+				EndsAt:    time.Now().Add(-time.Hour),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(home.Repository.UpdateRotaStatus(ctx, db.UpdateRotaStatusParams{ID: id, Status: db.RTEnded})).To(Succeed())
+			Expect(home.Repository.UpdateRotaState(ctx, db.UpdateRotaStateParams{
+				ID:    id,
+				State: db.RotaState{UserID: "U1"},
+			})).To(Succeed())
+
+			home.State.action = HAResumeRota
+			home.State.rotaID = id
+			sc.EXPECT().UpdateViewContext(ctx, gomock.Any(), "", "", "").Return(nil, nil).Times(1)
+
+			_, err = home.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			rota, err := home.Repository.FindRotaByID(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.Status).To(Equal(db.RTActive))
+			Expect(rota.EndsAt.Valid).To(BeFalse())
+			Expect(rota.State).To(Equal(db.RotaState{}))
+		})
+
+		It("forgets the end date once it passed", func() {
+			endsAt := time.Now().Add(time.Hour)
+			id, err := home.Repository.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      "Rota",
+				ChannelID: channelID,
+				TeamID:    teamID,
+				EndsAt:    endsAt,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(home.Repository.UpdateRotaStatus(ctx, db.UpdateRotaStatusParams{ID: id, Status: db.RTEnded})).To(Succeed())
+
+			home.Clock = rotation.FixedClock(endsAt)
+			home.State.action = HAResumeRota
+			home.State.rotaID = id
+			sc.EXPECT().UpdateViewContext(ctx, gomock.Any(), "", "", "").Return(nil, nil).Times(1)
+
+			_, err = home.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			rota, err := home.Repository.FindRotaByID(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.EndsAt.Valid).To(BeFalse())
+		})
+
+		It("doesn't resume an archived rota", func() {
+			id, err := home.Repository.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+				Name:      "Rota",
+				ChannelID: channelID,
+				TeamID:    teamID,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(home.Repository.UpdateRotaStatus(ctx, db.UpdateRotaStatusParams{ID: id, Status: db.RTArchived})).To(Succeed())
+
+			home.State.action = HAResumeRota
+			home.State.rotaID = id
+			sc.EXPECT().UpdateViewContext(ctx, gomock.Any(), "", "", "").Return(nil, nil).Times(1)
+
+			_, err = home.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			rota, err := home.Repository.FindRotaByID(ctx, id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.Status).To(Equal(db.RTArchived))
+		})
+
+		It("calls slack api to push add_unavailability modal", func() {
+			home.State.action = HAAddUnavailability
+			home.State.UserID = "U123"
+			sc.EXPECT().PushViewContext(ctx, triggerID, gomock.Cond(func(x any) bool {
+				view := x.(slack.ModalViewRequest)
+
+				var m Metadata
+				err := json.Unmarshal([]byte(view.PrivateMetadata), &m)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(view.CallbackID).To(Equal(string(VTAddUnavailability)))
+				Expect(m.ChannelID).To(Equal(channelID))
+				return Expect(m.RotaID).To(BeEmpty())
+			})).Return(nil, nil).Times(1)
+
+			_, err := home.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
 	})
 
 	Describe("OnClose", func() {
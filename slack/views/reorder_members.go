@@ -0,0 +1,215 @@
+package views
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/slack/block"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/goaerrors"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+	"github.com/slack-go/slack"
+)
+
+// ReorderMembersAction defines the list of possible actions on each of the members of the reorder view
+type ReorderMembersAction string
+
+const (
+	RMAMoveUp   = ReorderMembersAction("MEMBER_MOVE_UP")
+	RMAMoveDown = ReorderMembersAction("MEMBER_MOVE_DOWN")
+)
+
+// ReorderMembers lets users pick the order in which members of a rota are on duty. Members are moved as soon as
+// the buttons are clicked, so there's nothing to submit.
+type ReorderMembers struct {
+	Repository db.Repository
+	State      *ReorderMembersState
+}
+
+type ReorderMembersState struct {
+	TriggerID string
+	ChannelID string
+	TeamID    string
+	UserID    string
+	rotaID    string
+	// member is the user id of the member being moved.
+	member string
+	action ReorderMembersAction
+	viewID string
+}
+
+type ReorderMembersProps struct {
+	title  *slack.TextBlockObject
+	close  *slack.TextBlockObject
+	blocks slack.Blocks
+}
+
+func (v ReorderMembers) CallbackID() ViewType {
+	return VTReorderMembers
+}
+
+func (v ReorderMembers) DefaultState() interface{} {
+	return &ReorderMembersState{}
+}
+
+func (v ReorderMembers) BuildProps(ctx context.Context) (interface{}, error) {
+	l := zapctx.Logger(ctx)
+	rota, err := v.Repository.FindRotaByID(ctx, v.State.rotaID)
+	if err != nil {
+		l.Error("failed_to_find", zap.Error(err))
+		return nil, err
+	}
+	userIDs, err := v.Repository.ListUserIDsByRotaID(ctx, v.State.rotaID)
+	if err != nil {
+		l.Error("failed_to_list_members", zap.Error(err))
+		return nil, err
+	}
+
+	props := &ReorderMembersProps{
+		title: block.NewDefaultText("Reorder Members"),
+		close: block.NewDefaultText("Done"),
+	}
+	if len(userIDs) == 0 {
+		props.blocks = slack.Blocks{BlockSet: []slack.Block{
+			slack.NewSectionBlock(block.NewMarkdownText("*"+rota.Name+"* doesn't have any members yet."), nil, nil),
+		}}
+		return props, nil
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			block.NewMarkdownText(fmt.Sprintf(
+				"Members of *%s* are on duty in this order when it's scheduled by *%s*.", rota.Name, db.RSCreated,
+			)),
+			nil,
+			nil,
+		),
+	}
+	for i, userID := range userIDs {
+		buttons := []slack.BlockElement{}
+		if i > 0 {
+			buttons = append(buttons, block.NewButton(block.Button{Text: ":arrow_up: Up", ActionID: string(RMAMoveUp), Value: userID}))
+		}
+		if i < len(userIDs)-1 {
+			buttons = append(buttons, block.NewButton(block.Button{Text: ":arrow_down: Down", ActionID: string(RMAMoveDown), Value: userID}))
+		}
+		blocks = append(blocks, slack.NewSectionBlock(block.NewMarkdownText(fmt.Sprintf("*%d.* <@%s>", i+1, userID)), nil, nil))
+		if len(buttons) > 0 {
+			// Block ids must be unique within a view, so the buttons of each member are grouped under their user id.
+			blocks = append(blocks, slack.NewActionBlock(userID, buttons...))
+		}
+	}
+	props.blocks = slack.Blocks{BlockSet: blocks}
+	return props, nil
+}
+
+func (v ReorderMembers) OnAction(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx).With(zap.String("rota_id", v.State.rotaID), zap.String("member", v.State.member))
+	offset := 0
+	switch v.State.action {
+	case RMAMoveUp:
+		offset = -1
+	case RMAMoveDown:
+		offset = 1
+	default:
+		l.Warn("unknown_action", zap.String("action", string(v.State.action)))
+		sentry.CaptureMessage("unknown_action")
+		return nil, errors.New("unknown_action")
+	}
+	err := v.Repository.MoveMember(ctx, db.MoveMemberParams{RotaID: v.State.rotaID, UserID: v.State.member, Offset: offset})
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		l.Error("failed_to_move_member", zap.Error(err))
+		return nil, err
+	}
+	// Members that were removed from the rota in the meantime can't be moved, showing the current order is enough.
+
+	p, err := v.BuildProps(ctx)
+	if err != nil {
+		l.Error("failed_to_build_props", zap.Error(err))
+		return nil, err
+	}
+	props, ok := p.(*ReorderMembersProps)
+	if !ok {
+		l.Error("received_invalid_props")
+		return nil, errors.New("received invalid props")
+	}
+
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return nil, err
+	}
+	r, err := v.modal(props)
+	if err != nil {
+		l.Error("failed_to_marshal_metadata", zap.Error(err))
+		return nil, err
+	}
+	if _, err = client.UpdateViewContext(ctx, r, "", "", v.State.viewID); err != nil {
+		l.Error("failed_to_update_view", zap.Error(err))
+		return nil, err
+	}
+	return &gen.ActionResponse{}, nil
+}
+
+func (v ReorderMembers) OnClose(ctx context.Context) (*gen.ActionResponse, error) {
+	zapctx.Logger(ctx).Debug("closing_view")
+	return &gen.ActionResponse{}, nil
+}
+
+func (v ReorderMembers) OnSubmit(ctx context.Context) (*gen.ActionResponse, error) {
+	zapctx.Logger(ctx).Error("submitting_reorder_members_view")
+	return nil, goaerrors.NewInternalError()
+}
+
+func (v ReorderMembers) Render(ctx context.Context, p interface{}) error {
+	l := zapctx.Logger(ctx)
+	props, ok := p.(*ReorderMembersProps)
+	if !ok {
+		return errors.New("received invalid props")
+	}
+
+	view, err := v.modal(props)
+	if err != nil {
+		l.Error("failed_to_marshal_metadata", zap.Error(err))
+		return err
+	}
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return err
+	}
+	_, err = client.OpenViewContext(ctx, v.State.TriggerID, view)
+	if err != nil {
+		l.Error("failed_to_open_view", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (v ReorderMembers) modal(props *ReorderMembersProps) (slack.ModalViewRequest, error) {
+	bytes, err := json.Marshal(Metadata{RotaID: v.State.rotaID, ChannelID: v.State.ChannelID})
+	if err != nil {
+		return slack.ModalViewRequest{}, err
+	}
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Title:           props.title,
+		Close:           props.close,
+		Blocks:          props.blocks,
+		CallbackID:      string(v.CallbackID()),
+		NotifyOnClose:   true,
+		ClearOnClose:    true,
+		PrivateMetadata: string(bytes),
+	}, nil
+}
@@ -0,0 +1,138 @@
+package views
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/testcontainers/testcontainers-go"
+
+	"github.com/rotabot-io/rotabot/internal"
+
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	"github.com/jackc/pgx/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/slack/slackclient/mock_slackclient"
+	"github.com/slack-go/slack"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"go.uber.org/mock/gomock"
+)
+
+var _ = Describe("ReorderMembers", func() {
+	var (
+		ctx     context.Context
+		sc      *mock_slackclient.MockSlackClient
+		repo    db.Repository
+		reorder *ReorderMembers
+		conn    *pgx.Conn
+		rotaID  string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		container, err := internal.RunContainer(ctx,
+			postgres.WithInitScripts(filepath.Join("..", "..", "assets", "structure.sql")),
+			testcontainers.WithWaitStrategy(internal.DefaultWaitStrategy()),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		connString, err := container.ConnectionString(ctx, "sslmode=disable")
+		Expect(err).ToNot(HaveOccurred())
+
+		conn, err = pgx.Connect(ctx, connString)
+		Expect(err).ToNot(HaveOccurred())
+
+		tx, err := conn.Begin(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		repo = db.New(tx)
+		rotaID, err = repo.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+			Name:      "On Call",
+			TeamID:    "TM123",
+			ChannelID: "CH123",
+			Metadata: db.RotaMetadata{
+				Frequency:      db.RFWeekly,
+				SchedulingType: db.RSCreated,
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repo.UpdateRotaMembers(ctx, []db.Member{
+			{RotaID: rotaID, UserID: "alice"},
+			{RotaID: rotaID, UserID: "bob"},
+			{RotaID: rotaID, UserID: "carol"},
+		})).To(Succeed())
+
+		reorder = &ReorderMembers{
+			Repository: repo,
+			State: &ReorderMembersState{
+				ChannelID: "CH123",
+				TeamID:    "TM123",
+				UserID:    "U123",
+				rotaID:    rotaID,
+				viewID:    "V123",
+			},
+		}
+
+		DeferCleanup(func() {
+			_ = container.Terminate(ctx)
+			_ = conn.Close(ctx)
+			_ = tx.Rollback(ctx)
+		})
+	})
+
+	// Create a mock and assign it to the sc variable at the start of each test
+	slackclient.MockSlackClient(&ctx, &sc, nil)
+
+	Describe("BuildProps", func() {
+		It("lists the members in order with buttons to move them", func() {
+			p, err := reorder.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			props := p.(*ReorderMembersProps)
+			Expect(props.title.Text).To(Equal("Reorder Members"))
+			Expect(props.blocks.BlockSet).To(HaveLen(7))
+
+			first := props.blocks.BlockSet[1].(*slack.SectionBlock)
+			Expect(first.Text.Text).To(Equal("*1.* <@alice>"))
+			buttons := props.blocks.BlockSet[2].(*slack.ActionBlock)
+			Expect(buttons.BlockID).To(Equal("alice"))
+			Expect(buttons.Elements.ElementSet).To(HaveLen(1))
+			Expect(buttons.Elements.ElementSet[0].(*slack.ButtonBlockElement).ActionID).To(Equal(string(RMAMoveDown)))
+
+			buttons = props.blocks.BlockSet[4].(*slack.ActionBlock)
+			Expect(buttons.Elements.ElementSet).To(HaveLen(2))
+
+			buttons = props.blocks.BlockSet[6].(*slack.ActionBlock)
+			Expect(buttons.Elements.ElementSet).To(HaveLen(1))
+			Expect(buttons.Elements.ElementSet[0].(*slack.ButtonBlockElement).ActionID).To(Equal(string(RMAMoveUp)))
+		})
+	})
+
+	Describe("OnAction", func() {
+		It("moves the member and shows the new order", func() {
+			reorder.State.action = RMAMoveUp
+			reorder.State.member = "carol"
+
+			sc.EXPECT().
+				UpdateViewContext(ctx, gomock.Any(), "", "", "V123").
+				DoAndReturn(func(_ context.Context, r slack.ModalViewRequest, _, _, _ string) (*slack.ViewResponse, error) {
+					Expect(r.CallbackID).To(Equal(string(VTReorderMembers)))
+					second := r.Blocks.BlockSet[3].(*slack.SectionBlock)
+					Expect(second.Text.Text).To(Equal("*2.* <@carol>"))
+					return nil, nil
+				}).Times(1)
+
+			res, err := reorder.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&gen.ActionResponse{}))
+
+			userIDs, err := repo.ListUserIDsByRotaID(ctx, rotaID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(userIDs).To(Equal([]string{"alice", "carol", "bob"}))
+		})
+	})
+})
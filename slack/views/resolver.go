@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/rotabot-io/rotabot/lib/zapctx"
 	"go.uber.org/zap"
@@ -26,11 +29,28 @@ var (
 )
 
 func Resolve(ctx context.Context, p ResolverParams) (View, error) {
+	if p.Action.Container.Type == "message" {
+		return resolveMessage(ctx, p)
+	}
 	switch p.Action.View.CallbackID {
 	case string(VTHome):
 		return resolveHomeView(ctx, p)
 	case string(VTSaveRota):
 		return resolveSaveRota(ctx, p)
+	case string(VTAddOverride):
+		return resolveAddOverride(ctx, p)
+	case string(VTSwapShift):
+		return resolveSwapShift(ctx, p)
+	case string(VTReorderMembers):
+		return resolveReorderMembers(ctx, p)
+	case string(VTSchedule):
+		return resolveSchedule(ctx, p)
+	case string(VTSkipShift):
+		return resolveSkipShift(ctx, p)
+	case string(VTAddUnavailability):
+		return resolveAddUnavailability(ctx, p)
+	case string(VTAppHome):
+		return resolveAppHome(p), nil
 	default:
 		zapctx.Logger(ctx).Warn("unknown_callback_id", zap.String("callback_id", p.Action.View.CallbackID))
 		sentry.CaptureMessage(fmt.Sprintf("unknown_callback_id: %s", p.Action.View.CallbackID))
@@ -38,6 +58,53 @@ func Resolve(ctx context.Context, p ResolverParams) (View, error) {
 	}
 }
 
+// resolveMessage finds the view of a message whose buttons were clicked. Messages don't have a callback id, so
+// it's the block id of their actions block that tells which view they belong to.
+func resolveMessage(ctx context.Context, p ResolverParams) (View, error) {
+	blockID := ""
+	if len(p.Action.ActionCallback.BlockActions) > 0 {
+		blockID = p.Action.ActionCallback.BlockActions[0].BlockID
+	}
+	switch blockID {
+	case string(VTSwapRequest):
+		return resolveSwapRequest(p), nil
+	default:
+		zapctx.Logger(ctx).Warn("unknown_message_block_id", zap.String("block_id", blockID))
+		sentry.CaptureMessage(fmt.Sprintf("unknown_message_block_id: %s", blockID))
+		return nil, ErrUnknownCallbackID
+	}
+}
+
+func resolveSwapRequest(p ResolverParams) View {
+	blockAction := p.Action.ActionCallback.BlockActions[0]
+	view := &SwapRequest{}
+	view.Repository = p.Repository
+	view.State = view.DefaultState().(*SwapRequestState)
+	view.State.TeamID = p.Action.Team.ID
+	view.State.UserID = p.Action.User.ID
+	view.State.ChannelID = p.Action.Container.ChannelID
+	view.State.messageTS = p.Action.Container.MessageTs
+	view.State.swapID = blockAction.Value
+	view.State.action = SwapRequestAction(blockAction.ActionID)
+	return view
+}
+
+func resolveAppHome(p ResolverParams) View {
+	view := &AppHome{}
+	view.Repository = p.Repository
+	view.State = view.DefaultState().(*AppHomeState)
+	view.State.TriggerID = p.Action.TriggerID
+	view.State.TeamID = p.Action.Team.ID
+	view.State.UserID = p.Action.User.ID
+
+	if p.Action.ActionCallback.BlockActions != nil {
+		blockAction := p.Action.ActionCallback.BlockActions[0]
+		view.State.action = AppHomeAction(blockAction.ActionID)
+		view.State.rotaID = blockAction.Value
+	}
+	return view
+}
+
 func resolveHomeView(ctx context.Context, p ResolverParams) (View, error) {
 	m, err := unMarshallMetadata(p.Action.View.PrivateMetadata)
 	if err != nil {
@@ -50,7 +117,9 @@ func resolveHomeView(ctx context.Context, p ResolverParams) (View, error) {
 	view.State = view.DefaultState().(*HomeState)
 	view.State.TriggerID = p.Action.TriggerID
 	view.State.TeamID = p.Action.Team.ID
+	view.State.UserID = p.Action.User.ID
 	view.State.ChannelID = m.ChannelID
+	view.State.viewID = p.Action.View.ID
 
 	if p.Action.ActionCallback.BlockActions != nil {
 		blockAction := p.Action.ActionCallback.BlockActions[0]
@@ -79,15 +148,247 @@ func resolveSaveRota(ctx context.Context, p ResolverParams) (View, error) {
 	view.State.rotaID = m.RotaID
 	view.State.ChannelID = m.ChannelID
 	view.State.TeamID = p.Action.Team.ID
+	view.State.UserID = p.Action.User.ID
 	view.State.previousViewID = p.Action.View.PreviousViewID
 	view.State.externalID = p.Action.View.ExternalID
+	view.State.viewID = p.Action.View.ID
+
+	if p.Action.ActionCallback.BlockActions != nil {
+		view.State.action = p.Action.ActionCallback.BlockActions[0].ActionID
+	}
 
 	values := p.Action.View.State.Values
 	if values != nil {
+		view.State.edited = true
 		view.State.rotaName = values["ROTA_NAME"]["ROTA_NAME"].Value
 		view.State.frequency = db.RotaFrequency(values["ROTA_FREQUENCY"]["ROTA_FREQUENCY"].SelectedOption.Value)
 		view.State.schedulingType = db.RotaSchedule(values["ROTA_TYPE"]["ROTA_TYPE"].SelectedOption.Value)
 		view.State.userIds = values["ROTA_MEMBERS"]["ROTA_MEMBERS"].SelectedUsers
+		// Fields that haven't been filled in keep their default value so the modal can be rendered again.
+		if every, err := strconv.Atoi(values["ROTA_EVERY"]["ROTA_EVERY"].Value); err == nil {
+			view.State.every = every
+		}
+		if tiers, err := strconv.Atoi(values["ROTA_TIERS"]["ROTA_TIERS"].Value); err == nil {
+			view.State.tiers = tiers
+		}
+		view.State.cron = strings.TrimSpace(values["ROTA_CRON"]["ROTA_CRON"].Value)
+		view.State.timeZone = strings.TrimSpace(values["ROTA_TIME_ZONE"]["ROTA_TIME_ZONE"].Value)
+		if t, ok := values["ROTA_TIME"]["ROTA_TIME"]; ok {
+			view.State.handoverTime = t.SelectedTime
+		}
+		view.State.skipWeekends = values["ROTA_WORKING_DAYS"]["ROTA_WORKING_DAYS"].SelectedOption.Value == weekdaysOnly
+		if c := values["ROTA_CALENDAR"]["ROTA_CALENDAR"].SelectedOption.Value; c != "" {
+			view.State.calendar = c
+		}
+		view.State.calendarFile = strings.TrimSpace(values["ROTA_CALENDAR_FILE"]["ROTA_CALENDAR_FILE"].Value)
+		if p := values["ROTA_NON_WORKING_DAYS"]["ROTA_NON_WORKING_DAYS"].SelectedOption.Value; p != "" {
+			view.State.nonWorkingDays = db.NonWorkingDayPolicy(p)
+		}
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if d.String() == values["ROTA_WEEKDAY"]["ROTA_WEEKDAY"].SelectedOption.Value {
+				view.State.weekday = d
+			}
+		}
+		view.State.windows = resolveWindows(values)
+		view.State.removeLeavers = values["ROTA_LEAVERS"]["ROTA_LEAVERS"].SelectedOption.Value == leaversRemoved
+		view.State.startsOn = values["ROTA_STARTS"]["ROTA_STARTS"].SelectedDate
+		view.State.endsOn = values["ROTA_ENDS"]["ROTA_ENDS"].SelectedDate
+	}
+
+	return view, nil
+}
+
+// resolveWindows reads the windows of a rota that follows the sun. Windows that were just added, because the user
+// picked more of them, split the day evenly until they're filled in.
+func resolveWindows(values map[string]map[string]slack.BlockAction) []db.Window {
+	n, err := strconv.Atoi(values["ROTA_WINDOWS"]["ROTA_WINDOWS"].SelectedOption.Value)
+	if err != nil || n < 2 {
+		return nil
+	}
+	windows := make([]db.Window, 0, n)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("ROTA_WINDOW_NAME_%d", i)
+		start := fmt.Sprintf("ROTA_WINDOW_START_%d", i)
+		members := fmt.Sprintf("ROTA_WINDOW_MEMBERS_%d", i)
+		w := db.Window{
+			Name:    strings.TrimSpace(values[name][name].Value),
+			Start:   fmt.Sprintf("%02d:00", 24*i/n),
+			UserIDs: values[members][members].SelectedUsers,
+		}
+		if _, ok := values[start]; ok {
+			w.Start = values[start][start].SelectedTime
+		}
+		windows = append(windows, w)
+	}
+	return windows
+}
+
+func resolveAddOverride(ctx context.Context, p ResolverParams) (View, error) {
+	m, err := unMarshallMetadata(p.Action.View.PrivateMetadata)
+	if err != nil {
+		zapctx.Logger(ctx).Error("unmarshall_metadata", zap.Error(err))
+		return nil, ErrInvalidMetadata
+	}
+
+	view := &AddOverride{}
+	view.Repository = p.Repository
+	view.State = view.DefaultState().(*AddOverrideState)
+	view.State.TriggerID = p.Action.TriggerID
+	view.State.rotaID = m.RotaID
+	view.State.ChannelID = m.ChannelID
+	view.State.TeamID = p.Action.Team.ID
+	view.State.UserID = p.Action.User.ID
+	view.State.previousViewID = p.Action.View.PreviousViewID
+	view.State.externalID = p.Action.View.ExternalID
+
+	values := p.Action.View.State.Values
+	if values != nil {
+		view.State.overrideUser = values["OVERRIDE_USER"]["OVERRIDE_USER"].SelectedUser
+		if t := values["OVERRIDE_START"]["OVERRIDE_START"].SelectedDateTime; t != 0 {
+			view.State.startsAt = time.Unix(t, 0).UTC()
+		}
+		if t := values["OVERRIDE_END"]["OVERRIDE_END"].SelectedDateTime; t != 0 {
+			view.State.endsAt = time.Unix(t, 0).UTC()
+		}
+	}
+
+	return view, nil
+}
+
+func resolveSwapShift(ctx context.Context, p ResolverParams) (View, error) {
+	m, err := unMarshallMetadata(p.Action.View.PrivateMetadata)
+	if err != nil {
+		zapctx.Logger(ctx).Error("unmarshall_metadata", zap.Error(err))
+		return nil, ErrInvalidMetadata
+	}
+
+	view := &SwapShift{}
+	view.Repository = p.Repository
+	view.State = view.DefaultState().(*SwapShiftState)
+	view.State.TriggerID = p.Action.TriggerID
+	view.State.rotaID = m.RotaID
+	view.State.ChannelID = m.ChannelID
+	view.State.TeamID = p.Action.Team.ID
+	view.State.UserID = p.Action.User.ID
+	view.State.previousViewID = p.Action.View.PreviousViewID
+	view.State.externalID = p.Action.View.ExternalID
+	view.State.viewID = p.Action.View.ID
+
+	if p.Action.ActionCallback.BlockActions != nil {
+		view.State.action = p.Action.ActionCallback.BlockActions[0].ActionID
+	}
+
+	values := p.Action.View.State.Values
+	if values != nil {
+		view.State.shift = values["SWAP_SHIFT"]["SWAP_SHIFT"].SelectedOption.Value
+		view.State.recipient = values["SWAP_USER"]["SWAP_USER"].SelectedUser
+		view.State.recipientShift = values["SWAP_WITH"]["SWAP_WITH"].SelectedOption.Value
+	}
+
+	return view, nil
+}
+
+func resolveReorderMembers(ctx context.Context, p ResolverParams) (View, error) {
+	m, err := unMarshallMetadata(p.Action.View.PrivateMetadata)
+	if err != nil {
+		zapctx.Logger(ctx).Error("unmarshall_metadata", zap.Error(err))
+		return nil, ErrInvalidMetadata
+	}
+
+	view := &ReorderMembers{}
+	view.Repository = p.Repository
+	view.State = view.DefaultState().(*ReorderMembersState)
+	view.State.TriggerID = p.Action.TriggerID
+	view.State.rotaID = m.RotaID
+	view.State.ChannelID = m.ChannelID
+	view.State.TeamID = p.Action.Team.ID
+	view.State.UserID = p.Action.User.ID
+	view.State.viewID = p.Action.View.ID
+
+	if p.Action.ActionCallback.BlockActions != nil {
+		blockAction := p.Action.ActionCallback.BlockActions[0]
+		view.State.action = ReorderMembersAction(blockAction.ActionID)
+		view.State.member = blockAction.Value
+	}
+
+	return view, nil
+}
+
+func resolveSchedule(ctx context.Context, p ResolverParams) (View, error) {
+	m, err := unMarshallMetadata(p.Action.View.PrivateMetadata)
+	if err != nil {
+		zapctx.Logger(ctx).Error("unmarshall_metadata", zap.Error(err))
+		return nil, ErrInvalidMetadata
+	}
+
+	view := &Schedule{}
+	view.Repository = p.Repository
+	view.State = view.DefaultState().(*ScheduleState)
+	view.State.TriggerID = p.Action.TriggerID
+	view.State.rotaID = m.RotaID
+	view.State.ChannelID = m.ChannelID
+	view.State.TeamID = p.Action.Team.ID
+	view.State.UserID = p.Action.User.ID
+
+	if p.Action.ActionCallback.BlockActions != nil {
+		view.State.action = ScheduleAction(p.Action.ActionCallback.BlockActions[0].ActionID)
+	}
+
+	return view, nil
+}
+
+func resolveSkipShift(ctx context.Context, p ResolverParams) (View, error) {
+	m, err := unMarshallMetadata(p.Action.View.PrivateMetadata)
+	if err != nil {
+		zapctx.Logger(ctx).Error("unmarshall_metadata", zap.Error(err))
+		return nil, ErrInvalidMetadata
+	}
+
+	view := &SkipShift{}
+	view.Repository = p.Repository
+	view.State = view.DefaultState().(*SkipShiftState)
+	view.State.TriggerID = p.Action.TriggerID
+	view.State.rotaID = m.RotaID
+	view.State.ChannelID = m.ChannelID
+	view.State.TeamID = p.Action.Team.ID
+	view.State.UserID = p.Action.User.ID
+	view.State.previousViewID = p.Action.View.PreviousViewID
+	view.State.externalID = p.Action.View.ExternalID
+
+	values := p.Action.View.State.Values
+	if values != nil {
+		view.State.skipUser = values["SKIP_USER"]["SKIP_USER"].SelectedUser
+		view.State.reason = values["SKIP_REASON"]["SKIP_REASON"].Value
+	}
+
+	return view, nil
+}
+
+func resolveAddUnavailability(ctx context.Context, p ResolverParams) (View, error) {
+	m, err := unMarshallMetadata(p.Action.View.PrivateMetadata)
+	if err != nil {
+		zapctx.Logger(ctx).Error("unmarshall_metadata", zap.Error(err))
+		return nil, ErrInvalidMetadata
+	}
+
+	view := &AddUnavailability{}
+	view.Repository = p.Repository
+	view.State = view.DefaultState().(*AddUnavailabilityState)
+	view.State.TriggerID = p.Action.TriggerID
+	view.State.ChannelID = m.ChannelID
+	view.State.TeamID = p.Action.Team.ID
+	view.State.UserID = p.Action.User.ID
+	view.State.previousViewID = p.Action.View.PreviousViewID
+	view.State.externalID = p.Action.View.ExternalID
+
+	values := p.Action.View.State.Values
+	if values != nil {
+		if t := values["UNAVAILABILITY_START"]["UNAVAILABILITY_START"].SelectedDateTime; t != 0 {
+			view.State.startsAt = time.Unix(t, 0).UTC()
+		}
+		if t := values["UNAVAILABILITY_END"]["UNAVAILABILITY_END"].SelectedDateTime; t != 0 {
+			view.State.endsAt = time.Unix(t, 0).UTC()
+		}
 	}
 
 	return view, nil
@@ -2,6 +2,7 @@ package views
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -50,6 +51,7 @@ var _ = Describe("Resolver", func() {
 			params := ResolverParams{
 				Action: slack.InteractionCallback{
 					View: slack.View{
+						ID:              "V123",
 						CallbackID:      string(VTHome),
 						PrivateMetadata: "{\"rota_id\":\"\",\"channel_id\":\"C123\"}",
 					},
@@ -69,6 +71,7 @@ var _ = Describe("Resolver", func() {
 			Expect(homeView.State.TriggerID).To(Equal("T123"))
 			Expect(homeView.State.ChannelID).To(Equal("C123"))
 			Expect(homeView.State.TeamID).To(Equal("TE123"))
+			Expect(homeView.State.viewID).To(Equal("V123"))
 			Expect(homeView.State.action).To(BeEmpty())
 		})
 
@@ -225,5 +228,468 @@ var _ = Describe("Resolver", func() {
 			Expect(addView.State.frequency).To(Equal(db.RFMonthly))
 			Expect(addView.State.schedulingType).To(Equal(db.RSRandom))
 		})
+
+		It("resolves the cadence given on the action", func() {
+			params := ResolverParams{
+				Action: slack.InteractionCallback{
+					View: slack.View{
+						ID:              "V123",
+						CallbackID:      string(VTSaveRota),
+						PrivateMetadata: "{\"rota_id\":\"\",\"channel_id\":\"C123\"}",
+						State: &slack.ViewState{
+							Values: map[string]map[string]slack.BlockAction{
+								"ROTA_FREQUENCY":        {"ROTA_FREQUENCY": {SelectedOption: slack.OptionBlockObject{Value: string(db.RFWeekly)}}},
+								"ROTA_TYPE":             {"ROTA_TYPE": {SelectedOption: slack.OptionBlockObject{Value: string(db.RSCreated)}}},
+								"ROTA_EVERY":            {"ROTA_EVERY": {Value: "2"}},
+								"ROTA_TIERS":            {"ROTA_TIERS": {Value: "2"}},
+								"ROTA_WEEKDAY":          {"ROTA_WEEKDAY": {SelectedOption: slack.OptionBlockObject{Value: "Thursday"}}},
+								"ROTA_TIME":             {"ROTA_TIME": {SelectedTime: "18:30"}},
+								"ROTA_CRON":             {"ROTA_CRON": {Value: " 0 10 * * MON "}},
+								"ROTA_TIME_ZONE":        {"ROTA_TIME_ZONE": {Value: "America/New_York "}},
+								"ROTA_WORKING_DAYS":     {"ROTA_WORKING_DAYS": {SelectedOption: slack.OptionBlockObject{Value: "Weekdays only"}}},
+								"ROTA_CALENDAR":         {"ROTA_CALENDAR": {SelectedOption: slack.OptionBlockObject{Value: "England and Wales"}}},
+								"ROTA_NON_WORKING_DAYS": {"ROTA_NON_WORKING_DAYS": {SelectedOption: slack.OptionBlockObject{Value: string(db.NWSkip)}}},
+								"ROTA_STARTS":           {"ROTA_STARTS": {SelectedDate: "2023-06-01"}},
+								"ROTA_ENDS":             {"ROTA_ENDS": {SelectedDate: "2023-06-30"}},
+								"ROTA_LEAVERS":          {"ROTA_LEAVERS": {SelectedOption: slack.OptionBlockObject{Value: "Are removed from the rota"}}},
+							},
+						},
+					},
+					ActionCallback: slack.ActionCallbacks{
+						BlockActions: []*slack.BlockAction{{ActionID: "ROTA_FREQUENCY"}},
+					},
+				},
+			}
+
+			view, err := Resolve(ctx, params)
+			Expect(err).ToNot(HaveOccurred())
+
+			addView, ok := view.(*SaveRota)
+			Expect(ok).To(BeTrue())
+			Expect(addView.State.viewID).To(Equal("V123"))
+			Expect(addView.State.action).To(Equal("ROTA_FREQUENCY"))
+			Expect(addView.State.edited).To(BeTrue())
+			Expect(addView.State.every).To(Equal(2))
+			Expect(addView.State.weekday).To(Equal(time.Thursday))
+			Expect(addView.State.handoverTime).To(Equal("18:30"))
+			Expect(addView.State.cron).To(Equal("0 10 * * MON"))
+			Expect(addView.State.timeZone).To(Equal("America/New_York"))
+			Expect(addView.State.skipWeekends).To(BeTrue())
+			Expect(addView.State.calendar).To(Equal("England and Wales"))
+			Expect(addView.State.nonWorkingDays).To(Equal(db.NWSkip))
+			Expect(addView.State.tiers).To(Equal(2))
+			Expect(addView.State.startsOn).To(Equal("2023-06-01"))
+			Expect(addView.State.endsOn).To(Equal("2023-06-30"))
+			Expect(addView.State.removeLeavers).To(BeTrue())
+		})
+
+		It("resolves the windows of a rota that follows the sun", func() {
+			params := ResolverParams{
+				Action: slack.InteractionCallback{
+					View: slack.View{
+						CallbackID:      string(VTSaveRota),
+						PrivateMetadata: "{\"rota_id\":\"\",\"channel_id\":\"C123\"}",
+						State: &slack.ViewState{
+							Values: map[string]map[string]slack.BlockAction{
+								"ROTA_TYPE":             {"ROTA_TYPE": {SelectedOption: slack.OptionBlockObject{Value: string(db.RSCreated)}}},
+								"ROTA_WINDOWS":          {"ROTA_WINDOWS": {SelectedOption: slack.OptionBlockObject{Value: "3"}}},
+								"ROTA_WINDOW_NAME_0":    {"ROTA_WINDOW_NAME_0": {Value: " APAC "}},
+								"ROTA_WINDOW_START_0":   {"ROTA_WINDOW_START_0": {SelectedTime: "01:00"}},
+								"ROTA_WINDOW_MEMBERS_0": {"ROTA_WINDOW_MEMBERS_0": {SelectedUsers: []string{"U1"}}},
+								"ROTA_WINDOW_NAME_1":    {"ROTA_WINDOW_NAME_1": {Value: "EMEA"}},
+								"ROTA_WINDOW_START_1":   {"ROTA_WINDOW_START_1": {SelectedTime: "09:00"}},
+								"ROTA_WINDOW_MEMBERS_1": {"ROTA_WINDOW_MEMBERS_1": {SelectedUsers: []string{"U2"}}},
+							},
+						},
+					},
+				},
+			}
+
+			view, err := Resolve(ctx, params)
+			Expect(err).ToNot(HaveOccurred())
+
+			addView, ok := view.(*SaveRota)
+			Expect(ok).To(BeTrue())
+			// The third window was just added, it starts so the day is split evenly until it's filled in.
+			Expect(addView.State.windows).To(Equal([]db.Window{
+				{Name: "APAC", Start: "01:00", UserIDs: []string{"U1"}},
+				{Name: "EMEA", Start: "09:00", UserIDs: []string{"U2"}},
+				{Name: "", Start: "16:00"},
+			}))
+		})
+
+		It("reports a field error when the holiday calendar is unknown", func() {
+			params := ResolverParams{
+				Action: slack.InteractionCallback{
+					View: slack.View{
+						CallbackID:      string(VTSaveRota),
+						PrivateMetadata: "{\"rota_id\":\"ROTA_ID\",\"channel_id\":\"C123\"}",
+						State: &slack.ViewState{
+							Values: map[string]map[string]slack.BlockAction{
+								"ROTA_TYPE":     {"ROTA_TYPE": {SelectedOption: slack.OptionBlockObject{Value: string(db.RSCreated)}}},
+								"ROTA_CALENDAR": {"ROTA_CALENDAR": {SelectedOption: slack.OptionBlockObject{Value: "Atlantis"}}},
+							},
+						},
+					},
+				},
+			}
+
+			view, err := Resolve(ctx, params)
+			Expect(err).ToNot(HaveOccurred())
+
+			res, err := view.(*SaveRota).OnSubmit(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.Errors).To(HaveKey("ROTA_CALENDAR"))
+		})
+
+		It("reports a field error when the scheduling type is unknown", func() {
+			params := ResolverParams{
+				Action: slack.InteractionCallback{
+					View: slack.View{
+						CallbackID:      string(VTSaveRota),
+						PrivateMetadata: "{\"rota_id\":\"ROTA_ID\",\"channel_id\":\"C123\"}",
+						State: &slack.ViewState{
+							Values: map[string]map[string]slack.BlockAction{
+								"ROTA_TYPE": {
+									"ROTA_TYPE": {
+										SelectedOption: slack.OptionBlockObject{
+											Value: "Alphabetically",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			view, err := Resolve(ctx, params)
+			Expect(err).ToNot(HaveOccurred())
+
+			res, err := view.(*SaveRota).OnSubmit(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.Errors).To(HaveKey("ROTA_TYPE"))
+		})
+	})
+
+	Describe("AddOverride", func() {
+		It("returns an error when Private metadata is not a valid json", func() {
+			params := ResolverParams{
+				Action: slack.InteractionCallback{
+					View: slack.View{
+						CallbackID:      string(VTAddOverride),
+						PrivateMetadata: "invalid",
+					},
+				},
+			}
+
+			_, err := Resolve(ctx, params)
+			Expect(err).To(MatchError(ErrInvalidMetadata))
+		})
+
+		It("resolves the override given on the action", func() {
+			params := ResolverParams{
+				Action: slack.InteractionCallback{
+					TriggerID: "T123",
+					Team:      slack.Team{ID: "TM123"},
+					User:      slack.User{ID: "U123"},
+					View: slack.View{
+						CallbackID:      string(VTAddOverride),
+						PrivateMetadata: "{\"rota_id\":\"ROTA_ID\",\"channel_id\":\"C123\"}",
+						PreviousViewID:  "V1",
+						ExternalID:      "E1",
+						State: &slack.ViewState{
+							Values: map[string]map[string]slack.BlockAction{
+								"OVERRIDE_USER":  {"OVERRIDE_USER": {SelectedUser: "U456"}},
+								"OVERRIDE_START": {"OVERRIDE_START": {SelectedDateTime: 1688979600}},
+								"OVERRIDE_END":   {"OVERRIDE_END": {SelectedDateTime: 1689066000}},
+							},
+						},
+					},
+				},
+			}
+
+			view, err := Resolve(ctx, params)
+			Expect(err).ToNot(HaveOccurred())
+
+			overrideView, ok := view.(*AddOverride)
+			Expect(ok).To(BeTrue())
+			Expect(overrideView.State).To(Equal(&AddOverrideState{
+				TriggerID:      "T123",
+				ChannelID:      "C123",
+				TeamID:         "TM123",
+				UserID:         "U123",
+				rotaID:         "ROTA_ID",
+				overrideUser:   "U456",
+				startsAt:       time.Date(2023, time.July, 10, 9, 0, 0, 0, time.UTC),
+				endsAt:         time.Date(2023, time.July, 11, 9, 0, 0, 0, time.UTC),
+				externalID:     "E1",
+				previousViewID: "V1",
+			}))
+		})
+	})
+
+	Describe("SkipShift", func() {
+		It("resolves the member and reason given on the action", func() {
+			params := ResolverParams{
+				Action: slack.InteractionCallback{
+					TriggerID: "T123",
+					Team:      slack.Team{ID: "TM123"},
+					User:      slack.User{ID: "U123"},
+					View: slack.View{
+						CallbackID:      string(VTSkipShift),
+						PrivateMetadata: "{\"rota_id\":\"ROTA_ID\",\"channel_id\":\"C123\"}",
+						PreviousViewID:  "V1",
+						ExternalID:      "E1",
+						State: &slack.ViewState{
+							Values: map[string]map[string]slack.BlockAction{
+								"SKIP_USER":   {"SKIP_USER": {SelectedUser: "U456"}},
+								"SKIP_REASON": {"SKIP_REASON": {Value: "Out sick"}},
+							},
+						},
+					},
+				},
+			}
+
+			view, err := Resolve(ctx, params)
+			Expect(err).ToNot(HaveOccurred())
+
+			skipView, ok := view.(*SkipShift)
+			Expect(ok).To(BeTrue())
+			Expect(skipView.State).To(Equal(&SkipShiftState{
+				TriggerID:      "T123",
+				ChannelID:      "C123",
+				TeamID:         "TM123",
+				UserID:         "U123",
+				rotaID:         "ROTA_ID",
+				skipUser:       "U456",
+				reason:         "Out sick",
+				externalID:     "E1",
+				previousViewID: "V1",
+			}))
+		})
+	})
+
+	Describe("AppHome", func() {
+		It("resolves the rota of the button clicked on the home tab", func() {
+			params := ResolverParams{
+				Action: slack.InteractionCallback{
+					TriggerID: "T123",
+					Team:      slack.Team{ID: "TM123"},
+					User:      slack.User{ID: "U123"},
+					Container: slack.Container{Type: "view", ViewID: "V123"},
+					View: slack.View{
+						Type:       slack.VTHomeTab,
+						CallbackID: string(VTAppHome),
+					},
+					ActionCallback: slack.ActionCallbacks{
+						BlockActions: []*slack.BlockAction{{ActionID: string(AHEditRota), Value: "ROTA_ID"}},
+					},
+				},
+			}
+
+			view, err := Resolve(ctx, params)
+			Expect(err).ToNot(HaveOccurred())
+
+			appHome, ok := view.(*AppHome)
+			Expect(ok).To(BeTrue())
+			Expect(appHome.State).To(Equal(&AppHomeState{
+				TriggerID: "T123",
+				TeamID:    "TM123",
+				UserID:    "U123",
+				rotaID:    "ROTA_ID",
+				action:    AHEditRota,
+			}))
+		})
+	})
+
+	Describe("AddUnavailability", func() {
+		It("resolves the unavailability given on the action", func() {
+			params := ResolverParams{
+				Action: slack.InteractionCallback{
+					TriggerID: "T123",
+					Team:      slack.Team{ID: "TM123"},
+					User:      slack.User{ID: "U123"},
+					View: slack.View{
+						CallbackID:      string(VTAddUnavailability),
+						PrivateMetadata: "{\"channel_id\":\"C123\"}",
+						PreviousViewID:  "V1",
+						ExternalID:      "E1",
+						State: &slack.ViewState{
+							Values: map[string]map[string]slack.BlockAction{
+								"UNAVAILABILITY_START": {"UNAVAILABILITY_START": {SelectedDateTime: 1688979600}},
+								"UNAVAILABILITY_END":   {"UNAVAILABILITY_END": {SelectedDateTime: 1689066000}},
+							},
+						},
+					},
+				},
+			}
+
+			view, err := Resolve(ctx, params)
+			Expect(err).ToNot(HaveOccurred())
+
+			unavailabilityView, ok := view.(*AddUnavailability)
+			Expect(ok).To(BeTrue())
+			Expect(unavailabilityView.State).To(Equal(&AddUnavailabilityState{
+				TriggerID:      "T123",
+				ChannelID:      "C123",
+				TeamID:         "TM123",
+				UserID:         "U123",
+				startsAt:       time.Date(2023, time.July, 10, 9, 0, 0, 0, time.UTC),
+				endsAt:         time.Date(2023, time.July, 11, 9, 0, 0, 0, time.UTC),
+				externalID:     "E1",
+				previousViewID: "V1",
+			}))
+		})
+	})
+
+	Describe("ReorderMembers", func() {
+		It("resolves the member being moved", func() {
+			params := ResolverParams{
+				Action: slack.InteractionCallback{
+					Team: slack.Team{ID: "TM123"},
+					User: slack.User{ID: "U123"},
+					View: slack.View{
+						ID:              "V123",
+						CallbackID:      string(VTReorderMembers),
+						PrivateMetadata: "{\"rota_id\":\"ROTA_ID\",\"channel_id\":\"C123\"}",
+					},
+					ActionCallback: slack.ActionCallbacks{
+						BlockActions: []*slack.BlockAction{{BlockID: "U456", ActionID: string(RMAMoveUp), Value: "U456"}},
+					},
+				},
+			}
+
+			view, err := Resolve(ctx, params)
+			Expect(err).ToNot(HaveOccurred())
+
+			reorderView, ok := view.(*ReorderMembers)
+			Expect(ok).To(BeTrue())
+			Expect(reorderView.State).To(Equal(&ReorderMembersState{
+				ChannelID: "C123",
+				TeamID:    "TM123",
+				UserID:    "U123",
+				rotaID:    "ROTA_ID",
+				member:    "U456",
+				action:    RMAMoveUp,
+				viewID:    "V123",
+			}))
+		})
+	})
+
+	Describe("Schedule", func() {
+		It("resolves the action on the schedule of the rota", func() {
+			params := ResolverParams{
+				Action: slack.InteractionCallback{
+					TriggerID: "TR123",
+					Team:      slack.Team{ID: "TM123"},
+					User:      slack.User{ID: "U123"},
+					View: slack.View{
+						CallbackID:      string(VTSchedule),
+						PrivateMetadata: "{\"rota_id\":\"ROTA_ID\",\"channel_id\":\"C123\"}",
+					},
+					ActionCallback: slack.ActionCallbacks{
+						BlockActions: []*slack.BlockAction{{BlockID: "SCHEDULE_ACTIONS", ActionID: string(SAReorderMembers)}},
+					},
+				},
+			}
+
+			view, err := Resolve(ctx, params)
+			Expect(err).ToNot(HaveOccurred())
+
+			scheduleView, ok := view.(*Schedule)
+			Expect(ok).To(BeTrue())
+			Expect(scheduleView.State).To(Equal(&ScheduleState{
+				TriggerID: "TR123",
+				ChannelID: "C123",
+				TeamID:    "TM123",
+				UserID:    "U123",
+				rotaID:    "ROTA_ID",
+				action:    SAReorderMembers,
+			}))
+		})
+	})
+
+	Describe("SwapShift", func() {
+		It("resolves the shifts given on the action", func() {
+			params := ResolverParams{
+				Action: slack.InteractionCallback{
+					User: slack.User{ID: "U123"},
+					View: slack.View{
+						ID:              "V123",
+						CallbackID:      string(VTSwapShift),
+						PrivateMetadata: "{\"rota_id\":\"ROTA_ID\",\"channel_id\":\"C123\"}",
+						State: &slack.ViewState{
+							Values: map[string]map[string]slack.BlockAction{
+								"SWAP_SHIFT": {"SWAP_SHIFT": {SelectedOption: slack.OptionBlockObject{Value: "1688979600"}}},
+								"SWAP_USER":  {"SWAP_USER": {SelectedUser: "U456"}},
+								"SWAP_WITH":  {"SWAP_WITH": {SelectedOption: slack.OptionBlockObject{Value: "1689584400"}}},
+							},
+						},
+					},
+					ActionCallback: slack.ActionCallbacks{
+						BlockActions: []*slack.BlockAction{{ActionID: "SWAP_USER"}},
+					},
+				},
+			}
+
+			view, err := Resolve(ctx, params)
+			Expect(err).ToNot(HaveOccurred())
+
+			swapView, ok := view.(*SwapShift)
+			Expect(ok).To(BeTrue())
+			Expect(swapView.State.rotaID).To(Equal("ROTA_ID"))
+			Expect(swapView.State.UserID).To(Equal("U123"))
+			Expect(swapView.State.viewID).To(Equal("V123"))
+			Expect(swapView.State.action).To(Equal("SWAP_USER"))
+			Expect(swapView.State.shift).To(Equal("1688979600"))
+			Expect(swapView.State.recipient).To(Equal("U456"))
+			Expect(swapView.State.recipientShift).To(Equal("1689584400"))
+		})
+	})
+
+	Describe("Messages", func() {
+		It("resolves the answer to a swap request from the buttons of the message", func() {
+			params := ResolverParams{
+				Action: slack.InteractionCallback{
+					Team:      slack.Team{ID: "TM123"},
+					User:      slack.User{ID: "U456"},
+					Container: slack.Container{Type: "message", ChannelID: "D123", MessageTs: "1700000000.000100"},
+					ActionCallback: slack.ActionCallbacks{
+						BlockActions: []*slack.BlockAction{
+							{BlockID: string(VTSwapRequest), ActionID: string(SRAAccept), Value: "SW123"},
+						},
+					},
+				},
+			}
+
+			view, err := Resolve(ctx, params)
+			Expect(err).ToNot(HaveOccurred())
+
+			requestView, ok := view.(*SwapRequest)
+			Expect(ok).To(BeTrue())
+			Expect(requestView.State).To(Equal(&SwapRequestState{
+				TeamID:    "TM123",
+				UserID:    "U456",
+				ChannelID: "D123",
+				messageTS: "1700000000.000100",
+				swapID:    "SW123",
+				action:    SRAAccept,
+			}))
+		})
+
+		It("returns an unknown callback error for messages it doesn't know", func() {
+			params := ResolverParams{
+				Action: slack.InteractionCallback{
+					Container: slack.Container{Type: "message"},
+					ActionCallback: slack.ActionCallbacks{
+						BlockActions: []*slack.BlockAction{{BlockID: "SOMETHING_ELSE"}},
+					},
+				},
+			}
+
+			_, err := Resolve(ctx, params)
+			Expect(err).To(MatchError(ErrUnknownCallbackID))
+		})
 	})
 })
@@ -1,11 +1,21 @@
 package views
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/getsentry/sentry-go"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rotabot-io/rotabot/slack/announce"
 	"github.com/rotabot-io/rotabot/slack/slackclient"
 
 	gen "github.com/rotabot-io/rotabot/gen/slack"
@@ -13,7 +23,10 @@ import (
 
 	"github.com/rotabot-io/rotabot/slack/block"
 
+	"github.com/rotabot-io/rotabot/lib/calendar"
+	"github.com/rotabot-io/rotabot/lib/cron"
 	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/rotation"
 	"github.com/rotabot-io/rotabot/lib/zapctx"
 	"github.com/slack-go/slack"
 )
@@ -21,19 +34,44 @@ import (
 type SaveRota struct {
 	Repository db.Repository
 	State      *SaveRotaState
+	// Clock resolves "now", the system clock is used when it's nil.
+	Clock rotation.Clock
 }
 
 type SaveRotaState struct {
 	TriggerID      string
 	ChannelID      string
 	TeamID         string
+	UserID         string
 	rotaID         string
 	rotaName       string
 	frequency      db.RotaFrequency
 	schedulingType db.RotaSchedule
+	every          int
+	weekday        time.Weekday
+	handoverTime   string
+	cron           string
+	timeZone       string
+	skipWeekends   bool
+	calendar       string
+	calendarFile   string
+	nonWorkingDays db.NonWorkingDayPolicy
+	tiers          int
+	removeLeavers  bool
+	// windows is nil for rotas that have a single rotation around the clock, see db.Window.
+	windows []db.Window
+	// startsOn and endsOn are the dates, formatted as YYYY-MM-DD, of the first and last days of rotas that don't
+	// run indefinitely.
+	startsOn       string
+	endsOn         string
 	externalID     string
 	previousViewID string
-	userIds        []string
+	viewID         string
+	action         string
+	// edited is set when the state comes from the values of a modal the user is filling in, those take precedence
+	// over what's stored for the rota.
+	edited  bool
+	userIds []string
 }
 
 type SaveRotaProps struct {
@@ -47,43 +85,103 @@ func (v SaveRota) CallbackID() ViewType {
 	return VTSaveRota
 }
 
+// NewCreateRota returns the modal that creates a rota in the channel of the state, with its name filled in.
+func NewCreateRota(repo db.Repository, state SaveRotaState, name string) SaveRota {
+	v := SaveRota{Repository: repo}
+	v.State = v.DefaultState().(*SaveRotaState)
+	v.State.TriggerID = state.TriggerID
+	v.State.ChannelID = state.ChannelID
+	v.State.TeamID = state.TeamID
+	v.State.UserID = state.UserID
+	v.State.rotaName = name
+	return v
+}
+
 func (v SaveRota) DefaultState() interface{} {
 	return &SaveRotaState{
 		frequency:      db.RFWeekly,
 		schedulingType: db.RSCreated,
+		every:          1,
+		weekday:        time.Monday,
+		handoverTime:   "09:00",
+		calendar:       noHolidays,
+		nonWorkingDays: db.NWExtend,
+		tiers:          1,
 	}
 }
 
+const (
+	everyDay         = "Every day"
+	weekdaysOnly     = "Weekdays only"
+	noHolidays       = "No holidays"
+	uploadedCalendar = "Uploaded file"
+	leaversStay      = "Stay in the rota"
+	leaversRemoved   = "Are removed from the rota"
+)
+
 func (v SaveRota) BuildProps(ctx context.Context) (interface{}, error) {
-	var title *slack.TextBlockObject
-	var submit *slack.TextBlockObject
-	var rotaName string
-	var frequency db.RotaFrequency
-	var schedulingType db.RotaSchedule
+	title := block.NewDefaultText("Create Rota")
+	submit := block.NewDefaultText("Create")
 
 	l := zapctx.Logger(ctx)
 	if v.State.rotaID != "" {
-		rota, err := v.Repository.FindRotaByID(ctx, v.State.rotaID)
-		if err != nil {
-			l.Error("failed_to_find", zap.Error(err))
-			return nil, err
-		}
-		rotaName = rota.Name
-		frequency = rota.Metadata.Frequency
-		schedulingType = rota.Metadata.SchedulingType
 		title = block.NewDefaultText("Update Rota")
 		submit = block.NewDefaultText("Update")
-		v.State.userIds, err = v.Repository.ListUserIDsByRotaID(ctx, v.State.rotaID)
-		if err != nil {
-			l.Error("failed_to_list_members", zap.Error(err))
-			return nil, err
+		if !v.State.edited {
+			rota, err := v.Repository.FindRotaByID(ctx, v.State.rotaID)
+			if err != nil {
+				l.Error("failed_to_find", zap.Error(err))
+				return nil, err
+			}
+			v.State.rotaName = rota.Name
+			v.State.frequency = rota.Metadata.Frequency
+			v.State.schedulingType = rota.Metadata.SchedulingType
+			if c := rota.Metadata.Cadence; c != nil {
+				v.State.every = c.Every
+				v.State.weekday = c.Weekday
+				v.State.handoverTime = c.Time
+			}
+			v.State.cron = rota.Metadata.Cron
+			v.State.timeZone = rota.Metadata.TimeZone
+			v.State.tiers = rotation.Tiers(rota)
+			v.State.windows = rota.Metadata.Windows
+			v.State.removeLeavers = rota.Metadata.RemoveLeavers
+			v.State.startsOn, v.State.endsOn = rotaDates(rota)
+			if wd := rota.Metadata.WorkingDays; wd != nil {
+				v.State.skipWeekends = wd.SkipWeekends
+				v.State.calendarFile = wd.CalendarFile
+				v.State.nonWorkingDays = wd.Policy
+				switch {
+				case wd.Calendar != "":
+					v.State.calendar = wd.Calendar
+				case wd.CalendarFile != "":
+					v.State.calendar = uploadedCalendar
+				}
+			}
+			v.State.userIds, err = v.Repository.ListUserIDsByRotaID(ctx, v.State.rotaID)
+			if err != nil {
+				l.Error("failed_to_list_members", zap.Error(err))
+				return nil, err
+			}
 		}
-	} else {
-		title = block.NewDefaultText("Create Rota")
-		submit = block.NewDefaultText("Create")
-		rotaName = v.State.rotaName
-		frequency = v.State.frequency
-		schedulingType = v.State.schedulingType
+	}
+
+	if v.State.timeZone == "" {
+		v.State.timeZone = v.defaultTimeZone(ctx)
+	}
+	if v.State.calendar == "" {
+		v.State.calendar = noHolidays
+	}
+	if v.State.nonWorkingDays == "" {
+		v.State.nonWorkingDays = db.NWExtend
+	}
+	if v.State.tiers < 1 {
+		v.State.tiers = 1
+	}
+
+	schedulingTypes := []block.StaticSelectOption{}
+	for _, t := range db.SchedulingTypes {
+		schedulingTypes = append(schedulingTypes, block.StaticSelectOption{Text: string(t)})
 	}
 
 	blocks := []slack.Block{
@@ -91,33 +189,86 @@ func (v SaveRota) BuildProps(ctx context.Context) (interface{}, error) {
 			BlockID: "ROTA_NAME",
 			Label:   "Name:",
 			Hint:    "e.g. 'On Call'",
-			Value:   rotaName,
+			Value:   v.State.rotaName,
 		}),
 		block.NewStaticSelect(block.StaticSelect{
 			BlockID:       "ROTA_FREQUENCY",
 			Label:         "Frequency:",
-			InitialOption: block.StaticSelectOption{Text: string(frequency)},
+			InitialOption: block.StaticSelectOption{Text: string(v.State.frequency)},
 			Options: []block.StaticSelectOption{
 				{Text: string(db.RFDaily)},
 				{Text: string(db.RFWeekly)},
 				{Text: string(db.RFMonthly)},
+				{Text: string(db.RFCron)},
 			},
 		}),
+	}
+	blocks = append(blocks, v.cadenceBlocks()...)
+	blocks = append(blocks,
+		block.NewTextInput(block.TextInput{
+			BlockID: "ROTA_TIME_ZONE",
+			Label:   "Time zone:",
+			Hint:    "e.g. Europe/London",
+			Value:   v.State.timeZone,
+		}),
 		block.NewStaticSelect(block.StaticSelect{
 			BlockID:       "ROTA_TYPE",
 			Label:         "Scheduling Type:",
-			InitialOption: block.StaticSelectOption{Text: string(schedulingType)},
+			InitialOption: block.StaticSelectOption{Text: string(v.State.schedulingType)},
+			Options:       schedulingTypes,
+		}),
+		block.NewStaticSelect(block.StaticSelect{
+			BlockID:       "ROTA_WINDOWS",
+			Label:         "Follow the sun:",
+			InitialOption: windowsOption(len(v.State.windows)),
 			Options: []block.StaticSelectOption{
-				{Text: string(db.RSCreated)},
-				{Text: string(db.RSRandom)},
+				windowsOption(0),
+				windowsOption(2),
+				windowsOption(3),
+				windowsOption(4),
 			},
 		}),
-		block.NewUserSelect(block.UserSelect{
-			BlockID: "ROTA_MEMBERS",
-			Label:   "Members:",
-			UserIDs: v.State.userIds,
+	)
+	blocks = append(blocks, v.membersBlocks()...)
+	blocks = append(blocks,
+		block.NewNumberInput(block.NumberInput{
+			BlockID:  "ROTA_TIERS",
+			Label:    "How many members are on duty at once:",
+			Hint:     "e.g. 2 for a primary and a secondary",
+			Value:    strconv.Itoa(v.State.tiers),
+			MinValue: "1",
 		}),
+	)
+	if v.State.rotaID != "" {
+		notice, err := v.unavailabilityNotice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if notice != "" {
+			blocks = append(blocks, slack.NewContextBlock("ROTA_UNAVAILABLE", block.NewMarkdownText(notice)))
+		}
 	}
+	blocks = append(blocks, v.workingDaysBlocks()...)
+	blocks = append(blocks,
+		block.NewDatePicker(block.DatePicker{
+			BlockID:  "ROTA_STARTS",
+			Label:    "Starts on:",
+			Date:     v.State.startsOn,
+			Optional: true,
+		}),
+		block.NewDatePicker(block.DatePicker{
+			BlockID:  "ROTA_ENDS",
+			Label:    "Ends after:",
+			Date:     v.State.endsOn,
+			Optional: true,
+		}),
+		v.leaversBlock(),
+	)
+	preview, err := v.preview(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blocks = append(blocks, slack.NewContextBlock("ROTA_PREVIEW", block.NewMarkdownText(preview)))
 	return &SaveRotaProps{
 		title:  title,
 		submit: submit,
@@ -126,11 +277,388 @@ func (v SaveRota) BuildProps(ctx context.Context) (interface{}, error) {
 	}, nil
 }
 
+// leaversBlock asks what happens to the members of the rota that leave its channel.
+func (v SaveRota) leaversBlock() slack.Block {
+	leavers := leaversStay
+	if v.State.removeLeavers {
+		leavers = leaversRemoved
+	}
+	return block.NewStaticSelect(block.StaticSelect{
+		BlockID:       "ROTA_LEAVERS",
+		Label:         "Members who leave the channel:",
+		InitialOption: block.StaticSelectOption{Text: leavers},
+		Options:       []block.StaticSelectOption{{Text: leaversStay}, {Text: leaversRemoved}},
+	})
+}
+
+// unavailabilityNotice flags the members picked that are out during the current or next shift of the rota as
+// it's saved.
+func (v SaveRota) unavailabilityNotice(ctx context.Context) (string, error) {
+	rota, err := v.Repository.FindRotaByID(ctx, v.State.rotaID)
+	if err != nil {
+		zapctx.Logger(ctx).Error("failed_to_find", zap.Error(err))
+		return "", err
+	}
+	return unavailabilityNotice(ctx, v.Repository, rota, v.State.memberIDs())
+}
+
+func (v SaveRota) clock() rotation.Clock {
+	if v.Clock == nil {
+		return rotation.SystemClock
+	}
+	return v.Clock
+}
+
+// previewSize is how many shifts the preview of the rota lists.
+const previewSize = 5
+
+// preview describes the next few shifts of the rota as it's filled in, before it's saved. Existing members keep
+// their position and new ones are added at the end, like they will be once the rota is saved.
+func (v SaveRota) preview(ctx context.Context) (string, error) {
+	l := zapctx.Logger(ctx)
+	now := v.clock().Now()
+	rota := db.Rota{ID: v.State.rotaID, TeamID: v.State.TeamID, CreatedAt: db.Timestamptz(now)}
+	existing := []db.Member{}
+	if v.State.rotaID != "" {
+		var err error
+		if rota, err = v.Repository.FindRotaByID(ctx, v.State.rotaID); err != nil {
+			l.Error("failed_to_find", zap.Error(err))
+			return "", err
+		}
+		if existing, err = v.Repository.ListMembersByRotaID(ctx, v.State.rotaID); err != nil {
+			l.Error("failed_to_list_members", zap.Error(err))
+			return "", err
+		}
+	}
+	metadata, err := v.buildMetadata(ctx, rota.Metadata, false)
+	if err != nil {
+		return "", err
+	}
+	rota.Metadata = metadata
+	startsAt, endsAt := v.State.period()
+	rota.StartsAt, rota.EndsAt = pgtype.Timestamptz{}, pgtype.Timestamptz{}
+	if !startsAt.IsZero() {
+		rota.StartsAt = db.Timestamptz(startsAt)
+	}
+	if !endsAt.IsZero() {
+		rota.EndsAt = db.Timestamptz(endsAt)
+	}
+
+	userIDs := v.State.memberIDs()
+	members := []db.Member{}
+	for i, userID := range userIDs {
+		m := db.Member{RotaID: rota.ID, UserID: userID, Position: int32(len(existing) + i), CreatedAt: db.Timestamptz(now)}
+		if inx := slices.IndexFunc(existing, func(e db.Member) bool { return e.UserID == userID }); inx != -1 {
+			m = existing[inx]
+		}
+		members = append(members, m)
+	}
+	engine, err := rotation.LoadWith(ctx, v.Repository, rota, members, v.clock())
+	if err != nil {
+		return "", err
+	}
+	lines, err := scheduleLines(ctx, v.Repository, rota, engine, userIDs, previewSize)
+	if err != nil {
+		return "", err
+	}
+	if len(lines) == 0 {
+		return "Once the rota has members and a valid schedule, its upcoming shifts show up here.", nil
+	}
+	return "Upcoming shifts:\n" + strings.Join(lines, "\n"), nil
+}
+
+// rotaDates returns the first and last days of the rota in its own time zone, they're empty when the rota started
+// as soon as it was created or runs indefinitely.
+func rotaDates(rota db.Rota) (string, string) {
+	loc, err := rotation.Location(rota)
+	if err != nil {
+		loc = time.UTC
+	}
+	startsOn, endsOn := "", ""
+	if rota.StartsAt.Valid {
+		startsOn = rota.StartsAt.Time.In(loc).Format(time.DateOnly)
+	}
+	if rota.EndsAt.Valid {
+		// The rota ends at midnight, once the last day is over.
+		endsOn = rota.EndsAt.Time.In(loc).AddDate(0, 0, -1).Format(time.DateOnly)
+	}
+	return startsOn, endsOn
+}
+
+// period returns when the rota starts and ends, from midnight of its first day until midnight after its last one
+// in the rota's time zone. Either of them is zero when the user didn't pick a date.
+func (s *SaveRotaState) period() (time.Time, time.Time) {
+	loc, err := time.LoadLocation(s.timeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	var startsAt, endsAt time.Time
+	if d, err := time.ParseInLocation(time.DateOnly, s.startsOn, loc); err == nil {
+		startsAt = d
+	}
+	if d, err := time.ParseInLocation(time.DateOnly, s.endsOn, loc); err == nil {
+		endsAt = d.AddDate(0, 0, 1)
+	}
+	return startsAt, endsAt
+}
+
+// windowsOption is the option to split the day into the given number of windows, rotas that don't follow the sun
+// have none.
+func windowsOption(n int) block.StaticSelectOption {
+	if n == 0 {
+		return block.StaticSelectOption{Text: "Around the clock", Value: "0"}
+	}
+	return block.StaticSelectOption{Text: fmt.Sprintf("%d windows a day", n), Value: strconv.Itoa(n)}
+}
+
+// membersBlocks returns the fields to pick the members of the rota, rotas that follow the sun pick them for each
+// of their windows.
+func (v SaveRota) membersBlocks() []slack.Block {
+	if len(v.State.windows) == 0 {
+		return []slack.Block{
+			block.NewUserSelect(block.UserSelect{
+				BlockID: "ROTA_MEMBERS",
+				Label:   "Members:",
+				UserIDs: v.State.userIds,
+			}),
+		}
+	}
+	blocks := []slack.Block{}
+	for i, w := range v.State.windows {
+		blocks = append(blocks,
+			block.NewTextInput(block.TextInput{
+				BlockID: fmt.Sprintf("ROTA_WINDOW_NAME_%d", i),
+				Label:   fmt.Sprintf("Window %d:", i+1),
+				Hint:    "e.g. 'EMEA'",
+				Value:   w.Name,
+			}),
+			block.NewTimePicker(block.TimePicker{
+				BlockID: fmt.Sprintf("ROTA_WINDOW_START_%d", i),
+				Label:   "Starts at, and lasts until the next window starts:",
+				Time:    w.Start,
+			}),
+			block.NewMultiUserInput(block.MultiUserInput{
+				BlockID: fmt.Sprintf("ROTA_WINDOW_MEMBERS_%d", i),
+				Label:   "Members:",
+				UserIDs: w.UserIDs,
+			}),
+		)
+	}
+	return blocks
+}
+
+// cadenceBlocks returns the fields that decide when the rota hands over, they depend on the frequency picked
+// and the modal is updated whenever it changes.
+func (v SaveRota) cadenceBlocks() []slack.Block {
+	if v.State.frequency == db.RFCron {
+		return []slack.Block{
+			block.NewTextInput(block.TextInput{
+				BlockID:         "ROTA_CRON",
+				Label:           "Cron expression:",
+				Hint:            "e.g. 0 10 * * MON,THU",
+				Value:           v.State.cron,
+				DispatchOnEnter: true,
+			}),
+			slack.NewContextBlock("ROTA_CRON_PREVIEW", block.NewMarkdownText(v.cronPreview())),
+		}
+	}
+
+	blocks := []slack.Block{
+		block.NewNumberInput(block.NumberInput{
+			BlockID:  "ROTA_EVERY",
+			Label:    fmt.Sprintf("Hand over every how many %s:", frequencyUnits[v.State.frequency]),
+			Hint:     "e.g. 2",
+			Value:    strconv.Itoa(v.State.every),
+			MinValue: "1",
+		}),
+	}
+	if v.State.frequency == db.RFWeekly {
+		weekdays := []block.StaticSelectOption{}
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			weekdays = append(weekdays, block.StaticSelectOption{Text: d.String()})
+		}
+		blocks = append(blocks, block.NewStaticSelect(block.StaticSelect{
+			BlockID:       "ROTA_WEEKDAY",
+			Label:         "Handover day:",
+			InitialOption: block.StaticSelectOption{Text: v.State.weekday.String()},
+			Options:       weekdays,
+		}))
+	}
+	return append(blocks, block.NewTimePicker(block.TimePicker{
+		BlockID: "ROTA_TIME",
+		Label:   "Handover time:",
+		Time:    v.State.handoverTime,
+	}))
+}
+
+// workingDaysBlocks returns the fields that decide on which days the rota hands over, the modal is updated
+// whenever they change since the fields for uploading holidays and what to do on non-working days depend on them.
+func (v SaveRota) workingDaysBlocks() []slack.Block {
+	workingDays := everyDay
+	if v.State.skipWeekends {
+		workingDays = weekdaysOnly
+	}
+	calendars := []block.StaticSelectOption{{Text: noHolidays}}
+	for _, name := range calendar.Names() {
+		calendars = append(calendars, block.StaticSelectOption{Text: name})
+	}
+	calendars = append(calendars, block.StaticSelectOption{Text: uploadedCalendar})
+
+	blocks := []slack.Block{
+		block.NewStaticSelect(block.StaticSelect{
+			BlockID:       "ROTA_WORKING_DAYS",
+			Label:         "Hands over on:",
+			InitialOption: block.StaticSelectOption{Text: workingDays},
+			Options:       []block.StaticSelectOption{{Text: everyDay}, {Text: weekdaysOnly}},
+		}),
+		block.NewStaticSelect(block.StaticSelect{
+			BlockID:       "ROTA_CALENDAR",
+			Label:         "Holidays:",
+			InitialOption: block.StaticSelectOption{Text: v.State.calendar},
+			Options:       calendars,
+		}),
+	}
+	if v.State.calendar == uploadedCalendar {
+		blocks = append(blocks, block.NewTextInput(block.TextInput{
+			BlockID: "ROTA_CALENDAR_FILE",
+			Label:   "Holidays file:",
+			Hint:    "Link to an iCalendar or CSV file shared in Slack",
+			Value:   v.State.calendarFile,
+		}))
+	}
+	if v.State.skipWeekends || v.State.hasHolidays() {
+		blocks = append(blocks, block.NewStaticSelect(block.StaticSelect{
+			BlockID:       "ROTA_NON_WORKING_DAYS",
+			Label:         "On non-working days:",
+			InitialOption: block.StaticSelectOption{Text: string(v.State.nonWorkingDays)},
+			Options:       []block.StaticSelectOption{{Text: string(db.NWExtend)}, {Text: string(db.NWSkip)}},
+		}))
+	}
+	return blocks
+}
+
+// cronPreview describes when the rota will hand over next, or what's wrong with the cron expression.
+func (v SaveRota) cronPreview() string {
+	if v.State.cron == "" {
+		return "Press enter to see when the rota will hand over."
+	}
+	schedule, err := cron.Parse(v.State.cron)
+	if err != nil {
+		return ":warning: " + cronError(err)
+	}
+	loc, err := time.LoadLocation(v.State.timeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	times := []string{}
+	t := v.clock().Now().In(loc)
+	for i := 0; i < cronPreviewSize; i++ {
+		if t = schedule.Next(t); t.IsZero() {
+			break
+		}
+		times = append(times, announce.FormatTime(t))
+	}
+	if len(times) == 0 {
+		return ":warning: This expression never hands over."
+	}
+	return "Next handovers: " + strings.Join(times, ", ")
+}
+
+const cronPreviewSize = 5
+
+// defaultTimeZone returns the time zone of the user creating the rota, since they are the most likely to know
+// when it should hand over.
+func (v SaveRota) defaultTimeZone(ctx context.Context) string {
+	l := zapctx.Logger(ctx)
+	if v.State.UserID == "" {
+		return "UTC"
+	}
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		return "UTC"
+	}
+	user, err := client.GetUserInfoContext(ctx, v.State.UserID)
+	if err != nil || user.TZ == "" {
+		l.Warn("failed_to_get_user_time_zone", zap.Error(err))
+		return "UTC"
+	}
+	return user.TZ
+}
+
+// cronError turns a parsing error into a message we can show to users.
+func cronError(err error) string {
+	return strings.TrimPrefix(err.Error(), cron.ErrInvalidExpression.Error()+": ")
+}
+
+var frequencyUnits = map[db.RotaFrequency]string{
+	db.RFDaily:   "days",
+	db.RFWeekly:  "weeks",
+	db.RFMonthly: "months",
+}
+
 func (v SaveRota) OnAction(ctx context.Context) (*gen.ActionResponse, error) {
-	zapctx.Logger(ctx).Debug("action_view")
+	l := zapctx.Logger(ctx)
+	l.Debug("action_view")
+	if !slices.Contains(saveRotaRenderActions, v.State.action) {
+		return &gen.ActionResponse{}, nil
+	}
+
+	// Changing the frequency, the working days or the windows changes which fields make sense, and those together
+	// with the cron expression, the scheduling type and the members change the preview of the rota, so the modal
+	// is rendered again with what the user has filled in so far.
+	p, err := v.BuildProps(ctx)
+	if err != nil {
+		l.Error("failed_to_build_props", zap.Error(err))
+		return nil, err
+	}
+	props, ok := p.(*SaveRotaProps)
+	if !ok {
+		l.Error("received_invalid_props")
+		return nil, errors.New("received invalid props")
+	}
+
+	bytes, err := json.Marshal(Metadata{RotaID: v.State.rotaID, ChannelID: v.State.ChannelID})
+	if err != nil {
+		l.Error("failed_to_marshal_metadata", zap.Error(err))
+		return nil, err
+	}
+
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return nil, err
+	}
+	r := slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Title:           props.title,
+		Submit:          props.submit,
+		Close:           props.close,
+		Blocks:          props.blocks,
+		CallbackID:      string(v.CallbackID()),
+		NotifyOnClose:   true,
+		ClearOnClose:    true,
+		PrivateMetadata: string(bytes),
+	}
+	if _, err = client.UpdateViewContext(ctx, r, "", "", v.State.viewID); err != nil {
+		l.Error("failed_to_update_view", zap.Error(err))
+		return nil, err
+	}
 	return &gen.ActionResponse{}, nil
 }
 
+// saveRotaRenderActions are the actions after which the modal is rendered again.
+var saveRotaRenderActions = []string{
+	"ROTA_FREQUENCY",
+	"ROTA_CRON",
+	"ROTA_WORKING_DAYS",
+	"ROTA_CALENDAR",
+	"ROTA_WINDOWS",
+	"ROTA_TYPE",
+	"ROTA_MEMBERS",
+}
+
 func (v SaveRota) OnClose(ctx context.Context) (*gen.ActionResponse, error) {
 	zapctx.Logger(ctx).Debug("closing_view")
 	return &gen.ActionResponse{}, nil
@@ -138,12 +666,34 @@ func (v SaveRota) OnClose(ctx context.Context) (*gen.ActionResponse, error) {
 
 func (v SaveRota) OnSubmit(ctx context.Context) (*gen.ActionResponse, error) {
 	l := zapctx.Logger(ctx)
+	if errs := v.validate(); len(errs) > 0 {
+		response := string(slack.RAErrors)
+		return &gen.ActionResponse{ResponseAction: &response, Errors: errs}, nil
+	}
+	metadata, err := v.metadata(ctx)
+	if errors.Is(err, errUnreadableCalendar) {
+		l.Warn("failed_to_read_calendar_file", zap.Error(err))
+		response := string(slack.RAErrors)
+		return &gen.ActionResponse{
+			ResponseAction: &response,
+			Errors: map[string]string{
+				"ROTA_CALENDAR_FILE": "We couldn't read the holidays in this file, share an iCalendar or CSV file in Slack and paste its link here.",
+			},
+		}, nil
+	}
+	if err != nil {
+		l.Error("failed_to_build_metadata", zap.Error(err))
+		return nil, err
+	}
+	startsAt, endsAt := v.State.period()
 	rotaId, err := v.Repository.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
 		RotaID:    v.State.rotaID,
 		TeamID:    v.State.TeamID,
 		ChannelID: v.State.ChannelID,
 		Name:      v.State.rotaName,
-		Metadata:  db.RotaMetadata{Frequency: v.State.frequency, SchedulingType: v.State.schedulingType},
+		Metadata:  metadata,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
 	})
 	if err != nil {
 		if errors.Is(err, db.ErrAlreadyExists) {
@@ -160,7 +710,7 @@ func (v SaveRota) OnSubmit(ctx context.Context) (*gen.ActionResponse, error) {
 	}
 	l.Info("saved_rota", zap.String("rotaId", rotaId))
 	members := []db.Member{}
-	for _, userId := range v.State.userIds {
+	for _, userId := range v.State.memberIDs() {
 		members = append(members, db.Member{
 			UserID:   userId,
 			RotaID:   rotaId,
@@ -172,56 +722,209 @@ func (v SaveRota) OnSubmit(ctx context.Context) (*gen.ActionResponse, error) {
 		return nil, err
 	}
 
+	if v.State.previousViewID == "" {
+		// The modal was opened straight from a slash command, there's no home view to go back to.
+		return &gen.ActionResponse{}, nil
+	}
 	h := Home{
 		Repository: v.Repository,
 		State: &HomeState{
 			TriggerID: v.State.TriggerID,
 			ChannelID: v.State.ChannelID,
 			TeamID:    v.State.TeamID,
+			rotaID:    rotaId,
 		},
 	}
-	p, err := h.BuildProps(ctx)
-	if err != nil {
-		l.Error("failed_to_build_home_props", zap.Error(err))
+	if err = h.replace(ctx, v.State.externalID, v.State.previousViewID); err != nil {
 		return nil, err
 	}
-	props, ok := p.(*HomeProps)
-	if !ok {
-		l.Error("received_invalid_props")
-		return nil, errors.New("received invalid props")
+	return &gen.ActionResponse{}, nil
+}
+
+// metadata merges what the user submitted into the metadata the rota already has, so settings that are not part
+// of this modal, like the seed used for random scheduling, are kept when the rota is updated.
+func (v SaveRota) metadata(ctx context.Context) (db.RotaMetadata, error) {
+	metadata := db.RotaMetadata{}
+	if v.State.rotaID != "" {
+		rota, err := v.Repository.FindRotaByID(ctx, v.State.rotaID)
+		if err != nil {
+			return db.RotaMetadata{}, err
+		}
+		metadata = rota.Metadata
+	} else {
+		metadata.CreatedBy = v.State.UserID
 	}
+	return v.buildMetadata(ctx, metadata, true)
+}
 
-	// Slack does not recommend using the update view API when a modal has been submitted but in our case
-	// it's the only way to go back to the home view after creating the new rota.
-	// See https://slack.dev/java-slack-sdk/guides/modals
-	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+// buildMetadata merges what the user filled in into the given metadata. Holidays are only downloaded when download
+// is set, previews leave out the holidays of files that haven't been downloaded yet.
+func (v SaveRota) buildMetadata(ctx context.Context, metadata db.RotaMetadata, download bool) (db.RotaMetadata, error) {
+	metadata.Frequency = v.State.frequency
+	metadata.SchedulingType = v.State.schedulingType
+	metadata.TimeZone = v.State.timeZone
+	metadata.Tiers = v.State.tiers
+	metadata.Windows = v.State.windows
+	metadata.RemoveLeavers = v.State.removeLeavers
+	if v.State.frequency == db.RFCron {
+		metadata.Cron = v.State.cron
+	} else {
+		metadata.Cadence = &db.Cadence{
+			Every:   v.State.every,
+			Weekday: v.State.weekday,
+			Time:    v.State.handoverTime,
+		}
+	}
+	if metadata.Seed == 0 {
+		metadata.Seed = rand.Int63() // nolint:gosec
+	}
+	workingDays, err := v.workingDays(ctx, metadata.WorkingDays, download)
 	if err != nil {
-		l.Error("failed_to_get_client", zap.Error(err))
-		sentry.CaptureException(err)
-		return nil, err
+		return db.RotaMetadata{}, err
+	}
+	metadata.WorkingDays = workingDays
+	return metadata, nil
+}
+
+// memberIDs returns the members of the rota, which are those of all its windows when it follows the sun.
+func (s *SaveRotaState) memberIDs() []string {
+	if len(s.windows) == 0 {
+		return s.userIds
 	}
+	userIDs := []string{}
+	for _, w := range s.windows {
+		for _, userID := range w.UserIDs {
+			if !slices.Contains(userIDs, userID) {
+				userIDs = append(userIDs, userID)
+			}
+		}
+	}
+	return userIDs
+}
+
+func (s *SaveRotaState) hasHolidays() bool {
+	return s.calendar != "" && s.calendar != noHolidays
+}
+
+var errUnreadableCalendar = errors.New("unable to read holidays from the calendar file")
+
+// workingDays builds the working days of the rota from what the user submitted. Holidays are only downloaded
+// again when the link to the file they were uploaded from changes.
+func (v SaveRota) workingDays(ctx context.Context, existing *db.WorkingDays, download bool) (*db.WorkingDays, error) {
+	if !v.State.skipWeekends && !v.State.hasHolidays() {
+		return nil, nil
+	}
+	wd := &db.WorkingDays{SkipWeekends: v.State.skipWeekends, Policy: v.State.nonWorkingDays}
+	switch {
+	case !v.State.hasHolidays():
+	case v.State.calendar == uploadedCalendar:
+		wd.CalendarFile = v.State.calendarFile
+		if existing != nil && existing.CalendarFile == v.State.calendarFile && len(existing.Holidays) > 0 {
+			wd.Holidays = existing.Holidays
+			break
+		}
+		if !download {
+			break
+		}
+		holidays, err := v.downloadHolidays(ctx)
+		if err != nil {
+			return nil, err
+		}
+		wd.Holidays = holidays
+	default:
+		wd.Calendar = v.State.calendar
+	}
+	return wd, nil
+}
+
+// slackFileID matches the id of a file within a link to it, like https://acme.slack.com/files/U123/F0S43PZDF/holidays.ics
+var slackFileID = regexp.MustCompile(`\bF[A-Z0-9]{8,}\b`)
 
-	bytes, err := json.Marshal(Metadata{RotaID: rotaId, ChannelID: v.State.ChannelID})
+// downloadHolidays reads the holidays from the file shared in Slack the user linked to.
+func (v SaveRota) downloadHolidays(ctx context.Context) ([]calendar.Holiday, error) {
+	id := slackFileID.FindString(v.State.calendarFile)
+	if id == "" {
+		return nil, fmt.Errorf("%w: no file id in %q", errUnreadableCalendar, v.State.calendarFile)
+	}
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
 	if err != nil {
-		l.Error("failed_to_marshal_metadata", zap.Error(err))
 		return nil, err
 	}
+	file, _, _, err := client.GetFileInfoContext(ctx, id, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errUnreadableCalendar, err)
+	}
+	var buf bytes.Buffer
+	if err = client.GetFileContext(ctx, file.URLPrivateDownload, &buf); err != nil {
+		return nil, fmt.Errorf("%w: %s", errUnreadableCalendar, err)
+	}
+	holidays, err := calendar.Parse(file.Name, buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errUnreadableCalendar, err)
+	}
+	return holidays, nil
+}
 
-	r := slack.ModalViewRequest{
-		Type:            slack.VTModal,
-		Title:           props.title,
-		Blocks:          props.blocks,
-		CallbackID:      string(h.CallbackID()),
-		NotifyOnClose:   true,
-		ClearOnClose:    true,
-		PrivateMetadata: string(bytes),
+// validate returns the errors to display next to each of the fields of the modal.
+func (v SaveRota) validate() map[string]string {
+	errs := map[string]string{}
+	if !slices.Contains(db.SchedulingTypes, v.State.schedulingType) {
+		errs["ROTA_TYPE"] = "Pick one of the scheduling types in the list."
 	}
-	emptyHash := "" // This is empty to avoid slack thinking this view is outdated (and fail with a hash_conflict error)
-	if _, err = client.UpdateViewContext(ctx, r, v.State.externalID, emptyHash, v.State.previousViewID); err != nil {
-		l.Error("failed_to_home_view", zap.Error(err))
-		return nil, err
+	if !slices.Contains(append([]string{"", noHolidays, uploadedCalendar}, calendar.Names()...), v.State.calendar) {
+		errs["ROTA_CALENDAR"] = "Pick one of the holiday calendars in the list."
 	}
-	return &gen.ActionResponse{}, nil
+	if !slices.Contains([]db.NonWorkingDayPolicy{"", db.NWSkip, db.NWExtend}, v.State.nonWorkingDays) {
+		errs["ROTA_NON_WORKING_DAYS"] = "Pick what happens to the shifts that fall on a non-working day."
+	}
+	if _, err := time.LoadLocation(v.State.timeZone); err != nil || v.State.timeZone == "" {
+		errs["ROTA_TIME_ZONE"] = "This is not a valid time zone, use one like Europe/London."
+	}
+	if v.State.calendar == uploadedCalendar && v.State.calendarFile == "" {
+		errs["ROTA_CALENDAR_FILE"] = "Share an iCalendar or CSV file in Slack and paste its link here."
+	}
+	if userIDs := v.State.memberIDs(); len(userIDs) > 0 && v.State.tiers > len(userIDs) {
+		errs["ROTA_TIERS"] = "The rota doesn't have enough members to have this many on duty at once."
+	}
+	if startsAt, endsAt := v.State.period(); !startsAt.IsZero() && !endsAt.IsZero() && !endsAt.After(startsAt) {
+		errs["ROTA_ENDS"] = "The rota can't end before it starts."
+	}
+	starts := []string{}
+	for i, w := range v.State.windows {
+		if w.Name == "" {
+			errs[fmt.Sprintf("ROTA_WINDOW_NAME_%d", i)] = "Give the window a name, e.g. EMEA."
+		}
+		switch _, err := time.Parse("15:04", w.Start); {
+		case err != nil:
+			errs[fmt.Sprintf("ROTA_WINDOW_START_%d", i)] = "Pick the time at which the window starts."
+		case slices.Contains(starts, w.Start):
+			errs[fmt.Sprintf("ROTA_WINDOW_START_%d", i)] = "Another window already starts at this time."
+		}
+		starts = append(starts, w.Start)
+		switch {
+		case len(w.UserIDs) == 0:
+			errs[fmt.Sprintf("ROTA_WINDOW_MEMBERS_%d", i)] = "Pick the members that are on duty during the window."
+		case v.State.tiers > len(w.UserIDs):
+			errs[fmt.Sprintf("ROTA_WINDOW_MEMBERS_%d", i)] = "The window doesn't have enough members to have this many on duty at once."
+		}
+	}
+	if v.State.frequency == db.RFCron {
+		schedule, err := cron.Parse(v.State.cron)
+		switch {
+		case err != nil:
+			errs["ROTA_CRON"] = "This is not a valid cron expression, " + cronError(err) + "."
+		case schedule.Next(v.clock().Now().UTC()).IsZero():
+			errs["ROTA_CRON"] = "This cron expression never hands over."
+		}
+		return errs
+	}
+	if v.State.every < 1 {
+		errs["ROTA_EVERY"] = "The rota must hand over at least every 1 " + strings.TrimSuffix(frequencyUnits[v.State.frequency], "s") + "."
+	}
+	if _, err := time.Parse("15:04", v.State.handoverTime); err != nil {
+		errs["ROTA_TIME"] = "Pick the time at which the rota hands over."
+	}
+	return errs
 }
 
 func (v SaveRota) Render(ctx context.Context, p interface{}) error {
@@ -231,7 +934,7 @@ func (v SaveRota) Render(ctx context.Context, p interface{}) error {
 		return errors.New("received invalid props")
 	}
 
-	bytes, err := json.Marshal(Metadata{ChannelID: v.State.ChannelID})
+	bytes, err := json.Marshal(Metadata{RotaID: v.State.rotaID, ChannelID: v.State.ChannelID})
 	if err != nil {
 		l.Error("failed_to_marshal_metadata", zap.Error(err))
 		return err
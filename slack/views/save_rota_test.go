@@ -2,18 +2,24 @@ package views
 
 import (
 	"context"
+	"io"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/testcontainers/testcontainers-go"
 
 	"github.com/rotabot-io/rotabot/internal"
 
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/slack/announce"
 	"github.com/rotabot-io/rotabot/slack/slackclient"
 
 	"github.com/jackc/pgx/v5"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"github.com/rotabot-io/rotabot/lib/calendar"
 	"github.com/rotabot-io/rotabot/lib/db"
 	"github.com/rotabot-io/rotabot/slack/slackclient/mock_slackclient"
 	"github.com/slack-go/slack"
@@ -80,6 +86,9 @@ var _ = Describe("SaveRota", func() {
 			expectedState := &SaveRotaState{
 				frequency:      db.RFWeekly,
 				schedulingType: db.RSCreated,
+				every:          1,
+				weekday:        time.Monday,
+				handoverTime:   "09:00",
 			}
 
 			Expect(addRota.DefaultState()).To(Equal(expectedState))
@@ -113,11 +122,14 @@ var _ = Describe("SaveRota", func() {
 				Expect(props.close.Text).To(Equal("Cancel"))
 				Expect(props.submit.Text).To(Equal("Create"))
 
-				Expect(props.blocks.BlockSet).To(HaveLen(4))
+				Expect(props.blocks.BlockSet).To(HaveLen(15))
 				Expect(props.blocks.BlockSet[0]).To(BeAssignableToTypeOf(&slack.InputBlock{}))
 				Expect(props.blocks.BlockSet[1]).To(BeAssignableToTypeOf(&slack.SectionBlock{}))
-				Expect(props.blocks.BlockSet[2]).To(BeAssignableToTypeOf(&slack.SectionBlock{}))
-				Expect(props.blocks.BlockSet[3]).To(BeAssignableToTypeOf(&slack.SectionBlock{}))
+				Expect(props.blocks.BlockSet[2]).To(BeAssignableToTypeOf(&slack.InputBlock{}))
+				Expect(props.blocks.BlockSet[3]).To(BeAssignableToTypeOf(&slack.InputBlock{}))
+				Expect(props.blocks.BlockSet[4]).To(BeAssignableToTypeOf(&slack.InputBlock{}))
+				Expect(props.blocks.BlockSet[5]).To(BeAssignableToTypeOf(&slack.SectionBlock{}))
+				Expect(props.blocks.BlockSet[7]).To(BeAssignableToTypeOf(&slack.SectionBlock{}))
 
 				inputBlock := props.blocks.BlockSet[0].(*slack.InputBlock)
 				Expect(inputBlock.BlockID).To(Equal("ROTA_NAME"))
@@ -125,11 +137,97 @@ var _ = Describe("SaveRota", func() {
 				frequencySelect := props.blocks.BlockSet[1].(*slack.SectionBlock)
 				Expect(frequencySelect.BlockID).To(Equal("ROTA_FREQUENCY"))
 
-				schedulingType := props.blocks.BlockSet[2].(*slack.SectionBlock)
+				every := props.blocks.BlockSet[2].(*slack.InputBlock)
+				Expect(every.BlockID).To(Equal("ROTA_EVERY"))
+
+				handoverTime := props.blocks.BlockSet[3].(*slack.InputBlock)
+				Expect(handoverTime.BlockID).To(Equal("ROTA_TIME"))
+
+				timeZone := props.blocks.BlockSet[4].(*slack.InputBlock)
+				Expect(timeZone.BlockID).To(Equal("ROTA_TIME_ZONE"))
+
+				schedulingType := props.blocks.BlockSet[5].(*slack.SectionBlock)
 				Expect(schedulingType.BlockID).To(Equal("ROTA_TYPE"))
+				options := schedulingType.Accessory.SelectElement.Options
+				Expect(options).To(HaveLen(3))
+				Expect(options[2].Value).To(Equal(string(db.RSFair)))
 
-				userSelect := props.blocks.BlockSet[3].(*slack.SectionBlock)
+				windows := props.blocks.BlockSet[6].(*slack.SectionBlock)
+				Expect(windows.BlockID).To(Equal("ROTA_WINDOWS"))
+				Expect(windows.Accessory.SelectElement.InitialOption.Value).To(Equal("0"))
+				Expect(windows.Accessory.SelectElement.Options).To(HaveLen(4))
+
+				userSelect := props.blocks.BlockSet[7].(*slack.SectionBlock)
 				Expect(userSelect.BlockID).To(Equal("ROTA_MEMBERS"))
+
+				tiers := props.blocks.BlockSet[8].(*slack.InputBlock)
+				Expect(tiers.BlockID).To(Equal("ROTA_TIERS"))
+				Expect(tiers.Element.(*slack.NumberInputBlockElement).InitialValue).To(Equal("1"))
+
+				workingDays := props.blocks.BlockSet[9].(*slack.SectionBlock)
+				Expect(workingDays.BlockID).To(Equal("ROTA_WORKING_DAYS"))
+				Expect(workingDays.Accessory.SelectElement.InitialOption.Value).To(Equal("Every day"))
+
+				holidays := props.blocks.BlockSet[10].(*slack.SectionBlock)
+				Expect(holidays.BlockID).To(Equal("ROTA_CALENDAR"))
+				Expect(holidays.Accessory.SelectElement.InitialOption.Value).To(Equal("No holidays"))
+				Expect(holidays.Accessory.SelectElement.Options).To(HaveLen(4))
+
+				startsOn := props.blocks.BlockSet[11].(*slack.InputBlock)
+				Expect(startsOn.BlockID).To(Equal("ROTA_STARTS"))
+				Expect(startsOn.Optional).To(BeTrue())
+				endsOn := props.blocks.BlockSet[12].(*slack.InputBlock)
+				Expect(endsOn.BlockID).To(Equal("ROTA_ENDS"))
+				Expect(endsOn.Optional).To(BeTrue())
+
+				leavers := props.blocks.BlockSet[13].(*slack.SectionBlock)
+				Expect(leavers.BlockID).To(Equal("ROTA_LEAVERS"))
+				Expect(leavers.Accessory.SelectElement.InitialOption.Value).To(Equal("Stay in the rota"))
+			})
+			It("asks for the name, start and members of each window when the rota follows the sun", func() {
+				addRota.State = addRota.DefaultState().(*SaveRotaState)
+				addRota.State.TeamID = teamID
+				addRota.State.timeZone = "UTC"
+				addRota.State.windows = []db.Window{
+					{Name: "EMEA", Start: "08:00", UserIDs: []string{"U1"}},
+					{Name: "AMER", Start: "16:00", UserIDs: []string{"U2"}},
+				}
+
+				p, err := addRota.BuildProps(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				props := p.(*SaveRotaProps)
+				Expect(props.blocks.BlockSet).To(HaveLen(21))
+				windows := props.blocks.BlockSet[7].(*slack.SectionBlock)
+				Expect(windows.Accessory.SelectElement.InitialOption.Value).To(Equal("2"))
+
+				name := props.blocks.BlockSet[8].(*slack.InputBlock)
+				Expect(name.BlockID).To(Equal("ROTA_WINDOW_NAME_0"))
+				Expect(name.Element.(*slack.PlainTextInputBlockElement).InitialValue).To(Equal("EMEA"))
+				start := props.blocks.BlockSet[9].(*slack.InputBlock)
+				Expect(start.BlockID).To(Equal("ROTA_WINDOW_START_0"))
+				Expect(start.Element.(*slack.TimePickerBlockElement).InitialTime).To(Equal("08:00"))
+				members := props.blocks.BlockSet[10].(*slack.InputBlock)
+				Expect(members.BlockID).To(Equal("ROTA_WINDOW_MEMBERS_0"))
+				Expect(members.Element.(*slack.MultiSelectBlockElement).InitialUsers).To(Equal([]string{"U1"}))
+				Expect(props.blocks.BlockSet[11].(*slack.InputBlock).BlockID).To(Equal("ROTA_WINDOW_NAME_1"))
+				Expect(props.blocks.BlockSet[14].(*slack.InputBlock).BlockID).To(Equal("ROTA_TIERS"))
+			})
+			It("defaults to the time zone of the user creating the rota", func() {
+				addRota.State = addRota.DefaultState().(*SaveRotaState)
+				addRota.State.TeamID = teamID
+				addRota.State.UserID = "U123"
+				sc.EXPECT().
+					GetUserInfoContext(ctx, "U123").
+					Return(&slack.User{TZ: "Europe/London"}, nil).Times(1)
+
+				p, err := addRota.BuildProps(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				props := p.(*SaveRotaProps)
+				timeZone := props.blocks.BlockSet[5].(*slack.InputBlock)
+				Expect(timeZone.BlockID).To(Equal("ROTA_TIME_ZONE"))
+				Expect(timeZone.Element.(*slack.PlainTextInputBlockElement).InitialValue).To(Equal("Europe/London"))
 			})
 		})
 
@@ -163,11 +261,14 @@ var _ = Describe("SaveRota", func() {
 				Expect(props.close.Text).To(Equal("Cancel"))
 				Expect(props.submit.Text).To(Equal("Update"))
 
-				Expect(props.blocks.BlockSet).To(HaveLen(4))
+				Expect(props.blocks.BlockSet).To(HaveLen(15))
 				Expect(props.blocks.BlockSet[0]).To(BeAssignableToTypeOf(&slack.InputBlock{}))
 				Expect(props.blocks.BlockSet[1]).To(BeAssignableToTypeOf(&slack.SectionBlock{}))
-				Expect(props.blocks.BlockSet[2]).To(BeAssignableToTypeOf(&slack.SectionBlock{}))
-				Expect(props.blocks.BlockSet[3]).To(BeAssignableToTypeOf(&slack.SectionBlock{}))
+				Expect(props.blocks.BlockSet[2]).To(BeAssignableToTypeOf(&slack.InputBlock{}))
+				Expect(props.blocks.BlockSet[3]).To(BeAssignableToTypeOf(&slack.InputBlock{}))
+				Expect(props.blocks.BlockSet[4]).To(BeAssignableToTypeOf(&slack.InputBlock{}))
+				Expect(props.blocks.BlockSet[5]).To(BeAssignableToTypeOf(&slack.SectionBlock{}))
+				Expect(props.blocks.BlockSet[7]).To(BeAssignableToTypeOf(&slack.SectionBlock{}))
 
 				inputBlock := props.blocks.BlockSet[0].(*slack.InputBlock)
 				Expect(inputBlock.BlockID).To(Equal("ROTA_NAME"))
@@ -176,16 +277,84 @@ var _ = Describe("SaveRota", func() {
 				frequencySelect := props.blocks.BlockSet[1].(*slack.SectionBlock)
 				Expect(frequencySelect.BlockID).To(Equal("ROTA_FREQUENCY"))
 
-				schedulingType := props.blocks.BlockSet[2].(*slack.SectionBlock)
+				every := props.blocks.BlockSet[2].(*slack.InputBlock)
+				Expect(every.BlockID).To(Equal("ROTA_EVERY"))
+
+				handoverTime := props.blocks.BlockSet[3].(*slack.InputBlock)
+				Expect(handoverTime.BlockID).To(Equal("ROTA_TIME"))
+
+				timeZone := props.blocks.BlockSet[4].(*slack.InputBlock)
+				Expect(timeZone.BlockID).To(Equal("ROTA_TIME_ZONE"))
+
+				schedulingType := props.blocks.BlockSet[5].(*slack.SectionBlock)
 				Expect(schedulingType.BlockID).To(Equal("ROTA_TYPE"))
 
-				userSelect := props.blocks.BlockSet[3].(*slack.SectionBlock)
+				userSelect := props.blocks.BlockSet[7].(*slack.SectionBlock)
 				Expect(userSelect.BlockID).To(Equal("ROTA_MEMBERS"))
 			})
+
+			It("shows the first and last days of the rota in its time zone", func() {
+				loc, err := time.LoadLocation("Europe/London")
+				Expect(err).ToNot(HaveOccurred())
+				_, err = repo.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+					RotaID: addRota.State.rotaID,
+					Name:   "Test Rota",
+					Metadata: db.RotaMetadata{
+						Frequency:      db.RFMonthly,
+						SchedulingType: db.RSRandom,
+						TimeZone:       "Europe/London",
+					},
+					StartsAt: time.Date(2023, time.June, 1, 0, 0, 0, 0, loc),
+					EndsAt:   time.Date(2023, time.July, 1, 0, 0, 0, 0, loc),
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				p, err := addRota.BuildProps(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				props := p.(*SaveRotaProps)
+				Expect(props.blocks.BlockSet).To(HaveLen(15))
+				startsOn := props.blocks.BlockSet[11].(*slack.InputBlock)
+				Expect(startsOn.Element.(*slack.DatePickerBlockElement).InitialDate).To(Equal("2023-06-01"))
+				endsOn := props.blocks.BlockSet[12].(*slack.InputBlock)
+				Expect(endsOn.Element.(*slack.DatePickerBlockElement).InitialDate).To(Equal("2023-06-30"))
+			})
+
+			It("flags the members that are out during the current shift", func() {
+				Expect(repo.UpdateRotaMembers(ctx, []db.Member{
+					{RotaID: addRota.State.rotaID, UserID: "U1"},
+					{RotaID: addRota.State.rotaID, UserID: "U2"},
+				})).To(Succeed())
+				_, err := repo.CreateUnavailability(ctx, db.CreateUnavailabilityParams{
+					TeamID:   teamID,
+					UserID:   "U1",
+					StartsAt: time.Now().Add(-time.Minute),
+					EndsAt:   time.Now().Add(time.Minute),
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				p, err := addRota.BuildProps(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				props := p.(*SaveRotaProps)
+				Expect(props.blocks.BlockSet).To(HaveLen(16))
+				notice := props.blocks.BlockSet[9].(*slack.ContextBlock)
+				Expect(notice.BlockID).To(Equal("ROTA_UNAVAILABLE"))
+				text := notice.ContextElements.Elements[0].(*slack.TextBlockObject)
+				Expect(text.Text).To(Equal(":palm_tree: <@U1> is out during the current shift."))
+			})
 		})
 	})
 
 	Describe("OnAction", func() {
+		BeforeEach(func() {
+			addRota.State = addRota.DefaultState().(*SaveRotaState)
+			addRota.State.TeamID = teamID
+			addRota.State.ChannelID = channelID
+			addRota.State.viewID = "V123"
+			addRota.State.edited = true
+		})
+
 		It("returns without doing anything", func() {
 			res, err := addRota.OnAction(ctx)
 			Expect(err).ToNot(HaveOccurred())
@@ -193,6 +362,152 @@ var _ = Describe("SaveRota", func() {
 			expectedRes := &gen.ActionResponse{}
 			Expect(res).To(Equal(expectedRes))
 		})
+
+		It("shows the handover day when the frequency changes to weekly", func() {
+			addRota.State.action = "ROTA_FREQUENCY"
+			sc.EXPECT().
+				UpdateViewContext(ctx, gomock.Any(), "", "", "V123").
+				DoAndReturn(func(_ context.Context, r slack.ModalViewRequest, _, _, _ string) (*slack.ViewResponse, error) {
+					Expect(r.Blocks.BlockSet).To(HaveLen(16))
+					weekday := r.Blocks.BlockSet[3].(*slack.SectionBlock)
+					Expect(weekday.BlockID).To(Equal("ROTA_WEEKDAY"))
+					Expect(weekday.Accessory.SelectElement.InitialOption.Value).To(Equal("Monday"))
+					return nil, nil
+				}).Times(1)
+
+			res, err := addRota.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&gen.ActionResponse{}))
+		})
+
+		It("previews when a cron expression hands over", func() {
+			addRota.State.action = "ROTA_CRON"
+			addRota.State.frequency = db.RFCron
+			addRota.State.cron = "0 10 * * MON,THU"
+			// A Monday, before the rota hands over that day.
+			addRota.Clock = rotation.FixedClock(time.Date(2030, time.January, 7, 9, 0, 0, 0, time.UTC))
+			sc.EXPECT().
+				UpdateViewContext(ctx, gomock.Any(), "", "", "V123").
+				DoAndReturn(func(_ context.Context, r slack.ModalViewRequest, _, _, _ string) (*slack.ViewResponse, error) {
+					Expect(r.Blocks.BlockSet).To(HaveLen(15))
+					Expect(r.Blocks.BlockSet[2].(*slack.InputBlock).BlockID).To(Equal("ROTA_CRON"))
+					preview := r.Blocks.BlockSet[3].(*slack.ContextBlock)
+					text := preview.ContextElements.Elements[0].(*slack.TextBlockObject).Text
+					Expect(text).To(HavePrefix("Next handovers: " +
+						announce.FormatTime(time.Date(2030, time.January, 7, 10, 0, 0, 0, time.UTC)) + ", " +
+						announce.FormatTime(time.Date(2030, time.January, 10, 10, 0, 0, 0, time.UTC)),
+					))
+					return nil, nil
+				}).Times(1)
+
+			_, err := addRota.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("explains what's wrong with a cron expression", func() {
+			addRota.State.action = "ROTA_CRON"
+			addRota.State.frequency = db.RFCron
+			addRota.State.cron = "0 25 * * *"
+			sc.EXPECT().
+				UpdateViewContext(ctx, gomock.Any(), "", "", "V123").
+				DoAndReturn(func(_ context.Context, r slack.ModalViewRequest, _, _, _ string) (*slack.ViewResponse, error) {
+					preview := r.Blocks.BlockSet[3].(*slack.ContextBlock)
+					text := preview.ContextElements.Elements[0].(*slack.TextBlockObject).Text
+					Expect(text).To(Equal(`:warning: invalid hour "25"`))
+					return nil, nil
+				}).Times(1)
+
+			_, err := addRota.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("asks what happens on non-working days when they are skipped", func() {
+			addRota.State.action = "ROTA_WORKING_DAYS"
+			addRota.State.skipWeekends = true
+			sc.EXPECT().
+				UpdateViewContext(ctx, gomock.Any(), "", "", "V123").
+				DoAndReturn(func(_ context.Context, r slack.ModalViewRequest, _, _, _ string) (*slack.ViewResponse, error) {
+					Expect(r.Blocks.BlockSet).To(HaveLen(17))
+					policy := r.Blocks.BlockSet[12].(*slack.SectionBlock)
+					Expect(policy.BlockID).To(Equal("ROTA_NON_WORKING_DAYS"))
+					Expect(policy.Accessory.SelectElement.InitialOption.Value).To(Equal(string(db.NWExtend)))
+					return nil, nil
+				}).Times(1)
+
+			_, err := addRota.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("asks for the file to read holidays from", func() {
+			addRota.State.action = "ROTA_CALENDAR"
+			addRota.State.calendar = "Uploaded file"
+			sc.EXPECT().
+				UpdateViewContext(ctx, gomock.Any(), "", "", "V123").
+				DoAndReturn(func(_ context.Context, r slack.ModalViewRequest, _, _, _ string) (*slack.ViewResponse, error) {
+					Expect(r.Blocks.BlockSet).To(HaveLen(18))
+					Expect(r.Blocks.BlockSet[12].(*slack.InputBlock).BlockID).To(Equal("ROTA_CALENDAR_FILE"))
+					return nil, nil
+				}).Times(1)
+
+			_, err := addRota.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("previews who will be on duty when the members change", func() {
+			addRota.State.action = "ROTA_MEMBERS"
+			addRota.State.frequency = db.RFDaily
+			addRota.State.timeZone = "UTC"
+			addRota.State.userIds = []string{"U1", "U2"}
+			addRota.Clock = rotation.FixedClock(time.Date(2030, time.January, 7, 12, 0, 0, 0, time.UTC))
+			sc.EXPECT().
+				UpdateViewContext(ctx, gomock.Any(), "", "", "V123").
+				DoAndReturn(func(_ context.Context, r slack.ModalViewRequest, _, _, _ string) (*slack.ViewResponse, error) {
+					preview := r.Blocks.BlockSet[len(r.Blocks.BlockSet)-1].(*slack.ContextBlock)
+					Expect(preview.BlockID).To(Equal("ROTA_PREVIEW"))
+					text := preview.ContextElements.Elements[0].(*slack.TextBlockObject).Text
+					lines := strings.Split(text, "\n")
+					Expect(lines).To(HaveLen(6))
+					Expect(lines[0]).To(Equal("Upcoming shifts:"))
+					// The rota hands over daily at 09:00, so the shift taking place at noon ends the next morning.
+					Expect(lines[1]).To(HaveSuffix("until " + announce.FormatTime(time.Date(2030, time.January, 8, 9, 0, 0, 0, time.UTC))))
+					Expect(text).To(ContainSubstring(":calendar: <@U1> from"))
+					Expect(text).To(ContainSubstring(":calendar: <@U2> from"))
+					return nil, nil
+				}).Times(1)
+
+			_, err := addRota.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("explains why there's nothing to preview", func() {
+			addRota.State.action = "ROTA_TYPE"
+			addRota.State.timeZone = "UTC"
+			sc.EXPECT().
+				UpdateViewContext(ctx, gomock.Any(), "", "", "V123").
+				DoAndReturn(func(_ context.Context, r slack.ModalViewRequest, _, _, _ string) (*slack.ViewResponse, error) {
+					preview := r.Blocks.BlockSet[len(r.Blocks.BlockSet)-1].(*slack.ContextBlock)
+					text := preview.ContextElements.Elements[0].(*slack.TextBlockObject).Text
+					Expect(text).To(Equal("Once the rota has members and a valid schedule, its upcoming shifts show up here."))
+					return nil, nil
+				}).Times(1)
+
+			_, err := addRota.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("hides the handover day for other frequencies", func() {
+			addRota.State.action = "ROTA_FREQUENCY"
+			addRota.State.frequency = db.RFDaily
+			sc.EXPECT().
+				UpdateViewContext(ctx, gomock.Any(), "", "", "V123").
+				DoAndReturn(func(_ context.Context, r slack.ModalViewRequest, _, _, _ string) (*slack.ViewResponse, error) {
+					Expect(r.Blocks.BlockSet).To(HaveLen(15))
+					return nil, nil
+				}).Times(1)
+
+			_, err := addRota.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
 	})
 
 	Describe("OnClose", func() {
@@ -226,6 +541,9 @@ var _ = Describe("SaveRota", func() {
 					rotaName:       "test",
 					frequency:      db.RFWeekly,
 					schedulingType: db.RSCreated,
+					every:          1,
+					handoverTime:   "09:00",
+					timeZone:       "Europe/London",
 				}
 
 				res, err := addRota.OnSubmit(ctx)
@@ -242,15 +560,234 @@ var _ = Describe("SaveRota", func() {
 				Expect(res).To(Equal(expectedRes))
 			})
 		})
+		When("the user picks an invalid cadence", func() {
+			It("returns an error for each field", func() {
+				addRota.State = &SaveRotaState{
+					TriggerID:      triggerID,
+					ChannelID:      channelID,
+					TeamID:         teamID,
+					rotaName:       "test",
+					frequency:      db.RFDaily,
+					schedulingType: db.RSCreated,
+					timeZone:       "UTC",
+				}
+
+				res, err := addRota.OnSubmit(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				expectedResAction := string(slack.RAErrors)
+				Expect(res).To(Equal(&gen.ActionResponse{
+					ResponseAction: &expectedResAction,
+					Errors: map[string]string{
+						"ROTA_EVERY": "The rota must hand over at least every 1 day.",
+						"ROTA_TIME":  "Pick the time at which the rota hands over.",
+					},
+				}))
+			})
+		})
+		When("the user picks an invalid cron expression", func() {
+			It("returns an error for the expression", func() {
+				addRota.State = &SaveRotaState{
+					TriggerID:      triggerID,
+					ChannelID:      channelID,
+					TeamID:         teamID,
+					rotaName:       "test",
+					frequency:      db.RFCron,
+					schedulingType: db.RSCreated,
+					cron:           "0 10 * *",
+					timeZone:       "UTC",
+				}
+
+				res, err := addRota.OnSubmit(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				expectedResAction := string(slack.RAErrors)
+				Expect(res).To(Equal(&gen.ActionResponse{
+					ResponseAction: &expectedResAction,
+					Errors: map[string]string{
+						"ROTA_CRON": "This is not a valid cron expression, expected 5 fields but got 4.",
+					},
+				}))
+			})
+		})
+		When("the user wants more members on duty at once than the rota has", func() {
+			It("returns an error for the tiers", func() {
+				addRota.State = &SaveRotaState{
+					TriggerID:      triggerID,
+					ChannelID:      channelID,
+					TeamID:         teamID,
+					rotaName:       "test",
+					frequency:      db.RFDaily,
+					schedulingType: db.RSCreated,
+					every:          1,
+					handoverTime:   "09:00",
+					timeZone:       "UTC",
+					tiers:          3,
+					userIds:        []string{"U1", "U2"},
+				}
+
+				res, err := addRota.OnSubmit(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				expectedResAction := string(slack.RAErrors)
+				Expect(res).To(Equal(&gen.ActionResponse{
+					ResponseAction: &expectedResAction,
+					Errors: map[string]string{
+						"ROTA_TIERS": "The rota doesn't have enough members to have this many on duty at once.",
+					},
+				}))
+			})
+		})
+		When("the user splits the day into windows", func() {
+			BeforeEach(func() {
+				addRota.State = &SaveRotaState{
+					TriggerID:      triggerID,
+					ChannelID:      channelID,
+					TeamID:         teamID,
+					rotaName:       "test",
+					frequency:      db.RFDaily,
+					schedulingType: db.RSCreated,
+					every:          1,
+					handoverTime:   "09:00",
+					timeZone:       "UTC",
+					windows: []db.Window{
+						{Name: "EMEA", Start: "08:00", UserIDs: []string{"U1", "U2"}},
+						{Name: "AMER", Start: "16:00", UserIDs: []string{"U2", "U3"}},
+					},
+					previousViewID: "V123",
+				}
+			})
+
+			It("saves the windows and the members of all of them", func() {
+				sc.EXPECT().UpdateViewContext(ctx, gomock.Any(), "", "", "V123").Return(nil, nil).Times(1)
+
+				res, err := addRota.OnSubmit(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(&gen.ActionResponse{}))
+
+				rotas, err := repo.ListRotasByChannel(ctx, db.ListRotasByChannelParams{TeamID: teamID, ChannelID: channelID})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rotas).To(HaveLen(1))
+				Expect(rotas[0].Metadata.Windows).To(Equal(addRota.State.windows))
+
+				userIDs, err := repo.ListUserIDsByRotaID(ctx, rotas[0].ID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(userIDs).To(Equal([]string{"U1", "U2", "U3"}))
+			})
+
+			It("returns an error for each field of the windows", func() {
+				addRota.State.tiers = 2
+				addRota.State.windows = []db.Window{
+					{Name: "", Start: "08:00", UserIDs: []string{"U1", "U2"}},
+					{Name: "AMER", Start: "08:00", UserIDs: []string{"U3"}},
+					{Name: "APAC", Start: "00:00"},
+				}
+
+				res, err := addRota.OnSubmit(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				expectedResAction := string(slack.RAErrors)
+				Expect(res).To(Equal(&gen.ActionResponse{
+					ResponseAction: &expectedResAction,
+					Errors: map[string]string{
+						"ROTA_WINDOW_NAME_0":    "Give the window a name, e.g. EMEA.",
+						"ROTA_WINDOW_START_1":   "Another window already starts at this time.",
+						"ROTA_WINDOW_MEMBERS_1": "The window doesn't have enough members to have this many on duty at once.",
+						"ROTA_WINDOW_MEMBERS_2": "Pick the members that are on duty during the window.",
+					},
+				}))
+			})
+		})
+		When("the user picks when the rota starts and ends", func() {
+			BeforeEach(func() {
+				addRota.State = &SaveRotaState{
+					TriggerID:      triggerID,
+					ChannelID:      channelID,
+					TeamID:         teamID,
+					rotaName:       "test",
+					frequency:      db.RFDaily,
+					schedulingType: db.RSCreated,
+					every:          1,
+					handoverTime:   "09:00",
+					timeZone:       "Europe/London",
+					startsOn:       "2023-06-01",
+					endsOn:         "2023-06-30",
+					previousViewID: "V123",
+				}
+			})
+
+			It("runs the rota from midnight of the first day until midnight after the last one", func() {
+				sc.EXPECT().UpdateViewContext(ctx, gomock.Any(), "", "", "V123").Return(nil, nil).Times(1)
+
+				res, err := addRota.OnSubmit(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(&gen.ActionResponse{}))
+
+				loc, err := time.LoadLocation("Europe/London")
+				Expect(err).ToNot(HaveOccurred())
+				rotas, err := repo.ListRotasByChannel(ctx, db.ListRotasByChannelParams{TeamID: teamID, ChannelID: channelID})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rotas).To(HaveLen(1))
+				Expect(rotas[0].StartsAt.Time).To(BeTemporally("==", time.Date(2023, time.June, 1, 0, 0, 0, 0, loc)))
+				Expect(rotas[0].EndsAt.Time).To(BeTemporally("==", time.Date(2023, time.July, 1, 0, 0, 0, 0, loc)))
+			})
+
+			It("returns an error when the rota ends before it starts", func() {
+				addRota.State.endsOn = "2023-05-31"
+
+				res, err := addRota.OnSubmit(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				expectedResAction := string(slack.RAErrors)
+				Expect(res).To(Equal(&gen.ActionResponse{
+					ResponseAction: &expectedResAction,
+					Errors: map[string]string{
+						"ROTA_ENDS": "The rota can't end before it starts.",
+					},
+				}))
+			})
+		})
+		When("the user picks an unknown time zone", func() {
+			It("returns an error for the time zone", func() {
+				addRota.State = &SaveRotaState{
+					TriggerID:      triggerID,
+					ChannelID:      channelID,
+					TeamID:         teamID,
+					rotaName:       "test",
+					frequency:      db.RFDaily,
+					schedulingType: db.RSCreated,
+					every:          1,
+					handoverTime:   "09:00",
+					timeZone:       "Europe/Atlantis",
+				}
+
+				res, err := addRota.OnSubmit(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				expectedResAction := string(slack.RAErrors)
+				Expect(res).To(Equal(&gen.ActionResponse{
+					ResponseAction: &expectedResAction,
+					Errors: map[string]string{
+						"ROTA_TIME_ZONE": "This is not a valid time zone, use one like Europe/London.",
+					},
+				}))
+			})
+		})
 		When("the user creates a rota that does not exist", func() {
 			It("creates the rota and updates the home view", func() {
 				addRota.State = &SaveRotaState{
 					TriggerID:      triggerID,
 					ChannelID:      channelID,
 					TeamID:         teamID,
+					UserID:         "U123",
 					rotaName:       "test",
 					frequency:      db.RFWeekly,
 					schedulingType: db.RSCreated,
+					every:          1,
+					handoverTime:   "09:00",
+					timeZone:       "Europe/London",
+					tiers:          2,
+					removeLeavers:  true,
 					externalID:     "E123",
 					previousViewID: "PV123",
 				}
@@ -264,6 +801,140 @@ var _ = Describe("SaveRota", func() {
 
 				expectedRes := &gen.ActionResponse{}
 				Expect(res).To(Equal(expectedRes))
+
+				rotas, err := repo.ListRotasByChannel(ctx, db.ListRotasByChannelParams{TeamID: teamID, ChannelID: channelID})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rotas).To(HaveLen(1))
+				Expect(rotas[0].Metadata.Seed).ToNot(BeZero())
+				Expect(rotas[0].Metadata.TimeZone).To(Equal("Europe/London"))
+				Expect(rotas[0].Metadata.Tiers).To(Equal(2))
+				Expect(rotas[0].Metadata.CreatedBy).To(Equal("U123"))
+				Expect(rotas[0].Metadata.RemoveLeavers).To(BeTrue())
+			})
+
+			It("only closes the modal when it was opened from the slash command", func() {
+				view := NewCreateRota(repo, SaveRotaState{TriggerID: triggerID, ChannelID: channelID, TeamID: teamID}, "Deploys")
+				view.State.timeZone = "Europe/London"
+
+				res, err := view.OnSubmit(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(&gen.ActionResponse{}))
+
+				rotas, err := repo.ListRotasByChannel(ctx, db.ListRotasByChannelParams{TeamID: teamID, ChannelID: channelID})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rotas).To(HaveLen(1))
+				Expect(rotas[0].Name).To(Equal("Deploys"))
+			})
+		})
+		When("the user uploads holidays", func() {
+			BeforeEach(func() {
+				addRota.State = &SaveRotaState{
+					TriggerID:      triggerID,
+					ChannelID:      channelID,
+					TeamID:         teamID,
+					rotaName:       "test",
+					frequency:      db.RFDaily,
+					schedulingType: db.RSCreated,
+					every:          1,
+					handoverTime:   "09:00",
+					timeZone:       "Europe/London",
+					skipWeekends:   true,
+					calendar:       "Uploaded file",
+					calendarFile:   "https://acme.slack.com/files/U123/F0S43PZDF/holidays.csv",
+					nonWorkingDays: db.NWSkip,
+					externalID:     "E123",
+					previousViewID: "PV123",
+				}
+				sc.EXPECT().
+					GetFileInfoContext(ctx, "F0S43PZDF", 0, 0).
+					Return(&slack.File{Name: "holidays.csv", URLPrivateDownload: "https://files.slack.com/holidays.csv"}, nil, nil, nil).
+					Times(1)
+			})
+
+			It("saves the holidays in the file", func() {
+				sc.EXPECT().
+					GetFileContext(ctx, "https://files.slack.com/holidays.csv", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, w io.Writer) error {
+						_, err := w.Write([]byte("date,name\n2024-12-25,Christmas Day\n"))
+						return err
+					}).Times(1)
+				sc.EXPECT().
+					UpdateViewContext(ctx, gomock.Any(), "E123", "", "PV123").
+					Return(nil, nil).Times(1)
+
+				res, err := addRota.OnSubmit(ctx)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(&gen.ActionResponse{}))
+
+				rotas, err := repo.ListRotasByChannel(ctx, db.ListRotasByChannelParams{TeamID: teamID, ChannelID: channelID})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rotas[0].Metadata.WorkingDays).To(Equal(&db.WorkingDays{
+					SkipWeekends: true,
+					CalendarFile: "https://acme.slack.com/files/U123/F0S43PZDF/holidays.csv",
+					Holidays:     []calendar.Holiday{{Date: "2024-12-25", Name: "Christmas Day"}},
+					Policy:       db.NWSkip,
+				}))
+			})
+
+			It("returns an error when the file can't be read", func() {
+				sc.EXPECT().
+					GetFileContext(ctx, "https://files.slack.com/holidays.csv", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, w io.Writer) error {
+						_, err := w.Write([]byte("not a calendar"))
+						return err
+					}).Times(1)
+
+				res, err := addRota.OnSubmit(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				expectedResAction := string(slack.RAErrors)
+				Expect(res).To(Equal(&gen.ActionResponse{
+					ResponseAction: &expectedResAction,
+					Errors: map[string]string{
+						"ROTA_CALENDAR_FILE": "We couldn't read the holidays in this file, share an iCalendar or CSV file in Slack and paste its link here.",
+					},
+				}))
+			})
+		})
+		When("the user updates a rota", func() {
+			It("keeps the seed used for random scheduling", func() {
+				rotaID, err := repo.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+					Name:      "test",
+					TeamID:    teamID,
+					ChannelID: channelID,
+					Metadata: db.RotaMetadata{
+						Frequency:      db.RFMonthly,
+						SchedulingType: db.RSRandom,
+						Seed:           42,
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				addRota.State = &SaveRotaState{
+					TriggerID:      triggerID,
+					ChannelID:      channelID,
+					TeamID:         teamID,
+					rotaID:         rotaID,
+					rotaName:       "test",
+					frequency:      db.RFWeekly,
+					schedulingType: db.RSRandom,
+					every:          1,
+					handoverTime:   "09:00",
+					timeZone:       "Europe/London",
+					externalID:     "E123",
+					previousViewID: "PV123",
+				}
+				sc.EXPECT().
+					UpdateViewContext(ctx, gomock.Any(), "E123", "", "PV123").
+					Return(nil, nil).Times(1)
+
+				_, err = addRota.OnSubmit(ctx)
+				Expect(err).ToNot(HaveOccurred())
+
+				rota, err := repo.FindRotaByID(ctx, rotaID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rota.Metadata.Frequency).To(Equal(db.RFWeekly))
+				Expect(rota.Metadata.Seed).To(Equal(int64(42)))
 			})
 		})
 	})
@@ -0,0 +1,252 @@
+package views
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rotabot-io/rotabot/slack/announce"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/slack/block"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/goaerrors"
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+	"github.com/slack-go/slack"
+)
+
+// ScheduleAction defines the list of possible actions on the schedule view
+type ScheduleAction string
+
+const (
+	SAReorderMembers = ScheduleAction("SCHEDULE_REORDER_MEMBERS")
+	SAAddOverride    = ScheduleAction("SCHEDULE_ADD_OVERRIDE")
+
+	// scheduleSize is how many shifts the schedule view lists.
+	scheduleSize = 10
+)
+
+// Schedule lists who is on duty for the next few shifts of a rota. There's nothing to submit, but the members can
+// be reordered, or someone can cover some of the shifts, when the upcoming shifts aren't to the user's liking.
+type Schedule struct {
+	Repository db.Repository
+	State      *ScheduleState
+	// Clock resolves "now", the system clock is used when it's nil.
+	Clock rotation.Clock
+}
+
+type ScheduleState struct {
+	TriggerID string
+	ChannelID string
+	TeamID    string
+	UserID    string
+	rotaID    string
+	action    ScheduleAction
+}
+
+type ScheduleProps struct {
+	title  *slack.TextBlockObject
+	close  *slack.TextBlockObject
+	blocks slack.Blocks
+}
+
+func (v Schedule) CallbackID() ViewType {
+	return VTSchedule
+}
+
+func (v Schedule) DefaultState() interface{} {
+	return &ScheduleState{}
+}
+
+func (v Schedule) BuildProps(ctx context.Context) (interface{}, error) {
+	l := zapctx.Logger(ctx)
+	rota, err := v.Repository.FindRotaByID(ctx, v.State.rotaID)
+	if err != nil {
+		l.Error("failed_to_find", zap.Error(err))
+		return nil, err
+	}
+	engine, err := rotation.Load(ctx, v.Repository, rota, v.clock())
+	if err != nil {
+		return nil, err
+	}
+	userIDs, err := v.Repository.ListUserIDsByRotaID(ctx, v.State.rotaID)
+	if err != nil {
+		l.Error("failed_to_list_members", zap.Error(err))
+		return nil, err
+	}
+	lines, err := scheduleLines(ctx, v.Repository, rota, engine, userIDs, scheduleSize)
+	if err != nil {
+		return nil, err
+	}
+
+	text := fmt.Sprintf("*%s* has nobody to put on duty, make sure it has members and hasn't ended.", rota.Name)
+	if len(lines) > 0 {
+		text = fmt.Sprintf("Who is on duty for *%s*:\n%s", rota.Name, strings.Join(lines, "\n"))
+	}
+	return &ScheduleProps{
+		title: block.NewDefaultText("Schedule"),
+		close: block.NewDefaultText("Done"),
+		blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewSectionBlock(block.NewMarkdownText(text), nil, nil),
+			slack.NewActionBlock(
+				"SCHEDULE_ACTIONS",
+				block.NewButton(block.Button{Text: ":arrow_up_down: Reorder Members", ActionID: string(SAReorderMembers)}),
+				block.NewButton(block.Button{Text: ":arrows_counterclockwise: Add Override", ActionID: string(SAAddOverride)}),
+			),
+		}},
+	}, nil
+}
+
+func (v Schedule) clock() rotation.Clock {
+	if v.Clock == nil {
+		return rotation.SystemClock
+	}
+	return v.Clock
+}
+
+// scheduleLines describes the next n shifts of the rota, starting with the one taking place according to the
+// clock of the engine. Shifts someone covers for are marked, together with the members that are out during each of
+// them. Rotas that can't be scheduled, or have already ended, have none.
+func scheduleLines(
+	ctx context.Context,
+	repo db.Repository,
+	rota db.Rota,
+	engine *rotation.Engine,
+	userIDs []string,
+	n int,
+) ([]string, error) {
+	l := zapctx.Logger(ctx)
+	shifts, err := engine.Shifts(engine.Now(), n)
+	if err != nil {
+		l.Debug("unable_to_schedule_rota", zap.Error(err))
+		return []string{}, nil
+	}
+	if rota.EndsAt.Valid {
+		// Nobody is on duty once the rota ends.
+		shifts = slices.DeleteFunc(shifts, func(s rotation.Shift) bool { return !s.Start.Before(rota.EndsAt.Time) })
+		if len(shifts) == 0 {
+			return []string{}, nil
+		}
+		if last := &shifts[len(shifts)-1]; last.End.After(rota.EndsAt.Time) {
+			last.End = rota.EndsAt.Time
+		}
+	}
+	unavailabilities, err := repo.ListUnavailabilitiesByUserIDs(ctx, db.ListUnavailabilitiesByUserIDsParams{
+		TeamID:  rota.TeamID,
+		UserIds: userIDs,
+		Since:   db.Timestamptz(shifts[0].Start),
+		Until:   db.Timestamptz(shifts[len(shifts)-1].End),
+	})
+	if err != nil {
+		l.Error("failed_to_list_unavailabilities", zap.Error(err))
+		return nil, err
+	}
+
+	lines := []string{}
+	for _, shift := range shifts {
+		icon := ":calendar:"
+		if shift.Reason == db.SROverride {
+			icon = ":arrows_counterclockwise:"
+		}
+		line := fmt.Sprintf("%s <@%s>", icon, shift.UserID)
+		if shift.Window != "" {
+			line += " (" + shift.Window + ")"
+		}
+		line += fmt.Sprintf(" from %s until %s", announce.FormatTime(shift.Start), announce.FormatTime(shift.End))
+
+		out := []string{}
+		for _, u := range unavailabilities {
+			mention := "<@" + u.UserID + ">"
+			if u.StartsAt.Time.Before(shift.End) && u.EndsAt.Time.After(shift.Start) && !slices.Contains(out, mention) {
+				out = append(out, mention)
+			}
+		}
+		switch len(out) {
+		case 0:
+		case 1:
+			line += " · :palm_tree: " + out[0] + " is out"
+		default:
+			line += " · :palm_tree: " + strings.Join(out, ", ") + " are out"
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+func (v Schedule) OnAction(ctx context.Context) (*gen.ActionResponse, error) {
+	// Both actions push a modal on top of this one, the same way they do from the home view.
+	h := Home{
+		Repository: v.Repository,
+		State: &HomeState{
+			TriggerID: v.State.TriggerID,
+			ChannelID: v.State.ChannelID,
+			TeamID:    v.State.TeamID,
+			UserID:    v.State.UserID,
+			rotaID:    v.State.rotaID,
+		},
+	}
+	switch v.State.action {
+	case SAReorderMembers:
+		return h.handleReorderMembersAction(ctx)
+	case SAAddOverride:
+		return h.handleAddOverrideAction(ctx)
+	default:
+		zapctx.Logger(ctx).Warn("unknown_action", zap.String("action", string(v.State.action)))
+		sentry.CaptureMessage("unknown_action")
+		return nil, errors.New("unknown_action")
+	}
+}
+
+func (v Schedule) OnClose(ctx context.Context) (*gen.ActionResponse, error) {
+	zapctx.Logger(ctx).Debug("closing_view")
+	return &gen.ActionResponse{}, nil
+}
+
+func (v Schedule) OnSubmit(ctx context.Context) (*gen.ActionResponse, error) {
+	zapctx.Logger(ctx).Error("submitting_schedule_view")
+	return nil, goaerrors.NewInternalError()
+}
+
+func (v Schedule) Render(ctx context.Context, p interface{}) error {
+	l := zapctx.Logger(ctx)
+	props, ok := p.(*ScheduleProps)
+	if !ok {
+		return errors.New("received invalid props")
+	}
+
+	bytes, err := json.Marshal(Metadata{RotaID: v.State.rotaID, ChannelID: v.State.ChannelID})
+	if err != nil {
+		l.Error("failed_to_marshal_metadata", zap.Error(err))
+		return err
+	}
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return err
+	}
+	_, err = client.OpenViewContext(ctx, v.State.TriggerID, slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Title:           props.title,
+		Close:           props.close,
+		Blocks:          props.blocks,
+		CallbackID:      string(v.CallbackID()),
+		NotifyOnClose:   true,
+		ClearOnClose:    true,
+		PrivateMetadata: string(bytes),
+	})
+	if err != nil {
+		l.Error("failed_to_open_view", zap.Error(err))
+		return err
+	}
+	return nil
+}
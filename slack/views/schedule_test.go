@@ -0,0 +1,183 @@
+package views
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+
+	"github.com/rotabot-io/rotabot/internal"
+
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	"github.com/jackc/pgx/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/slack/slackclient/mock_slackclient"
+	"github.com/slack-go/slack"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"go.uber.org/mock/gomock"
+)
+
+var _ = Describe("Schedule", func() {
+	var (
+		ctx      context.Context
+		sc       *mock_slackclient.MockSlackClient
+		repo     db.Repository
+		schedule *Schedule
+		conn     *pgx.Conn
+		rotaID   string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		container, err := internal.RunContainer(ctx,
+			postgres.WithInitScripts(filepath.Join("..", "..", "assets", "structure.sql")),
+			testcontainers.WithWaitStrategy(internal.DefaultWaitStrategy()),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		connString, err := container.ConnectionString(ctx, "sslmode=disable")
+		Expect(err).ToNot(HaveOccurred())
+
+		conn, err = pgx.Connect(ctx, connString)
+		Expect(err).ToNot(HaveOccurred())
+
+		tx, err := conn.Begin(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		repo = db.New(tx)
+		rotaID, err = repo.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+			Name:      "On Call",
+			TeamID:    "TM123",
+			ChannelID: "CH123",
+			Metadata: db.RotaMetadata{
+				Frequency:      db.RFWeekly,
+				SchedulingType: db.RSCreated,
+				TimeZone:       "UTC",
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repo.UpdateRotaMembers(ctx, []db.Member{
+			{RotaID: rotaID, UserID: "alice"},
+			{RotaID: rotaID, UserID: "bob"},
+			{RotaID: rotaID, UserID: "carol"},
+		})).To(Succeed())
+
+		schedule = &Schedule{
+			Repository: repo,
+			State: &ScheduleState{
+				TriggerID: "TR123",
+				ChannelID: "CH123",
+				TeamID:    "TM123",
+				UserID:    "U123",
+				rotaID:    rotaID,
+			},
+		}
+
+		DeferCleanup(func() {
+			_ = container.Terminate(ctx)
+			_ = conn.Close(ctx)
+			_ = tx.Rollback(ctx)
+		})
+	})
+
+	// Create a mock and assign it to the sc variable at the start of each test
+	slackclient.MockSlackClient(&ctx, &sc, nil)
+
+	Describe("BuildProps", func() {
+		It("lists the upcoming shifts and who is out during them", func() {
+			// The rota hands over weekly at midnight, starting on the day it was created.
+			nextWeek := time.Now().UTC().Truncate(24 * time.Hour).Add(7 * 24 * time.Hour)
+			_, err := repo.CreateUnavailability(ctx, db.CreateUnavailabilityParams{
+				TeamID:   "TM123",
+				UserID:   "bob",
+				StartsAt: nextWeek.Add(time.Hour),
+				EndsAt:   nextWeek.Add(2 * time.Hour),
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			p, err := schedule.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			props := p.(*ScheduleProps)
+			Expect(props.title.Text).To(Equal("Schedule"))
+			Expect(props.blocks.BlockSet).To(HaveLen(2))
+
+			text := props.blocks.BlockSet[0].(*slack.SectionBlock).Text.Text
+			lines := strings.Split(text, "\n")
+			Expect(lines).To(HaveLen(scheduleSize + 1))
+			Expect(lines[0]).To(Equal("Who is on duty for *On Call*:"))
+			Expect(lines[1]).To(HavePrefix(":calendar: <@alice> from <!date^"))
+			Expect(lines[2]).To(HavePrefix(":calendar: <@carol> from <!date^"))
+			Expect(lines[2]).To(HaveSuffix(" · :palm_tree: <@bob> is out"))
+
+			actions := props.blocks.BlockSet[1].(*slack.ActionBlock)
+			Expect(actions.Elements.ElementSet[0].(*slack.ButtonBlockElement).ActionID).To(Equal(string(SAReorderMembers)))
+			Expect(actions.Elements.ElementSet[1].(*slack.ButtonBlockElement).ActionID).To(Equal(string(SAAddOverride)))
+		})
+
+		It("lists the shifts from the time of its clock", func() {
+			// A week after the rota was created, so the second shift is taking place.
+			schedule.Clock = rotation.FixedClock(time.Now().Add(7*24*time.Hour + time.Hour))
+
+			p, err := schedule.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			text := p.(*ScheduleProps).blocks.BlockSet[0].(*slack.SectionBlock).Text.Text
+			Expect(strings.Split(text, "\n")[1]).To(HavePrefix(":calendar: <@carol> from <!date^"))
+		})
+
+		It("marks the shifts someone covers for", func() {
+			_, err := repo.CreateOverride(ctx, db.CreateOverrideParams{
+				RotaID:   rotaID,
+				UserID:   "dave",
+				StartsAt: time.Now().Add(-time.Minute),
+				EndsAt:   time.Now().Add(time.Hour),
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			p, err := schedule.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			text := p.(*ScheduleProps).blocks.BlockSet[0].(*slack.SectionBlock).Text.Text
+			Expect(strings.Split(text, "\n")[1]).To(HavePrefix(":arrows_counterclockwise: <@dave> from <!date^"))
+		})
+	})
+
+	Describe("OnAction", func() {
+		It("pushes the modal to reorder the members", func() {
+			schedule.State.action = SAReorderMembers
+			sc.EXPECT().PushViewContext(ctx, "TR123", gomock.Cond(func(x any) bool {
+				view := x.(slack.ModalViewRequest)
+				return Expect(view.CallbackID).To(Equal(string(VTReorderMembers)))
+			})).Return(nil, nil).Times(1)
+
+			_, err := schedule.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("pushes the modal to add an override", func() {
+			schedule.State.action = SAAddOverride
+			sc.EXPECT().PushViewContext(ctx, "TR123", gomock.Cond(func(x any) bool {
+				view := x.(slack.ModalViewRequest)
+				return Expect(view.CallbackID).To(Equal(string(VTAddOverride)))
+			})).Return(nil, nil).Times(1)
+
+			_, err := schedule.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns an error when the action is unknown", func() {
+			schedule.State.action = "unknown"
+
+			_, err := schedule.OnAction(ctx)
+			Expect(err).To(MatchError("unknown_action"))
+		})
+	})
+})
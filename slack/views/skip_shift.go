@@ -0,0 +1,208 @@
+package views
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rotabot-io/rotabot/lib/rotas"
+	"github.com/rotabot-io/rotabot/slack/announce"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/slack/block"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+	"github.com/slack-go/slack"
+)
+
+// SkipShift lets someone skip the next shift of a member of a rota. Skipping whoever is on duty hands the rota over
+// to the next member straight away, either way the channel is told who is on duty instead.
+type SkipShift struct {
+	Repository db.Repository
+	State      *SkipShiftState
+}
+
+type SkipShiftState struct {
+	TriggerID      string
+	ChannelID      string
+	TeamID         string
+	UserID         string
+	rotaID         string
+	skipUser       string
+	reason         string
+	externalID     string
+	previousViewID string
+}
+
+type SkipShiftProps struct {
+	title  *slack.TextBlockObject
+	submit *slack.TextBlockObject
+	close  *slack.TextBlockObject
+	blocks slack.Blocks
+}
+
+func (v SkipShift) CallbackID() ViewType {
+	return VTSkipShift
+}
+
+func (v SkipShift) DefaultState() interface{} {
+	return &SkipShiftState{}
+}
+
+func (v SkipShift) BuildProps(ctx context.Context) (interface{}, error) {
+	l := zapctx.Logger(ctx)
+	rota, err := v.Repository.FindRotaByID(ctx, v.State.rotaID)
+	if err != nil {
+		l.Error("failed_to_find", zap.Error(err))
+		return nil, err
+	}
+
+	// Whoever is on duty is the one most likely to be skipped, they may have been called away.
+	if v.State.skipUser == "" {
+		engine, err := rotation.Load(ctx, v.Repository, rota, rotation.SystemClock)
+		if err != nil {
+			return nil, err
+		}
+		if shift, err := engine.Current(); err == nil && shift.Reason == db.SRScheduled {
+			v.State.skipUser = shift.UserID
+		}
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			block.NewMarkdownText(fmt.Sprintf(
+				"Pick who to skip on *%s*. Skipping whoever is on duty hands over to the next member straight away, anyone else misses their next shift.",
+				rota.Name,
+			)),
+			nil,
+			nil,
+		),
+		block.NewUserInput(block.UserInput{
+			BlockID: "SKIP_USER",
+			Label:   "Skip:",
+			UserID:  v.State.skipUser,
+		}),
+		block.NewTextInput(block.TextInput{
+			BlockID:  "SKIP_REASON",
+			Label:    "Reason:",
+			Hint:     "e.g. Out sick",
+			Value:    v.State.reason,
+			Optional: true,
+		}),
+	}
+	return &SkipShiftProps{
+		title:  block.NewDefaultText("Skip Shift"),
+		submit: block.NewDefaultText("Skip"),
+		close:  block.NewDefaultText("Cancel"),
+		blocks: slack.Blocks{BlockSet: blocks},
+	}, nil
+}
+
+func (v SkipShift) OnAction(ctx context.Context) (*gen.ActionResponse, error) {
+	// None of the fields dispatch actions, they're only read once the modal is submitted.
+	return &gen.ActionResponse{}, nil
+}
+
+func (v SkipShift) OnClose(ctx context.Context) (*gen.ActionResponse, error) {
+	zapctx.Logger(ctx).Debug("closing_view")
+	return &gen.ActionResponse{}, nil
+}
+
+func (v SkipShift) OnSubmit(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
+	if v.State.skipUser == "" {
+		return skipShiftError("Pick who to skip."), nil
+	}
+	rota, err := v.Repository.FindRotaByID(ctx, v.State.rotaID)
+	if err != nil {
+		l.Error("failed_to_find", zap.Error(err))
+		return nil, err
+	}
+	skipped, err := rotas.SkipShift(ctx, v.Repository, rota, rotas.SkipParams{
+		UserID:    v.State.skipUser,
+		CreatedBy: v.State.UserID,
+		Reason:    strings.TrimSpace(v.State.reason),
+	})
+	switch {
+	case errors.Is(err, rotas.ErrInactive):
+		return skipShiftError("Nobody is on duty while the rota is paused or after it ended."), nil
+	case errors.Is(err, rotas.ErrOverridden):
+		return skipShiftError("They're covering the rota right now, remove their override instead."), nil
+	case errors.Is(err, rotas.ErrNoUpcomingShift), errors.Is(err, rotation.ErrNoMembers), errors.Is(err, rotation.ErrNotStarted):
+		return skipShiftError("They have no upcoming shift on this rota."), nil
+	case err != nil:
+		l.Error("failed_to_skip_shift", zap.Error(err))
+		return nil, err
+	}
+
+	if err = announce.Skipped(ctx, rota, skipped); err != nil {
+		l.Error("failed_to_announce_skip", zap.Error(err))
+		return nil, err
+	}
+
+	h := Home{
+		Repository: v.Repository,
+		State: &HomeState{
+			TriggerID: v.State.TriggerID,
+			ChannelID: v.State.ChannelID,
+			TeamID:    v.State.TeamID,
+			rotaID:    v.State.rotaID,
+		},
+	}
+	if err = h.replace(ctx, v.State.externalID, v.State.previousViewID); err != nil {
+		return nil, err
+	}
+	return &gen.ActionResponse{}, nil
+}
+
+// skipShiftError shows why the member can't be skipped next to the member, slack only shows errors on inputs.
+func skipShiftError(text string) *gen.ActionResponse {
+	response := string(slack.RAErrors)
+	return &gen.ActionResponse{ResponseAction: &response, Errors: map[string]string{"SKIP_USER": text}}
+}
+
+func (v SkipShift) Render(ctx context.Context, p interface{}) error {
+	l := zapctx.Logger(ctx)
+	props, ok := p.(*SkipShiftProps)
+	if !ok {
+		return errors.New("received invalid props")
+	}
+
+	bytes, err := json.Marshal(Metadata{RotaID: v.State.rotaID, ChannelID: v.State.ChannelID})
+	if err != nil {
+		l.Error("failed_to_marshal_metadata", zap.Error(err))
+		return err
+	}
+
+	view := slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Title:           props.title,
+		Submit:          props.submit,
+		Close:           props.close,
+		Blocks:          props.blocks,
+		CallbackID:      string(v.CallbackID()),
+		NotifyOnClose:   true,
+		ClearOnClose:    true,
+		PrivateMetadata: string(bytes),
+	}
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return err
+	}
+	_, err = client.OpenViewContext(ctx, v.State.TriggerID, view)
+	if err != nil {
+		l.Error("failed_to_open_view", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package views
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/testcontainers/testcontainers-go"
+
+	"github.com/rotabot-io/rotabot/internal"
+
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	"github.com/jackc/pgx/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/slack/slackclient/mock_slackclient"
+	"github.com/slack-go/slack"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"go.uber.org/mock/gomock"
+)
+
+var _ = Describe("SkipShift", func() {
+	var (
+		ctx       context.Context
+		sc        *mock_slackclient.MockSlackClient
+		repo      db.Repository
+		skipShift *SkipShift
+		conn      *pgx.Conn
+		rotaID    string
+		channelID string
+		teamID    string
+		triggerID string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		container, err := internal.RunContainer(ctx,
+			postgres.WithInitScripts(filepath.Join("..", "..", "assets", "structure.sql")),
+			testcontainers.WithWaitStrategy(internal.DefaultWaitStrategy()),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		connString, err := container.ConnectionString(ctx, "sslmode=disable")
+		Expect(err).ToNot(HaveOccurred())
+
+		conn, err = pgx.Connect(ctx, connString)
+		Expect(err).ToNot(HaveOccurred())
+
+		tx, err := conn.Begin(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		repo = db.New(tx)
+		channelID = "CH123"
+		teamID = "TM123"
+		triggerID = "TR123"
+
+		rotaID, err = repo.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+			Name:      "On Call",
+			TeamID:    teamID,
+			ChannelID: channelID,
+			Metadata: db.RotaMetadata{
+				Frequency:      db.RFWeekly,
+				SchedulingType: db.RSCreated,
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repo.UpdateRotaMembers(ctx, []db.Member{
+			{RotaID: rotaID, UserID: "alice", Metadata: db.MemberMetadata{}},
+			{RotaID: rotaID, UserID: "bob", Metadata: db.MemberMetadata{}},
+		})).To(Succeed())
+
+		skipShift = &SkipShift{
+			Repository: repo,
+			State: &SkipShiftState{
+				TriggerID: triggerID,
+				ChannelID: channelID,
+				TeamID:    teamID,
+				UserID:    "U123",
+				rotaID:    rotaID,
+			},
+		}
+
+		DeferCleanup(func() {
+			_ = container.Terminate(ctx)
+			_ = conn.Close(ctx)
+			_ = tx.Rollback(ctx)
+		})
+	})
+
+	// Create a mock and assign it to the sc variable at the start of each test
+	slackclient.MockSlackClient(&ctx, &sc, nil)
+
+	Describe("Callback", func() {
+		It("resolves a skip shift view", func() {
+			Expect(skipShift.CallbackID()).To(Equal(VTSkipShift))
+		})
+	})
+
+	Describe("BuildProps", func() {
+		It("suggests skipping whoever is on duty", func() {
+			p, err := skipShift.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(p).To(BeAssignableToTypeOf(&SkipShiftProps{}))
+			props := p.(*SkipShiftProps)
+			Expect(props.title.Text).To(Equal("Skip Shift"))
+			Expect(props.submit.Text).To(Equal("Skip"))
+			Expect(props.blocks.BlockSet).To(HaveLen(3))
+
+			user := props.blocks.BlockSet[1].(*slack.InputBlock)
+			Expect(user.BlockID).To(Equal("SKIP_USER"))
+			Expect(user.Element.(*slack.SelectBlockElement).InitialUser).To(Equal("alice"))
+
+			reason := props.blocks.BlockSet[2].(*slack.InputBlock)
+			Expect(reason.BlockID).To(Equal("SKIP_REASON"))
+			Expect(reason.Optional).To(BeTrue())
+		})
+	})
+
+	Describe("OnSubmit", func() {
+		It("returns an error when the member has no shift on the rota", func() {
+			skipShift.State.skipUser = "carol"
+
+			res, err := skipShift.OnSubmit(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			expectedResAction := string(slack.RAErrors)
+			Expect(res).To(Equal(&gen.ActionResponse{
+				ResponseAction: &expectedResAction,
+				Errors: map[string]string{
+					"SKIP_USER": "They have no upcoming shift on this rota.",
+				},
+			}))
+		})
+
+		It("hands over to the next member, tells the channel and goes back to the home view", func() {
+			skipShift.State.skipUser = "alice"
+			skipShift.State.reason = " Out sick "
+			skipShift.State.previousViewID = "V123"
+
+			sc.EXPECT().PostMessageContext(ctx, channelID, gomock.Any()).DoAndReturn(
+				func(_ context.Context, _ string, options ...slack.MsgOption) (string, string, error) {
+					_, values, err := slack.UnsafeApplyMsgOptions("", channelID, "", options...)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(values.Get("text")).To(HavePrefix(":fast_forward: <@U123> skipped <@alice> on *On Call*, <@bob> is now on duty"))
+					Expect(values.Get("text")).To(HaveSuffix("\n>Out sick"))
+					return "", "", nil
+				},
+			).Times(1)
+			sc.EXPECT().UpdateViewContext(ctx, gomock.Any(), "", "", "V123").Return(nil, nil).Times(1)
+
+			res, err := skipShift.OnSubmit(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&gen.ActionResponse{}))
+
+			rota, err := repo.FindRotaByID(ctx, rotaID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rota.State.UserID).To(Equal("bob"))
+		})
+	})
+})
@@ -0,0 +1,308 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/jackc/pgx/v5"
+	"github.com/rotabot-io/rotabot/slack/announce"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/slack/block"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/goaerrors"
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+	"github.com/slack-go/slack"
+)
+
+// SwapRequestAction defines the list of possible answers to a request to swap shifts
+type SwapRequestAction string
+
+const (
+	SRAAccept  = SwapRequestAction("SWAP_ACCEPT")
+	SRADecline = SwapRequestAction("SWAP_DECLINE")
+)
+
+// SwapRequest is the message a member receives when a teammate asks them to swap shifts. Once they accept, each
+// of them covers the other's shift through an override.
+type SwapRequest struct {
+	Repository db.Repository
+	// Clock resolves "now", the system clock is used when it's nil.
+	Clock rotation.Clock
+	State *SwapRequestState
+}
+
+type SwapRequestState struct {
+	TeamID string
+	// UserID is whoever answered the request.
+	UserID string
+	// ChannelID and messageTS identify the message the request was answered from.
+	ChannelID   string
+	messageTS   string
+	swapID      string
+	recipientID string
+	action      SwapRequestAction
+}
+
+type SwapRequestProps struct {
+	text   string
+	blocks slack.Blocks
+}
+
+func (v SwapRequest) CallbackID() ViewType {
+	return VTSwapRequest
+}
+
+func (v SwapRequest) DefaultState() interface{} {
+	return &SwapRequestState{}
+}
+
+func (v SwapRequest) BuildProps(ctx context.Context) (interface{}, error) {
+	l := zapctx.Logger(ctx)
+	swap, err := v.Repository.FindSwapByIDForUpdate(ctx, v.State.swapID)
+	if err != nil {
+		l.Error("failed_to_find_swap", zap.Error(err))
+		return nil, err
+	}
+	rota, err := v.Repository.FindRotaByID(ctx, swap.RotaID)
+	if err != nil {
+		l.Error("failed_to_find", zap.Error(err))
+		return nil, err
+	}
+	v.State.recipientID = swap.RecipientID
+
+	text := fmt.Sprintf(
+		":left_right_arrow: <@%s> would like to swap shifts on *%s*, you'd cover their shift %s and they'd cover yours %s.",
+		swap.RequesterID,
+		rota.Name,
+		swapWindow(swap.RequesterStartsAt.Time, swap.RequesterEndsAt.Time),
+		swapWindow(swap.RecipientStartsAt.Time, swap.RecipientEndsAt.Time),
+	)
+	if !v.clock().Now().Before(swap.ExpiresAt.Time) {
+		// There's nothing left to answer, the buttons would only tell the request expired.
+		return &SwapRequestProps{
+			text: text,
+			blocks: slack.Blocks{BlockSet: []slack.Block{
+				slack.NewSectionBlock(block.NewMarkdownText(text), nil, nil),
+				slack.NewContextBlock("", block.NewMarkdownText("This request expired "+announce.FormatTime(swap.ExpiresAt.Time)+".")),
+			}},
+		}, nil
+	}
+	return &SwapRequestProps{
+		text: text,
+		blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewSectionBlock(block.NewMarkdownText(text), nil, nil),
+			slack.NewContextBlock("", block.NewMarkdownText("This request expires "+announce.FormatTime(swap.ExpiresAt.Time)+".")),
+			slack.NewActionBlock(
+				string(VTSwapRequest),
+				block.NewButton(block.Button{Text: "Accept", ActionID: string(SRAAccept), Value: swap.ID, Style: slack.StylePrimary}),
+				block.NewButton(block.Button{Text: "Decline", ActionID: string(SRADecline), Value: swap.ID, Style: slack.StyleDanger}),
+			),
+		}},
+	}, nil
+}
+
+func (v SwapRequest) clock() rotation.Clock {
+	if v.Clock == nil {
+		return rotation.SystemClock
+	}
+	return v.Clock
+}
+
+func (v SwapRequest) OnAction(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx).With(zap.String("swap_id", v.State.swapID))
+	swap, err := v.Repository.FindSwapByIDForUpdate(ctx, v.State.swapID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Swaps are deleted together with their rota.
+		return &gen.ActionResponse{}, v.answer(ctx, ":wastebasket: This rota was deleted, there's nothing left to swap.")
+	}
+	if err != nil {
+		l.Error("failed_to_find_swap", zap.Error(err))
+		return nil, err
+	}
+	if v.State.UserID != swap.RecipientID {
+		l.Warn("swap_answered_by_someone_else", zap.String("user_id", v.State.UserID))
+		return &gen.ActionResponse{}, nil
+	}
+	rota, err := v.Repository.FindRotaByID(ctx, swap.RotaID)
+	if err != nil {
+		l.Error("failed_to_find", zap.Error(err))
+		return nil, err
+	}
+
+	switch {
+	case swap.Status != db.SSPending:
+		// The request was answered already, most likely a double click.
+		return &gen.ActionResponse{}, nil
+	case !v.clock().Now().Before(swap.ExpiresAt.Time):
+		if err = v.updateStatus(ctx, swap, db.SSExpired); err != nil {
+			return nil, err
+		}
+		return &gen.ActionResponse{}, v.answer(ctx, fmt.Sprintf(
+			":hourglass: This request to swap shifts with <@%s> on *%s* expired.", swap.RequesterID, rota.Name,
+		))
+	case v.State.action == SRAAccept:
+		return &gen.ActionResponse{}, v.accept(ctx, rota, swap)
+	case v.State.action == SRADecline:
+		return &gen.ActionResponse{}, v.decline(ctx, rota, swap)
+	default:
+		l.Warn("unknown_action", zap.String("action", string(v.State.action)))
+		sentry.CaptureMessage("unknown_action")
+		return nil, errors.New("unknown_action")
+	}
+}
+
+// accept has each member of the swap cover the other's shift and lets the rota's channel know.
+func (v SwapRequest) accept(ctx context.Context, rota db.Rota, swap db.Swap) error {
+	l := zapctx.Logger(ctx)
+	overrides := []db.CreateOverrideParams{
+		{
+			RotaID:   rota.ID,
+			UserID:   swap.RecipientID,
+			StartsAt: swap.RequesterStartsAt.Time,
+			EndsAt:   swap.RequesterEndsAt.Time,
+			Metadata: db.OverrideMetadata{CreatedBy: swap.RequesterID, SwapID: swap.ID},
+		},
+		{
+			RotaID:   rota.ID,
+			UserID:   swap.RequesterID,
+			StartsAt: swap.RecipientStartsAt.Time,
+			EndsAt:   swap.RecipientEndsAt.Time,
+			Metadata: db.OverrideMetadata{CreatedBy: swap.RequesterID, SwapID: swap.ID},
+		},
+	}
+	for _, o := range overrides {
+		if _, err := v.Repository.CreateOverride(ctx, o); err != nil {
+			l.Error("failed_to_create_override", zap.Error(err))
+			return err
+		}
+	}
+	if err := v.updateStatus(ctx, swap, db.SSAccepted); err != nil {
+		return err
+	}
+	l.Info("accepted_swap", zap.String("swap_id", swap.ID))
+
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return err
+	}
+	text := fmt.Sprintf(
+		":left_right_arrow: <@%s> and <@%s> swapped shifts on *%s*, <@%s> covers %s and <@%s> covers %s.",
+		swap.RequesterID,
+		swap.RecipientID,
+		rota.Name,
+		swap.RecipientID,
+		swapWindow(swap.RequesterStartsAt.Time, swap.RequesterEndsAt.Time),
+		swap.RequesterID,
+		swapWindow(swap.RecipientStartsAt.Time, swap.RecipientEndsAt.Time),
+	)
+	if _, _, err = client.PostMessageContext(ctx, rota.ChannelID, slack.MsgOptionText(text, false)); err != nil {
+		l.Error("failed_to_announce_swap", zap.Error(err))
+		return err
+	}
+	return v.answer(ctx, fmt.Sprintf(
+		":white_check_mark: You accepted to swap shifts with <@%s> on *%s*.", swap.RequesterID, rota.Name,
+	))
+}
+
+// decline lets the member that asked for the swap know it won't happen.
+func (v SwapRequest) decline(ctx context.Context, rota db.Rota, swap db.Swap) error {
+	l := zapctx.Logger(ctx)
+	if err := v.updateStatus(ctx, swap, db.SSDeclined); err != nil {
+		return err
+	}
+	l.Info("declined_swap", zap.String("swap_id", swap.ID))
+
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return err
+	}
+	text := fmt.Sprintf(":x: <@%s> declined to swap shifts with you on *%s*.", swap.RecipientID, rota.Name)
+	if _, _, err = client.PostMessageContext(ctx, swap.RequesterID, slack.MsgOptionText(text, false)); err != nil {
+		l.Error("failed_to_notify_requester", zap.Error(err))
+		return err
+	}
+	return v.answer(ctx, fmt.Sprintf(
+		":x: You declined to swap shifts with <@%s> on *%s*.", swap.RequesterID, rota.Name,
+	))
+}
+
+func (v SwapRequest) updateStatus(ctx context.Context, swap db.Swap, status db.SwapStatus) error {
+	err := v.Repository.UpdateSwapStatus(ctx, db.UpdateSwapStatusParams{ID: swap.ID, Status: status})
+	if err != nil {
+		zapctx.Logger(ctx).Error("failed_to_update_swap_status", zap.Error(err))
+	}
+	return err
+}
+
+// answer replaces the request, and its buttons, with the given text.
+func (v SwapRequest) answer(ctx context.Context, text string) error {
+	l := zapctx.Logger(ctx)
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return err
+	}
+	_, _, _, err = client.UpdateMessageContext(ctx, v.State.ChannelID, v.State.messageTS,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionBlocks(slack.NewSectionBlock(block.NewMarkdownText(text), nil, nil)),
+	)
+	if err != nil {
+		l.Error("failed_to_update_message", zap.Error(err))
+	}
+	return err
+}
+
+func (v SwapRequest) OnClose(ctx context.Context) (*gen.ActionResponse, error) {
+	zapctx.Logger(ctx).Error("closing_swap_request")
+	return nil, goaerrors.NewInternalError()
+}
+
+func (v SwapRequest) OnSubmit(ctx context.Context) (*gen.ActionResponse, error) {
+	zapctx.Logger(ctx).Error("submitting_swap_request")
+	return nil, goaerrors.NewInternalError()
+}
+
+// Render sends the request to the member that is asked to swap shifts.
+func (v SwapRequest) Render(ctx context.Context, p interface{}) error {
+	l := zapctx.Logger(ctx)
+	props, ok := p.(*SwapRequestProps)
+	if !ok {
+		l.Error("received_invalid_props")
+		return errors.New("received invalid props")
+	}
+
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return err
+	}
+	_, _, err = client.PostMessageContext(ctx, v.State.recipientID,
+		slack.MsgOptionText(props.text, false),
+		slack.MsgOptionBlocks(props.blocks.BlockSet...),
+	)
+	if err != nil {
+		l.Error("failed_to_send_swap_request", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// swapWindow describes one of the shifts of a swap using slack's date formatting.
+func swapWindow(start, end time.Time) string {
+	return "from " + announce.FormatTime(start) + " until " + announce.FormatTime(end)
+}
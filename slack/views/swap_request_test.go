@@ -0,0 +1,208 @@
+package views
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+
+	"github.com/rotabot-io/rotabot/internal"
+
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	"github.com/jackc/pgx/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/slack/slackclient/mock_slackclient"
+	"github.com/slack-go/slack"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"go.uber.org/mock/gomock"
+)
+
+var _ = Describe("SwapRequest", func() {
+	var (
+		ctx         context.Context
+		sc          *mock_slackclient.MockSlackClient
+		repo        db.Repository
+		swapRequest *SwapRequest
+		conn        *pgx.Conn
+		rotaID      string
+		mine        time.Time
+		theirs      time.Time
+	)
+
+	createSwap := func(expiresAt time.Time) string {
+		swapID, err := repo.CreateSwap(ctx, db.CreateSwapParams{
+			RotaID:            rotaID,
+			RequesterID:       "U1",
+			RequesterStartsAt: mine,
+			RequesterEndsAt:   mine.Add(24 * time.Hour),
+			RecipientID:       "U2",
+			RecipientStartsAt: theirs,
+			RecipientEndsAt:   theirs.Add(24 * time.Hour),
+			ExpiresAt:         expiresAt,
+			Metadata:          db.SwapMetadata{},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		return swapID
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		container, err := internal.RunContainer(ctx,
+			postgres.WithInitScripts(filepath.Join("..", "..", "assets", "structure.sql")),
+			testcontainers.WithWaitStrategy(internal.DefaultWaitStrategy()),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		connString, err := container.ConnectionString(ctx, "sslmode=disable")
+		Expect(err).ToNot(HaveOccurred())
+
+		conn, err = pgx.Connect(ctx, connString)
+		Expect(err).ToNot(HaveOccurred())
+
+		tx, err := conn.Begin(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		repo = db.New(tx)
+		rotaID, err = repo.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+			Name:      "On Call",
+			TeamID:    "TM123",
+			ChannelID: "CH123",
+			Metadata: db.RotaMetadata{
+				Frequency:      db.RFDaily,
+				SchedulingType: db.RSCreated,
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		mine = time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+		theirs = mine.Add(24 * time.Hour)
+		swapRequest = &SwapRequest{
+			Repository: repo,
+			State: &SwapRequestState{
+				TeamID:    "TM123",
+				UserID:    "U2",
+				ChannelID: "D123",
+				messageTS: "1700000000.000100",
+				swapID:    createSwap(mine),
+			},
+		}
+
+		DeferCleanup(func() {
+			_ = container.Terminate(ctx)
+			_ = conn.Close(ctx)
+			_ = tx.Rollback(ctx)
+		})
+	})
+
+	// Create a mock and assign it to the sc variable at the start of each test
+	slackclient.MockSlackClient(&ctx, &sc, nil)
+
+	overrides := func() []db.Override {
+		overrides, err := repo.ListOverridesByRotaID(ctx, db.ListOverridesByRotaIDParams{
+			RotaID: rotaID,
+			Since:  db.Timestamptz(time.Now()),
+			Until:  db.Timestamptz(time.Now().Add(7 * 24 * time.Hour)),
+		})
+		Expect(err).ToNot(HaveOccurred())
+		return overrides
+	}
+
+	status := func() db.SwapStatus {
+		swap, err := repo.FindSwapByIDForUpdate(ctx, swapRequest.State.swapID)
+		Expect(err).ToNot(HaveOccurred())
+		return swap.Status
+	}
+
+	Describe("BuildProps", func() {
+		It("asks the teammate to accept or decline the swap", func() {
+			p, err := swapRequest.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			props := p.(*SwapRequestProps)
+			Expect(props.text).To(HavePrefix(":left_right_arrow: <@U1> would like to swap shifts on *On Call*"))
+			Expect(props.blocks.BlockSet).To(HaveLen(3))
+
+			actions := props.blocks.BlockSet[2].(*slack.ActionBlock)
+			Expect(actions.BlockID).To(Equal(string(VTSwapRequest)))
+			accept := actions.Elements.ElementSet[0].(*slack.ButtonBlockElement)
+			Expect(accept.ActionID).To(Equal(string(SRAAccept)))
+			Expect(accept.Value).To(Equal(swapRequest.State.swapID))
+			Expect(swapRequest.State.recipientID).To(Equal("U2"))
+		})
+
+		It("leaves the buttons out once the request expired", func() {
+			swapRequest.Clock = rotation.FixedClock(mine)
+
+			p, err := swapRequest.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			blocks := p.(*SwapRequestProps).blocks.BlockSet
+			Expect(blocks).To(HaveLen(2))
+			Expect(blocks[1].(*slack.ContextBlock).ContextElements.Elements[0].(*slack.TextBlockObject).Text).To(HavePrefix("This request expired "))
+		})
+	})
+
+	Describe("OnAction", func() {
+		It("swaps the shifts when the teammate accepts", func() {
+			swapRequest.State.action = SRAAccept
+			sc.EXPECT().PostMessageContext(ctx, "CH123", gomock.Any()).Return("", "", nil).Times(1)
+			sc.EXPECT().UpdateMessageContext(ctx, "D123", "1700000000.000100", gomock.Any(), gomock.Any()).Return("", "", "", nil).Times(1)
+
+			res, err := swapRequest.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&gen.ActionResponse{}))
+
+			Expect(status()).To(Equal(db.SSAccepted))
+			// Both overrides are created within the same transaction, so they're looked up by who covers.
+			covers := map[string]db.Override{}
+			for _, o := range overrides() {
+				covers[o.UserID] = o
+			}
+			Expect(covers).To(HaveLen(2))
+			Expect(covers["U2"].StartsAt.Time).To(BeTemporally("==", mine))
+			Expect(covers["U2"].Metadata.SwapID).To(Equal(swapRequest.State.swapID))
+			Expect(covers["U1"].StartsAt.Time).To(BeTemporally("==", theirs))
+		})
+
+		It("lets the requester know when the teammate declines", func() {
+			swapRequest.State.action = SRADecline
+			sc.EXPECT().PostMessageContext(ctx, "U1", gomock.Any()).Return("", "", nil).Times(1)
+			sc.EXPECT().UpdateMessageContext(ctx, "D123", "1700000000.000100", gomock.Any(), gomock.Any()).Return("", "", "", nil).Times(1)
+
+			_, err := swapRequest.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(status()).To(Equal(db.SSDeclined))
+			Expect(overrides()).To(BeEmpty())
+		})
+
+		It("doesn't swap the shifts once the request expired", func() {
+			swapRequest.Clock = rotation.FixedClock(mine)
+			swapRequest.State.action = SRAAccept
+			sc.EXPECT().UpdateMessageContext(ctx, "D123", "1700000000.000100", gomock.Any(), gomock.Any()).Return("", "", "", nil).Times(1)
+
+			_, err := swapRequest.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(status()).To(Equal(db.SSExpired))
+			Expect(overrides()).To(BeEmpty())
+		})
+
+		It("ignores anyone but the teammate", func() {
+			swapRequest.State.UserID = "U3"
+			swapRequest.State.action = SRAAccept
+
+			_, err := swapRequest.OnAction(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(status()).To(Equal(db.SSPending))
+		})
+	})
+})
@@ -0,0 +1,348 @@
+package views
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"go.uber.org/zap"
+
+	"github.com/rotabot-io/rotabot/slack/block"
+
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/lib/rotation"
+	"github.com/rotabot-io/rotabot/lib/zapctx"
+	"github.com/slack-go/slack"
+)
+
+// swapLookahead is how many upcoming shifts of the rota members can pick from when they swap shifts.
+const swapLookahead = 100
+
+// SwapShift lets a member propose to trade one of their upcoming shifts with a teammate. Nothing changes until
+// the teammate accepts the SwapRequest they receive.
+type SwapShift struct {
+	Repository db.Repository
+	State      *SwapShiftState
+}
+
+type SwapShiftState struct {
+	TriggerID string
+	ChannelID string
+	TeamID    string
+	UserID    string
+	rotaID    string
+	// shift and recipientShift are the unix timestamps at which the shifts being swapped start.
+	shift          string
+	recipient      string
+	recipientShift string
+	externalID     string
+	previousViewID string
+	viewID         string
+	action         string
+}
+
+type SwapShiftProps struct {
+	title  *slack.TextBlockObject
+	submit *slack.TextBlockObject
+	close  *slack.TextBlockObject
+	blocks slack.Blocks
+}
+
+func (v SwapShift) CallbackID() ViewType {
+	return VTSwapShift
+}
+
+func (v SwapShift) DefaultState() interface{} {
+	return &SwapShiftState{}
+}
+
+func (v SwapShift) BuildProps(ctx context.Context) (interface{}, error) {
+	l := zapctx.Logger(ctx)
+	rota, err := v.Repository.FindRotaByID(ctx, v.State.rotaID)
+	if err != nil {
+		l.Error("failed_to_find", zap.Error(err))
+		return nil, err
+	}
+	upcoming, err := v.upcoming(ctx, rota)
+	if err != nil {
+		return nil, err
+	}
+
+	props := &SwapShiftProps{
+		title: block.NewDefaultText("Swap Shift"),
+		close: block.NewDefaultText("Cancel"),
+	}
+	shifts := shiftOptions(upcoming, v.State.UserID)
+	if len(shifts) == 0 {
+		props.blocks = slack.Blocks{BlockSet: []slack.Block{
+			slack.NewSectionBlock(block.NewMarkdownText("You don't have any upcoming shifts on *"+rota.Name+"* to swap."), nil, nil),
+		}}
+		return props, nil
+	}
+	if v.State.shift == "" {
+		v.State.shift = shifts[0].Value
+	}
+
+	blocks := []slack.Block{
+		block.NewStaticSelect(block.StaticSelect{
+			BlockID:       "SWAP_SHIFT",
+			Label:         "Your shift:",
+			InitialOption: initialOption(shifts, v.State.shift),
+			Options:       shifts,
+		}),
+		block.NewUserInput(block.UserInput{
+			BlockID:        "SWAP_USER",
+			Label:          "Swap with:",
+			UserID:         v.State.recipient,
+			DispatchAction: true,
+		}),
+	}
+	if v.State.recipient != "" {
+		theirs := shiftOptions(upcoming, v.State.recipient)
+		if len(theirs) == 0 {
+			blocks = append(blocks, slack.NewContextBlock(
+				"SWAP_WITH",
+				block.NewMarkdownText("<@"+v.State.recipient+"> doesn't have any upcoming shifts on this rota."),
+			))
+		} else {
+			if v.State.recipientShift == "" {
+				v.State.recipientShift = theirs[0].Value
+			}
+			blocks = append(blocks, block.NewStaticSelect(block.StaticSelect{
+				BlockID:       "SWAP_WITH",
+				Label:         "Their shift:",
+				InitialOption: initialOption(theirs, v.State.recipientShift),
+				Options:       theirs,
+			}))
+		}
+	}
+	props.submit = block.NewDefaultText("Request")
+	props.blocks = slack.Blocks{BlockSet: blocks}
+	return props, nil
+}
+
+// upcoming returns the shifts of the rota members can swap, rotas that can't be scheduled have none.
+func (v SwapShift) upcoming(ctx context.Context, rota db.Rota) ([]rotation.Shift, error) {
+	engine, err := rotation.Load(ctx, v.Repository, rota, rotation.SystemClock)
+	if err != nil {
+		return nil, err
+	}
+	upcoming, err := engine.Upcoming(swapLookahead)
+	if err != nil {
+		zapctx.Logger(ctx).Debug("unable_to_schedule_rota", zap.Error(err))
+		return []rotation.Shift{}, nil
+	}
+	return upcoming, nil
+}
+
+// shiftOptions returns the options to pick one of the shifts of the given user.
+func shiftOptions(shifts []rotation.Shift, userID string) []block.StaticSelectOption {
+	options := []block.StaticSelectOption{}
+	for _, s := range shifts {
+		if s.UserID == userID {
+			options = append(options, block.StaticSelectOption{
+				Text:  formatWindow(s.Start, s.End),
+				Value: strconv.FormatInt(s.Start.Unix(), 10),
+			})
+		}
+	}
+	return options
+}
+
+func initialOption(options []block.StaticSelectOption, value string) block.StaticSelectOption {
+	for _, o := range options {
+		if o.Value == value {
+			return o
+		}
+	}
+	return options[0]
+}
+
+// findShift returns the shift of the given user that starts at the given unix timestamp.
+func findShift(shifts []rotation.Shift, userID, start string) (rotation.Shift, bool) {
+	for _, s := range shifts {
+		if s.UserID == userID && strconv.FormatInt(s.Start.Unix(), 10) == start {
+			return s, true
+		}
+	}
+	return rotation.Shift{}, false
+}
+
+func (v SwapShift) OnAction(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
+	l.Debug("action_view")
+	if v.State.action != "SWAP_USER" {
+		return &gen.ActionResponse{}, nil
+	}
+
+	// The shifts to swap with depend on who was picked, so the modal is rendered again with their shifts.
+	v.State.recipientShift = ""
+	p, err := v.BuildProps(ctx)
+	if err != nil {
+		l.Error("failed_to_build_props", zap.Error(err))
+		return nil, err
+	}
+	props, ok := p.(*SwapShiftProps)
+	if !ok {
+		l.Error("received_invalid_props")
+		return nil, errors.New("received invalid props")
+	}
+
+	bytes, err := json.Marshal(Metadata{RotaID: v.State.rotaID, ChannelID: v.State.ChannelID})
+	if err != nil {
+		l.Error("failed_to_marshal_metadata", zap.Error(err))
+		return nil, err
+	}
+
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return nil, err
+	}
+	r := slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Title:           props.title,
+		Submit:          props.submit,
+		Close:           props.close,
+		Blocks:          props.blocks,
+		CallbackID:      string(v.CallbackID()),
+		NotifyOnClose:   true,
+		ClearOnClose:    true,
+		PrivateMetadata: string(bytes),
+	}
+	if _, err = client.UpdateViewContext(ctx, r, "", "", v.State.viewID); err != nil {
+		l.Error("failed_to_update_view", zap.Error(err))
+		return nil, err
+	}
+	return &gen.ActionResponse{}, nil
+}
+
+func (v SwapShift) OnClose(ctx context.Context) (*gen.ActionResponse, error) {
+	zapctx.Logger(ctx).Debug("closing_view")
+	return &gen.ActionResponse{}, nil
+}
+
+func (v SwapShift) OnSubmit(ctx context.Context) (*gen.ActionResponse, error) {
+	l := zapctx.Logger(ctx)
+	rota, err := v.Repository.FindRotaByID(ctx, v.State.rotaID)
+	if err != nil {
+		l.Error("failed_to_find", zap.Error(err))
+		return nil, err
+	}
+	// The shifts are worked out again, the rota may have changed since the modal was opened.
+	upcoming, err := v.upcoming(ctx, rota)
+	if err != nil {
+		return nil, err
+	}
+	// Slack only shows errors next to input blocks, picking who to swap with is the only one in this modal.
+	errs := map[string]string{}
+	mine, isMine := findShift(upcoming, v.State.UserID, v.State.shift)
+	theirs, ok := findShift(upcoming, v.State.recipient, v.State.recipientShift)
+	switch {
+	case !isMine:
+		errs["SWAP_USER"] = "The shift you picked isn't yours anymore, open this again to pick another one."
+	case v.State.recipient == "":
+		errs["SWAP_USER"] = "Pick who to swap with."
+	case v.State.recipient == v.State.UserID:
+		errs["SWAP_USER"] = "Pick someone other than yourself."
+	case !ok:
+		errs["SWAP_USER"] = "Pick one of their upcoming shifts to swap with."
+	}
+	if len(errs) > 0 {
+		response := string(slack.RAErrors)
+		return &gen.ActionResponse{ResponseAction: &response, Errors: errs}, nil
+	}
+
+	// The swap makes no sense once either of the shifts started.
+	expiresAt := mine.Start
+	if theirs.Start.Before(expiresAt) {
+		expiresAt = theirs.Start
+	}
+	swapID, err := v.Repository.CreateSwap(ctx, db.CreateSwapParams{
+		RotaID:            rota.ID,
+		RequesterID:       v.State.UserID,
+		RequesterStartsAt: mine.Start,
+		RequesterEndsAt:   mine.End,
+		RecipientID:       v.State.recipient,
+		RecipientStartsAt: theirs.Start,
+		RecipientEndsAt:   theirs.End,
+		ExpiresAt:         expiresAt,
+		Metadata:          db.SwapMetadata{},
+	})
+	if err != nil {
+		l.Error("failed_to_create_swap", zap.Error(err))
+		return nil, err
+	}
+	l.Info("saved_swap", zap.String("swapId", swapID))
+
+	request := SwapRequest{
+		Repository: v.Repository,
+		State:      &SwapRequestState{TeamID: v.State.TeamID, swapID: swapID},
+	}
+	p, err := request.BuildProps(ctx)
+	if err != nil {
+		l.Error("failed_to_build_swap_request_props", zap.Error(err))
+		return nil, err
+	}
+	if err = request.Render(ctx, p); err != nil {
+		return nil, err
+	}
+
+	h := Home{
+		Repository: v.Repository,
+		State: &HomeState{
+			TriggerID: v.State.TriggerID,
+			ChannelID: v.State.ChannelID,
+			TeamID:    v.State.TeamID,
+			rotaID:    v.State.rotaID,
+		},
+	}
+	if err = h.replace(ctx, v.State.externalID, v.State.previousViewID); err != nil {
+		return nil, err
+	}
+	return &gen.ActionResponse{}, nil
+}
+
+func (v SwapShift) Render(ctx context.Context, p interface{}) error {
+	l := zapctx.Logger(ctx)
+	props, ok := p.(*SwapShiftProps)
+	if !ok {
+		return errors.New("received invalid props")
+	}
+
+	bytes, err := json.Marshal(Metadata{RotaID: v.State.rotaID, ChannelID: v.State.ChannelID})
+	if err != nil {
+		l.Error("failed_to_marshal_metadata", zap.Error(err))
+		return err
+	}
+
+	view := slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Title:           props.title,
+		Submit:          props.submit,
+		Close:           props.close,
+		Blocks:          props.blocks,
+		CallbackID:      string(v.CallbackID()),
+		NotifyOnClose:   true,
+		ClearOnClose:    true,
+		PrivateMetadata: string(bytes),
+	}
+	client, err := slackclient.ClientFor(ctx, v.State.TeamID)
+	if err != nil {
+		l.Error("failed_to_get_client", zap.Error(err))
+		sentry.CaptureException(err)
+		return err
+	}
+	_, err = client.OpenViewContext(ctx, v.State.TriggerID, view)
+	if err != nil {
+		l.Error("failed_to_open_view", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package views
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+
+	"github.com/rotabot-io/rotabot/internal"
+
+	"github.com/rotabot-io/rotabot/slack/slackclient"
+
+	"github.com/jackc/pgx/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	gen "github.com/rotabot-io/rotabot/gen/slack"
+	"github.com/rotabot-io/rotabot/lib/db"
+	"github.com/rotabot-io/rotabot/slack/slackclient/mock_slackclient"
+	"github.com/slack-go/slack"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"go.uber.org/mock/gomock"
+)
+
+var _ = Describe("SwapShift", func() {
+	var (
+		ctx       context.Context
+		sc        *mock_slackclient.MockSlackClient
+		repo      db.Repository
+		swapShift *SwapShift
+		conn      *pgx.Conn
+		rotaID    string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		container, err := internal.RunContainer(ctx,
+			postgres.WithInitScripts(filepath.Join("..", "..", "assets", "structure.sql")),
+			testcontainers.WithWaitStrategy(internal.DefaultWaitStrategy()),
+		)
+		Expect(err).ToNot(HaveOccurred())
+
+		connString, err := container.ConnectionString(ctx, "sslmode=disable")
+		Expect(err).ToNot(HaveOccurred())
+
+		conn, err = pgx.Connect(ctx, connString)
+		Expect(err).ToNot(HaveOccurred())
+
+		tx, err := conn.Begin(ctx)
+		Expect(err).ToNot(HaveOccurred())
+
+		repo = db.New(tx)
+		rotaID, err = repo.CreateOrUpdateRota(ctx, db.CreateOrUpdateRotaParams{
+			Name:      "On Call",
+			TeamID:    "TM123",
+			ChannelID: "CH123",
+			Metadata: db.RotaMetadata{
+				Frequency:      db.RFDaily,
+				SchedulingType: db.RSCreated,
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(repo.UpdateRotaMembers(ctx, []db.Member{
+			{RotaID: rotaID, UserID: "U1", Metadata: db.MemberMetadata{}},
+			{RotaID: rotaID, UserID: "U2", Metadata: db.MemberMetadata{}},
+		})).To(Succeed())
+
+		swapShift = &SwapShift{
+			Repository: repo,
+			State: &SwapShiftState{
+				TriggerID: "TR123",
+				ChannelID: "CH123",
+				TeamID:    "TM123",
+				UserID:    "U1",
+				rotaID:    rotaID,
+			},
+		}
+
+		DeferCleanup(func() {
+			_ = container.Terminate(ctx)
+			_ = conn.Close(ctx)
+			_ = tx.Rollback(ctx)
+		})
+	})
+
+	// Create a mock and assign it to the sc variable at the start of each test
+	slackclient.MockSlackClient(&ctx, &sc, nil)
+
+	Describe("BuildProps", func() {
+		It("lists the upcoming shifts of whoever wants to swap", func() {
+			p, err := swapShift.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			props := p.(*SwapShiftProps)
+			Expect(props.submit.Text).To(Equal("Request"))
+			Expect(props.blocks.BlockSet).To(HaveLen(2))
+
+			shifts := props.blocks.BlockSet[0].(*slack.SectionBlock)
+			Expect(shifts.BlockID).To(Equal("SWAP_SHIFT"))
+			Expect(shifts.Accessory.SelectElement.Options).ToNot(BeEmpty())
+
+			user := props.blocks.BlockSet[1].(*slack.InputBlock)
+			Expect(user.BlockID).To(Equal("SWAP_USER"))
+			Expect(user.DispatchAction).To(BeTrue())
+		})
+
+		It("lists the upcoming shifts of whoever was picked to swap with", func() {
+			swapShift.State.recipient = "U2"
+
+			p, err := swapShift.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			props := p.(*SwapShiftProps)
+			Expect(props.blocks.BlockSet).To(HaveLen(3))
+			theirs := props.blocks.BlockSet[2].(*slack.SectionBlock)
+			Expect(theirs.BlockID).To(Equal("SWAP_WITH"))
+			Expect(theirs.Accessory.SelectElement.Options).ToNot(BeEmpty())
+			Expect(theirs.Accessory.SelectElement.Options[0].Value).ToNot(Equal(swapShift.State.shift))
+		})
+
+		It("explains there's nothing to swap without upcoming shifts", func() {
+			swapShift.State.UserID = "U3"
+
+			p, err := swapShift.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			props := p.(*SwapShiftProps)
+			Expect(props.submit).To(BeNil())
+			Expect(props.blocks.BlockSet).To(HaveLen(1))
+		})
+	})
+
+	Describe("OnSubmit", func() {
+		BeforeEach(func() {
+			p, err := swapShift.BuildProps(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p).ToNot(BeNil())
+		})
+
+		It("returns an error when swapping with yourself", func() {
+			swapShift.State.recipient = "U1"
+			swapShift.State.recipientShift = swapShift.State.shift
+
+			res, err := swapShift.OnSubmit(ctx)
+			Expect(err).ToNot(HaveOccurred())
+
+			expectedResAction := string(slack.RAErrors)
+			Expect(res).To(Equal(&gen.ActionResponse{
+				ResponseAction: &expectedResAction,
+				Errors: map[string]string{
+					"SWAP_USER": "Pick someone other than yourself.",
+				},
+			}))
+		})
+
+		It("sends the request to the teammate and goes back to the home view", func() {
+			shift, err := strconv.ParseInt(swapShift.State.shift, 10, 64)
+			Expect(err).ToNot(HaveOccurred())
+			// Members alternate, so the teammate's shift is the day after.
+			swapShift.State.recipient = "U2"
+			swapShift.State.recipientShift = strconv.FormatInt(time.Unix(shift, 0).Add(24*time.Hour).Unix(), 10)
+			swapShift.State.previousViewID = "V123"
+
+			sc.EXPECT().PostMessageContext(ctx, "U2", gomock.Any(), gomock.Any()).Return("", "", nil).Times(1)
+			sc.EXPECT().UpdateViewContext(ctx, gomock.Any(), "", "", "V123").Return(nil, nil).Times(1)
+
+			res, err := swapShift.OnSubmit(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res).To(Equal(&gen.ActionResponse{}))
+		})
+	})
+})
@@ -2,6 +2,7 @@ package views
 
 import (
 	"context"
+	"time"
 
 	gen "github.com/rotabot-io/rotabot/gen/slack"
 )
@@ -9,8 +10,20 @@ import (
 type ViewType string
 
 const (
-	VTHome     = ViewType("Home")
-	VTSaveRota = ViewType("SaveRota")
+	VTHome           = ViewType("Home")
+	VTSaveRota       = ViewType("SaveRota")
+	VTAddOverride    = ViewType("AddOverride")
+	VTSwapShift      = ViewType("SwapShift")
+	VTReorderMembers = ViewType("ReorderMembers")
+	VTSchedule       = ViewType("Schedule")
+	VTSkipShift      = ViewType("SkipShift")
+	// VTAddUnavailability is about the user rather than a rota, its metadata only has the channel to go back to.
+	VTAddUnavailability = ViewType("AddUnavailability")
+	// VTSwapRequest is a message rather than a modal, messages don't have a callback id so the block id of their
+	// actions is used to find out which view they belong to.
+	VTSwapRequest = ViewType("SwapRequest")
+	// VTAppHome is the Home tab of the app rather than a modal, it's about the user so it has no metadata.
+	VTAppHome = ViewType("AppHome")
 )
 
 type Metadata struct {
@@ -27,3 +40,9 @@ type View interface {
 	OnSubmit(ctx context.Context) (*gen.ActionResponse, error)
 	Render(ctx context.Context, props interface{}) error
 }
+
+// formatWindow is the plain text version of a window of time, used where slack's date formatting isn't available
+// like in the options of a select.
+func formatWindow(start, end time.Time) string {
+	return start.Format("Mon 2 Jan 15:04") + " - " + end.Format("Mon 2 Jan 15:04 MST")
+}